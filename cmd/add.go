@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/history"
+	"github.com/einenlum/edicon/internal/plugins"
+
+	"github.com/spf13/cobra"
+)
+
+// newAddCmd creates the add command, which adds a new occurrence of a
+// repeated key
+func newAddCmd() *cobra.Command {
+	addCmd := &cobra.Command{
+		Use:   "add",
+		Short: "Add a parameter, even if it already exists",
+		Long: `Add a new occurrence of a parameter, for configuration types
+allowing repeated keys.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			configurator, err := plugins.GetConfiguratorFromParentCmd(cmd.Parent())
+			if err != nil {
+				panic(err)
+			}
+			key, value, files := getSetCmdArguments(args)
+
+			adder, ok := configurator.(core.ParameterAdder)
+			if !ok {
+				panic(errors.New("The add command is not supported for this configuration type"))
+			}
+
+			notationStyle := getNotationStyle(cmd)
+			editFiles(cmd, files, func(file string) (core.Configuration, []history.Change, error) {
+				change := keyChange(cmd, configurator, notationStyle, file, key)
+				change.Added = true
+				config, err := adder.AddParameter(notationStyle, file, key, value)
+
+				return config, []history.Change{change}, err
+			})
+		},
+	}
+
+	initEditFlags(addCmd)
+
+	return addCmd
+}
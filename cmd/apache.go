@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// apacheCmd represents the apache command
+var apacheCmd = &cobra.Command{
+	Use:     "apache",
+	Aliases: []string{"httpd", "htaccess"},
+	Short:   "Apache httpd configuration",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	InitCommonCommands(apacheCmd)
+}
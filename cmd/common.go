@@ -1,24 +1,68 @@
 package cmd
 
 import (
+	"errors"
+
 	"github.com/einenlum/edicon/internal/core"
 	"github.com/spf13/cobra"
 )
 
+func initNotationFlags(cmd *cobra.Command) {
+	cmd.Flags().BoolP("brackets", "b", false, "Use brackts notation \"key[foo.bar]\" instead of dot notation")
+	cmd.Flags().String("notation", "", "Notation of the keys: dot (key.foo.bar), brackets (key[foo][bar]) or pointer (/key/foo/bar)")
+}
+
 func getNotationStyle(cmd *cobra.Command) core.NotationStyle {
 	useBrackets, err := cmd.Flags().GetBool("brackets")
 	if err != nil {
 		panic(err)
 	}
-	return core.GetNotationStyle(useBrackets)
+	notation, err := cmd.Flags().GetString("notation")
+	if err != nil {
+		panic(err)
+	}
+	if notation == "" {
+		return core.GetNotationStyle(useBrackets)
+	}
+
+	notationStyle, err := core.ParseNotationStyle(notation)
+	if err != nil {
+		panic(err)
+	}
+	if useBrackets && notationStyle != core.BracketsNotation {
+		panic(errors.New("--brackets cannot be used with another --notation"))
+	}
+
+	return notationStyle
 }
 
 func InitCommonCommands(cmd *cobra.Command) {
-	cmd.AddCommand(getCmd)
-	cmd.AddCommand(setCmd)
+	cmd.AddCommand(newGetCmd())
+	cmd.AddCommand(newSetCmd())
+	cmd.AddCommand(newAddCmd())
+	cmd.AddCommand(newUnsetCmd())
+	cmd.AddCommand(newListCmd())
+	cmd.AddCommand(newQueryCmd())
 }
 
 func InitConfigCommands(cmd *cobra.Command) {
 	cmd.AddCommand(iniCmd)
 	cmd.AddCommand(phpCmd)
+	cmd.AddCommand(tomlCmd)
+	cmd.AddCommand(xmlCmd)
+	cmd.AddCommand(nginxCmd)
+	cmd.AddCommand(apacheCmd)
+	cmd.AddCommand(sshCmd)
+	cmd.AddCommand(systemdCmd)
+	cmd.AddCommand(redisCmd)
+	cmd.AddCommand(postgresqlCmd)
+	cmd.AddCommand(sysctlCmd)
+	cmd.AddCommand(hclCmd)
+	cmd.AddCommand(desktopCmd)
+	cmd.AddCommand(editorconfigCmd)
+	cmd.AddCommand(hostsCmd)
+	cmd.AddCommand(fstabCmd)
+	cmd.AddCommand(crontabCmd)
+	cmd.AddCommand(regCmd)
+	cmd.AddCommand(plistCmd)
 }
@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+
+	"github.com/spf13/cobra"
+)
+
+// conditionFailedExitCode is the exit code of a command whose condition
+// (--if-equals, --if-missing, --if-exists) is not met, so that scripts can
+// tell it from an error
+const conditionFailedExitCode = 3
+
+// conditionError is the error of a file whose key does not meet the
+// condition of the command
+type conditionError struct {
+	message string
+}
+
+func (err conditionError) Error() string {
+	return err.message
+}
+
+// initConditionFlags adds the condition flags to a command, --if-missing
+// only if the command can edit a missing key
+func initConditionFlags(cmd *cobra.Command, withIfMissing bool) {
+	cmd.Flags().String("if-equals", "", "Only edit the key if its current value is the given one")
+	cmd.Flags().Bool("if-exists", false, "Only edit the key if it exists")
+	if !withIfMissing {
+		cmd.MarkFlagsMutuallyExclusive("if-equals", "if-exists")
+		return
+	}
+	cmd.Flags().Bool("if-missing", false, "Only edit the key if it is missing")
+	cmd.MarkFlagsMutuallyExclusive("if-equals", "if-missing", "if-exists")
+}
+
+// checkIfMissing panics if --if-missing is given for a configurator which
+// cannot set a missing key, as the condition could never succeed
+func checkIfMissing(cmd *cobra.Command, configurator core.Configurator) {
+	if !cmd.Flags().Changed("if-missing") {
+		return
+	}
+	if setter, ok := configurator.(core.ExistingKeySetter); ok && setter.SetsExistingKeysOnly() {
+		panic(errors.New(fmt.Sprintf("--if-missing cannot be used with %s files, whose missing keys cannot be set", cmd.Parent().Use)))
+	}
+}
+
+func hasCondition(cmd *cobra.Command) bool {
+	for _, name := range []string{"if-equals", "if-missing", "if-exists"} {
+		if cmd.Flags().Changed(name) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// checkCondition returns a conditionError if the current value of a key
+// does not meet the condition of the command. Only a key not found is
+// missing: a file that cannot be read or parsed, or an invalid or ambiguous
+// key, fails as any other error
+func checkCondition(
+	cmd *cobra.Command,
+	configurator core.Configurator,
+	notationStyle core.NotationStyle,
+	file string,
+	key string,
+) error {
+	value, err := configurator.GetParameter(notationStyle, file, key)
+	if err != nil && !isKeyNotFound(err) {
+		return err
+	}
+	missing := err != nil
+
+	switch {
+	case cmd.Flags().Changed("if-equals"):
+		expected, err := cmd.Flags().GetString("if-equals")
+		if err != nil {
+			panic(err)
+		}
+		if missing {
+			return conditionError{fmt.Sprintf("%s is missing, not %s", key, strconv.Quote(expected))}
+		}
+		if value != expected {
+			return conditionError{fmt.Sprintf("%s is %s, not %s", key, strconv.Quote(value), strconv.Quote(expected))}
+		}
+	case cmd.Flags().Changed("if-missing"):
+		if !missing {
+			return conditionError{fmt.Sprintf("%s exists, with value %s", key, strconv.Quote(value))}
+		}
+	case cmd.Flags().Changed("if-exists"):
+		if missing {
+			return conditionError{fmt.Sprintf("%s is missing", key)}
+		}
+	}
+
+	return nil
+}
+
+// isKeyNotFound tells whether the error of GetParameter is the one of a key
+// missing from the file
+func isKeyNotFound(err error) bool {
+	return strings.HasPrefix(err.Error(), "Key not found")
+}
+
+// exitConditionNotMet prints why the condition of the command is not met
+// and exits with conditionFailedExitCode
+func exitConditionNotMet(message string) {
+	fmt.Fprintln(os.Stderr, "Condition not met: "+message)
+	os.Exit(conditionFailedExitCode)
+}
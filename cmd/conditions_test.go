@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/history"
+	"github.com/einenlum/edicon/internal/plugins"
+
+	"github.com/spf13/cobra"
+)
+
+// conditionCmd returns a command with the given condition flag set
+func conditionCmd(t *testing.T, flag string, value string) *cobra.Command {
+	t.Helper()
+
+	cmd := &cobra.Command{}
+	initConditionFlags(cmd, true)
+	if err := cmd.Flags().Set(flag, value); err != nil {
+		t.Fatal(err)
+	}
+
+	return cmd
+}
+
+func getTestConfigurator(t *testing.T, configuratorType string) core.Configurator {
+	t.Helper()
+
+	configurator, err := plugins.GetConfigurator(configuratorType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return configurator
+}
+
+func TestCheckCondition(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"redis.conf": "port 6379\n", "invalid.conf": "port\n"})
+	redisFile := filepath.Join(dir, "redis.conf")
+
+	dataProvider := []struct {
+		flag     string
+		value    string
+		key      string
+		expected string
+	}{
+		{"if-equals", "6379", "port", ""},
+		{"if-equals", "6380", "port", `port is "6379", not "6380"`},
+		{"if-equals", "::1", "bind", `bind is missing, not "::1"`},
+		{"if-missing", "true", "port", `port exists, with value "6379"`},
+		{"if-missing", "true", "bind", ""},
+		{"if-exists", "true", "port", ""},
+		{"if-exists", "true", "bind", "bind is missing"},
+	}
+
+	for _, element := range dataProvider {
+		t.Run("it checks --"+element.flag+" for "+element.key, func(t *testing.T) {
+			cmd := conditionCmd(t, element.flag, element.value)
+
+			err := checkCondition(cmd, getTestConfigurator(t, "redis"), core.DotNotation, redisFile, element.key)
+			if element.expected == "" {
+				if err != nil {
+					t.Errorf("Expected the condition to be met, got %v", err)
+				}
+				return
+			}
+			if _, isConditionError := err.(conditionError); !isConditionError || err.Error() != element.expected {
+				t.Errorf("Expected the condition error %q, got %v", element.expected, err)
+			}
+		})
+	}
+
+	errorProvider := []struct {
+		name             string
+		configuratorType string
+		file             string
+		key              string
+	}{
+		{"a file that cannot be parsed", "redis", filepath.Join(dir, "invalid.conf"), "port"},
+		{"a file that cannot be read", "redis", filepath.Join(dir, "missing.conf"), "port"},
+		{"an ambiguous key", "ini", "../data/ini/dotted.ini", "log.level"},
+	}
+
+	for _, element := range errorProvider {
+		t.Run("it fails for "+element.name, func(t *testing.T) {
+			for _, flag := range []string{"if-missing", "if-exists"} {
+				cmd := conditionCmd(t, flag, "true")
+
+				err := checkCondition(cmd, getTestConfigurator(t, element.configuratorType), core.DotNotation, element.file, element.key)
+				if err == nil {
+					t.Errorf("Expected --%s to fail", flag)
+				}
+				if _, isConditionError := err.(conditionError); isConditionError {
+					t.Errorf("Expected --%s not to fail as a condition, got %v", flag, err)
+				}
+			}
+		})
+	}
+}
+
+// setPortIf returns an edit setting the port of the redis files when they
+// meet the condition of the command
+func setPortIf(t *testing.T, cmd *cobra.Command) func(file string) (core.Configuration, []history.Change, error) {
+	configurator := getTestConfigurator(t, "redis")
+
+	return func(file string) (core.Configuration, []history.Change, error) {
+		if err := checkCondition(cmd, configurator, core.DotNotation, file, "port"); err != nil {
+			return nil, nil, err
+		}
+		config, err := configurator.SetParameter(core.DotNotation, file, "port", "6380")
+		return config, nil, err
+	}
+}
+
+func TestRunEditsWithCondition(t *testing.T) {
+	t.Setenv("EDICON_STATE_DIR", t.TempDir())
+
+	t.Run("it writes nothing when a file does not meet the condition", func(t *testing.T) {
+		dir := writeTestFiles(t, map[string]string{"a.conf": "port 1\n", "b.conf": "bind ::1\n"})
+		files := []string{filepath.Join(dir, "a.conf"), filepath.Join(dir, "b.conf")}
+
+		err := runEdits(editOptions{overwrite: true}, files, setPortIf(t, conditionCmd(t, "if-exists", "true")), &strings.Builder{})
+		if _, isConditionError := err.(conditionError); !isConditionError {
+			t.Errorf("Expected a condition error, got %v", err)
+		}
+		assertFileContents(t, files[0], "port 1\n")
+		assertFileContents(t, files[1], "bind ::1\n")
+	})
+
+	t.Run("it writes the files meeting the condition with keepGoing", func(t *testing.T) {
+		dir := writeTestFiles(t, map[string]string{"a.conf": "port 1\n", "b.conf": "bind ::1\n"})
+		files := []string{filepath.Join(dir, "a.conf"), filepath.Join(dir, "b.conf")}
+
+		err := runEdits(editOptions{overwrite: true, keepGoing: true}, files, setPortIf(t, conditionCmd(t, "if-exists", "true")), &strings.Builder{})
+		if _, isConditionError := err.(conditionError); !isConditionError || !strings.HasPrefix(err.Error(), "1 of 2 files cannot be edited") {
+			t.Errorf("Expected a condition error, got %v", err)
+		}
+		assertFileContents(t, files[0], "port 6380\n")
+		assertFileContents(t, files[1], "bind ::1\n")
+	})
+
+	t.Run("it fails when a file cannot be parsed", func(t *testing.T) {
+		dir := writeTestFiles(t, map[string]string{"a.conf": "port 1\n", "b.conf": "port\n"})
+		files := []string{filepath.Join(dir, "a.conf"), filepath.Join(dir, "b.conf")}
+
+		err := runEdits(editOptions{overwrite: true, keepGoing: true}, files, setPortIf(t, conditionCmd(t, "if-exists", "true")), &strings.Builder{})
+		if _, isConditionError := err.(conditionError); err == nil || isConditionError {
+			t.Errorf("Expected a failure not to be a condition error, got %v", err)
+		}
+		assertFileContents(t, files[0], "port 6380\n")
+		assertFileContents(t, files[1], "port\n")
+	})
+}
+
+func TestConditionExitCode(t *testing.T) {
+	if args := os.Getenv("EDICON_TEST_ARGS"); args != "" {
+		rootCmd.SetArgs(strings.Split(args, " "))
+		Execute()
+		return
+	}
+
+	dir := writeTestFiles(t, map[string]string{"redis.conf": "port 6379\n", "invalid.conf": "port\n", "php.ini": "[PHP]\nengine = On\n"})
+
+	dataProvider := []struct {
+		args     string
+		exitCode int
+		output   string
+	}{
+		{"redis set port 6380 --if-exists " + filepath.Join(dir, "redis.conf"), 0, "port 6380"},
+		{"redis set port 6380 --if-missing " + filepath.Join(dir, "redis.conf"), conditionFailedExitCode, "Condition not met"},
+		{"redis unset bind --if-exists " + filepath.Join(dir, "redis.conf"), conditionFailedExitCode, "Condition not met"},
+		{"redis set port 6380 --if-missing " + filepath.Join(dir, "invalid.conf"), 2, "missing value for port"},
+		{"php set PHP.new 1 --if-missing " + filepath.Join(dir, "php.ini"), 2, "--if-missing cannot be used with php files"},
+		{"hosts set example.com.ip 10.0.0.1 --if-missing " + filepath.Join(dir, "hosts"), 2, "--if-missing cannot be used with hosts files"},
+		{"redis unset bind --if-missing " + filepath.Join(dir, "redis.conf"), 1, "unknown flag: --if-missing"},
+	}
+
+	for _, element := range dataProvider {
+		t.Run("it exits with "+element.args, func(t *testing.T) {
+			command := exec.Command(os.Args[0], "-test.run=^TestConditionExitCode$")
+			command.Env = append(os.Environ(), "EDICON_TEST_ARGS="+element.args, "EDICON_STATE_DIR="+t.TempDir())
+
+			output, err := command.CombinedOutput()
+			exitCode := 0
+			if exitError, ok := err.(*exec.ExitError); ok {
+				exitCode = exitError.ExitCode()
+			} else if err != nil {
+				t.Fatal(err)
+			}
+			if exitCode != element.exitCode {
+				t.Errorf("Expected the exit code %d, got %d", element.exitCode, exitCode)
+			}
+			if !strings.Contains(string(output), element.output) {
+				t.Errorf("Expected the output to contain %q, got %q", element.output, string(output))
+			}
+		})
+	}
+}
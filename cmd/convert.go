@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins"
+
+	"github.com/spf13/cobra"
+)
+
+// convertCmd converts a configuration file to another format
+var convertCmd = &cobra.Command{
+	Use:   "convert --from <type> --to <type> <file>",
+	Short: "Convert a configuration file to another format",
+	Long: `Convert a configuration file to another format and print it. The file
+is read into a common tree: sections are maps and repeated keys are lists.
+
+What cannot be converted (comments, duplicate keys, types of the values...)
+is reported as warnings, or makes the conversion fail with --strict.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		from, err := cmd.Flags().GetString("from")
+		if err != nil {
+			panic(err)
+		}
+		to, err := cmd.Flags().GetString("to")
+		if err != nil {
+			panic(err)
+		}
+		strict, err := cmd.Flags().GetBool("strict")
+		if err != nil {
+			panic(err)
+		}
+
+		reader, err := plugins.GetDocumentReader(from)
+		if err != nil {
+			panic(err)
+		}
+		writer, err := plugins.GetTreeWriter(to)
+		if err != nil {
+			panic(err)
+		}
+
+		losses := &core.Losses{}
+		tree, err := reader.ReadDocument(args[0], losses)
+		if err != nil {
+			panic(err)
+		}
+		output, err := writer.WriteTree(tree, losses)
+		if err != nil {
+			panic(err)
+		}
+
+		if strict && len(losses.Messages) > 0 {
+			panic(errors.New(fmt.Sprintf(
+				"Refusing to convert %s to %s:\n  %s",
+				from, to, strings.Join(losses.Messages, "\n  "),
+			)))
+		}
+		for _, message := range losses.Messages {
+			fmt.Fprintln(os.Stderr, "warning: "+message)
+		}
+
+		fmt.Print(output)
+	},
+}
+
+func init() {
+	readable, writable := plugins.ConvertibleTypes()
+	convertCmd.Long += fmt.Sprintf(
+		"\nFiles can be read from: %s.\nFiles can be written to: %s.\n",
+		strings.Join(readable, ", "), strings.Join(writable, ", "),
+	)
+
+	convertCmd.Flags().String("from", "", "Type of the file to convert (ini, toml, json...)")
+	convertCmd.Flags().String("to", "", "Type to convert the file to (json, yaml, ini...)")
+	convertCmd.Flags().Bool("strict", false, "Fail instead of losing information")
+	convertCmd.MarkFlagRequired("from")
+	convertCmd.MarkFlagRequired("to")
+
+	rootCmd.AddCommand(convertCmd)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// crontabCmd represents the crontab command
+var crontabCmd = &cobra.Command{
+	Use:   "crontab",
+	Short: "crontab files",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	InitCommonCommands(crontabCmd)
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// desktopCmd represents the desktop command
+var desktopCmd = &cobra.Command{
+	Use:   "desktop",
+	Short: "freedesktop .desktop entries",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	InitCommonCommands(desktopCmd)
+	desktopCmd.PersistentFlags().String("locale", "", "Locale of the localized keys (e.g. fr or pt_BR), Name meaning Name[fr]")
+}
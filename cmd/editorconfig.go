@@ -0,0 +1,48 @@
+package cmd
+
+import (
+	"fmt"
+
+	"github.com/einenlum/edicon/internal/plugins/editorconfig"
+
+	"github.com/spf13/cobra"
+)
+
+// editorconfigCmd represents the editorconfig command
+var editorconfigCmd = &cobra.Command{
+	Use:   "editorconfig",
+	Short: "EditorConfig files",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// editorconfigResolveCmd prints the properties applying to a file
+var editorconfigResolveCmd = &cobra.Command{
+	Use:   "resolve <path>",
+	Short: "Print the properties applying to a file",
+	Long: `Print the properties applying to a file, once the sections matching it
+in the .editorconfig files of its directory and of the parent directories
+(up to the one with root = true) are applied, with the section each
+property comes from.
+`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		properties, err := editorconfig.ResolveProperties(args[0])
+		if err != nil {
+			panic(err)
+		}
+
+		for _, property := range properties {
+			fmt.Printf("%s = %s\t# %s\n", property.Name, property.Value, property.Source())
+		}
+	},
+}
+
+func init() {
+	InitCommonCommands(editorconfigCmd)
+	editorconfigCmd.AddCommand(editorconfigResolveCmd)
+}
@@ -0,0 +1,429 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	goio "io"
+	"os"
+	"os/user"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/history"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/plugins"
+	"github.com/einenlum/edicon/internal/transaction"
+
+	"github.com/spf13/cobra"
+)
+
+// expandFiles expands the glob patterns of the file arguments, so that
+// "/etc/php/*/*/php.ini" does not depend on the shell. An existing file is
+// never taken as a pattern.
+func expandFiles(patterns []string) ([]string, error) {
+	files := []string{}
+	seen := map[string]bool{}
+
+	for _, pattern := range patterns {
+		matches := []string{pattern}
+		if _, err := os.Stat(pattern); err != nil && strings.ContainsAny(pattern, "*?[") {
+			matches, err = filepath.Glob(pattern)
+			if err != nil {
+				return nil, errors.New(fmt.Sprintf("Invalid file pattern %s", pattern))
+			}
+			if len(matches) == 0 {
+				return nil, errors.New(fmt.Sprintf("No file matches %s", pattern))
+			}
+		}
+
+		for _, file := range matches {
+			if !seen[file] {
+				seen[file] = true
+				files = append(files, file)
+			}
+		}
+	}
+
+	return files, nil
+}
+
+// printWithFile prints each line of an output prefixed by its file, when
+// several files are given
+func printWithFile(files []string, file string, output string) {
+	fprintWithFile(os.Stdout, files, file, output)
+}
+
+func fprintWithFile(writer goio.Writer, files []string, file string, output string) {
+	if len(files) == 1 {
+		fmt.Fprintln(writer, output)
+		return
+	}
+
+	for _, line := range strings.Split(output, "\n") {
+		fmt.Fprintf(writer, "%s: %s\n", file, line)
+	}
+}
+
+func initFilesFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("keep-going", false, "Write the files which could be edited even if others could not")
+}
+
+func keepGoing(cmd *cobra.Command) bool {
+	keepGoing, err := cmd.Flags().GetBool("keep-going")
+	if err != nil {
+		panic(err)
+	}
+
+	return keepGoing
+}
+
+// fileEdit is the edited configuration of a file, nil if the file is left
+// unchanged, with the changes of its keys for the change log. The file is
+// only written if it still matches its fingerprint, when there is one.
+type fileEdit struct {
+	file        string
+	config      core.Configuration
+	changes     []history.Change
+	fingerprint *io.Fingerprint
+}
+
+func initLockFlags(cmd *cobra.Command) {
+	cmd.Flags().Bool("wait", false, "Wait for the files being edited by another process, instead of failing")
+	cmd.Flags().Duration("timeout", 0, "Maximum time to wait for the files with --wait (e.g. 30s), no limit by default")
+}
+
+// lockOptions are the --wait and --timeout flags of the commands writing
+// files
+type lockOptions struct {
+	wait    bool
+	timeout time.Duration
+}
+
+func getLockOptions(cmd *cobra.Command) lockOptions {
+	wait, err := cmd.Flags().GetBool("wait")
+	if err != nil {
+		panic(err)
+	}
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		panic(err)
+	}
+
+	return lockOptions{wait, timeout}
+}
+
+// lockFiles locks the files to write for their whole read-modify-write
+// cycle, in a fixed order so that two processes cannot wait for each other.
+// The files which do not exist yet are locked too, as long as their
+// directory exists, so that two processes cannot both create them. It
+// returns the function releasing the locks.
+func lockFiles(options lockOptions, files []string) (func(), error) {
+	sorted := []string{}
+	for _, file := range files {
+		if _, err := os.Stat(filepath.Dir(file)); err == nil {
+			sorted = append(sorted, absolutePath(file))
+		}
+	}
+	sort.Strings(sorted)
+
+	locks := []*io.Lock{}
+	unlock := func() {
+		for _, lock := range locks {
+			lock.Unlock()
+		}
+	}
+	for _, file := range sorted {
+		lock, err := io.LockFile(file, options.wait || options.timeout > 0, options.timeout)
+		if err != nil {
+			unlock()
+			return nil, err
+		}
+		locks = append(locks, lock)
+	}
+
+	return unlock, nil
+}
+
+// getFingerprint returns the fingerprint of a file about to be read, which
+// records its absence if it does not exist, nil if it cannot be read
+func getFingerprint(file string) *io.Fingerprint {
+	fingerprint, err := io.GetFingerprint(file)
+	if err != nil {
+		return nil
+	}
+
+	return &fingerprint
+}
+
+// editOptions are the flags of the commands editing several files
+type editOptions struct {
+	overwrite  bool
+	keepGoing  bool
+	outputType core.OutputType
+	lock       lockOptions
+}
+
+func getEditOptions(cmd *cobra.Command) editOptions {
+	return editOptions{
+		overwrite:  shouldOverwrite(cmd),
+		keepGoing:  keepGoing(cmd),
+		outputType: getOutputType(cmd),
+		lock:       getLockOptions(cmd),
+	}
+}
+
+// editFiles runs the edits of a command, exiting with
+// conditionFailedExitCode when the files are left unchanged only because of
+// the condition of the command
+func editFiles(
+	cmd *cobra.Command,
+	files []string,
+	edit func(file string) (core.Configuration, []history.Change, error),
+) {
+	err := runEdits(getEditOptions(cmd), files, edit, os.Stdout)
+	if _, isConditionError := err.(conditionError); isConditionError {
+		exitConditionNotMet(err.Error())
+	}
+	if err != nil {
+		panic(err)
+	}
+}
+
+// runEdits edits all the files before outputting any of them: if a file
+// cannot be edited (it cannot be parsed, it lacks the key...), nothing is
+// written, unless keepGoing is set. The edited files are printed to writer
+// when they are not overwritten.
+func runEdits(
+	options editOptions,
+	files []string,
+	edit func(file string) (core.Configuration, []history.Change, error),
+	writer goio.Writer,
+) error {
+	if options.overwrite {
+		unlock, err := lockFiles(options.lock, files)
+		if err != nil {
+			return err
+		}
+		defer unlock()
+	}
+
+	edits := []fileEdit{}
+	failures := []string{}
+	conditionFailures := 0
+	for _, file := range files {
+		fingerprint := getFingerprint(file)
+		config, changes, err := edit(file)
+		if err != nil {
+			if len(files) == 1 {
+				return err
+			}
+			if _, isConditionError := err.(conditionError); isConditionError {
+				conditionFailures++
+			}
+			failures = append(failures, fmt.Sprintf("%s: %s", file, err.Error()))
+			continue
+		}
+		edits = append(edits, fileEdit{file, config, changes, fingerprint})
+	}
+
+	// the files left unchanged only because of the condition of the command
+	// are reported as such
+	failed := func(message string) error {
+		if conditionFailures == len(failures) {
+			return conditionError{message}
+		}
+		return errors.New(message)
+	}
+
+	if len(failures) > 0 && !options.keepGoing {
+		return failed(fmt.Sprintf(
+			"Nothing was written, %d of %d files cannot be edited:\n  %s",
+			len(failures), len(files), strings.Join(failures, "\n  "),
+		))
+	}
+
+	if options.overwrite {
+		if err := writeEdits(edits, options.outputType); err != nil {
+			return err
+		}
+	} else {
+		for _, edit := range edits {
+			if err := printEdit(writer, files, edit, options.outputType); err != nil {
+				return err
+			}
+		}
+	}
+
+	if len(failures) > 0 {
+		return failed(fmt.Sprintf(
+			"%d of %d files cannot be edited:\n  %s",
+			len(failures), len(files), strings.Join(failures, "\n  "),
+		))
+	}
+
+	return nil
+}
+
+// printEdit prints an edited file, or the file as is when it is left
+// unchanged
+func printEdit(writer goio.Writer, files []string, edit fileEdit, outputType core.OutputType) error {
+	var output string
+	var err error
+	if edit.config == nil {
+		output, err = io.GetFileContents(edit.file)
+	} else {
+		output, err = edit.config.OutputFile(outputType)
+	}
+	if err != nil {
+		return err
+	}
+
+	fprintWithFile(writer, files, edit.file, strings.TrimSuffix(output, "\n"))
+
+	return nil
+}
+
+// writeEdits writes the edited files in a single transaction, which can be
+// rolled back with the rollback command. The transactions interrupted before
+// are rolled back first.
+func writeEdits(edits []fileEdit, outputType core.OutputType) error {
+	recovered, errs := transaction.Recover()
+	for _, tx := range recovered {
+		fmt.Fprintln(os.Stderr, "warning: the files of the interrupted transaction "+tx.ID+" were restored")
+	}
+	for _, err := range errs {
+		fmt.Fprintln(os.Stderr, "warning: "+err.Error())
+	}
+
+	tx, err := transaction.Begin(commandLine())
+	if err != nil {
+		return err
+	}
+
+	for _, edit := range edits {
+		if edit.config == nil {
+			continue
+		}
+		config := edit.config
+		err := tx.Stage(edit.file, func(tempFilePath string) error {
+			return config.WriteToFile(tempFilePath, outputType)
+		})
+		if err != nil {
+			tx.Abort()
+			return err
+		}
+	}
+
+	if len(tx.Entries) == 0 {
+		tx.Abort()
+		return nil
+	}
+	for _, edit := range edits {
+		if edit.config == nil || edit.fingerprint == nil {
+			continue
+		}
+		if err := io.CheckUnchanged(edit.file, *edit.fingerprint); err != nil {
+			tx.Abort()
+			return errors.New(err.Error() + ", nothing was written")
+		}
+	}
+	if err := tx.Commit(); err != nil {
+		return err
+	}
+
+	changes := []history.Change{}
+	for _, edit := range edits {
+		if edit.config != nil {
+			changes = append(changes, writtenChanges(edit)...)
+		}
+	}
+	if len(changes) > 0 {
+		if err := history.Record(tx, currentUser(), changes); err != nil {
+			fmt.Fprintln(os.Stderr, "warning: the changes cannot be recorded in the change log: "+err.Error())
+		}
+	}
+
+	return nil
+}
+
+// keyChange returns the change of a key about to be edited, with its
+// current value
+func keyChange(
+	cmd *cobra.Command,
+	configurator core.Configurator,
+	notationStyle core.NotationStyle,
+	file string,
+	key string,
+) history.Change {
+	change := history.Change{
+		File:     absolutePath(file),
+		Type:     cmd.Parent().Use,
+		Notation: notationStyle.String(),
+		Key:      key,
+	}
+
+	value, err := configurator.GetParameter(notationStyle, file, key)
+	change.Old, change.OldMissing = value, err != nil
+
+	return change
+}
+
+// writtenChanges returns the changes of a file once written, with the new
+// values of their keys
+func writtenChanges(edit fileEdit) []history.Change {
+	changes := []history.Change{}
+	for _, change := range edit.changes {
+		configurator, err := plugins.GetConfigurator(change.Type)
+		if err != nil {
+			continue
+		}
+		notationStyle, err := core.ParseNotationStyle(change.Notation)
+		if err != nil {
+			continue
+		}
+
+		value, err := configurator.GetParameter(notationStyle, edit.file, change.Key)
+		change.New, change.NewMissing = value, err != nil
+		changes = append(changes, change)
+	}
+
+	return changes
+}
+
+// absolutePath returns the absolute path of a file, symbolic links resolved
+func absolutePath(file string) string {
+	if resolved, err := filepath.EvalSymlinks(file); err == nil {
+		file = resolved
+	}
+	if absolute, err := filepath.Abs(file); err == nil {
+		file = absolute
+	}
+
+	return file
+}
+
+func currentUser() string {
+	if current, err := user.Current(); err == nil {
+		return current.Username
+	}
+
+	return os.Getenv("USER")
+}
+
+// commandLine returns the command line of edicon, quoting the arguments
+// which need it
+func commandLine() string {
+	args := []string{}
+	for _, arg := range os.Args {
+		if arg == "" || strings.ContainsAny(arg, " \t\n'\"\\$*?[") {
+			arg = strconv.Quote(arg)
+		}
+		args = append(args, arg)
+	}
+
+	return strings.Join(args, " ")
+}
@@ -0,0 +1,187 @@
+package cmd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/history"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/plugins"
+)
+
+func writeTestFiles(t *testing.T, contents map[string]string) string {
+	t.Helper()
+
+	dir := t.TempDir()
+	for name, content := range contents {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func TestExpandFiles(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"a.conf": "", "b.conf": "", "c.ini": "", "d[1].conf": ""})
+	path := func(name string) string {
+		return filepath.Join(dir, name)
+	}
+
+	dataProvider := []struct {
+		patterns []string
+		expected []string
+	}{
+		{[]string{path("*.conf")}, []string{path("a.conf"), path("b.conf"), path("d[1].conf")}},
+		{[]string{path("c.ini"), path("*.ini"), path("?.conf")}, []string{path("c.ini"), path("a.conf"), path("b.conf")}},
+		// An existing file is not a pattern, and a missing one is kept
+		{[]string{path("d[1].conf"), path("missing.conf")}, []string{path("d[1].conf"), path("missing.conf")}},
+	}
+
+	for _, element := range dataProvider {
+		files, err := expandFiles(element.patterns)
+		if err != nil || !reflect.DeepEqual(files, element.expected) {
+			t.Errorf("Expected %v to expand to %v, got %v (%v)", element.patterns, element.expected, files, err)
+		}
+	}
+
+	for _, pattern := range []string{path("*.toml"), path("[.conf")} {
+		if files, err := expandFiles([]string{pattern}); err == nil {
+			t.Errorf("Expected %s not to be expanded, got %v", pattern, files)
+		}
+	}
+}
+
+// setPort returns an edit setting the port of the redis files, and failing
+// for the files without one
+func setPort(t *testing.T) func(file string) (core.Configuration, []history.Change, error) {
+	configurator, err := plugins.GetConfigurator("redis")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return func(file string) (core.Configuration, []history.Change, error) {
+		if _, err := configurator.GetParameter(core.DotNotation, file, "port"); err != nil {
+			return nil, nil, err
+		}
+		config, err := configurator.SetParameter(core.DotNotation, file, "port", "6380")
+		return config, nil, err
+	}
+}
+
+func assertFileContents(t *testing.T, file string, expected string) {
+	t.Helper()
+
+	content, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != expected {
+		t.Errorf("Expected %s to be %q, got %q", file, expected, string(content))
+	}
+}
+
+func TestRunEdits(t *testing.T) {
+	t.Setenv("EDICON_STATE_DIR", t.TempDir())
+
+	t.Run("it prints the edited file", func(t *testing.T) {
+		dir := writeTestFiles(t, map[string]string{"redis.conf": "port 6379\n"})
+		file := filepath.Join(dir, "redis.conf")
+
+		output := strings.Builder{}
+		err := runEdits(editOptions{}, []string{file}, setPort(t), &output)
+		if err != nil || output.String() != "port 6380\n" {
+			t.Errorf("Expected the edited file, got %q (%v)", output.String(), err)
+		}
+		assertFileContents(t, file, "port 6379\n")
+	})
+
+	t.Run("it prints the edited files prefixed by their file", func(t *testing.T) {
+		dir := writeTestFiles(t, map[string]string{"a.conf": "port 1\n", "b.conf": "port 2\n"})
+		files := []string{filepath.Join(dir, "a.conf"), filepath.Join(dir, "b.conf")}
+
+		output := strings.Builder{}
+		err := runEdits(editOptions{}, files, setPort(t), &output)
+		expected := files[0] + ": port 6380\n" + files[1] + ": port 6380\n"
+		if err != nil || output.String() != expected {
+			t.Errorf("Expected %q, got %q (%v)", expected, output.String(), err)
+		}
+	})
+
+	t.Run("it writes nothing when a file cannot be edited", func(t *testing.T) {
+		dir := writeTestFiles(t, map[string]string{"a.conf": "port 1\n", "b.conf": "bind ::1\n"})
+		files := []string{filepath.Join(dir, "a.conf"), filepath.Join(dir, "b.conf")}
+
+		err := runEdits(editOptions{overwrite: true}, files, setPort(t), &strings.Builder{})
+		if err == nil || !strings.HasPrefix(err.Error(), "Nothing was written, 1 of 2 files cannot be edited") {
+			t.Errorf("Expected nothing to be written, got %v", err)
+		}
+		if _, isConditionError := err.(conditionError); isConditionError {
+			t.Error("Expected a failure not to be a condition error")
+		}
+		assertFileContents(t, files[0], "port 1\n")
+		assertFileContents(t, files[1], "bind ::1\n")
+	})
+
+	t.Run("it writes the other files with keepGoing", func(t *testing.T) {
+		dir := writeTestFiles(t, map[string]string{"a.conf": "port 1\n", "b.conf": "bind ::1\n"})
+		files := []string{filepath.Join(dir, "a.conf"), filepath.Join(dir, "b.conf")}
+
+		err := runEdits(editOptions{overwrite: true, keepGoing: true}, files, setPort(t), &strings.Builder{})
+		if err == nil || !strings.HasPrefix(err.Error(), "1 of 2 files cannot be edited") {
+			t.Errorf("Expected the failure to be reported, got %v", err)
+		}
+		assertFileContents(t, files[0], "port 6380\n")
+		assertFileContents(t, files[1], "bind ::1\n")
+	})
+
+	t.Run("it reports files left unchanged by a condition as such", func(t *testing.T) {
+		dir := writeTestFiles(t, map[string]string{"a.conf": "port 1\n", "b.conf": "port 2\n"})
+		files := []string{filepath.Join(dir, "a.conf"), filepath.Join(dir, "b.conf")}
+		edit := func(file string) (core.Configuration, []history.Change, error) {
+			return nil, nil, conditionError{"port exists"}
+		}
+
+		err := runEdits(editOptions{overwrite: true}, files, edit, &strings.Builder{})
+		if _, isConditionError := err.(conditionError); !isConditionError {
+			t.Errorf("Expected a condition error, got %v", err)
+		}
+	})
+
+	t.Run("it returns the error of a single file as is", func(t *testing.T) {
+		dir := writeTestFiles(t, map[string]string{"a.conf": "port 1\n"})
+		expected := errors.New("Key not found")
+		edit := func(file string) (core.Configuration, []history.Change, error) {
+			return nil, nil, expected
+		}
+
+		if err := runEdits(editOptions{}, []string{filepath.Join(dir, "a.conf")}, edit, &strings.Builder{}); err != expected {
+			t.Errorf("Expected %v, got %v", expected, err)
+		}
+	})
+}
+
+func TestLockFiles(t *testing.T) {
+	dir := writeTestFiles(t, map[string]string{"a.conf": ""})
+	files := []string{filepath.Join(dir, "a.conf"), filepath.Join(dir, "new.conf"), filepath.Join(dir, "missing", "b.conf")}
+
+	unlock, err := lockFiles(lockOptions{}, files)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer unlock()
+
+	for _, file := range files[:2] {
+		if _, err := io.TryLockFile(file); err != io.ErrLocked {
+			t.Errorf("Expected %s to be locked, got %v", file, err)
+		}
+	}
+	if _, err := os.Stat(filepath.Join(dir, "missing")); !os.IsNotExist(err) {
+		t.Errorf("Expected no directory to be created for a file in a missing directory, got %v", err)
+	}
+}
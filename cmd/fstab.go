@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// fstabCmd represents the fstab command
+var fstabCmd = &cobra.Command{
+	Use:   "fstab",
+	Short: "fstab files",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	InitCommonCommands(fstabCmd)
+}
@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/einenlum/edicon/internal/core"
 	"github.com/einenlum/edicon/internal/plugins"
@@ -9,47 +11,123 @@ import (
 	"github.com/spf13/cobra"
 )
 
-// getCmd represents the get command
-var getCmd = &cobra.Command{
-	Use:   "get",
-	Short: "Get a parameter",
-	Long: `Something
+// newGetCmd creates the get command. A new instance is needed for each
+// configuration type as a cobra command can only have one parent.
+func newGetCmd() *cobra.Command {
+	getCmd := &cobra.Command{
+		Use:   "get",
+		Short: "Get a parameter",
+		Long: `Something
 Longer
 `,
-	Run: func(cmd *cobra.Command, args []string) {
-		configurator, err := plugins.GetConfiguratorFromParentCmd(cmd.Parent())
-		if err != nil {
-			panic(err)
-		}
+		Run: func(cmd *cobra.Command, args []string) {
+			configurator, err := plugins.GetConfiguratorFromParentCmd(cmd.Parent())
+			if err != nil {
+				panic(err)
+			}
 
-		key, file := getGetCmdArguments(args)
+			key, files := getGetCmdArguments(args)
 
-		useBrackets, err := cmd.Flags().GetBool("brackets")
-		if err != nil {
-			fmt.Println(err)
-		}
-		notationStyle := core.GetNotationStyle(useBrackets)
+			notationStyle := getNotationStyle(cmd)
+
+			pattern, err := plugins.ParseKeyPattern(configurator, notationStyle, key)
+			if err != nil {
+				panic(err)
+			}
+
+			if len(files) == 1 && pattern == nil {
+				value, err := configurator.GetParameter(notationStyle, files[0], key)
+				if err != nil {
+					fmt.Println(err.Error())
+				}
+
+				fmt.Println(value)
+				return
+			}
+
+			failures := []string{}
+			for _, file := range files {
+				lines, err := getLines(cmd, configurator, notationStyle, file, key, pattern)
+				if err != nil {
+					if len(files) == 1 {
+						panic(err)
+					}
+					failures = append(failures, fmt.Sprintf("%s: %s", file, err.Error()))
+					continue
+				}
+				for _, line := range lines {
+					printWithFile(files, file, line)
+				}
+			}
+
+			if len(failures) > 0 {
+				panic(errors.New(fmt.Sprintf(
+					"%d of %d files cannot be read:\n  %s",
+					len(failures), len(files), strings.Join(failures, "\n  "),
+				)))
+			}
+		},
+	}
+
+	initNotationFlags(getCmd)
+	getCmd.Flags().Bool("allow-empty", false, "Do not fail when a key pattern matches nothing")
+
+	return getCmd
+}
 
+// getLines returns the value of a key, or the values whose key matches a
+// pattern with their full key
+func getLines(
+	cmd *cobra.Command,
+	configurator core.Configurator,
+	notationStyle core.NotationStyle,
+	file string,
+	key string,
+	pattern core.KeyPattern,
+) ([]string, error) {
+	if pattern == nil {
 		value, err := configurator.GetParameter(notationStyle, file, key)
 		if err != nil {
-			fmt.Println(err.Error())
+			return nil, err
 		}
+		return []string{value}, nil
+	}
+
+	matches, err := plugins.FindMatches(configurator, file, pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(matches) == 0 && !allowEmpty(cmd) {
+		return nil, errors.New(fmt.Sprintf("No key matches %s", key))
+	}
 
-		fmt.Println(value)
-	},
+	lines := []string{}
+	for _, match := range matches {
+		lines = append(lines, fmt.Sprintf("%s = %s", core.ComposeKey(notationStyle, match.Path), match.Value))
+	}
+
+	return lines, nil
 }
 
-func getGetCmdArguments(args []string) (string, string) {
+func allowEmpty(cmd *cobra.Command) bool {
+	allowEmpty, err := cmd.Flags().GetBool("allow-empty")
+	if err != nil {
+		panic(err)
+	}
+
+	return allowEmpty
+}
+
+func getGetCmdArguments(args []string) (string, []string) {
 	if len(args) < 2 {
 		panic("Not enough arguments")
 	}
 
 	key := args[0]
-	file := args[1]
-
-	return key, file
-}
+	files, err := expandFiles(args[1:])
+	if err != nil {
+		panic(err)
+	}
 
-func init() {
-	getCmd.Flags().BoolP("brackets", "b", false, "Use brackts notation \"key[foo.bar]\" instead of dot notation")
+	return key, files
 }
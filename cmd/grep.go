@@ -0,0 +1,96 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"runtime"
+
+	"github.com/einenlum/edicon/internal/plugins"
+
+	"github.com/spf13/cobra"
+)
+
+// grepCmd searches parameters among configuration files
+var grepCmd = &cobra.Command{
+	Use:   "grep [--key RE] [--value RE] [--section RE] <path>...",
+	Short: "Search parameters among configuration files",
+	Long: `Search the parameters whose key, value and section match regular
+expressions among files and directories, printing them as
+"file:line: section.key = value".
+
+Directories are searched recursively. The type of the files is detected from
+their name (*.ini, *.toml, nginx.conf, sshd_config, *.service, hosts...) and
+can be restricted with --type. A single --type also gives its type to the
+files whose type cannot be detected, including those found in directories.
+Files which cannot be parsed are skipped with a warning.
+`,
+	Args: cobra.MinimumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		types, err := cmd.Flags().GetStringSlice("type")
+		if err != nil {
+			panic(err)
+		}
+		workers, err := cmd.Flags().GetInt("jobs")
+		if err != nil {
+			panic(err)
+		}
+		if workers < 1 {
+			panic(errors.New("--jobs must be at least 1"))
+		}
+		for _, ctype := range types {
+			if _, err := plugins.GetDocumentReader(ctype); err != nil {
+				panic(err)
+			}
+		}
+
+		criteria := plugins.SearchCriteria{
+			Key:     getRegexpFlag(cmd, "key"),
+			Value:   getRegexpFlag(cmd, "value"),
+			Section: getRegexpFlag(cmd, "section"),
+		}
+
+		files, errs := plugins.CollectSearchFiles(args, types)
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, "warning: "+err.Error())
+		}
+
+		plugins.SearchFiles(files, criteria, workers, func(result plugins.SearchResult) {
+			if result.Err != nil {
+				fmt.Fprintf(os.Stderr, "warning: skipping %s: %s\n", result.File.Path, result.Err.Error())
+				return
+			}
+			for _, match := range result.Matches {
+				fmt.Printf("%s:%d: %s = %s\n", result.File.Path, match.Line, match.Key, match.Value)
+			}
+		})
+	},
+}
+
+func getRegexpFlag(cmd *cobra.Command, name string) *regexp.Regexp {
+	pattern, err := cmd.Flags().GetString(name)
+	if err != nil {
+		panic(err)
+	}
+	if pattern == "" {
+		return nil
+	}
+
+	compiled, err := regexp.Compile(pattern)
+	if err != nil {
+		panic(errors.New(fmt.Sprintf("Invalid --%s regexp %s", name, pattern)))
+	}
+
+	return compiled
+}
+
+func init() {
+	grepCmd.Flags().String("key", "", "Regexp the name of the keys must match")
+	grepCmd.Flags().String("value", "", "Regexp the values must match")
+	grepCmd.Flags().String("section", "", "Regexp the sections must match, global keys being in the \"\" section")
+	grepCmd.Flags().StringSliceP("type", "t", []string{}, "Types of the files to search (ini, toml...)")
+	grepCmd.Flags().IntP("jobs", "j", runtime.NumCPU(), "Number of files parsed at the same time")
+
+	rootCmd.AddCommand(grepCmd)
+}
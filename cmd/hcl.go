@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// hclCmd represents the hcl command
+var hclCmd = &cobra.Command{
+	Use:     "hcl",
+	Aliases: []string{"tfvars", "terraform"},
+	Short:   "HCL and Terraform configuration",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	InitCommonCommands(hclCmd)
+}
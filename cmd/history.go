@@ -0,0 +1,60 @@
+package cmd
+
+import (
+	"fmt"
+	"strconv"
+
+	"github.com/einenlum/edicon/internal/history"
+
+	"github.com/spf13/cobra"
+)
+
+// historyCmd prints the change log
+var historyCmd = &cobra.Command{
+	Use:   "history [file]",
+	Short: "Print the changes written by edicon",
+	Long: `Print the changes of keys written by edicon, the oldest first, grouped
+by command: the old and the new value of each key, when and by whom it was
+changed. Only the changes of a file are printed if it is given.
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		changes, err := history.Load()
+		if err != nil {
+			panic(err)
+		}
+
+		file := ""
+		if len(args) == 1 {
+			file = absolutePath(args[0])
+		}
+
+		undone := history.Undone(changes)
+		for _, step := range history.Steps(changes, file) {
+			first := step.Changes[0]
+			fmt.Printf("%s  %s  %s\n", first.Time.Format("2006-01-02 15:04:05"), first.User, first.Command)
+			for _, change := range step.Changes {
+				line := fmt.Sprintf(
+					"    %s  %s: %s -> %s",
+					change.File, change.Key, historyValue(change.Old, change.OldMissing), historyValue(change.New, change.NewMissing),
+				)
+				if undone[change.ID] {
+					line += " (undone)"
+				}
+				fmt.Println(line)
+			}
+		}
+	},
+}
+
+func historyValue(value string, missing bool) string {
+	if missing {
+		return "(none)"
+	}
+
+	return strconv.Quote(value)
+}
+
+func init() {
+	rootCmd.AddCommand(historyCmd)
+}
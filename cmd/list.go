@@ -0,0 +1,74 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins"
+
+	"github.com/spf13/cobra"
+)
+
+// newListCmd creates the list command, which prints all the parameters of a
+// file from its document tree
+func newListCmd() *cobra.Command {
+	listCmd := &cobra.Command{
+		Use:   "list <file>",
+		Short: "List all the parameters",
+		Long: `List all the parameters of a file, one "key = value" line per value,
+with the key in the notation used by get and set.
+`,
+		Args: cobra.ExactArgs(1),
+		Run: func(cmd *cobra.Command, args []string) {
+			configurator, err := plugins.GetConfiguratorFromParentCmd(cmd.Parent())
+			if err != nil {
+				panic(err)
+			}
+
+			reader, ok := configurator.(core.DocumentReader)
+			if !ok {
+				panic(errors.New("The list command is not supported for this configuration type"))
+			}
+
+			document, err := reader.ReadDocument(args[0], nil)
+			if err != nil {
+				panic(err)
+			}
+
+			notationStyle := getNotationStyle(cmd)
+			withComments, err := cmd.Flags().GetBool("comments")
+			if err != nil {
+				panic(err)
+			}
+			withLines, err := cmd.Flags().GetBool("line-numbers")
+			if err != nil {
+				panic(err)
+			}
+
+			document.Walk(func(node *core.Node) error {
+				if withComments {
+					for _, comment := range node.Comments {
+						fmt.Println("# " + comment)
+					}
+				}
+				if node.Kind != core.ScalarNode {
+					return nil
+				}
+
+				if withLines {
+					fmt.Printf("%d: ", node.Span.Start)
+				}
+				fmt.Printf("%s = %s\n", core.ComposeKey(notationStyle, node.Path), node.Value)
+
+				return nil
+			})
+		},
+	}
+
+	initNotationFlags(listCmd)
+	listCmd.Flags().BoolP("comments", "c", false, "Print the comments of the parameters")
+	listCmd.Flags().BoolP("line-numbers", "n", false, "Print the line of each parameter")
+
+	return listCmd
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// nginxCmd represents the nginx command
+var nginxCmd = &cobra.Command{
+	Use:   "nginx",
+	Short: "nginx configuration",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	InitCommonCommands(nginxCmd)
+}
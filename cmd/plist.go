@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// plistCmd represents the plist command
+var plistCmd = &cobra.Command{
+	Use:   "plist",
+	Short: "Property lists (XML and binary)",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	InitCommonCommands(plistCmd)
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// postgresqlCmd represents the postgresql command
+var postgresqlCmd = &cobra.Command{
+	Use:     "postgresql",
+	Aliases: []string{"postgres"},
+	Short:   "PostgreSQL configuration",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	InitCommonCommands(postgresqlCmd)
+}
@@ -0,0 +1,123 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins"
+	"github.com/einenlum/edicon/internal/plugins/json"
+	"github.com/einenlum/edicon/internal/query"
+
+	"github.com/spf13/cobra"
+)
+
+// newQueryCmd creates the query command, which evaluates a query over the
+// document tree of a file
+func newQueryCmd() *cobra.Command {
+	queryCmd := &cobra.Command{
+		Use:   "query <expression> <file>",
+		Short: "Query the parameters of a file",
+		Long: `Query the parameters of a file with a jq-like expression, e.g.
+'.[] | select(.enabled == false) | section' prints the sections disabled.
+
+.a.b, ."a.b", .["a.b"]     select a key
+.[0], .[]                 select an item, iterate over a list or a map
+..                        every value, recursively
+a | b                     evaluate b on each result of a
+== != < <= > >=           compare, as numbers when both values are numbers
+and, or, not              boolean logic
+select(f)                 keep the values for which f is true
+keys, length, scalars     keys of a map, length, values which are scalars
+key, path, section        name, full key and section of a value
+comment, line             comments above a value, line of a value
+test(re), contains(s)     match a value (also startswith and endswith)
+`,
+		Args: cobra.ExactArgs(2),
+		Run: func(cmd *cobra.Command, args []string) {
+			configurator, err := plugins.GetConfiguratorFromParentCmd(cmd.Parent())
+			if err != nil {
+				panic(err)
+			}
+
+			reader, ok := configurator.(core.DocumentReader)
+			if !ok {
+				panic(errors.New("The query command is not supported for this configuration type"))
+			}
+
+			parsedQuery, err := query.Parse(args[0])
+			if err != nil {
+				panic(err)
+			}
+
+			document, err := reader.ReadDocument(args[1], nil)
+			if err != nil {
+				panic(err)
+			}
+
+			notationStyle := getNotationStyle(cmd)
+			results, err := parsedQuery.Run(document, notationStyle)
+			if err != nil {
+				panic(err)
+			}
+
+			output, err := cmd.Flags().GetString("output")
+			if err != nil {
+				panic(err)
+			}
+			switch output {
+			case "plain":
+				for _, result := range results {
+					printPlainResult(notationStyle, result)
+				}
+			case "json":
+				for _, result := range results {
+					printJSONResult(result)
+				}
+			default:
+				panic(errors.New(fmt.Sprintf("Unknown output %s, expected plain or json", output)))
+			}
+		},
+	}
+
+	initNotationFlags(queryCmd)
+	queryCmd.Flags().StringP("output", "o", "plain", "Output of the results: plain or json")
+
+	return queryCmd
+}
+
+// printPlainResult prints a scalar as is, and the values of a map or a list
+// as "key = value" lines
+func printPlainResult(notationStyle core.NotationStyle, result *core.Node) {
+	if result == nil {
+		fmt.Println("null")
+		return
+	}
+	if result.Kind == core.ScalarNode {
+		fmt.Println(result.Value)
+		return
+	}
+
+	result.Walk(func(node *core.Node) error {
+		if node.Kind != core.ScalarNode {
+			return nil
+		}
+		if node.Path == nil {
+			fmt.Println(node.Value)
+			return nil
+		}
+		fmt.Printf("%s = %s\n", core.ComposeKey(notationStyle, node.Path), node.Value)
+
+		return nil
+	})
+}
+
+func printJSONResult(result *core.Node) {
+	if result == nil {
+		fmt.Println("null")
+		return
+	}
+
+	output, _ := json.JsonFormat{}.WriteTree(result, &core.Losses{})
+	fmt.Print(output)
+}
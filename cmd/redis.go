@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// redisCmd represents the redis command
+var redisCmd = &cobra.Command{
+	Use:   "redis",
+	Short: "Redis configuration",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	InitCommonCommands(redisCmd)
+}
@@ -0,0 +1,22 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// regCmd represents the reg command
+var regCmd = &cobra.Command{
+	Use:     "reg",
+	Aliases: []string{"regedit"},
+	Short:   "Windows registry files",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	InitCommonCommands(regCmd)
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/einenlum/edicon/internal/transaction"
+
+	"github.com/spf13/cobra"
+)
+
+// rollbackCmd restores the files written by a previous command
+var rollbackCmd = &cobra.Command{
+	Use:   "rollback [--last | <id>]",
+	Short: "Restore the files written by a previous command",
+	Long: `Restore the files written by a previous command (set -w, unset -w...),
+all the files written by a command being written in a single transaction.
+The transactions are listed with --list.
+
+A file changed since the transaction is not restored, unless --force is
+given. A pending transaction, interrupted before all its files were
+written, can be rolled back too: only the files it wrote are restored. The
+interrupted transactions are also rolled back before any file is written.
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		list, err := cmd.Flags().GetBool("list")
+		if err != nil {
+			panic(err)
+		}
+		last, err := cmd.Flags().GetBool("last")
+		if err != nil {
+			panic(err)
+		}
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			panic(err)
+		}
+
+		if list {
+			printTransactions()
+			return
+		}
+
+		var tx *transaction.Transaction
+		switch {
+		case last && len(args) == 0:
+			tx, err = transaction.Last()
+		case !last && len(args) == 1:
+			tx, err = transaction.Load(args[0])
+		default:
+			panic(errors.New("Give either the ID of a transaction or --last"))
+		}
+		if err != nil {
+			panic(err)
+		}
+
+		if err := tx.Rollback(force); err != nil {
+			panic(err)
+		}
+		for _, entry := range tx.Entries {
+			fmt.Println("Restored " + entry.Path)
+		}
+	},
+}
+
+func printTransactions() {
+	transactions, err := transaction.List()
+	if err != nil {
+		panic(err)
+	}
+
+	for _, tx := range transactions {
+		fmt.Printf("%s  %-11s  %s\n", tx.ID, tx.Status, tx.Command)
+		for _, entry := range tx.Entries {
+			fmt.Println("    " + entry.Path)
+		}
+	}
+}
+
+func init() {
+	rollbackCmd.Flags().Bool("last", false, "Roll back the last transaction")
+	rollbackCmd.Flags().Bool("list", false, "List the transactions")
+	rollbackCmd.Flags().Bool("force", false, "Restore the files even if they were changed since the transaction")
+
+	rootCmd.AddCommand(rollbackCmd)
+}
@@ -1,45 +1,141 @@
 package cmd
 
 import (
+	"errors"
 	"fmt"
+	"os"
 
 	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/history"
 	"github.com/einenlum/edicon/internal/plugins"
 
 	"github.com/spf13/cobra"
 )
 
-var setCmd = &cobra.Command{
-	Use:   "set",
-	Short: "Set a parameter",
-	Long: `Something
+// newSetCmd creates the set command. A new instance is needed for each
+// configuration type as a cobra command can only have one parent.
+func newSetCmd() *cobra.Command {
+	setCmd := &cobra.Command{
+		Use:   "set",
+		Short: "Set a parameter",
+		Long: `Something
 Longer
 `,
-	Run: func(cmd *cobra.Command, args []string) {
-		configurator, err := plugins.GetConfiguratorFromParentCmd(cmd.Parent())
-		if err != nil {
-			panic(err)
-		}
-		key, value, file := getSetCmdArguments(args)
-
-		notationStyle := getNotationStyle(cmd)
-		outputType := getOutputType(cmd)
-		shouldOverwrite := shouldOverwrite(cmd)
+		Run: func(cmd *cobra.Command, args []string) {
+			configurator, err := plugins.GetConfiguratorFromParentCmd(cmd.Parent())
+			if err != nil {
+				panic(err)
+			}
+			key, value, files := getSetCmdArguments(args)
 
-		config, err := configurator.SetParameter(notationStyle, file, key, value)
-		if err != nil {
-			panic(err)
-		}
+			notationStyle := getNotationStyle(cmd)
+			valueType := getValueType(cmd)
+			checkIfMissing(cmd, configurator)
 
-		if shouldOverwrite {
-			err = config.WriteToFile(file, outputType)
+			pattern, err := plugins.ParseKeyPattern(configurator, notationStyle, key)
 			if err != nil {
 				panic(err)
 			}
-		} else {
-			fmt.Println(config.OutputFile(outputType))
+			if pattern != nil {
+				if hasCondition(cmd) {
+					panic(errors.New("The --if-equals, --if-missing and --if-exists flags cannot be used with a key pattern"))
+				}
+				allMatches, err := cmd.Flags().GetBool("all-matches")
+				if err != nil {
+					panic(err)
+				}
+				if !allMatches {
+					panic(errors.New(fmt.Sprintf("%s is a key pattern, use --all-matches to set all the keys it matches", key)))
+				}
+
+				editFiles(cmd, files, func(file string) (core.Configuration, []history.Change, error) {
+					return setAllMatches(cmd, configurator, file, key, pattern, value, valueType)
+				})
+				return
+			}
+
+			editFiles(cmd, files, func(file string) (core.Configuration, []history.Change, error) {
+				if err := checkCondition(cmd, configurator, notationStyle, file, key); err != nil {
+					return nil, nil, err
+				}
+				changes := []history.Change{keyChange(cmd, configurator, notationStyle, file, key)}
+
+				if valueType == "" {
+					config, err := configurator.SetParameter(notationStyle, file, key, value)
+					return config, changes, err
+				}
+
+				typedConfigurator, ok := configurator.(core.TypedConfigurator)
+				if !ok {
+					return nil, nil, errors.New("The --type flag is not supported for this configuration type")
+				}
+
+				config, err := typedConfigurator.SetTypedParameter(notationStyle, file, key, value, valueType)
+				return config, changes, err
+			})
+		},
+	}
+
+	initEditFlags(setCmd)
+	initConditionFlags(setCmd, true)
+	setCmd.Flags().Bool("all-matches", false, "Set all the keys matching a key pattern (e.g. \"*.memory_limit\")")
+	setCmd.Flags().Bool("allow-empty", false, "Do not fail when a key pattern matches nothing")
+	setCmd.Flags().String("type", "", "Force the type of the value (only for typed configuration types, e.g. string, integer, float, boolean, datetime, array, inline-table)")
+
+	return setCmd
+}
+
+// setAllMatches sets the value of all the keys of a file matching a pattern.
+// The configuration is nil if nothing matches and --allow-empty is given.
+func setAllMatches(
+	cmd *cobra.Command,
+	configurator core.Configurator,
+	file string,
+	key string,
+	pattern core.KeyPattern,
+	value string,
+	valueType string,
+) (core.Configuration, []history.Change, error) {
+	matches, err := plugins.FindMatches(configurator, file, pattern)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(matches) == 0 {
+		if !allowEmpty(cmd) {
+			return nil, nil, errors.New(fmt.Sprintf("No key matches %s", key))
 		}
-	},
+		return nil, nil, nil
+	}
+
+	changes := []history.Change{}
+	for _, match := range matches {
+		matchKey := core.ComposeKey(core.PointerNotation, match.Path)
+		changes = append(changes, keyChange(cmd, configurator, core.PointerNotation, file, matchKey))
+	}
+
+	config, err := plugins.SetAllMatches(configurator, file, matches, value, valueType)
+
+	return config, changes, err
+}
+
+func initEditFlags(cmd *cobra.Command) {
+	initNotationFlags(cmd)
+	cmd.Flags().BoolP("write", "w", false, "Write the changes to the file")
+	cmd.Flags().Bool("values-only", false, "Only output the values (remove empty lines and comments)")
+	initFilesFlags(cmd)
+	initLockFlags(cmd)
+}
+
+func outputConfiguration(edit fileEdit, outputType core.OutputType, shouldOverwrite bool) {
+	var err error
+	if shouldOverwrite {
+		err = writeEdits([]fileEdit{edit}, outputType)
+	} else {
+		err = printEdit(os.Stdout, []string{edit.file}, edit, outputType)
+	}
+	if err != nil {
+		panic(err)
+	}
 }
 
 func shouldOverwrite(cmd *cobra.Command) bool {
@@ -51,6 +147,15 @@ func shouldOverwrite(cmd *cobra.Command) bool {
 	return overwrite
 }
 
+func getValueType(cmd *cobra.Command) string {
+	valueType, err := cmd.Flags().GetString("type")
+	if err != nil {
+		panic(err)
+	}
+
+	return valueType
+}
+
 func getOutputType(cmd *cobra.Command) core.OutputType {
 	onlyValues, err := cmd.Flags().GetBool("values-only")
 	if err != nil {
@@ -64,20 +169,17 @@ func getOutputType(cmd *cobra.Command) core.OutputType {
 	return core.FullOutput
 }
 
-func getSetCmdArguments(args []string) (string, string, string) {
+func getSetCmdArguments(args []string) (string, string, []string) {
 	if len(args) < 3 {
 		panic("Not enough arguments")
 	}
 
 	key := args[0]
 	value := args[1]
-	file := args[2]
-
-	return key, value, file
-}
+	files, err := expandFiles(args[2:])
+	if err != nil {
+		panic(err)
+	}
 
-func init() {
-	setCmd.Flags().BoolP("brackets", "b", false, "Use brackts notation \"key[foo.bar]\" instead of dot notation")
-	setCmd.Flags().BoolP("write", "w", false, "Write the changes to the file")
-	setCmd.Flags().Bool("values-only", false, "Only output the values (remove empty lines and comments)")
+	return key, value, files
 }
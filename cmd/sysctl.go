@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// sysctlCmd represents the sysctl command
+var sysctlCmd = &cobra.Command{
+	Use:   "sysctl",
+	Short: "sysctl configuration",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	InitCommonCommands(sysctlCmd)
+}
@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/einenlum/edicon/internal/history"
+	"github.com/einenlum/edicon/internal/plugins"
+	"github.com/einenlum/edicon/internal/plugins/systemd"
+
+	"github.com/spf13/cobra"
+)
+
+// systemdCmd represents the systemd command
+var systemdCmd = &cobra.Command{
+	Use:   "systemd",
+	Short: "systemd unit files",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+// systemdOverrideCmd sets a key in the override.conf drop-in of a unit
+var systemdOverrideCmd = &cobra.Command{
+	Use:   "override <unit-file> <key> <value>",
+	Short: "Set a parameter in a drop-in instead of the unit file",
+	Long: `Set a parameter in the override.conf drop-in of a unit
+(e.g. nginx.service.d/override.conf), creating it if needed, so that
+the vendor unit file is left untouched.
+`,
+	Args: cobra.ExactArgs(3),
+	Run: func(cmd *cobra.Command, args []string) {
+		unitFile, key, value := args[0], args[1], args[2]
+		notationStyle := getNotationStyle(cmd)
+
+		configurator, err := plugins.GetConfiguratorFromParentCmd(cmd.Parent())
+		if err != nil {
+			panic(err)
+		}
+
+		directory, err := cmd.Flags().GetString("directory")
+		if err != nil {
+			panic(err)
+		}
+
+		// the directory of the drop-in is created first, so that the drop-in
+		// can be locked even if it does not exist yet
+		dropInPath := systemd.DropInPath(unitFile, directory)
+		if shouldOverwrite(cmd) {
+			if err := os.MkdirAll(filepath.Dir(dropInPath), 0755); err != nil {
+				panic(err)
+			}
+			unlock, err := lockFiles(getLockOptions(cmd), []string{dropInPath})
+			if err != nil {
+				panic(err)
+			}
+			defer unlock()
+		}
+		fingerprint := getFingerprint(dropInPath)
+		changes := []history.Change{keyChange(cmd, configurator, notationStyle, dropInPath, key)}
+
+		config, err := systemd.OverrideParameter(notationStyle, unitFile, directory, key, value)
+		if err != nil {
+			panic(err)
+		}
+
+		outputConfiguration(fileEdit{file: config.FilePath, config: config, changes: changes, fingerprint: fingerprint}, getOutputType(cmd), shouldOverwrite(cmd))
+	},
+}
+
+// systemdEffectiveCmd prints the value of a key once the unit and its
+// drop-ins are merged
+var systemdEffectiveCmd = &cobra.Command{
+	Use:   "effective <key> <unit-file>",
+	Short: "Get a parameter merged across the unit file and its drop-ins",
+	Long: `Get the value of a parameter once the unit file and all its drop-ins
+(*.conf files in the <unit>.d directories) are applied.
+`,
+	Args: cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		key, unitFile := args[0], args[1]
+
+		directories, err := cmd.Flags().GetStringSlice("directory")
+		if err != nil {
+			panic(err)
+		}
+
+		value, err := systemd.GetEffectiveParameter(getNotationStyle(cmd), unitFile, directories, key)
+		if err != nil {
+			fmt.Println(err.Error())
+		}
+
+		fmt.Println(value)
+	},
+}
+
+func init() {
+	InitCommonCommands(systemdCmd)
+
+	initEditFlags(systemdOverrideCmd)
+	systemdOverrideCmd.Flags().String("directory", "", "Directory of the drop-in (e.g. /etc/systemd/system), the directory of the unit file by default")
+	systemdCmd.AddCommand(systemdOverrideCmd)
+
+	initNotationFlags(systemdEffectiveCmd)
+	systemdEffectiveCmd.Flags().StringSlice("directory", []string{}, "Other directories containing drop-ins, by increasing priority (e.g. /etc/systemd/system)")
+	systemdCmd.AddCommand(systemdEffectiveCmd)
+}
@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// tomlCmd represents the toml command
+var tomlCmd = &cobra.Command{
+	Use:   "toml",
+	Short: "TOML configuration",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	InitCommonCommands(tomlCmd)
+}
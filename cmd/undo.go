@@ -0,0 +1,108 @@
+package cmd
+
+import (
+	"errors"
+	"fmt"
+	"os"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/history"
+	"github.com/einenlum/edicon/internal/plugins"
+
+	"github.com/spf13/cobra"
+)
+
+// undoCmd reverts the last changes written by edicon
+var undoCmd = &cobra.Command{
+	Use:   "undo [file] [--steps N]",
+	Short: "Revert the last changes written by edicon",
+	Long: `Set the keys changed by the last commands back to their previous value,
+only for a file if it is given. Only the lines of these keys are changed, so
+that the other edits made since are kept.
+
+A key whose value is no longer the one written by edicon is not reverted,
+unless --force is given.
+`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		steps, err := cmd.Flags().GetInt("steps")
+		if err != nil {
+			panic(err)
+		}
+		force, err := cmd.Flags().GetBool("force")
+		if err != nil {
+			panic(err)
+		}
+		if steps < 1 {
+			panic(errors.New("--steps must be at least 1"))
+		}
+
+		changes, err := history.Load()
+		if err != nil {
+			panic(err)
+		}
+
+		file := ""
+		if len(args) == 1 {
+			file = absolutePath(args[0])
+		}
+
+		undoable := history.UndoableSteps(changes, file)
+		if len(undoable) == 0 {
+			panic(errors.New("Nothing to undo"))
+		}
+		if steps < len(undoable) {
+			undoable = undoable[:steps]
+		}
+
+		// The changes of each file, the last first
+		files := []string{}
+		fileChanges := map[string][]history.Change{}
+		for _, step := range undoable {
+			for idx := len(step.Changes) - 1; idx >= 0; idx-- {
+				change := step.Changes[idx]
+				if _, exists := fileChanges[change.File]; !exists {
+					files = append(files, change.File)
+				}
+				fileChanges[change.File] = append(fileChanges[change.File], change)
+			}
+		}
+
+		unlock, err := lockFiles(getLockOptions(cmd), files)
+		if err != nil {
+			panic(err)
+		}
+		defer unlock()
+
+		edits := []fileEdit{}
+		for _, file := range files {
+			fingerprint := getFingerprint(file)
+			config, reverts, err := plugins.RevertChanges(file, fileChanges[file], force, func(message string) {
+				fmt.Fprintln(os.Stderr, "warning: "+message)
+			})
+			if err != nil {
+				panic(errors.New(fmt.Sprintf("Nothing was reverted, %s: %s", file, err.Error())))
+			}
+			if config != nil {
+				edits = append(edits, fileEdit{file, config, reverts, fingerprint})
+			}
+		}
+
+		if err := writeEdits(edits, core.FullOutput); err != nil {
+			panic(err)
+		}
+		for _, edit := range edits {
+			for _, revert := range edit.changes {
+				fmt.Printf("Reverted %s in %s\n", revert.Key, edit.file)
+			}
+		}
+	},
+}
+
+func init() {
+	undoCmd.Flags().IntP("steps", "n", 1, "Number of commands to undo")
+	undoCmd.Flags().Bool("force", false, "Revert the keys even if they were changed since")
+	initLockFlags(undoCmd)
+
+	rootCmd.AddCommand(undoCmd)
+}
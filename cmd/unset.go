@@ -0,0 +1,49 @@
+package cmd
+
+import (
+	"errors"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/history"
+	"github.com/einenlum/edicon/internal/plugins"
+
+	"github.com/spf13/cobra"
+)
+
+// newUnsetCmd creates the unset command, which removes a parameter
+func newUnsetCmd() *cobra.Command {
+	unsetCmd := &cobra.Command{
+		Use:   "unset",
+		Short: "Remove a parameter",
+		Long: `Remove a parameter from the configuration file.
+`,
+		Run: func(cmd *cobra.Command, args []string) {
+			configurator, err := plugins.GetConfiguratorFromParentCmd(cmd.Parent())
+			if err != nil {
+				panic(err)
+			}
+			key, files := getGetCmdArguments(args)
+
+			remover, ok := configurator.(core.ParameterRemover)
+			if !ok {
+				panic(errors.New("The unset command is not supported for this configuration type"))
+			}
+
+			notationStyle := getNotationStyle(cmd)
+			editFiles(cmd, files, func(file string) (core.Configuration, []history.Change, error) {
+				if err := checkCondition(cmd, configurator, notationStyle, file, key); err != nil {
+					return nil, nil, err
+				}
+				change := keyChange(cmd, configurator, notationStyle, file, key)
+				config, err := remover.UnsetParameter(notationStyle, file, key)
+
+				return config, []history.Change{change}, err
+			})
+		},
+	}
+
+	initEditFlags(unsetCmd)
+	initConditionFlags(unsetCmd, false)
+
+	return unsetCmd
+}
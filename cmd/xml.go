@@ -0,0 +1,21 @@
+package cmd
+
+import (
+	"github.com/spf13/cobra"
+)
+
+// xmlCmd represents the xml command
+var xmlCmd = &cobra.Command{
+	Use:   "xml",
+	Short: "XML configuration",
+	Long: `Something
+Longer
+`,
+	Run: func(cmd *cobra.Command, args []string) {
+		cmd.Help()
+	},
+}
+
+func init() {
+	InitCommonCommands(xmlCmd)
+}
@@ -0,0 +1,56 @@
+# Input variables
+variable "region" {
+  description = "AWS region"
+  default     = "eu-west-3"
+}
+
+variable "instance_count" {
+  type    = number
+  default = 2 // two instances by default
+}
+
+/* The provider is configured
+   from the variables */
+provider "aws" {
+  region = var.region
+}
+
+resource "aws_instance" "web" {
+  ami           = "ami-0c55b159cbfafe1f0"
+  instance_type = "t3.micro"
+  count         = var.instance_count
+  monitoring    = true
+
+  tags = {
+    Name        = "web"
+    Environment = "production"
+  }
+
+  security_groups = ["web", "ssh"]
+
+  ebs_block_device {
+    device_name = "/dev/sdb"
+    volume_size = 10
+  }
+
+  ebs_block_device {
+    device_name = "/dev/sdc"
+    volume_size = 20
+  }
+
+  user_data = <<-EOT
+    #!/bin/bash
+    echo "Hello"
+  EOT
+}
+
+locals {
+  zones = [
+    "eu-west-3a",
+    "eu-west-3b",
+  ]
+  settings = {
+    retries = 3
+    verbose = false
+  }
+}
@@ -0,0 +1,207 @@
+// Package blockpath resolves the keys of the configurations made of nested
+// block directives (nginx blocks, Apache containers): paths of directive
+// names whose segments can select directives with predicates.
+package blockpath
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+var predicateRegexp = regexp.MustCompile(`^([A-Za-z0-9_]+)\s*=\s*(.*)$`)
+
+// Directive is a directive of a configuration, as seen by the resolver
+type Directive interface {
+	DirectiveName() string
+	// ArgumentValues are the arguments of the directive, without their quotes
+	ArgumentValues() []string
+	// Children are the directives of its block, nil if it is not a block
+	Children() []Directive
+}
+
+// Predicate filters directives with the same name: by position (listen[2],
+// starting at 1), by arguments (location[/api]) or, for blocks, by the
+// arguments of one of their directives (server[server_name=example.com])
+type Predicate struct {
+	Index     int
+	Directive string
+	Value     string
+}
+
+type Segment struct {
+	Name       string
+	Predicates []Predicate
+}
+
+// Resolver resolves keys against directives
+type Resolver struct {
+	// SameName compares the name of a directive with the name of a key
+	SameName func(name string, other string) bool
+	// BlockKind is how blocks are called in errors ("block", "container")
+	BlockKind string
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
+
+func parseSegment(part string) (Segment, error) {
+	bracket := strings.IndexByte(part, '[')
+	if bracket == -1 {
+		return Segment{Name: part}, nil
+	}
+
+	segment := Segment{Name: part[:bracket]}
+	rest := part[bracket:]
+	for rest != "" {
+		end := strings.LastIndexByte(rest, ']')
+		if next := strings.Index(rest, "]["); next != -1 {
+			end = next
+		}
+		if !strings.HasPrefix(rest, "[") || end == -1 {
+			return segment, errors.New(fmt.Sprintf("Invalid key segment: %s", part))
+		}
+		content := strings.TrimSpace(rest[1:end])
+		rest = rest[end+1:]
+
+		if index, err := strconv.Atoi(content); err == nil {
+			if index < 1 {
+				return segment, errors.New(fmt.Sprintf("Invalid position in %s: positions start at 1", part))
+			}
+			segment.Predicates = append(segment.Predicates, Predicate{Index: index})
+		} else if matches := predicateRegexp.FindStringSubmatch(content); matches != nil {
+			segment.Predicates = append(segment.Predicates, Predicate{Directive: matches[1], Value: unquote(strings.TrimSpace(matches[2]))})
+		} else {
+			segment.Predicates = append(segment.Predicates, Predicate{Value: unquote(content)})
+		}
+	}
+
+	return segment, nil
+}
+
+// DecomposeKey splits a key into segments and parses their predicates
+func DecomposeKey(notationStyle core.NotationStyle, key string) ([]Segment, error) {
+	parts, err := core.DecomposeKeyKeepingPredicates(notationStyle, key)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := []Segment{}
+	for _, part := range parts {
+		segment, err := parseSegment(part)
+		if err != nil {
+			return nil, err
+		}
+		segments = append(segments, segment)
+	}
+
+	return segments, nil
+}
+
+// ArgumentText returns an argument without its quotes, or as written if it
+// needs them (spaces, quotes, escapes...), so that arguments can be printed
+// and set again as they are
+func ArgumentText(raw string, value string) string {
+	if value == "" || strings.ContainsAny(value, " \t\r\n\"'\\;{}#") {
+		return raw
+	}
+
+	return value
+}
+
+// ArgumentsValue returns the arguments of a directive without their quotes,
+// separated by spaces
+func ArgumentsValue(directive Directive) string {
+	return strings.Join(directive.ArgumentValues(), " ")
+}
+
+func (resolver Resolver) matches(directive Directive, predicate Predicate) bool {
+	if predicate.Directive == "" {
+		return ArgumentsValue(directive) == predicate.Value
+	}
+
+	for _, child := range directive.Children() {
+		if !resolver.SameName(child.DirectiveName(), predicate.Directive) {
+			continue
+		}
+		if ArgumentsValue(child) == predicate.Value {
+			return true
+		}
+		for _, value := range child.ArgumentValues() {
+			if value == predicate.Value {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// Find returns the directives matching a segment
+func (resolver Resolver) Find(directives []Directive, segment Segment) []Directive {
+	matching := []Directive{}
+	for _, directive := range directives {
+		if resolver.SameName(directive.DirectiveName(), segment.Name) {
+			matching = append(matching, directive)
+		}
+	}
+
+	for _, predicate := range segment.Predicates {
+		if predicate.Index > 0 {
+			if predicate.Index > len(matching) {
+				return []Directive{}
+			}
+			matching = matching[predicate.Index-1 : predicate.Index]
+			continue
+		}
+
+		filtered := []Directive{}
+		for _, directive := range matching {
+			if resolver.matches(directive, predicate) {
+				filtered = append(filtered, directive)
+			}
+		}
+		matching = filtered
+	}
+
+	return matching
+}
+
+// Resolve returns the directives matching the last segment of the key, and
+// the block directive containing them, nil for the main directives
+func (resolver Resolver) Resolve(main []Directive, segments []Segment) ([]Directive, Directive, error) {
+	if len(segments) == 0 {
+		return nil, nil, errors.New("Key not found")
+	}
+
+	directives := main
+	var parent Directive
+	for _, segment := range segments[:len(segments)-1] {
+		matching := resolver.Find(directives, segment)
+		if len(matching) == 0 {
+			return nil, nil, errors.New("Key not found")
+		}
+		if len(matching) > 1 {
+			return nil, nil, errors.New(fmt.Sprintf(
+				"%d directives match %s, use a predicate to choose one (e.g. %s[1])",
+				len(matching), segment.Name, segment.Name,
+			))
+		}
+		parent = matching[0]
+		directives = parent.Children()
+		if directives == nil {
+			return nil, nil, errors.New(fmt.Sprintf("%s is not a %s", segment.Name, resolver.BlockKind))
+		}
+	}
+
+	return resolver.Find(directives, segments[len(segments)-1]), parent, nil
+}
@@ -0,0 +1,86 @@
+package blockpath
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+type testDirective struct {
+	name      string
+	arguments []string
+	children  []Directive
+}
+
+func (directive testDirective) DirectiveName() string {
+	return directive.name
+}
+
+func (directive testDirective) ArgumentValues() []string {
+	return directive.arguments
+}
+
+func (directive testDirective) Children() []Directive {
+	return directive.children
+}
+
+func TestResolve(t *testing.T) {
+	main := []Directive{
+		testDirective{name: "Listen", arguments: []string{"80"}},
+		testDirective{name: "Listen", arguments: []string{"443"}},
+		testDirective{name: "Server", arguments: []string{"*:443"}, children: []Directive{
+			testDirective{name: "Name", arguments: []string{"example.com", "www.example.com"}},
+		}},
+		testDirective{name: "Empty", children: []Directive{}},
+	}
+	exact := Resolver{SameName: func(name string, other string) bool { return name == other }, BlockKind: "block"}
+	insensitive := Resolver{SameName: strings.EqualFold, BlockKind: "container"}
+
+	dataProvider := []struct {
+		resolver Resolver
+		key      string
+		expected int
+	}{
+		{exact, "Listen", 2},
+		{exact, "listen", 0},
+		{insensitive, "listen", 2},
+		{exact, "Listen[2]", 1},
+		{exact, "Server[*:443]", 1},
+		{exact, "Server[Name=www.example.com].Name", 1},
+		{insensitive, "server[name=example.com].NAME", 1},
+		{exact, "Server[Name=other.com]", 0},
+		{exact, "Empty.Name", 0},
+	}
+
+	for _, element := range dataProvider {
+		t.Run("it resolves "+element.key, func(t *testing.T) {
+			segments, err := DecomposeKey(core.DotNotation, element.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			directives, _, err := element.resolver.Resolve(main, segments)
+			if err != nil || len(directives) != element.expected {
+				t.Errorf("Expected %d directives, got %v (%v)", element.expected, directives, err)
+			}
+		})
+	}
+
+	for _, key := range []string{"Listen.Name", "Missing.Name", "Listen[0]"} {
+		t.Run("it does not resolve "+key, func(t *testing.T) {
+			segments, err := DecomposeKey(core.DotNotation, key)
+			if err == nil {
+				_, _, err = exact.Resolve(main, segments)
+			}
+			if err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+
+	segments, _ := DecomposeKey(core.DotNotation, "Listen[1].Name")
+	if _, _, err := insensitive.Resolve(main, segments); err == nil || !strings.Contains(err.Error(), "not a container") {
+		t.Errorf("Expected Listen not to be a container, got %v", err)
+	}
+}
@@ -1,7 +1,8 @@
 package core
 
 import (
-	"regexp"
+	"errors"
+	"fmt"
 	"strings"
 )
 
@@ -12,37 +13,355 @@ const (
 	MeaningFullOutput
 )
 
-func DecomposeKey(notationStyle NotationStyle, key string) []string {
+// DecomposeKey splits a key into its segments, returning an error for
+// malformed keys (e.g. an unterminated quote or an empty segment)
+func DecomposeKey(notationStyle NotationStyle, key string) ([]string, error) {
+	var parts []string
+	var err error
+
+	switch notationStyle {
+	case BracketsNotation:
+		parts, err = DecomposeKeyWithBracketNotation(key)
+	case PointerNotation:
+		parts, err = DecomposeKeyWithPointerNotation(key)
+	default:
+		parts, err = DecomposeKeyWithDotNotation(key)
+	}
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid key %s: %s", key, err.Error()))
+	}
+
+	return parts, nil
+}
+
+// DecomposeKeyWithBracketNotation splits a "key[foo][bar]" key. A bracket
+// which is part of a name is escaped with a backslash ("key[foo\[0\]]").
+func DecomposeKeyWithBracketNotation(key string) ([]string, error) {
+	parts := []string{}
+	current := strings.Builder{}
+	inBrackets := false
+	closed := false
+
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+		afterBrackets := closed
+		if closed && c != '[' {
+			return nil, errors.New("expected \"[\" after \"]\"")
+		}
+		closed = false
+
+		switch {
+		case c == '\\':
+			if i+1 == len(key) {
+				return nil, errors.New("trailing backslash")
+			}
+			i++
+			current.WriteByte(key[i])
+		case c == '[' && !inBrackets:
+			if i > 0 && !afterBrackets {
+				if current.Len() == 0 {
+					return nil, errors.New("empty segment")
+				}
+				parts = append(parts, current.String())
+				current.Reset()
+			}
+			inBrackets = true
+		case c == ']' && inBrackets:
+			if current.Len() == 0 {
+				return nil, errors.New("empty segment")
+			}
+			parts = append(parts, current.String())
+			current.Reset()
+			inBrackets = false
+			closed = true
+		case c == '[' || c == ']':
+			return nil, errors.New(fmt.Sprintf("unexpected \"%c\", escape it with a backslash", c))
+		default:
+			current.WriteByte(c)
+		}
+	}
+
+	if inBrackets {
+		return nil, errors.New("missing \"]\"")
+	}
+	if current.Len() > 0 {
+		parts = append(parts, current.String())
+	}
+	if len(parts) == 0 {
+		return nil, errors.New("empty key")
+	}
+
+	return parts, nil
+}
+
+// DecomposeKeyWithDotNotation splits a "key.foo.bar" key. A dot which is part
+// of a name is escaped with a backslash ("foo\.bar"), or the segment is quoted
+// ('CLI Server'."cli_server.color"), a backslash escaping the next character
+// in quotes.
+func DecomposeKeyWithDotNotation(key string) ([]string, error) {
+	segments, err := decomposeDotNotation(key, false)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := []string{}
+	for _, segment := range segments {
+		parts = append(parts, segment.Name)
+	}
+
+	return parts, nil
+}
+
+// KeySegment is a segment of a key. Literal is true for the segments of a
+// dot notation key which are quoted or contain an escaped character: they
+// are names as written, which are neither joined with the next ones nor
+// patterns.
+type KeySegment struct {
+	Name    string
+	Literal bool
+}
+
+// DecomposeKeySegments splits a key like DecomposeKey, telling which
+// segments are literal
+func DecomposeKeySegments(notationStyle NotationStyle, key string) ([]KeySegment, error) {
 	if notationStyle == DotNotation {
-		return DecomposeKeyWithDotNotation(key)
+		segments, err := decomposeDotNotation(key, false)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid key %s: %s", key, err.Error()))
+		}
+		return segments, nil
+	}
+
+	parts, err := DecomposeKey(notationStyle, key)
+	if err != nil {
+		return nil, err
+	}
+
+	segments := []KeySegment{}
+	for _, part := range parts {
+		segments = append(segments, KeySegment{Name: part})
+	}
+
+	return segments, nil
+}
+
+// decomposeDotNotation splits a dot notation key. With regexps, a segment
+// starting with "re:" is a regexp taking the rest of the key as is, so that
+// it can contain dots and backslashes (**.re:max_.*).
+func decomposeDotNotation(key string, regexps bool) ([]KeySegment, error) {
+	segments := []KeySegment{}
+	current := strings.Builder{}
+	// segmentStart is true at the start of a segment, where a quote starts a
+	// quoted segment
+	segmentStart := true
+	quoted := false
+	literal := false
+
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+
+		switch {
+		case c == '.':
+			if current.Len() == 0 && !quoted {
+				return nil, errors.New("empty segment")
+			}
+			segments = append(segments, KeySegment{Name: current.String(), Literal: literal})
+			current.Reset()
+			segmentStart, quoted, literal = true, false, false
+			continue
+		case quoted:
+			return nil, errors.New("expected \".\" after a quoted segment")
+		case segmentStart && regexps && strings.HasPrefix(key[i:], "re:"):
+			return append(segments, KeySegment{Name: key[i:]}), nil
+		case segmentStart && (c == '\'' || c == '"'):
+			end := i + 1
+			for end < len(key) && key[end] != c {
+				if key[end] == '\\' && end+1 < len(key) {
+					end++
+				}
+				current.WriteByte(key[end])
+				end++
+			}
+			if end >= len(key) {
+				return nil, errors.New(fmt.Sprintf("missing closing %c", c))
+			}
+			i = end
+			quoted, literal = true, true
+		case c == '\\':
+			if i+1 == len(key) {
+				return nil, errors.New("trailing backslash")
+			}
+			i++
+			current.WriteByte(key[i])
+			literal = true
+		default:
+			current.WriteByte(c)
+		}
+		segmentStart = false
+	}
+
+	if current.Len() == 0 && !quoted {
+		return nil, errors.New("empty segment")
+	}
+
+	return append(segments, KeySegment{Name: current.String(), Literal: literal}), nil
+}
+
+// DecomposeKeyWithPointerNotation splits a JSON Pointer style key
+// ("/CLI Server/cli_server.color"), "~1" being a slash and "~0" a tilde in a
+// name
+func DecomposeKeyWithPointerNotation(key string) ([]string, error) {
+	if !strings.HasPrefix(key, "/") {
+		return nil, errors.New("expected a key starting with \"/\"")
+	}
+
+	parts := strings.Split(key[1:], "/")
+	for idx, part := range parts {
+		for i := 0; i < len(part); i++ {
+			if part[i] == '~' && (i+1 == len(part) || (part[i+1] != '0' && part[i+1] != '1')) {
+				return nil, errors.New("\"~\" must be followed by 0 or 1")
+			}
+		}
+		parts[idx] = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
 	}
 
-	return DecomposeKeyWithBracketNotation(key)
+	return parts, nil
 }
 
-func DecomposeKeyWithBracketNotation(key string) []string {
-	re := regexp.MustCompile(`([^\[\]]+)|\[([^\[\]]+)\]`)
-	matches := re.FindAllStringSubmatch(key, -1)
+// DecomposeKeyWithPredicates splits a key written in dot notation, ignoring
+// the dots inside brackets so that segments can carry predicates (e.g.
+// "server[server_name=example.com].listen"). Outside of the brackets, names
+// are quoted and escaped as in DecomposeKeyWithDotNotation
+// (project.properties."java.version").
+func DecomposeKeyWithPredicates(key string) ([]string, error) {
+	parts := []string{}
+	current := strings.Builder{}
+	segmentStart := true
+	quoted := false
+	depth := 0
+	// quote is the quote opened inside brackets, if any
+	var quote byte
 
-	var result []string
-	for _, match := range matches {
-		// Add the first part (outside the brackets) or any part inside the brackets
-		if match[1] != "" {
-			result = append(result, match[1])
-		} else if match[2] != "" {
-			result = append(result, match[2])
+	for i := 0; i < len(key); i++ {
+		c := key[i]
+
+		switch {
+		case quote != 0:
+			if c == '\\' && i+1 < len(key) {
+				current.WriteByte(c)
+				i++
+				c = key[i]
+			} else if c == quote {
+				quote = 0
+			}
+		case depth > 0:
+			if c == '\'' || c == '"' {
+				quote = c
+			} else if c == '[' {
+				depth++
+			} else if c == ']' {
+				depth--
+			}
+		case c == '.':
+			if current.Len() == 0 && !quoted {
+				return nil, errors.New("empty segment")
+			}
+			parts = append(parts, current.String())
+			current.Reset()
+			segmentStart, quoted = true, false
+			continue
+		case c == '[':
+			depth++
+		case c == ']':
+			return nil, errors.New("unexpected \"]\"")
+		case quoted:
+			return nil, errors.New("expected \".\" or \"[\" after a quoted segment")
+		case segmentStart && (c == '\'' || c == '"'):
+			end := i + 1
+			for end < len(key) && key[end] != c {
+				if key[end] == '\\' && end+1 < len(key) {
+					end++
+				}
+				current.WriteByte(key[end])
+				end++
+			}
+			if end >= len(key) {
+				return nil, errors.New(fmt.Sprintf("missing closing %c", c))
+			}
+			i = end
+			quoted = true
+			segmentStart = false
+			continue
+		case c == '\\':
+			if i+1 == len(key) {
+				return nil, errors.New("trailing backslash")
+			}
+			i++
+			c = key[i]
 		}
+		current.WriteByte(c)
+		segmentStart = false
+	}
+
+	if quote != 0 {
+		return nil, errors.New(fmt.Sprintf("missing closing %c", quote))
+	}
+	if depth > 0 {
+		return nil, errors.New("missing \"]\"")
+	}
+	if current.Len() == 0 && !quoted {
+		return nil, errors.New("empty segment")
 	}
 
-	return result
+	return append(parts, current.String()), nil
 }
 
-func DecomposeKeyWithDotNotation(key string) []string {
-	if !strings.Contains(key, ".") {
-		return []string{key}
+// DecomposeKeyKeepingPredicates splits a key of a format whose segments can
+// carry predicates, the ones of a dot notation key being kept in their
+// segment
+func DecomposeKeyKeepingPredicates(notationStyle NotationStyle, key string) ([]string, error) {
+	if notationStyle != DotNotation {
+		return DecomposeKey(notationStyle, key)
 	}
 
-	keyParts := strings.Split(key, ".")
+	parts, err := DecomposeKeyWithPredicates(key)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid key %s: %s", key, err.Error()))
+	}
 
-	return keyParts
+	return parts, nil
+}
+
+// ComposeKey returns the key of a path in the given notation, escaping the
+// names which could not be read back as a single segment
+func ComposeKey(notationStyle NotationStyle, path []string) string {
+	segments := []string{}
+
+	switch notationStyle {
+	case PointerNotation:
+		escaper := strings.NewReplacer("~", "~0", "/", "~1")
+		for _, segment := range path {
+			segments = append(segments, "/"+escaper.Replace(segment))
+		}
+		return strings.Join(segments, "")
+	case BracketsNotation:
+		escaper := strings.NewReplacer(`\`, `\\`, "[", `\[`, "]", `\]`)
+		for idx, segment := range path {
+			if idx == 0 {
+				segments = append(segments, escaper.Replace(segment))
+			} else {
+				segments = append(segments, "["+escaper.Replace(segment)+"]")
+			}
+		}
+		return strings.Join(segments, "")
+	default:
+		escaper := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+		for _, segment := range path {
+			if segment == "" || strings.ContainsAny(segment, `.'"\`) {
+				segment = `"` + escaper.Replace(segment) + `"`
+			}
+			segments = append(segments, segment)
+		}
+		return strings.Join(segments, ".")
+	}
 }
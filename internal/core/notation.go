@@ -1,10 +1,18 @@
 package core
 
+import (
+	"errors"
+	"fmt"
+)
+
 type NotationStyle int
 
 const (
 	DotNotation = iota
 	BracketsNotation
+	// PointerNotation is the JSON Pointer style "/key/foo/bar", where any
+	// name can be written without ambiguity
+	PointerNotation
 )
 
 func GetNotationStyle(useBrackets bool) NotationStyle {
@@ -14,3 +22,29 @@ func GetNotationStyle(useBrackets bool) NotationStyle {
 
 	return DotNotation
 }
+
+// ParseNotationStyle returns the notation style of a --notation flag
+func ParseNotationStyle(name string) (NotationStyle, error) {
+	switch name {
+	case "dot":
+		return DotNotation, nil
+	case "brackets":
+		return BracketsNotation, nil
+	case "pointer":
+		return PointerNotation, nil
+	default:
+		return DotNotation, errors.New(fmt.Sprintf("Unknown notation %s, expected dot, brackets or pointer", name))
+	}
+}
+
+// String returns the name of the notation style, as given to --notation
+func (notationStyle NotationStyle) String() string {
+	switch notationStyle {
+	case BracketsNotation:
+		return "brackets"
+	case PointerNotation:
+		return "pointer"
+	default:
+		return "dot"
+	}
+}
@@ -0,0 +1,184 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type SegmentKind int
+
+const (
+	LiteralSegment SegmentKind = iota
+	// GlobSegment is a segment with "*" (any characters), "?" (any
+	// character) or "{a,b}" (alternatives)
+	GlobSegment
+	// RecursiveSegment is "**", matching any number of segments
+	RecursiveSegment
+	// RegexpSegment is "re:<regexp>", matching the whole segment
+	RegexpSegment
+)
+
+type PatternSegment struct {
+	Kind   SegmentKind
+	Value  string
+	regexp *regexp.Regexp
+}
+
+// KeyPattern is a key whose segments can be patterns, e.g. "*.memory_limit"
+// or "**.re:max_.*"
+type KeyPattern []PatternSegment
+
+// ParseKeyPattern decomposes a key and parses the patterns of its segments.
+// The quoted or escaped segments of a dot notation key are literal
+// ('*.indent_style'), and a "re:" segment takes the rest of the key, dots
+// and backslashes included: a regexp followed by other segments needs
+// another notation (/**/re:pm\..*/value).
+func ParseKeyPattern(notationStyle NotationStyle, key string) (KeyPattern, error) {
+	var segments []KeySegment
+	var err error
+	if notationStyle == DotNotation {
+		segments, err = decomposeDotNotation(key, true)
+		if err != nil {
+			err = errors.New(fmt.Sprintf("Invalid key %s: %s", key, err.Error()))
+		}
+	} else {
+		segments, err = DecomposeKeySegments(notationStyle, key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	pattern := KeyPattern{}
+	for _, keySegment := range segments {
+		if keySegment.Literal {
+			pattern = append(pattern, PatternSegment{Kind: LiteralSegment, Value: keySegment.Name})
+			continue
+		}
+
+		segment, err := parsePatternSegment(keySegment.Name)
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid key %s: %s", key, err.Error()))
+		}
+		pattern = append(pattern, segment)
+	}
+
+	return pattern, nil
+}
+
+func parsePatternSegment(part string) (PatternSegment, error) {
+	segment := PatternSegment{Kind: LiteralSegment, Value: part}
+
+	switch {
+	case part == "**":
+		segment.Kind = RecursiveSegment
+	case strings.HasPrefix(part, "re:"):
+		compiled, err := regexp.Compile("^(?:" + part[3:] + ")$")
+		if err != nil {
+			return PatternSegment{}, errors.New(fmt.Sprintf("invalid regexp %s", part[3:]))
+		}
+		segment.Kind, segment.regexp = RegexpSegment, compiled
+	case strings.ContainsAny(part, "*?{"):
+		compiled, err := globToRegexp(part)
+		if err != nil {
+			return PatternSegment{}, err
+		}
+		segment.Kind, segment.regexp = GlobSegment, compiled
+	}
+
+	return segment, nil
+}
+
+func globToRegexp(glob string) (*regexp.Regexp, error) {
+	var builder strings.Builder
+	builder.WriteString("^")
+
+	inAlternatives := false
+	for _, r := range glob {
+		switch {
+		case r == '*':
+			builder.WriteString(".*")
+		case r == '?':
+			builder.WriteString(".")
+		case r == '{' && !inAlternatives:
+			builder.WriteString("(?:")
+			inAlternatives = true
+		case r == ',' && inAlternatives:
+			builder.WriteString("|")
+		case r == '}' && inAlternatives:
+			builder.WriteString(")")
+			inAlternatives = false
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(r)))
+		}
+	}
+	if inAlternatives {
+		return nil, errors.New(fmt.Sprintf("missing \"}\" in %s", glob))
+	}
+	builder.WriteString("$")
+
+	return regexp.MustCompile(builder.String()), nil
+}
+
+// IsLiteral returns true if the key has no pattern, designating a single key
+func (pattern KeyPattern) IsLiteral() bool {
+	for _, segment := range pattern {
+		if segment.Kind != LiteralSegment {
+			return false
+		}
+	}
+
+	return true
+}
+
+func (segment PatternSegment) matches(name string) bool {
+	if segment.Kind == LiteralSegment {
+		return segment.Value == name
+	}
+
+	return segment.regexp.MatchString(name)
+}
+
+// Match returns true if a path matches the pattern
+func (pattern KeyPattern) Match(path []string) bool {
+	if len(pattern) == 0 {
+		return len(path) == 0
+	}
+
+	if pattern[0].Kind == RecursiveSegment {
+		for skipped := 0; skipped <= len(path); skipped++ {
+			if pattern[1:].Match(path[skipped:]) {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	return len(path) > 0 && pattern[0].matches(path[0]) && pattern[1:].Match(path[1:])
+}
+
+// FindAll returns the scalars of a document matching the pattern, in the
+// order of the document. The items of a list of scalars (e.g. a repeated
+// key) match when the list does.
+func (pattern KeyPattern) FindAll(document *Node) []*Node {
+	matches := []*Node{}
+
+	document.Walk(func(node *Node) error {
+		if node.Kind == ScalarNode && pattern.Match(node.Path) {
+			matches = append(matches, node)
+		}
+		if node.Kind == ListNode && pattern.Match(node.Path) {
+			for _, item := range node.Items {
+				if item.Kind == ScalarNode && !pattern.Match(item.Path) {
+					matches = append(matches, item)
+				}
+			}
+		}
+
+		return nil
+	})
+
+	return matches
+}
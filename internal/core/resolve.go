@@ -0,0 +1,103 @@
+package core
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// SectionKey is a key of a file with one level of sections, where section
+// names and keys can contain dots (e.g. the key session.save_path of the
+// section [Session] in php.ini). Global keys have an empty section.
+type SectionKey struct {
+	Section string
+	Key     string
+	Global  bool
+}
+
+// ResolveSectionKey finds the section and the key designated by a key,
+// whatever its depth. In dot notation, segments are joined with dots, trying
+// the longest section name first: "a.b.c" can be the key c of [a.b], the key
+// b.c of [a] or the global key a.b.c. The candidates for which exists returns
+// true are kept, and an error lists them if there are several, written so
+// that each designates only itself. A key with a quoted or escaped segment,
+// or in another notation, is taken as written: its last segment is the key,
+// in the section named by the other one if any.
+func ResolveSectionKey(
+	notationStyle NotationStyle,
+	key string,
+	exists func(candidate SectionKey) bool,
+) (SectionKey, error) {
+	segments, err := DecomposeKeySegments(notationStyle, key)
+	if err != nil {
+		return SectionKey{}, err
+	}
+
+	path := []string{}
+	literal := notationStyle != DotNotation
+	for _, segment := range segments {
+		path = append(path, segment.Name)
+		literal = literal || segment.Literal
+	}
+
+	if literal {
+		var candidate SectionKey
+		switch len(path) {
+		case 1:
+			candidate = SectionKey{Key: path[0], Global: true}
+		case 2:
+			candidate = SectionKey{Section: path[0], Key: path[1]}
+		default:
+			return SectionKey{}, errors.New(fmt.Sprintf("Invalid key %s, expected a section and a key", key))
+		}
+		if !exists(candidate) {
+			return SectionKey{}, errors.New("Key not found")
+		}
+		return candidate, nil
+	}
+
+	matches := []SectionKey{}
+	for idx := len(path) - 1; idx >= 0; idx-- {
+		candidate := SectionKey{
+			Section: strings.Join(path[:idx], "."),
+			Key:     strings.Join(path[idx:], "."),
+			Global:  idx == 0,
+		}
+		if exists(candidate) {
+			matches = append(matches, candidate)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return SectionKey{}, errors.New("Key not found")
+	case 1:
+		return matches[0], nil
+	}
+
+	candidates := []string{}
+	for _, match := range matches {
+		candidates = append(candidates, match.compose())
+	}
+
+	return SectionKey{}, errors.New(fmt.Sprintf(
+		"Ambiguous key %s, it matches %s",
+		key, strings.Join(candidates, " and "),
+	))
+}
+
+// compose returns the dot notation key of the section key, with at least
+// one quoted segment so that it is taken as written
+func (sectionKey SectionKey) compose() string {
+	if sectionKey.Global {
+		// The key has dots, otherwise it would not be ambiguous
+		return ComposeKey(DotNotation, []string{sectionKey.Key})
+	}
+
+	composed := ComposeKey(DotNotation, []string{sectionKey.Section, sectionKey.Key})
+	if composed == sectionKey.Section+"."+sectionKey.Key {
+		return `"` + sectionKey.Section + `".` + sectionKey.Key
+	}
+
+	return composed
+}
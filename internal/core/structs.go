@@ -20,3 +20,68 @@ type Configurator interface {
 		value string,
 	) (Configuration, error)
 }
+
+// TypedConfigurator is implemented by configurators whose values carry a type
+// (e.g. TOML), so that the type of a value can be forced when setting it.
+type TypedConfigurator interface {
+	SetTypedParameter(
+		notationStyle NotationStyle,
+		filePath string,
+		key string,
+		value string,
+		valueType string,
+	) (Configuration, error)
+}
+
+// ParameterAdder is implemented by configurators supporting repeated keys, to
+// add a new occurrence of a key instead of replacing the existing one
+type ParameterAdder interface {
+	AddParameter(
+		notationStyle NotationStyle,
+		filePath string,
+		key string,
+		value string,
+	) (Configuration, error)
+}
+
+// ParameterRemover is implemented by configurators able to remove a key
+type ParameterRemover interface {
+	UnsetParameter(
+		notationStyle NotationStyle,
+		filePath string,
+		key string,
+	) (Configuration, error)
+}
+
+// TreeWriter is implemented by configurators which can be written from
+// another format, reporting what cannot be written
+type TreeWriter interface {
+	WriteTree(tree *Node, losses *Losses) (string, error)
+}
+
+// DocumentReader is implemented by configurators exposing their files as a
+// document tree, with the path, comments and lines of each node, so that
+// commands can work on any format (listing, querying, converting...). What
+// the tree does not keep (e.g. comments when converting) is reported to
+// losses, which can be nil.
+type DocumentReader interface {
+	ReadDocument(filePath string, losses *Losses) (*Node, error)
+}
+
+// ExistingKeySetter is implemented by the configurators which may only set
+// the keys already in their files (INI, the records of hosts files...), so
+// that commands can reject what can never succeed, such as setting a key if
+// it is missing
+type ExistingKeySetter interface {
+	Configurator
+	SetsExistingKeysOnly() bool
+}
+
+// KeyPatternMatcher is implemented by the document readers whose tree paths
+// are the keys of their files (INI, TOML...), so that the segments of a key
+// can be patterns matched against the tree. The keys of the other
+// configurators are always literal (e.g. Apache's VirtualHost[*:443]).
+type KeyPatternMatcher interface {
+	DocumentReader
+	MatchesKeyPatterns()
+}
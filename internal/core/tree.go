@@ -0,0 +1,241 @@
+package core
+
+import (
+	"fmt"
+	"strconv"
+)
+
+type NodeKind int
+
+const (
+	ScalarNode NodeKind = iota
+	MapNode
+	ListNode
+	// SectionNode is a map defined by a section of the file (e.g. an INI
+	// section or a TOML table)
+	SectionNode
+)
+
+// ScalarType is the type of a scalar value. Values of formats without types
+// (INI, flat files...) are untyped.
+type ScalarType int
+
+const (
+	UntypedScalar ScalarType = iota
+	StringScalar
+	IntegerScalar
+	FloatScalar
+	BoolScalar
+	DateTimeScalar
+)
+
+// Span is the lines of the file a node comes from, starting at 1
+type Span struct {
+	Start int
+	End   int
+}
+
+// Node is a format-agnostic element of a configuration document, used by
+// the commands working on any format (conversion, listing...): sections are
+// maps and repeated keys are lists. Scalars are normalized: integers in
+// decimal, booleans as "true" or "false" and datetimes in RFC 3339.
+type Node struct {
+	Kind  NodeKind
+	Type  ScalarType
+	Value string
+	// Keys are the keys of a map, in the order of the file
+	Keys     []string
+	Children map[string]*Node
+	Items    []*Node
+	// Path is the path of the node from the root of the document, list
+	// items being designated by their index, or by their position in the
+	// formats which have a syntax for it (server[2], see AddIndexed)
+	Path []string
+	// Comments are the comments attached to the node (the comment lines
+	// right above it and its inline comment), without comment markers
+	Comments []string
+	// Span is empty for nodes which are not read from a file
+	Span Span
+}
+
+func NewMapNode() *Node {
+	return &Node{Kind: MapNode, Children: map[string]*Node{}}
+}
+
+func NewListNode() *Node {
+	return &Node{Kind: ListNode, Items: []*Node{}}
+}
+
+func NewScalarNode(scalarType ScalarType, value string) *Node {
+	return &Node{Kind: ScalarNode, Type: scalarType, Value: value}
+}
+
+// IsMap returns true for maps and sections
+func (node *Node) IsMap() bool {
+	return node.Kind == MapNode || node.Kind == SectionNode
+}
+
+// Set sets the child of a map, keeping the position of an existing key
+func (node *Node) Set(key string, child *Node) {
+	if _, exists := node.Children[key]; !exists {
+		node.Keys = append(node.Keys, key)
+	}
+	node.Children[key] = child
+}
+
+func (node *Node) Get(key string) *Node {
+	return node.Children[key]
+}
+
+// Add adds a value to a map, turning the existing value of a repeated key
+// into a list whose items are the occurrences of the key
+func (node *Node) Add(key string, child *Node) {
+	existing := node.Get(key)
+	switch {
+	case existing == nil:
+		node.Set(key, child)
+	case existing.Kind == ListNode:
+		existing.append(child)
+	default:
+		list := NewListNode()
+		list.Path = existing.Path
+		list.Span = existing.Span
+		list.append(existing)
+		list.append(child)
+		node.Set(key, list)
+	}
+}
+
+// AddIndexed adds a value to a map like Add, for the formats designating
+// the occurrences of a repeated key by their position, starting at 1: the
+// path of the occurrences of a repeated key (and of their descendants) is
+// the key followed by the position in brackets (server[2]). The values must
+// not be lists.
+func (node *Node) AddIndexed(key string, child *Node) {
+	existing := node.Get(key)
+	if existing == nil {
+		node.Set(key, child)
+		return
+	}
+
+	list := existing
+	if existing.Kind != ListNode {
+		list = NewListNode()
+		list.Path = existing.Path
+		list.Span = existing.Span
+		list.Items = append(list.Items, existing)
+		existing.setPath(indexedPath(list.Path, key, 1))
+		node.Set(key, list)
+	}
+
+	list.Items = append(list.Items, child)
+	if child.Span.End > list.Span.End {
+		list.Span.End = child.Span.End
+	}
+	child.setPath(indexedPath(list.Path, key, len(list.Items)))
+}
+
+// indexedPath returns the path of an occurrence of a repeated key, from the
+// path of the key
+func indexedPath(path []string, key string, position int) []string {
+	if path == nil {
+		return nil
+	}
+
+	return append(append([]string{}, path[:len(path)-1]...), fmt.Sprintf("%s[%d]", key, position))
+}
+
+// append adds an item to a list, updating its path and the span of the list
+func (node *Node) append(item *Node) {
+	if node.Path != nil {
+		item.setPath(append(append([]string{}, node.Path...), strconv.Itoa(len(node.Items))))
+	}
+	if item.Span.End > node.Span.End {
+		node.Span.End = item.Span.End
+	}
+	node.Items = append(node.Items, item)
+}
+
+// setPath sets the path of a node and updates the paths of its descendants,
+// which start with the path of the node
+func (node *Node) setPath(path []string) {
+	if path == nil {
+		return
+	}
+
+	depth := len(node.Path)
+	node.Walk(func(descendant *Node) error {
+		if descendant == node || len(descendant.Path) >= depth && descendant.Path != nil {
+			descendant.Path = append(append([]string{}, path...), descendant.Path[depth:]...)
+		}
+
+		return nil
+	})
+}
+
+// Walk calls fn for the node and its descendants, in the order of the
+// document, stopping at the first error
+func (node *Node) Walk(fn func(node *Node) error) error {
+	if err := fn(node); err != nil {
+		return err
+	}
+
+	children := node.Items
+	for _, key := range node.Keys {
+		children = append(children, node.Children[key])
+	}
+	for _, child := range children {
+		if err := child.Walk(fn); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (scalarType ScalarType) String() string {
+	switch scalarType {
+	case StringScalar:
+		return "string"
+	case IntegerScalar:
+		return "integer"
+	case FloatScalar:
+		return "float"
+	case BoolScalar:
+		return "boolean"
+	case DateTimeScalar:
+		return "datetime"
+	default:
+		return "untyped"
+	}
+}
+
+// Losses collects what is lost when converting a configuration, e.g.
+// comments or the types of the values
+type Losses struct {
+	Messages []string
+}
+
+// Add adds a message, once. Nil losses ignore it.
+func (losses *Losses) Add(format string, args ...interface{}) {
+	if losses == nil {
+		return
+	}
+
+	message := fmt.Sprintf(format, args...)
+	for _, existing := range losses.Messages {
+		if existing == message {
+			return
+		}
+	}
+
+	losses.Messages = append(losses.Messages, message)
+}
+
+// TypedValue reports the loss of the type of a scalar written to a format
+// without types
+func (losses *Losses) TypedValue(node *Node) {
+	if node.Type != UntypedScalar && node.Type != StringScalar {
+		losses.Add("%s values are written as strings", node.Type)
+	}
+}
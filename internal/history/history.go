@@ -0,0 +1,177 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/einenlum/edicon/internal/transaction"
+)
+
+// Change is a change of a key written by edicon. A key which did not exist
+// before the change, or which was removed by it, is "missing".
+type Change struct {
+	// ID is unique among the changes, e.g. "20240527-073200.123456#0"
+	ID          string    `json:"id"`
+	Transaction string    `json:"transaction"`
+	Time        time.Time `json:"time"`
+	User        string    `json:"user"`
+	Command     string    `json:"command"`
+	File        string    `json:"file"`
+	// Type is the configuration type of the file (ini, toml...)
+	Type       string `json:"type"`
+	Notation   string `json:"notation"`
+	Key        string `json:"key"`
+	Old        string `json:"old,omitempty"`
+	OldMissing bool   `json:"old_missing,omitempty"`
+	New        string `json:"new,omitempty"`
+	NewMissing bool   `json:"new_missing,omitempty"`
+	// Added is true for a new occurrence of a repeated key
+	Added bool `json:"added,omitempty"`
+	// Undoes is the ID of the change undone by this one
+	Undoes string `json:"undoes,omitempty"`
+}
+
+func logPath() (string, error) {
+	stateDir, err := transaction.StateDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(stateDir, "history.jsonl"), nil
+}
+
+// Record appends the changes written by a transaction to the change log
+func Record(tx *transaction.Transaction, user string, changes []Change) error {
+	path, err := logPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return err
+	}
+
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	for idx, change := range changes {
+		change.ID = tx.ID + "#" + strconv.Itoa(idx)
+		change.Transaction = tx.ID
+		change.Time = tx.Time
+		change.User = user
+		change.Command = tx.Command
+
+		line, err := json.Marshal(change)
+		if err != nil {
+			return err
+		}
+		if _, err := file.Write(append(line, '\n')); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Load returns the changes of the change log, the oldest first
+func Load() ([]Change, error) {
+	path, err := logPath()
+	if err != nil {
+		return nil, err
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []Change{}, nil
+		}
+		return nil, err
+	}
+	defer file.Close()
+
+	changes := []Change{}
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+	for lineNumber := 1; scanner.Scan(); lineNumber++ {
+		var change Change
+		if err := json.Unmarshal(scanner.Bytes(), &change); err != nil {
+			return nil, errors.New(fmt.Sprintf("Invalid change log on line %d: %s", lineNumber, err.Error()))
+		}
+		changes = append(changes, change)
+	}
+
+	return changes, scanner.Err()
+}
+
+// Step is the changes of a transaction
+type Step struct {
+	Transaction string
+	Changes     []Change
+}
+
+// Steps groups the changes of a file (all the files if it is empty) by
+// transaction, the oldest first
+func Steps(changes []Change, file string) []Step {
+	steps := []Step{}
+	for _, change := range changes {
+		if file != "" && change.File != file {
+			continue
+		}
+		if len(steps) == 0 || steps[len(steps)-1].Transaction != change.Transaction {
+			steps = append(steps, Step{change.Transaction, []Change{}})
+		}
+		last := &steps[len(steps)-1]
+		last.Changes = append(last.Changes, change)
+	}
+
+	return steps
+}
+
+// Undone returns the IDs of the changes which were undone
+func Undone(changes []Change) map[string]bool {
+	undone := map[string]bool{}
+	for _, change := range changes {
+		if change.Undoes != "" {
+			undone[change.Undoes] = true
+		}
+	}
+
+	return undone
+}
+
+// Revertible returns false for the changes which cannot be reverted: the
+// occurrences added to a repeated key, as they cannot be removed alone
+func (change Change) Revertible() bool {
+	return !change.Added || change.OldMissing
+}
+
+// UndoableSteps returns the steps which can be undone, the last first: the
+// revertible changes which are not undone, and which do not undo another
+// change
+func UndoableSteps(changes []Change, file string) []Step {
+	undone := Undone(changes)
+
+	steps := []Step{}
+	all := Steps(changes, file)
+	for idx := len(all) - 1; idx >= 0; idx-- {
+		step := Step{all[idx].Transaction, []Change{}}
+		for _, change := range all[idx].Changes {
+			if change.Undoes == "" && !undone[change.ID] && change.Revertible() {
+				step.Changes = append(step.Changes, change)
+			}
+		}
+		if len(step.Changes) > 0 {
+			steps = append(steps, step)
+		}
+	}
+
+	return steps
+}
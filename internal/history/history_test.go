@@ -0,0 +1,82 @@
+package history
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/transaction"
+)
+
+func record(t *testing.T, changes ...Change) *transaction.Transaction {
+	tx, err := transaction.Begin("edicon ini set -w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Record(tx, "jane", changes); err != nil {
+		t.Fatal(err)
+	}
+
+	return tx
+}
+
+func stepKeys(steps []Step) [][]string {
+	result := [][]string{}
+	for _, step := range steps {
+		keys := []string{}
+		for _, change := range step.Changes {
+			keys = append(keys, change.Key)
+		}
+		result = append(result, keys)
+	}
+
+	return result
+}
+
+func TestRecordAndLoad(t *testing.T) {
+	t.Setenv("EDICON_STATE_DIR", t.TempDir())
+
+	changes, err := Load()
+	if err != nil || len(changes) != 0 {
+		t.Fatalf("Expected an empty change log, got %v %v", changes, err)
+	}
+
+	tx := record(t, Change{File: "/a.ini", Key: "a", Old: "1", New: "2"}, Change{File: "/b.ini", Key: "b", OldMissing: true, New: "x"})
+
+	changes, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := Change{
+		ID: tx.ID + "#1", Transaction: tx.ID, Time: changes[1].Time, User: "jane", Command: "edicon ini set -w",
+		File: "/b.ini", Key: "b", OldMissing: true, New: "x",
+	}
+	if len(changes) != 2 || !reflect.DeepEqual(changes[1], expected) {
+		t.Errorf("Expected the second change to be %v, got %v", expected, changes)
+	}
+	if !changes[1].Time.Equal(tx.Time) {
+		t.Errorf("Expected the time of the transaction, got %v", changes[1].Time)
+	}
+}
+
+func TestUndoableSteps(t *testing.T) {
+	t.Setenv("EDICON_STATE_DIR", t.TempDir())
+
+	first := record(t, Change{File: "/a.ini", Key: "a1"}, Change{File: "/b.ini", Key: "b1"})
+	record(t, Change{File: "/a.ini", Key: "a2"})
+	record(t, Change{File: "/b.ini", Key: "b2", Added: true}, Change{File: "/b.ini", Key: "b3", Added: true, OldMissing: true})
+	record(t, Change{File: "/a.ini", Key: "a1", Undoes: first.ID + "#0"})
+
+	changes, _ := Load()
+	if actual := stepKeys(Steps(changes, "")); !reflect.DeepEqual(actual, [][]string{{"a1", "b1"}, {"a2"}, {"b2", "b3"}, {"a1"}}) {
+		t.Errorf("Unexpected steps %v", actual)
+	}
+	if actual := stepKeys(Steps(changes, "/b.ini")); !reflect.DeepEqual(actual, [][]string{{"b1"}, {"b2", "b3"}}) {
+		t.Errorf("Unexpected steps of b.ini %v", actual)
+	}
+	if actual := stepKeys(UndoableSteps(changes, "")); !reflect.DeepEqual(actual, [][]string{{"b3"}, {"a2"}, {"b1"}}) {
+		t.Errorf("Unexpected undoable steps %v", actual)
+	}
+	if actual := stepKeys(UndoableSteps(changes, "/a.ini")); !reflect.DeepEqual(actual, [][]string{{"a2"}}) {
+		t.Errorf("Unexpected undoable steps of a.ini %v", actual)
+	}
+}
@@ -51,3 +51,7 @@ func WriteFileContents(filepath string, content string) error {
 
 	return nil
 }
+
+func RemoveFile(filepath string) error {
+	return os.Remove(filepath)
+}
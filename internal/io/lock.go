@@ -0,0 +1,102 @@
+package io
+
+import (
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// ErrLocked is returned when a file is locked by another process
+var ErrLocked = errors.New("locked")
+
+const lockPollInterval = 50 * time.Millisecond
+
+// Lock is an advisory lock on a file, taken on a sidecar ".<name>.edicon.lock"
+// file next to it: the file itself cannot hold the lock, as it is replaced
+// when written.
+type Lock struct {
+	file *os.File
+	path string
+}
+
+func lockPath(filePath string) string {
+	if resolved, err := filepath.EvalSymlinks(filePath); err == nil {
+		filePath = resolved
+	}
+
+	return filepath.Join(filepath.Dir(filePath), "."+filepath.Base(filePath)+".edicon.lock")
+}
+
+// LockFile takes the lock of a file. If it is held by another process, it
+// fails at once unless wait is true, in which case it waits for the lock,
+// up to the timeout if it is not zero.
+func LockFile(filePath string, wait bool, timeout time.Duration) (*Lock, error) {
+	path := lockPath(filePath)
+	deadline := time.Now().Add(timeout)
+
+	for {
+		lock, err := tryLock(path)
+		if err == nil {
+			return lock, nil
+		}
+		if !errors.Is(err, ErrLocked) {
+			return nil, errors.New(fmt.Sprintf("Cannot lock %s: %s", filePath, err.Error()))
+		}
+
+		if !wait {
+			return nil, errors.New(fmt.Sprintf("%s is being edited by another process, use --wait to wait for it", filePath))
+		}
+		if timeout > 0 && time.Now().After(deadline) {
+			return nil, errors.New(fmt.Sprintf("%s is still being edited by another process after %s", filePath, timeout))
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
+
+// TryLockFile takes the lock of a file, returning ErrLocked at once if it is
+// held by another process
+func TryLockFile(filePath string) (*Lock, error) {
+	return tryLock(lockPath(filePath))
+}
+
+// Fingerprint identifies the content of a file when it was read, or its
+// absence if it did not exist yet
+type Fingerprint struct {
+	Absent  bool
+	Size    int64
+	ModTime time.Time
+	Hash    [sha256.Size]byte
+}
+
+func GetFingerprint(filePath string) (Fingerprint, error) {
+	info, err := os.Stat(filePath)
+	if os.IsNotExist(err) {
+		return Fingerprint{Absent: true}, nil
+	}
+	if err != nil {
+		return Fingerprint{}, err
+	}
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		return Fingerprint{}, err
+	}
+
+	return Fingerprint{Size: info.Size(), ModTime: info.ModTime(), Hash: sha256.Sum256(content)}, nil
+}
+
+// CheckUnchanged returns an error if a file changed since its fingerprint
+// was taken
+func CheckUnchanged(filePath string, fingerprint Fingerprint) error {
+	current, err := GetFingerprint(filePath)
+	if err == nil && fingerprint.Absent && !current.Absent {
+		return errors.New(fmt.Sprintf("%s: file created since read", filePath))
+	}
+	if err != nil || current.Absent != fingerprint.Absent || current.Size != fingerprint.Size || !current.ModTime.Equal(fingerprint.ModTime) || current.Hash != fingerprint.Hash {
+		return errors.New(fmt.Sprintf("%s: file changed since read", filePath))
+	}
+
+	return nil
+}
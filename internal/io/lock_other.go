@@ -0,0 +1,26 @@
+//go:build !unix
+
+package io
+
+import (
+	"os"
+)
+
+// tryLock creates the lock file, which exists as long as the lock is held
+func tryLock(path string) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_RDWR, 0600)
+	if err != nil {
+		if os.IsExist(err) {
+			return nil, ErrLocked
+		}
+		return nil, err
+	}
+
+	return &Lock{file, path}, nil
+}
+
+func (lock *Lock) Unlock() error {
+	lock.file.Close()
+
+	return os.Remove(lock.path)
+}
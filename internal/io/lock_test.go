@@ -0,0 +1,91 @@
+package io
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLockFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "php.ini")
+	if err := os.WriteFile(filePath, []byte("a=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	lock, err := LockFile(filePath, false, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = LockFile(filePath, false, 0)
+	if err == nil || !strings.Contains(err.Error(), "is being edited by another process") {
+		t.Errorf("Expected the file to be locked, got %v", err)
+	}
+
+	_, err = LockFile(filePath, true, 100*time.Millisecond)
+	if err == nil || !strings.Contains(err.Error(), "is still being edited by another process after 100ms") {
+		t.Errorf("Expected the wait to time out, got %v", err)
+	}
+
+	go func() {
+		time.Sleep(100 * time.Millisecond)
+		lock.Unlock()
+	}()
+	other, err := LockFile(filePath, true, 0)
+	if err != nil {
+		t.Fatalf("Expected the lock to be taken once released, got %v", err)
+	}
+	other.Unlock()
+}
+
+func TestCheckUnchanged(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "php.ini")
+	if err := os.WriteFile(filePath, []byte("a=1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	fingerprint, err := GetFingerprint(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckUnchanged(filePath, fingerprint); err != nil {
+		t.Errorf("Expected the file to be unchanged, got %v", err)
+	}
+
+	if err := os.WriteFile(filePath, []byte("a=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = CheckUnchanged(filePath, fingerprint)
+	if err == nil || !strings.Contains(err.Error(), "file changed since read") {
+		t.Errorf("Expected the file to be changed, got %v", err)
+	}
+
+	if err := os.Remove(filePath); err != nil {
+		t.Fatal(err)
+	}
+	if err := CheckUnchanged(filePath, fingerprint); err == nil {
+		t.Error("Expected a removed file to be changed")
+	}
+}
+
+func TestCheckUnchangedAbsentFile(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "override.conf")
+
+	fingerprint, err := GetFingerprint(filePath)
+	if err != nil || !fingerprint.Absent {
+		t.Fatalf("Expected the fingerprint of a missing file to record its absence, got %v (%v)", fingerprint, err)
+	}
+	if err := CheckUnchanged(filePath, fingerprint); err != nil {
+		t.Errorf("Expected the file to be still missing, got %v", err)
+	}
+
+	if err := os.WriteFile(filePath, []byte("[Service]\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	err = CheckUnchanged(filePath, fingerprint)
+	if err == nil || !strings.Contains(err.Error(), "file created since read") {
+		t.Errorf("Expected the file to be created, got %v", err)
+	}
+}
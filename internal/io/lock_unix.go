@@ -0,0 +1,35 @@
+//go:build unix
+
+package io
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+func tryLock(path string) (*Lock, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0600)
+	if err != nil {
+		return nil, err
+	}
+
+	err = syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	if err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, ErrLocked
+		}
+		return nil, err
+	}
+
+	return &Lock{file, path}, nil
+}
+
+// Unlock releases the lock. The lock file is kept, as removing it would let
+// another process lock a file which is no longer the lock file.
+func (lock *Lock) Unlock() error {
+	defer lock.file.Close()
+
+	return syscall.Flock(int(lock.file.Fd()), syscall.LOCK_UN)
+}
@@ -0,0 +1,269 @@
+package apache
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/blockpath"
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+var (
+	indentRegexp = regexp.MustCompile(`[ \t]*$`)
+	// directive names are case insensitive
+	resolver = blockpath.Resolver{SameName: strings.EqualFold, BlockKind: "container"}
+)
+
+func (directive *Directive) DirectiveName() string {
+	return directive.Name
+}
+
+func (directive *Directive) ArgumentValues() []string {
+	values := []string{}
+	for _, argument := range directive.Arguments {
+		values = append(values, argument.Value())
+	}
+
+	return values
+}
+
+func (directive *Directive) Children() []blockpath.Directive {
+	if directive.Section == nil {
+		return nil
+	}
+
+	return directive.Section.children()
+}
+
+func (section *Section) children() []blockpath.Directive {
+	children := []blockpath.Directive{}
+	for _, directive := range section.Directives {
+		children = append(children, directive)
+	}
+
+	return children
+}
+
+// Resolve returns the directives matching the last segment of the key, and
+// the section containing them
+func Resolve(main *Section, segments []blockpath.Segment) ([]*Directive, *Section, error) {
+	matching, parent, err := resolver.Resolve(main.children(), segments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	section := main
+	if parent != nil {
+		section = parent.(*Directive).Section
+	}
+	directives := []*Directive{}
+	for _, directive := range matching {
+		directives = append(directives, directive.(*Directive))
+	}
+
+	return directives, section, nil
+}
+
+func OutputConfigFile(config *ApacheConfiguration, outputType core.OutputType) string {
+	if outputType != core.MeaningFullOutput {
+		return config.Content
+	}
+
+	return source.RemoveComments(config.Content, config.Comments)
+}
+
+func getDirectives(notationStyle core.NotationStyle, config *ApacheConfiguration, key string) ([]*Directive, *Section, []blockpath.Segment, error) {
+	segments, err := blockpath.DecomposeKey(notationStyle, key)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	directives, section, err := Resolve(config.Main, segments)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return directives, section, segments, nil
+}
+
+// GetParameterFromPath returns the arguments of the matching directives, one
+// directive per line. Continued lines are joined and the arguments are
+// unquoted unless they need their quotes.
+func GetParameterFromPath(notationStyle core.NotationStyle, filePath string, key string) (string, error) {
+	config, err := GetParsedApacheFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	directives, _, _, err := getDirectives(notationStyle, &config, key)
+	if err != nil {
+		return "", err
+	}
+	if len(directives) == 0 {
+		return "", errors.New("Key not found")
+	}
+
+	values := []string{}
+	for _, directive := range directives {
+		values = append(values, directive.ArgumentsText())
+	}
+
+	return strings.Join(values, "\n"), nil
+}
+
+// EditConfigFile sets the arguments of a directive, or adds a new directive
+// if it does not exist yet or if add is true
+func EditConfigFile(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+	add bool,
+) (*ApacheConfiguration, error) {
+	config, err := GetParsedApacheFile(filePath)
+	if err != nil {
+		return &ApacheConfiguration{}, err
+	}
+
+	if strings.ContainsAny(value, "\r\n") {
+		return &ApacheConfiguration{}, errors.New("Values cannot contain newlines")
+	}
+	// A trailing backslash would continue the directive on the next line
+	if strings.HasSuffix(value, "\\") {
+		return &ApacheConfiguration{}, errors.New("Values cannot end with a backslash")
+	}
+
+	directives, section, segments, err := getDirectives(notationStyle, &config, key)
+	if err != nil {
+		return &ApacheConfiguration{}, err
+	}
+
+	last := segments[len(segments)-1]
+	switch {
+	case !add && len(directives) == 0 && len(last.Predicates) > 0:
+		return &ApacheConfiguration{}, errors.New("Key not found")
+	case add || len(directives) == 0:
+		if len(last.Predicates) > 0 {
+			return &ApacheConfiguration{}, errors.New(fmt.Sprintf("Cannot add %s: the last part of the key cannot have a predicate", key))
+		}
+		config.insertDirective(section, last.Name, value)
+	case len(directives) > 1:
+		return &ApacheConfiguration{}, errors.New(fmt.Sprintf(
+			"%d directives match %s, use a predicate to choose one (e.g. %s[1]) or add to add a new one",
+			len(directives), key, last.Name,
+		))
+	default:
+		config.setArguments(directives[0], value)
+	}
+
+	return config.validate(key)
+}
+
+// RemoveFromConfigFile removes a directive, or a whole container
+func RemoveFromConfigFile(notationStyle core.NotationStyle, filePath string, key string) (*ApacheConfiguration, error) {
+	config, err := GetParsedApacheFile(filePath)
+	if err != nil {
+		return &ApacheConfiguration{}, err
+	}
+
+	directives, _, segments, err := getDirectives(notationStyle, &config, key)
+	if err != nil {
+		return &ApacheConfiguration{}, err
+	}
+	if len(directives) == 0 {
+		return &ApacheConfiguration{}, errors.New("Key not found")
+	}
+	if len(directives) > 1 {
+		return &ApacheConfiguration{}, errors.New(fmt.Sprintf(
+			"%d directives match %s, use a predicate to choose one (e.g. %s[1])",
+			len(directives), key, segments[len(segments)-1].Name,
+		))
+	}
+
+	directive := directives[0]
+	end := directive.Lines.End
+	if end < len(config.Content) {
+		end++
+	}
+	config.replace(source.Span{Start: directive.Lines.Start, End: end}, "")
+
+	return config.validate(key)
+}
+
+func (config *ApacheConfiguration) validate(key string) (*ApacheConfiguration, error) {
+	main, comments, err := ParseApacheContent(config.Content)
+	if err != nil {
+		return &ApacheConfiguration{}, errors.New(fmt.Sprintf("Refusing to edit %s: %s", key, err.Error()))
+	}
+	config.Main = main
+	config.Comments = comments
+
+	return config, nil
+}
+
+func (config *ApacheConfiguration) replace(span source.Span, replacement string) {
+	config.Content = config.Content[:span.Start] + replacement + config.Content[span.End:]
+}
+
+func (config *ApacheConfiguration) setArguments(directive *Directive, value string) {
+	if len(directive.Arguments) == 0 {
+		config.replace(directive.Value, " "+value)
+
+		return
+	}
+
+	config.replace(directive.Value, value)
+}
+
+func (config *ApacheConfiguration) indentOf(position int) string {
+	return indentRegexp.FindString(config.Content[:position])
+}
+
+// insertDirective adds a directive after the last directive with the same
+// name in the section, or at the end of the section
+func (config *ApacheConfiguration) insertDirective(section *Section, name string, value string) {
+	line := name
+	if value != "" {
+		line += " " + value
+	}
+
+	var reference *Directive
+	for _, existing := range section.Directives {
+		if strings.EqualFold(existing.Name, name) {
+			reference = existing
+			line = existing.Name + strings.TrimPrefix(line, name)
+		}
+	}
+	if reference == nil && len(section.Directives) > 0 {
+		reference = section.Directives[len(section.Directives)-1]
+	}
+
+	if reference != nil {
+		indent := config.indentOf(reference.NameStart)
+		if reference.Section != nil {
+			indent = config.indentOf(reference.NameStart - 1)
+		}
+		config.replace(source.Span{Start: reference.Lines.End, End: reference.Lines.End}, "\n"+indent+line)
+
+		return
+	}
+
+	if section.Container == nil {
+		if config.Content != "" && !strings.HasSuffix(config.Content, "\n") {
+			line = "\n" + line
+		}
+		config.replace(source.Span{Start: len(config.Content), End: len(config.Content)}, line+"\n")
+
+		return
+	}
+
+	containerIndent := config.indentOf(section.Container.NameStart - 1)
+	indent := containerIndent + "    "
+	if strings.HasPrefix(containerIndent, "\t") {
+		indent = containerIndent + "\t"
+	}
+	config.replace(source.Span{Start: section.Closing.Start, End: section.Closing.Start}, indent+line+"\n")
+}
@@ -0,0 +1,155 @@
+package apache
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/testutil"
+)
+
+const APACHE_FILE_PATH = "../../../data/apache/httpd.conf"
+
+func TestGetParameter(t *testing.T) {
+	cases := map[string]string{
+		"ServerRoot":                          "/etc/httpd",
+		"serverroot":                          "/etc/httpd",
+		"Listen":                              "80\n443",
+		"Listen[2]":                           "443",
+		"Include":                             "conf.modules.d/*.conf",
+		"IncludeOptional":                     "conf.d/*.conf",
+		"Directory[/].Require":                "all denied",
+		"IfModule[dir_module].DirectoryIndex": "index.html",
+		"VirtualHost[*:443].SSLEngine":        "on",
+		"VirtualHost[ServerAlias=www.example.com].DocumentRoot": "/var/www/example",
+		"VirtualHost[2].Directory[/var/www/example].Options":    "Indexes FollowSymLinks",
+		"virtualhost[*:80].redirect":                            "permanent / https://example.com/",
+		"LogFormat":                                             "\"%h %l %u %t \\\"%r\\\" %>s %b \\\"%{Referer}i\\\" \\\"%{User-Agent}i\\\"\" combined",
+	}
+
+	for key, expectedValue := range cases {
+		t.Run("it gets existing parameter "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, APACHE_FILE_PATH, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value != expectedValue {
+				t.Fatal(fmt.Sprintf("Expected %s got %s", expectedValue, value))
+			}
+		})
+	}
+
+	errorCases := []string{
+		"PidFile",
+		"VirtualHost.ServerName",
+		"VirtualHost[ServerName=example.com].DocumentRoot",
+		"VirtualHost[3].ServerName",
+		"User.foo",
+	}
+	for _, key := range errorCases {
+		t.Run("it does not get "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, APACHE_FILE_PATH, key)
+			if err == nil {
+				t.Error("Should fail. Got " + value + " instead")
+			}
+		})
+	}
+}
+
+func TestEditParameter(t *testing.T) {
+	type EditTestElement struct {
+		key   string
+		value string
+		add   bool
+		old   string
+		new   string
+	}
+
+	cases := []EditTestElement{
+		{"User", "www-data", false, "User apache\n", "User www-data\n"},
+		{"serveradmin", "admin@example.com", false, "ServerAdmin root@localhost", "ServerAdmin admin@example.com"},
+		{"VirtualHost[*:443].DocumentRoot", "/srv/www", false, "    DocumentRoot /var/www/example\n    SSLEngine", "    DocumentRoot /srv/www\n    SSLEngine"},
+		{"VirtualHost[2].Directory[1].AllowOverride", "None", false, "AllowOverride All", "AllowOverride None"},
+		{"Listen", "8443", true, "Listen 443\n", "Listen 443\nListen 8443\n"},
+		{"IfModule[dir_module].DirectoryIndex", "index.php", true, "    DirectoryIndex index.html\n", "    DirectoryIndex index.html\n    DirectoryIndex index.php\n"},
+		{"VirtualHost[*:80].ErrorLog", "logs/error_log", false, "    Redirect permanent / https://example.com/\n", "    Redirect permanent / https://example.com/\n    ErrorLog logs/error_log\n"},
+		{"PidFile", "run/httpd.pid", false, "    </Directory>\n</VirtualHost>\n", "    </Directory>\n</VirtualHost>\nPidFile run/httpd.pid\n"},
+	}
+
+	for _, element := range cases {
+		t.Run("it sets "+element.key, func(t *testing.T) {
+			config, err := EditConfigFile(core.DotNotation, APACHE_FILE_PATH, element.key, element.value, element.add)
+			testutil.AssertEditedOutput(t, APACHE_FILE_PATH, config, err, element.old, element.new)
+		})
+	}
+
+	errorCases := map[string]string{
+		"Listen":                      "8080",
+		"VirtualHost.DocumentRoot":    "/tmp",
+		"User":                        "root\nGroup root",
+		"Directory[/].AllowOverride":  "none \\",
+		"VirtualHost[3].DocumentRoot": "/tmp",
+	}
+	for key, value := range errorCases {
+		t.Run("it refuses to set "+key+" to "+value, func(t *testing.T) {
+			if _, err := EditConfigFile(core.DotNotation, APACHE_FILE_PATH, key, value, false); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestUnsetParameter(t *testing.T) {
+	cases := map[string][]string{
+		"Group":                         {"Group apache\n", ""},
+		"Listen[1]":                     {"Listen 80\n", ""},
+		"IfModule[dir_module]":          {"<IfModule dir_module>\n    DirectoryIndex index.html\n</IfModule>\n", ""},
+		"VirtualHost[*:443].Directory":  {"    <Directory \"/var/www/example\">\n        Options Indexes \\\n            FollowSymLinks\n        AllowOverride All\n    </Directory>\n", ""},
+		"VirtualHost[*:80].ServerAlias": {"    ServerAlias www.example.com\n", ""},
+	}
+
+	for key, replacement := range cases {
+		t.Run("it unsets "+key, func(t *testing.T) {
+			config, err := RemoveFromConfigFile(core.DotNotation, APACHE_FILE_PATH, key)
+			testutil.AssertEditedOutput(t, APACHE_FILE_PATH, config, err, replacement[0], replacement[1])
+		})
+	}
+
+	for _, key := range []string{"PidFile", "Listen", "VirtualHost"} {
+		t.Run("it does not unset "+key, func(t *testing.T) {
+			if _, err := RemoveFromConfigFile(core.DotNotation, APACHE_FILE_PATH, key); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestParseInvalidApache(t *testing.T) {
+	cases := []string{
+		"<VirtualHost *:80>\nServerName example.com\n",
+		"</VirtualHost>",
+		"<VirtualHost *:80>\n</Directory>",
+		"<VirtualHost *:80",
+		"<>\n</>",
+	}
+
+	for _, content := range cases {
+		t.Run("it rejects "+content, func(t *testing.T) {
+			if _, _, err := ParseApacheContent(content); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestReadDocument(t *testing.T) {
+	testutil.AssertDocumentValues(t, ApacheConfigurator{}, APACHE_FILE_PATH, map[string]string{
+		"ServerRoot":                             "/etc/httpd",
+		"Listen[2]":                              "443",
+		"Directory.Require":                      "all denied",
+		"VirtualHost[2].SSLEngine":               "on",
+		"VirtualHost[2].Directory.AllowOverride": "All",
+		"VirtualHost[2].Directory.Options":       "Indexes FollowSymLinks",
+	})
+}
@@ -0,0 +1,51 @@
+package apache
+
+import (
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+// ReadDocument returns the document tree of the configuration: containers
+// are maps of their directives and the occurrences of a repeated directive
+// are a list, designated by their position (VirtualHost[2].DocumentRoot).
+// The arguments of the containers (VirtualHost *:443) are not part of the
+// tree.
+func (configurator ApacheConfigurator) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	config, err := GetParsedApacheFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Comments) > 0 {
+		losses.Add("comments are not converted")
+	}
+	document := source.NewDocument(config.Content, config.Comments, "#")
+
+	return config.readSection(document, config.Main, []string{}, losses), nil
+}
+
+func (config *ApacheConfiguration) readSection(document *source.Document, section *Section, path []string, losses *core.Losses) *core.Node {
+	node := core.NewMapNode()
+	node.Path = path
+
+	for _, directive := range section.Directives {
+		childPath := append(append([]string{}, path...), directive.Name)
+		value := directive.ArgumentsText()
+
+		var child *core.Node
+		if directive.Section != nil {
+			if len(directive.Arguments) > 0 {
+				losses.Add("the arguments of containers (%s %s) are not converted", directive.Name, value)
+			}
+			child = config.readSection(document, directive.Section, childPath, losses)
+		} else {
+			child = core.NewScalarNode(core.UntypedScalar, value)
+			child.Path = childPath
+		}
+		child.Span = document.Lines(directive.Lines)
+		child.Comments = document.AttachedComments(directive.Lines)
+		node.AddIndexed(directive.Name, child)
+	}
+
+	return node
+}
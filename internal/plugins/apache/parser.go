@@ -0,0 +1,234 @@
+package apache
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+var continuationRegexp = regexp.MustCompile(`[ \t]*\\\r?\n[ \t]*`)
+
+type parser struct {
+	content  string
+	comments []source.Span
+}
+
+func (p *parser) errorfAt(pos int, format string, args ...interface{}) error {
+	line := strings.Count(p.content[:pos], "\n") + 1
+	message := fmt.Sprintf(format, args...)
+
+	return errors.New(fmt.Sprintf("Invalid Apache configuration on line %d: %s", line, message))
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r'
+}
+
+// continuationLength returns the length of the line continuation (a
+// backslash at the end of a line) at the given position, or 0
+func continuationLength(content string, pos int) int {
+	switch {
+	case strings.HasPrefix(content[pos:], "\\\n"):
+		return 2
+	case strings.HasPrefix(content[pos:], "\\\r\n"):
+		return 3
+	}
+
+	return 0
+}
+
+// joinContinuations joins the continued lines of a text, replacing the
+// backslash, the newline and the indentation around them by a space
+func joinContinuations(text string) string {
+	return continuationRegexp.ReplaceAllString(text, " ")
+}
+
+// logicalLineEnd returns the end of the line starting at start, following the
+// line continuations
+func logicalLineEnd(content string, start int) int {
+	pos := start
+	for pos < len(content) && content[pos] != '\n' {
+		if length := continuationLength(content, pos); length > 0 {
+			pos += length
+			continue
+		}
+		pos++
+	}
+
+	if pos > start && content[pos-1] == '\r' {
+		pos--
+	}
+
+	return pos
+}
+
+// tokenize splits the arguments found between start and end
+func tokenize(content string, start int, end int) []*Argument {
+	arguments := []*Argument{}
+
+	pos := start
+	for pos < end {
+		c := content[pos]
+		if isSpace(c) || c == '\n' {
+			pos++
+			continue
+		}
+		if length := continuationLength(content, pos); length > 0 {
+			pos += length
+			continue
+		}
+
+		tokenStart := pos
+		if c == '"' || c == '\'' {
+			pos++
+			for pos < end && content[pos] != c {
+				if content[pos] == '\\' {
+					pos++
+				}
+				pos++
+			}
+			if pos < end {
+				pos++
+			}
+		} else {
+			for pos < end && !isSpace(content[pos]) && content[pos] != '\n' && continuationLength(content, pos) == 0 {
+				pos++
+			}
+		}
+		if pos > end {
+			pos = end
+		}
+
+		arguments = append(arguments, &Argument{content[tokenStart:pos], source.Span{Start: tokenStart, End: pos}})
+	}
+
+	return arguments
+}
+
+func argumentsSpan(arguments []*Argument, position int) source.Span {
+	if len(arguments) == 0 {
+		return source.Span{Start: position, End: position}
+	}
+
+	return source.Span{Start: arguments[0].Span.Start, End: arguments[len(arguments)-1].Span.End}
+}
+
+func (p *parser) parse() (*Section, error) {
+	main := &Section{}
+	current := main
+
+	pos := 0
+	for pos < len(p.content) {
+		lineStart := pos
+		lineEnd := logicalLineEnd(p.content, lineStart)
+		pos = lineEnd
+		for pos < len(p.content) && p.content[pos] != '\n' {
+			pos++
+		}
+		if pos < len(p.content) {
+			pos++
+		}
+
+		textStart := lineStart
+		for textStart < lineEnd && isSpace(p.content[textStart]) {
+			textStart++
+		}
+		text := p.content[textStart:lineEnd]
+
+		switch {
+		case text == "":
+			continue
+		case strings.HasPrefix(text, "#"):
+			p.comments = append(p.comments, source.Span{Start: lineStart, End: lineEnd})
+		case strings.HasPrefix(text, "</"):
+			closing := strings.TrimSpace(strings.TrimSuffix(text[2:], ">"))
+			if current.Container == nil {
+				return nil, p.errorfAt(lineStart, "unexpected closing tag </%s>", closing)
+			}
+			if !strings.HasSuffix(text, ">") || !strings.EqualFold(closing, current.Container.Name) {
+				return nil, p.errorfAt(lineStart, "expected </%s>, got %s", current.Container.Name, text)
+			}
+
+			current.Closing = source.Span{Start: lineStart, End: lineEnd}
+			current.Container.Lines.End = lineEnd
+			current = current.Container.Parent
+		case strings.HasPrefix(text, "<"):
+			closing := strings.LastIndexByte(text, '>')
+			if closing == -1 {
+				return nil, p.errorfAt(lineStart, "unterminated container tag %s", text)
+			}
+
+			nameStart := textStart + 1
+			nameEnd := nameStart
+			for nameEnd < textStart+closing && !isSpace(p.content[nameEnd]) && p.content[nameEnd] != '\n' {
+				nameEnd++
+			}
+			if nameEnd == nameStart {
+				return nil, p.errorfAt(lineStart, "missing container name")
+			}
+
+			arguments := tokenize(p.content, nameEnd, textStart+closing)
+			directive := &Directive{
+				Name:      p.content[nameStart:nameEnd],
+				Arguments: arguments,
+				Parent:    current,
+				Lines:     source.Span{Start: lineStart, End: lineEnd},
+				NameStart: nameStart,
+				Value:     argumentsSpan(arguments, nameEnd),
+			}
+			directive.Section = &Section{Container: directive}
+			current.Directives = append(current.Directives, directive)
+			current = directive.Section
+		default:
+			nameEnd := textStart
+			for nameEnd < lineEnd && !isSpace(p.content[nameEnd]) && continuationLength(p.content, nameEnd) == 0 {
+				nameEnd++
+			}
+
+			arguments := tokenize(p.content, nameEnd, lineEnd)
+			current.Directives = append(current.Directives, &Directive{
+				Name:      p.content[textStart:nameEnd],
+				Arguments: arguments,
+				Parent:    current,
+				Lines:     source.Span{Start: lineStart, End: lineEnd},
+				NameStart: textStart,
+				Value:     argumentsSpan(arguments, nameEnd),
+			})
+		}
+	}
+
+	if current.Container != nil {
+		return nil, p.errorfAt(current.Container.Lines.Start, "unclosed container <%s>", current.Container.Name)
+	}
+
+	return main, nil
+}
+
+func ParseApacheContent(content string) (*Section, []source.Span, error) {
+	p := &parser{content: content}
+
+	main, err := p.parse()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return main, p.comments, nil
+}
+
+func GetParsedApacheFile(filePath string) (ApacheConfiguration, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return ApacheConfiguration{}, err
+	}
+
+	main, comments, err := ParseApacheContent(content)
+	if err != nil {
+		return ApacheConfiguration{}, err
+	}
+
+	return ApacheConfiguration{content, main, comments, filePath}, nil
+}
@@ -0,0 +1,130 @@
+package apache
+
+import (
+	"strings"
+
+	"github.com/einenlum/edicon/internal/blockpath"
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+type Argument struct {
+	Raw  string
+	Span source.Span
+}
+
+// Value returns the argument without its quotes, its continued lines being
+// joined
+func (argument *Argument) Value() string {
+	raw := joinContinuations(argument.Raw)
+	if len(raw) >= 2 && (raw[0] == '"' || raw[0] == '\'') && raw[len(raw)-1] == raw[0] {
+		return strings.ReplaceAll(raw[1:len(raw)-1], `\`+raw[:1], raw[:1])
+	}
+
+	return raw
+}
+
+// Directive is a directive (DocumentRoot /var/www) or a container section
+// (<VirtualHost *:443> ... </VirtualHost>), in which case Section is set.
+// Lines goes from the start of its first line to the end of its last line,
+// continuation lines and closing tag included.
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Section   *Section
+	Parent    *Section
+	Lines     source.Span
+	// Name is at NameStart, Value covers the arguments
+	NameStart int
+	Value     source.Span
+}
+
+// Section is the content of the file or of a container
+type Section struct {
+	Directives []*Directive
+	Container  *Directive
+	// Closing is the span of the closing tag line of a container
+	Closing source.Span
+}
+
+// Text returns the argument as printed by get: without its quotes unless it
+// needs them, its continued lines being joined
+func (argument *Argument) Text() string {
+	return blockpath.ArgumentText(joinContinuations(argument.Raw), argument.Value())
+}
+
+// ArgumentsText returns the arguments as printed by get, separated by spaces
+func (directive *Directive) ArgumentsText() string {
+	texts := []string{}
+	for _, argument := range directive.Arguments {
+		texts = append(texts, argument.Text())
+	}
+
+	return strings.Join(texts, " ")
+}
+
+type ApacheConfiguration struct {
+	Content string
+	Main    *Section
+	// Comments are the spans of the comment lines
+	Comments []source.Span
+	FilePath string
+}
+
+func (config *ApacheConfiguration) OutputFile(outputType core.OutputType) (string, error) {
+	return OutputConfigFile(config, outputType), nil
+}
+
+func (config *ApacheConfiguration) WriteToFile(filepath string, outputType core.OutputType) error {
+	output, err := config.OutputFile(outputType)
+	if err != nil {
+		return err
+	}
+
+	return io.WriteFileContents(filepath, output)
+}
+
+type ApacheConfigurator struct{}
+
+func (configurator ApacheConfigurator) GetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (string, error) {
+	return GetParameterFromPath(notationStyle, filePath, key)
+}
+
+func (configurator ApacheConfigurator) SetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, false))
+}
+
+func (configurator ApacheConfigurator) AddParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, true))
+}
+
+func (configurator ApacheConfigurator) UnsetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (core.Configuration, error) {
+	return toConfiguration(RemoveFromConfigFile(notationStyle, filePath, key))
+}
+
+func toConfiguration(config *ApacheConfiguration, err error) (core.Configuration, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
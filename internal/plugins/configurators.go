@@ -5,7 +5,22 @@ import (
 	"fmt"
 
 	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/apache"
+	"github.com/einenlum/edicon/internal/plugins/desktop"
+	"github.com/einenlum/edicon/internal/plugins/editorconfig"
+	"github.com/einenlum/edicon/internal/plugins/flat"
+	"github.com/einenlum/edicon/internal/plugins/hcl"
 	"github.com/einenlum/edicon/internal/plugins/ini"
+	"github.com/einenlum/edicon/internal/plugins/json"
+	"github.com/einenlum/edicon/internal/plugins/nginx"
+	"github.com/einenlum/edicon/internal/plugins/plist"
+	"github.com/einenlum/edicon/internal/plugins/reg"
+	"github.com/einenlum/edicon/internal/plugins/ssh"
+	"github.com/einenlum/edicon/internal/plugins/systemd"
+	"github.com/einenlum/edicon/internal/plugins/table"
+	"github.com/einenlum/edicon/internal/plugins/toml"
+	"github.com/einenlum/edicon/internal/plugins/xml"
+	"github.com/einenlum/edicon/internal/plugins/yaml"
 
 	"github.com/spf13/cobra"
 )
@@ -22,14 +37,151 @@ func GetConfiguratorFromParentCmd(parentCmd *cobra.Command) (core.Configurator,
 		return nil, errors.New("Configurator not found")
 	}
 
+	// The locale of desktop entries is a flag of the desktop command
+	if desktopConfigurator, ok := configurator.(desktop.DesktopConfigurator); ok {
+		desktopConfigurator.Locale, err = parentCmd.PersistentFlags().GetString("locale")
+		if err != nil {
+			return nil, err
+		}
+
+		return desktopConfigurator, nil
+	}
+
 	return configurator, nil
 }
 
+// GetConfigurator returns the configurator of a configuration type
+func GetConfigurator(ctype string) (core.Configurator, error) {
+	return getConfigurator(ctype)
+}
+
 func getConfigurator(ctype string) (core.Configurator, error) {
 	switch ctype {
-	case "php":
+	case "ini", "php":
 		return ini.IniConfigurator{}, nil
+	case "toml":
+		return toml.TomlConfigurator{}, nil
+	case "xml":
+		return xml.XmlConfigurator{}, nil
+	case "nginx":
+		return nginx.NginxConfigurator{}, nil
+	case "apache", "httpd", "htaccess":
+		return apache.ApacheConfigurator{}, nil
+	case "ssh", "sshd":
+		return ssh.SshConfigurator{}, nil
+	case "systemd":
+		return systemd.SystemdConfigurator{}, nil
+	case "redis":
+		return flat.FlatConfigurator{Dialect: flat.Redis}, nil
+	case "postgresql", "postgres":
+		return flat.FlatConfigurator{Dialect: flat.PostgreSQL}, nil
+	case "sysctl":
+		return flat.FlatConfigurator{Dialect: flat.Sysctl}, nil
+	case "hcl", "tfvars", "terraform":
+		return hcl.HclConfigurator{}, nil
+	case "desktop":
+		return desktop.DesktopConfigurator{}, nil
+	case "editorconfig":
+		return editorconfig.EditorConfigConfigurator{}, nil
+	case "hosts":
+		return table.TableConfigurator{Format: table.Hosts}, nil
+	case "fstab":
+		return table.TableConfigurator{Format: table.Fstab}, nil
+	case "crontab":
+		return table.TableConfigurator{Format: table.Crontab}, nil
+	case "reg", "regedit":
+		return reg.RegConfigurator{}, nil
+	case "plist":
+		return plist.PlistConfigurator{}, nil
 	default:
 		return nil, errors.New(fmt.Sprintf("No configurator found for %s", ctype))
 	}
 }
+
+// Types are the configuration types, in the order of the documentation
+var Types = []string{
+	"ini", "php", "toml", "xml", "nginx", "apache", "httpd", "htaccess", "ssh", "sshd",
+	"systemd", "redis", "postgresql", "postgres", "sysctl", "hcl", "tfvars", "terraform",
+	"desktop", "editorconfig", "hosts", "fstab", "crontab", "reg", "regedit", "plist",
+}
+
+// typeAliases are the aliases of the configuration types, by alias
+var typeAliases = map[string]string{
+	"php":       "ini",
+	"httpd":     "apache",
+	"htaccess":  "apache",
+	"sshd":      "ssh",
+	"postgres":  "postgresql",
+	"tfvars":    "hcl",
+	"terraform": "hcl",
+	"regedit":   "reg",
+	"yml":       "yaml",
+}
+
+// canonicalType returns the type of which a type is an alias, or the type
+// itself
+func canonicalType(ctype string) string {
+	if canonical, ok := typeAliases[ctype]; ok {
+		return canonical
+	}
+
+	return ctype
+}
+
+// GetDocumentReader returns the document reader of a configuration type
+func GetDocumentReader(ctype string) (core.DocumentReader, error) {
+	switch ctype {
+	case "json":
+		return json.JsonFormat{}, nil
+	case "yaml", "yml":
+		return yaml.YamlFormat{}, nil
+	}
+
+	configurator, err := getConfigurator(ctype)
+	if err != nil {
+		return nil, err
+	}
+
+	reader, ok := configurator.(core.DocumentReader)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("Reading %s files as a document is not supported", ctype))
+	}
+
+	return reader, nil
+}
+
+// GetTreeWriter returns the writer of a configuration type, for conversions
+func GetTreeWriter(ctype string) (core.TreeWriter, error) {
+	switch ctype {
+	case "json":
+		return json.JsonFormat{}, nil
+	case "yaml", "yml":
+		return yaml.YamlFormat{}, nil
+	}
+
+	configurator, err := getConfigurator(ctype)
+	if err != nil {
+		return nil, err
+	}
+
+	writer, ok := configurator.(core.TreeWriter)
+	if !ok {
+		return nil, errors.New(fmt.Sprintf("Converting to %s is not supported", ctype))
+	}
+
+	return writer, nil
+}
+
+// ConvertibleTypes returns the types which can be converted from and to
+func ConvertibleTypes() (readable []string, writable []string) {
+	for _, ctype := range append(append([]string{}, Types...), "json", "yaml", "yml") {
+		if _, err := GetDocumentReader(ctype); err == nil {
+			readable = append(readable, ctype)
+		}
+		if _, err := GetTreeWriter(ctype); err == nil {
+			writable = append(writable, ctype)
+		}
+	}
+
+	return readable, writable
+}
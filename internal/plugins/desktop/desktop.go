@@ -0,0 +1,447 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+// KeyPath is a key of a group, with its locale and, for lists, the index of
+// an item (-1 for the whole value)
+type KeyPath struct {
+	Group  string
+	Name   string
+	Locale string
+	Index  int
+	Spec   KeySpec
+	// Fallback is true when the locale comes from --locale, so that getting
+	// a value falls back on less specific locales
+	Fallback bool
+}
+
+func (path KeyPath) key(locale string) string {
+	if locale == "" {
+		return path.Name
+	}
+
+	return path.Name + "[" + locale + "]"
+}
+
+func isIndex(part string) bool {
+	index, err := strconv.Atoi(part)
+
+	return err == nil && index >= 0
+}
+
+// parseKeyPath decomposes a key: "Name", "Desktop Action new-window.Exec",
+// "Categories.0" or "Desktop Entry.Name[fr]". Keys without a group belong to
+// the Desktop Entry group.
+func parseKeyPath(notationStyle core.NotationStyle, key string, locale string) (KeyPath, error) {
+	parts, err := core.DecomposeKeyKeepingPredicates(notationStyle, key)
+	if err != nil {
+		return KeyPath{}, err
+	}
+
+	path := KeyPath{Group: entryGroup, Index: -1}
+	switch {
+	case len(parts) == 1:
+		path.Name = parts[0]
+	case len(parts) == 2 && isIndex(parts[1]):
+		path.Name = parts[0]
+		path.Index, _ = strconv.Atoi(parts[1])
+	case len(parts) == 2:
+		path.Group, path.Name = parts[0], parts[1]
+	case len(parts) == 3 && isIndex(parts[2]):
+		path.Group, path.Name = parts[0], parts[1]
+		path.Index, _ = strconv.Atoi(parts[2])
+	default:
+		return path, errors.New(fmt.Sprintf("Invalid key %s, expected [group.]key[.index]", key))
+	}
+
+	name, keyLocale, err := splitKey(path.Name)
+	if err != nil {
+		return path, errors.New(fmt.Sprintf("Invalid key %s", path.Name))
+	}
+	path.Name = name
+
+	switch {
+	case keyLocale != "" && locale != "" && keyLocale != locale:
+		return path, errors.New(fmt.Sprintf("The locale of %s does not match --locale %s", key, locale))
+	case keyLocale != "":
+		path.Locale = keyLocale
+	case locale != "":
+		if _, _, err := splitKey(name + "[" + locale + "]"); err != nil {
+			return path, errors.New(fmt.Sprintf("Invalid locale %s, expected lang_COUNTRY@MODIFIER", locale))
+		}
+		path.Locale = locale
+		path.Fallback = true
+	}
+
+	path.Spec, err = getKeySpec(path.Group, path.Name)
+	if err != nil {
+		return path, err
+	}
+	if path.Locale != "" && !path.Spec.isLocalizable() {
+		return path, errors.New(fmt.Sprintf("%s cannot be localized", path.Name))
+	}
+	if path.Index >= 0 && !path.Spec.canBeList() {
+		return path, errors.New(fmt.Sprintf("%s is not a list", path.Name))
+	}
+
+	return path, nil
+}
+
+// localeCandidates returns the locales to look for, from the most specific
+// one to the unlocalized key, following the matching rules of the
+// specification: lang_COUNTRY@MODIFIER, lang_COUNTRY, lang@MODIFIER, lang.
+// The encoding is ignored.
+func localeCandidates(locale string) []string {
+	if locale == "" {
+		return []string{""}
+	}
+
+	modifier := ""
+	if at := strings.IndexByte(locale, '@'); at != -1 {
+		locale, modifier = locale[:at], locale[at:]
+	}
+	if dot := strings.IndexByte(locale, '.'); dot != -1 {
+		locale = locale[:dot]
+	}
+	lang, country, _ := strings.Cut(locale, "_")
+
+	candidates := []string{}
+	if country != "" && modifier != "" {
+		candidates = append(candidates, lang+"_"+country+modifier)
+	}
+	if country != "" {
+		candidates = append(candidates, lang+"_"+country)
+	}
+	if modifier != "" {
+		candidates = append(candidates, lang+modifier)
+	}
+
+	return append(candidates, lang, "")
+}
+
+// Groups returns the groups of the file, built from its lines
+func (config *DesktopConfiguration) Groups() []*Group {
+	groups := []*Group{}
+	for _, line := range config.Lines {
+		if line.ContentType == ini.SectionLineType {
+			groups = append(groups, &Group{Name: line.SectionLine.SectionName, Header: line})
+			continue
+		}
+		if len(groups) > 0 {
+			groups[len(groups)-1].Lines = append(groups[len(groups)-1].Lines, line)
+		}
+	}
+
+	return groups
+}
+
+func (config *DesktopConfiguration) findGroup(name string) *Group {
+	for _, group := range config.Groups() {
+		if group.Name == name {
+			return group
+		}
+	}
+
+	return nil
+}
+
+func (group *Group) find(key string) *ini.Line {
+	if group == nil {
+		return nil
+	}
+
+	for _, line := range group.Lines {
+		if line.ContentType == ini.KeyValueType && line.KeyValue.Key == key {
+			return line
+		}
+	}
+
+	return nil
+}
+
+func OutputConfigFile(config *DesktopConfiguration, outputType core.OutputType) string {
+	if outputType == core.MeaningFullOutput {
+		output := ""
+		for _, line := range config.Lines {
+			if line.ContentType != ini.OtherType {
+				output += line.ToString() + "\n"
+			}
+		}
+
+		return output
+	}
+
+	lines := []string{}
+	for _, line := range config.Lines {
+		lines = append(lines, line.ToString())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// GetParameterFromPath returns the decoded value of a key, one item per line
+// for lists. With --locale, the value of the closest locale is returned.
+func GetParameterFromPath(notationStyle core.NotationStyle, filePath string, key string, locale string) (string, error) {
+	config, err := GetParsedDesktopFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := parseKeyPath(notationStyle, key, locale)
+	if err != nil {
+		return "", err
+	}
+
+	candidates := []string{path.Locale}
+	if path.Fallback {
+		candidates = localeCandidates(path.Locale)
+	}
+
+	group := config.findGroup(path.Group)
+	for _, candidate := range candidates {
+		line := group.find(path.key(candidate))
+		if line == nil {
+			continue
+		}
+
+		if path.Index >= 0 {
+			items := splitList(line.KeyValue.Value)
+			if path.Index >= len(items) {
+				return "", errors.New("Key not found")
+			}
+			return unescape(items[path.Index]), nil
+		}
+
+		if !path.Spec.List {
+			return unescape(line.KeyValue.Value), nil
+		}
+
+		values := []string{}
+		for _, item := range splitList(line.KeyValue.Value) {
+			values = append(values, unescape(item))
+		}
+		return strings.Join(values, "\n"), nil
+	}
+
+	return "", errors.New("Key not found")
+}
+
+// EditConfigFile sets the value of a key, or of an item of a list. With add,
+// the value is added as a new item at the end of the list.
+func EditConfigFile(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+	locale string,
+	add bool,
+) (*DesktopConfiguration, error) {
+	config, err := GetParsedDesktopFile(filePath)
+	if err != nil {
+		return &DesktopConfiguration{}, err
+	}
+
+	path, err := parseKeyPath(notationStyle, key, locale)
+	if err != nil {
+		return &DesktopConfiguration{}, err
+	}
+
+	group := config.findGroup(path.Group)
+	line := group.find(path.key(path.Locale))
+	current := ""
+	if line != nil {
+		current = line.KeyValue.Value
+	}
+
+	if add {
+		if !path.Spec.canBeList() {
+			return &DesktopConfiguration{}, errors.New(fmt.Sprintf("%s is not a list, use set instead", path.Name))
+		}
+		if path.Index >= 0 {
+			return &DesktopConfiguration{}, errors.New("Items are added at the end of the list, remove the index from the key")
+		}
+		path.Index = len(splitList(current))
+	}
+
+	var encoded string
+	switch {
+	case path.Index >= 0:
+		if err := path.Spec.validateValue(path.Name, value); err != nil {
+			return &DesktopConfiguration{}, err
+		}
+
+		items := splitList(current)
+		switch {
+		case path.Index < len(items):
+			items[path.Index] = escape(value, true)
+		case path.Index == len(items):
+			items = append(items, escape(value, true))
+		default:
+			return &DesktopConfiguration{}, errors.New("Key not found")
+		}
+		encoded = joinList(items, current == "" || strings.HasSuffix(current, ";"))
+	case path.Spec.List:
+		// The whole list is given, with its separators
+		for _, item := range splitList(value) {
+			if err := path.Spec.validateValue(path.Name, unescape(item)); err != nil {
+				return &DesktopConfiguration{}, err
+			}
+		}
+		encoded = value
+		if value != "" && !strings.HasSuffix(value, ";") {
+			encoded += ";"
+		}
+	default:
+		if err := path.Spec.validateValue(path.Name, value); err != nil {
+			return &DesktopConfiguration{}, err
+		}
+		encoded = escape(value, false)
+	}
+
+	if line != nil {
+		line.SetValue(encoded)
+	} else {
+		config.addLine(group, path, encoded)
+	}
+
+	return config.validate(key)
+}
+
+// RemoveFromConfigFile removes a key, or an item of a list. Removing an
+// unlocalized key also removes its localized variants.
+func RemoveFromConfigFile(notationStyle core.NotationStyle, filePath string, key string, locale string) (*DesktopConfiguration, error) {
+	config, err := GetParsedDesktopFile(filePath)
+	if err != nil {
+		return &DesktopConfiguration{}, err
+	}
+
+	path, err := parseKeyPath(notationStyle, key, locale)
+	if err != nil {
+		return &DesktopConfiguration{}, err
+	}
+
+	group := config.findGroup(path.Group)
+	line := group.find(path.key(path.Locale))
+	if line == nil {
+		return &DesktopConfiguration{}, errors.New("Key not found")
+	}
+
+	if path.Index >= 0 {
+		items := splitList(line.KeyValue.Value)
+		if path.Index >= len(items) {
+			return &DesktopConfiguration{}, errors.New("Key not found")
+		}
+
+		if len(items) > 1 {
+			items = append(items[:path.Index], items[path.Index+1:]...)
+			line.SetValue(joinList(items, strings.HasSuffix(line.KeyValue.Value, ";")))
+
+			return config.validate(key)
+		}
+		// The line of a list left empty is removed
+	} else if path.Spec.Required && path.Locale == "" {
+		return &DesktopConfiguration{}, errors.New(fmt.Sprintf("%s is required by the Desktop Entry specification", path.Name))
+	}
+
+	removed := map[*ini.Line]bool{line: true}
+	if path.Locale == "" && path.Index < 0 {
+		for _, other := range group.Lines {
+			if other.ContentType == ini.KeyValueType && strings.HasPrefix(other.KeyValue.Key, path.Name+"[") {
+				removed[other] = true
+			}
+		}
+	}
+
+	lines := []*ini.Line{}
+	for _, other := range config.Lines {
+		if !removed[other] {
+			lines = append(lines, other)
+		}
+	}
+	config.Lines = lines
+
+	return config.validate(key)
+}
+
+func (config *DesktopConfiguration) validate(key string) (*DesktopConfiguration, error) {
+	if _, err := ParseDesktopContent(OutputConfigFile(config, core.FullOutput)); err != nil {
+		return &DesktopConfiguration{}, errors.New(fmt.Sprintf("Refusing to set %s: %s", key, err.Error()))
+	}
+
+	return config, nil
+}
+
+func (config *DesktopConfiguration) insertLines(position int, newLines ...*ini.Line) {
+	lines := append([]*ini.Line{}, config.Lines[:position]...)
+	lines = append(lines, newLines...)
+	config.Lines = append(lines, config.Lines[position:]...)
+}
+
+func (config *DesktopConfiguration) indexOf(line *ini.Line) int {
+	for idx, other := range config.Lines {
+		if other == line {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// addLine adds a key after its localized variants or after the last key of
+// its group, creating the group at the end of the file if needed
+func (config *DesktopConfiguration) addLine(group *Group, path KeyPath, value string) {
+	line := &ini.Line{
+		Status:      ini.Changed,
+		ContentType: ini.KeyValueType,
+		KeyValue:    &ini.KeyValue{Key: path.key(path.Locale), Value: value, Separator: "="},
+	}
+
+	if group == nil {
+		position := len(config.Lines)
+		if position > 0 && config.Lines[position-1].StringContent == "" && config.Lines[position-1].Status == ini.Original {
+			// Keep the newline at the end of the file
+			position--
+		}
+
+		header := &ini.Line{
+			Status:      ini.Changed,
+			ContentType: ini.SectionLineType,
+			SectionLine: &ini.SectionLine{SectionName: path.Group},
+		}
+		newLines := []*ini.Line{header, line}
+		if position > 0 && strings.TrimSpace(config.Lines[position-1].ToString()) != "" {
+			newLines = append([]*ini.Line{{Status: ini.Original, ContentType: ini.OtherType}}, newLines...)
+		}
+		config.insertLines(position, newLines...)
+
+		return
+	}
+
+	reference := group.Header
+	var variant *ini.Line
+	for _, other := range group.Lines {
+		if other.ContentType != ini.KeyValueType {
+			continue
+		}
+		reference = other
+		if name, _, _ := splitKey(other.KeyValue.Key); name == path.Name {
+			variant = other
+		}
+	}
+	if variant != nil {
+		reference = variant
+	}
+	if reference.KeyValue != nil {
+		line.KeyValue.Separator = reference.KeyValue.Separator
+	}
+
+	config.insertLines(config.indexOf(reference)+1, line)
+}
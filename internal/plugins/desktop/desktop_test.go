@@ -0,0 +1,185 @@
+package desktop
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/testutil"
+)
+
+const DESKTOP_FILE_PATH = "../../../data/desktop/firefox.desktop"
+
+func TestGetParameter(t *testing.T) {
+	type GetTestElement struct {
+		key      string
+		locale   string
+		expected string
+	}
+
+	cases := []GetTestElement{
+		{"Name", "", "Firefox Web Browser"},
+		{"Desktop Entry.Name", "", "Firefox Web Browser"},
+		{"Name[fr]", "", "Navigateur Web Firefox"},
+		{"Name", "fr", "Navigateur Web Firefox"},
+		{"Name", "fr_CA", "Navigateur Web Firefox"},
+		{"Name", "pt_BR.UTF-8", "Navegador Web Firefox"},
+		{"Name", "de", "Firefox Web Browser"},
+		{"Comment", "de_DE", "Browse the World Wide Web"},
+		{"Categories", "", "GNOME\nGTK\nNetwork\nWebBrowser"},
+		{"Categories.2", "", "Network"},
+		{"Desktop Entry.MimeType.0", "", "text/html"},
+		{"Desktop Action new-window.Name", "fr", "Ouvrir une nouvelle fenêtre"},
+		{"Desktop Action new-private-window.Exec", "", "firefox -private-window"},
+		{"X-MultipleArgs", "", "false"},
+	}
+
+	for _, element := range cases {
+		t.Run("it gets existing parameter "+element.key+" "+element.locale, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, DESKTOP_FILE_PATH, element.key, element.locale)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value != element.expected {
+				t.Fatal(fmt.Sprintf("Expected %s got %s", element.expected, value))
+			}
+		})
+	}
+
+	errorCases := []string{"Name[de]", "Categories.4", "Exec.0", "Exec[fr]", "Foo", "Desktop Action missing.Name", "Desktop Entry.Name.Foo.0"}
+	for _, key := range errorCases {
+		t.Run("it does not get "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, DESKTOP_FILE_PATH, key, "")
+			if err == nil {
+				t.Error("Should fail. Got " + value + " instead")
+			}
+		})
+	}
+
+	t.Run("it refuses a locale not matching the key", func(t *testing.T) {
+		if _, err := GetParameterFromPath(core.DotNotation, DESKTOP_FILE_PATH, "Name[fr]", "pt_BR"); err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
+
+func TestEditParameter(t *testing.T) {
+	type EditTestElement struct {
+		key    string
+		value  string
+		locale string
+		add    bool
+		old    string
+		new    string
+	}
+
+	cases := []EditTestElement{
+		{"Exec", "firefox --new-instance %u", "", false, "Exec=firefox %u", "Exec=firefox --new-instance %u"},
+		{"Name[fr]", "Firefox", "", false, "Name[fr]=Navigateur Web Firefox", "Name[fr]=Firefox"},
+		{"Name", "Firefox", "fr", false, "Name[fr]=Navigateur Web Firefox", "Name[fr]=Firefox"},
+		{"Name", "Firefox-Webbrowser", "de", false, "Name[pt_BR]=Navegador Web Firefox\n", "Name[pt_BR]=Navegador Web Firefox\nName[de]=Firefox-Webbrowser\n"},
+		{"Comment[de]", " Im Internet surfen", "", false, "Comment[fr]=Naviguer sur le Web\n", "Comment[fr]=Naviguer sur le Web\nComment[de]=\\sIm Internet surfen\n"},
+		{"Terminal", "true", "", false, "Terminal=false", "Terminal=true"},
+		{"Categories.1", "Qt", "", false, "Categories=GNOME;GTK;Network;", "Categories=GNOME;Qt;Network;"},
+		{"Categories", "Development", "", true, "Network;WebBrowser;", "Network;WebBrowser;Development;"},
+		{"Categories.4", "Development", "", false, "Network;WebBrowser;", "Network;WebBrowser;Development;"},
+		{"Categories", "Network;WebBrowser", "", false, "Categories=GNOME;GTK;Network;WebBrowser;", "Categories=Network;WebBrowser;"},
+		{"Keywords", "Navigateur", "fr", true, "Keywords=Internet;WWW;Browser;Web;Explorer;\n", "Keywords=Internet;WWW;Browser;Web;Explorer;\nKeywords[fr]=Navigateur;\n"},
+		{"X-Note", "a;b", "", true, "Actions=new-window;new-private-window;\n", "Actions=new-window;new-private-window;\nX-Note=a\\;b;\n"},
+		{"NoDisplay", "true", "", false, "Actions=new-window;new-private-window;\n", "Actions=new-window;new-private-window;\nNoDisplay=true\n"},
+		{"Desktop Action new-private-window.Icon", "firefox-private", "", false, "Exec = firefox -private-window\n", "Exec = firefox -private-window\nIcon = firefox-private\n"},
+		{"Desktop Action safe-mode.Name", "Safe Mode", "", false, "Exec = firefox -private-window\n", "Exec = firefox -private-window\n\n[Desktop Action safe-mode]\nName=Safe Mode\n"},
+	}
+
+	for _, element := range cases {
+		t.Run("it sets "+element.key+" "+element.locale, func(t *testing.T) {
+			config, err := EditConfigFile(core.DotNotation, DESKTOP_FILE_PATH, element.key, element.value, element.locale, element.add)
+			testutil.AssertEditedOutput(t, DESKTOP_FILE_PATH, config, err, element.old, element.new)
+		})
+	}
+
+	errorCases := map[string]string{
+		"Terminal":                           "yes",
+		"Type":                               "Service",
+		"Exec":                               "firefox —new-window",
+		"Exec[fr]":                           "firefox",
+		"Exec.0":                             "firefox",
+		"Categories.5":                       "Development",
+		"Categories":                         "GNOME;\x01;",
+		"Foo":                                "bar",
+		"Desktop Action new-window.Terminal": "true",
+	}
+	for key, value := range errorCases {
+		t.Run("it refuses to set "+key+" to "+value, func(t *testing.T) {
+			if _, err := EditConfigFile(core.DotNotation, DESKTOP_FILE_PATH, key, value, "", false); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+
+	t.Run("it refuses to add an item to a key which is not a list", func(t *testing.T) {
+		if _, err := EditConfigFile(core.DotNotation, DESKTOP_FILE_PATH, "Exec", "--safe-mode", "", true); err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
+
+func TestUnsetParameter(t *testing.T) {
+	type UnsetTestElement struct {
+		key    string
+		locale string
+		old    string
+		new    string
+	}
+
+	cases := []UnsetTestElement{
+		{"Comment", "", "Comment=Browse the World Wide Web\nComment[fr]=Naviguer sur le Web\n", ""},
+		{"Name", "fr", "Name[fr]=Navigateur Web Firefox\n", ""},
+		{"Categories.0", "", "Categories=GNOME;GTK;", "Categories=GTK;"},
+		{"Actions.1", "", "Actions=new-window;new-private-window;", "Actions=new-window;"},
+		{"Desktop Action new-window.Exec", "", "Exec=firefox -new-window\n", ""},
+	}
+
+	for _, element := range cases {
+		t.Run("it unsets "+element.key+" "+element.locale, func(t *testing.T) {
+			config, err := RemoveFromConfigFile(core.DotNotation, DESKTOP_FILE_PATH, element.key, element.locale)
+			testutil.AssertEditedOutput(t, DESKTOP_FILE_PATH, config, err, element.old, element.new)
+		})
+	}
+
+	for _, key := range []string{"Name", "Type", "Name[de]", "Categories.4"} {
+		t.Run("it does not unset "+key, func(t *testing.T) {
+			if _, err := RemoveFromConfigFile(core.DotNotation, DESKTOP_FILE_PATH, key, ""); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestParseInvalidDesktop(t *testing.T) {
+	cases := []string{
+		"Name=Firefox",
+		"[Desktop Entry]\n; comment",
+		"[Desktop Entry]\nName",
+		"[Desktop Entry]\nName=a\nName=b",
+		"[Desktop Entry]\n[Desktop Entry]",
+		"[Desktop Entry]\nName[fr=a",
+		"[Desktop Entry\nName=a",
+	}
+
+	for _, content := range cases {
+		t.Run("it rejects "+content, func(t *testing.T) {
+			if _, err := ParseDesktopContent(content); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestReadDocument(t *testing.T) {
+	testutil.AssertDocumentValues(t, DesktopConfigurator{}, DESKTOP_FILE_PATH, map[string]string{
+		"Desktop Entry.Name[fr]":   "Navigateur Web Firefox",
+		"Desktop Entry.Keywords.1": "WWW",
+	})
+}
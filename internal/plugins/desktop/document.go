@@ -0,0 +1,55 @@
+package desktop
+
+import (
+	"strconv"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+// ReadDocument returns the document tree of the desktop entry: groups are
+// section nodes, localized keys keep their locale (Name[fr]) and the values
+// of list keys (Categories...) are lists
+func (configurator DesktopConfigurator) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	config, err := GetParsedDesktopFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	globalSection, sections := ini.GetSections(config.Lines)
+	document := ini.BuildDocument(globalSection, sections, ini.DocumentOptions{}, losses)
+	for _, groupName := range document.Keys {
+		group := document.Get(groupName)
+		for _, key := range group.Keys {
+			value := group.Get(key)
+			name, _, _ := splitKey(key)
+			spec, err := getKeySpec(groupName, name)
+			switch {
+			case err == nil && spec.List:
+				group.Set(key, readList(value))
+			case err == nil && spec.Type == BooleanType:
+				value.Type = core.BoolScalar
+			default:
+				value.Value = unescape(value.Value)
+			}
+		}
+	}
+
+	return document, nil
+}
+
+func readList(value *core.Node) *core.Node {
+	list := core.NewListNode()
+	list.Path = value.Path
+	list.Span = value.Span
+	list.Comments = value.Comments
+
+	for idx, raw := range splitList(value.Value) {
+		item := core.NewScalarNode(core.UntypedScalar, unescape(raw))
+		item.Path = append(append([]string{}, value.Path...), strconv.Itoa(idx))
+		item.Span = value.Span
+		list.Items = append(list.Items, item)
+	}
+
+	return list
+}
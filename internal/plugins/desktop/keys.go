@@ -0,0 +1,229 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+type ValueType int
+
+const (
+	StringType ValueType = iota
+	LocaleStringType
+	IconStringType
+	BooleanType
+	NumericType
+	// ExtensionType is the type of X- keys and of the keys of other groups,
+	// which can hold anything
+	ExtensionType
+)
+
+// KeySpec is the type of a key defined by the Desktop Entry specification
+type KeySpec struct {
+	Type     ValueType
+	List     bool
+	Required bool
+	// Values are the allowed values, if restricted
+	Values []string
+}
+
+const entryGroup = "Desktop Entry"
+const actionGroupPrefix = "Desktop Action "
+
+var numericRegexp = regexp.MustCompile(`^[+-]?(?:[0-9]+(?:\.[0-9]*)?|\.[0-9]+)(?:[eE][+-]?[0-9]+)?$`)
+
+var entryKeys = map[string]KeySpec{
+	"Type":                 {Type: StringType, Required: true, Values: []string{"Application", "Link", "Directory"}},
+	"Version":              {Type: StringType},
+	"Name":                 {Type: LocaleStringType, Required: true},
+	"GenericName":          {Type: LocaleStringType},
+	"NoDisplay":            {Type: BooleanType},
+	"Comment":              {Type: LocaleStringType},
+	"Icon":                 {Type: IconStringType},
+	"Hidden":               {Type: BooleanType},
+	"OnlyShowIn":           {Type: StringType, List: true},
+	"NotShowIn":            {Type: StringType, List: true},
+	"DBusActivatable":      {Type: BooleanType},
+	"TryExec":              {Type: StringType},
+	"Exec":                 {Type: StringType},
+	"Path":                 {Type: StringType},
+	"Terminal":             {Type: BooleanType},
+	"Actions":              {Type: StringType, List: true},
+	"MimeType":             {Type: StringType, List: true},
+	"Categories":           {Type: StringType, List: true},
+	"Implements":           {Type: StringType, List: true},
+	"Keywords":             {Type: LocaleStringType, List: true},
+	"StartupNotify":        {Type: BooleanType},
+	"StartupWMClass":       {Type: StringType},
+	"URL":                  {Type: StringType},
+	"PrefersNonDefaultGPU": {Type: BooleanType},
+	"SingleMainWindow":     {Type: BooleanType},
+}
+
+var actionKeys = map[string]KeySpec{
+	"Name": {Type: LocaleStringType, Required: true},
+	"Icon": {Type: IconStringType},
+	"Exec": {Type: StringType},
+}
+
+// getKeySpec returns the spec of a key of a group. Keys of the standard
+// groups which are not defined by the specification must start with X-.
+func getKeySpec(group string, name string) (KeySpec, error) {
+	if strings.HasPrefix(name, "X-") {
+		return KeySpec{Type: ExtensionType}, nil
+	}
+
+	var keys map[string]KeySpec
+	switch {
+	case group == entryGroup:
+		keys = entryKeys
+	case strings.HasPrefix(group, actionGroupPrefix):
+		keys = actionKeys
+	default:
+		return KeySpec{Type: ExtensionType}, nil
+	}
+
+	spec, ok := keys[name]
+	if !ok {
+		return spec, errors.New(fmt.Sprintf("Unknown key %s in [%s], extension keys must start with X-", name, group))
+	}
+
+	return spec, nil
+}
+
+func (spec KeySpec) isLocalizable() bool {
+	return spec.Type == LocaleStringType || spec.Type == IconStringType || spec.Type == ExtensionType
+}
+
+// canBeList tells if a key can be edited item by item
+func (spec KeySpec) canBeList() bool {
+	return spec.List || spec.Type == ExtensionType
+}
+
+// validateValue checks a decoded value, or an item of a list, against the
+// type of its key
+func (spec KeySpec) validateValue(name string, value string) error {
+	for _, r := range value {
+		if r < 0x20 && r != '\n' && r != '\t' && r != '\r' || r == 0x7f {
+			return errors.New(fmt.Sprintf("%s cannot contain control characters", name))
+		}
+	}
+
+	switch spec.Type {
+	case BooleanType:
+		if value != "true" && value != "false" {
+			return errors.New(fmt.Sprintf("%q is not a valid value for %s, use true or false", value, name))
+		}
+	case NumericType:
+		if !numericRegexp.MatchString(value) {
+			return errors.New(fmt.Sprintf("%q is not a valid number for %s", value, name))
+		}
+	case StringType:
+		for _, r := range value {
+			if r > 0x7f {
+				return errors.New(fmt.Sprintf("%s only accepts ASCII values, %q is not", name, value))
+			}
+		}
+	}
+
+	if len(spec.Values) > 0 {
+		for _, allowed := range spec.Values {
+			if value == allowed {
+				return nil
+			}
+		}
+		return errors.New(fmt.Sprintf("%q is not a valid value for %s, expected one of %s", value, name, strings.Join(spec.Values, ", ")))
+	}
+
+	return nil
+}
+
+// unescape decodes the \s, \n, \t, \r and \\ escape sequences, and \; in
+// lists
+func unescape(raw string) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(raw); i++ {
+		if raw[i] != '\\' || i+1 == len(raw) {
+			builder.WriteByte(raw[i])
+			continue
+		}
+
+		i++
+		switch raw[i] {
+		case 's':
+			builder.WriteByte(' ')
+		case 'n':
+			builder.WriteByte('\n')
+		case 't':
+			builder.WriteByte('\t')
+		case 'r':
+			builder.WriteByte('\r')
+		default:
+			builder.WriteByte(raw[i])
+		}
+	}
+
+	return builder.String()
+}
+
+// escape encodes a value, or an item of a list whose semicolons must be
+// escaped
+func escape(value string, item bool) string {
+	var builder strings.Builder
+
+	for i, r := range value {
+		switch {
+		case r == '\\':
+			builder.WriteString(`\\`)
+		case r == '\n':
+			builder.WriteString(`\n`)
+		case r == '\t':
+			builder.WriteString(`\t`)
+		case r == '\r':
+			builder.WriteString(`\r`)
+		case r == ' ' && i == 0:
+			// Leading spaces would be ignored
+			builder.WriteString(`\s`)
+		case r == ';' && item:
+			builder.WriteString(`\;`)
+		default:
+			builder.WriteRune(r)
+		}
+	}
+
+	return builder.String()
+}
+
+// splitList returns the raw items of a list, separated by unescaped
+// semicolons, the last semicolon being optional
+func splitList(raw string) []string {
+	items := []string{}
+
+	start := 0
+	for i := 0; i < len(raw); i++ {
+		switch raw[i] {
+		case '\\':
+			i++
+		case ';':
+			items = append(items, raw[start:i])
+			start = i + 1
+		}
+	}
+	if start < len(raw) {
+		items = append(items, raw[start:])
+	}
+
+	return items
+}
+
+func joinList(items []string, trailingSemicolon bool) string {
+	raw := strings.Join(items, ";")
+	if trailingSemicolon && len(items) > 0 {
+		raw += ";"
+	}
+
+	return raw
+}
@@ -0,0 +1,130 @@
+package desktop
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+var (
+	// keyRegexp matches a key and its optional locale (Name[fr_FR@euro])
+	keyRegexp   = regexp.MustCompile(`^([A-Za-z0-9-]+)(?:\[([A-Za-z]+(?:_[A-Za-z]+)?(?:\.[A-Za-z0-9-]+)?(?:@[A-Za-z0-9]+)?)\])?$`)
+	groupRegexp = regexp.MustCompile(`^\[([^\[\]\x00-\x1f\x7f]+)\]$`)
+)
+
+// splitKey returns the name of a key and its locale
+func splitKey(key string) (string, string, error) {
+	matches := keyRegexp.FindStringSubmatch(key)
+	if matches == nil {
+		return "", "", errors.New(fmt.Sprintf("invalid key %s", key))
+	}
+
+	return matches[1], matches[2], nil
+}
+
+func parseLine(lineNumber int, lineString string) (*ini.Line, error) {
+	trimmedLine := strings.TrimRight(lineString, "\r")
+
+	line := &ini.Line{
+		LineNumber:    lineNumber,
+		StringContent: lineString,
+		Status:        ini.Original,
+		ContentType:   ini.OtherType,
+	}
+
+	// Only "#" starts a comment, ";" lines are invalid
+	if strings.TrimSpace(trimmedLine) == "" || strings.HasPrefix(trimmedLine, "#") {
+		return line, nil
+	}
+
+	if strings.HasPrefix(trimmedLine, "[") {
+		matches := groupRegexp.FindStringSubmatch(trimmedLine)
+		if matches == nil {
+			return nil, errors.New(fmt.Sprintf("Invalid desktop entry on line %d: invalid group header %s", lineNumber, trimmedLine))
+		}
+		line.ContentType = ini.SectionLineType
+		line.SectionLine = &ini.SectionLine{SectionName: matches[1]}
+
+		return line, nil
+	}
+
+	equals := strings.IndexByte(trimmedLine, '=')
+	if equals == -1 {
+		return nil, errors.New(fmt.Sprintf("Invalid desktop entry on line %d: missing \"=\"", lineNumber))
+	}
+
+	key := strings.TrimRight(trimmedLine[:equals], " \t")
+	if _, _, err := splitKey(key); err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid desktop entry on line %d: %s", lineNumber, err.Error()))
+	}
+
+	valueStart := equals + 1
+	for valueStart < len(trimmedLine) && (trimmedLine[valueStart] == ' ' || trimmedLine[valueStart] == '\t') {
+		valueStart++
+	}
+
+	line.ContentType = ini.KeyValueType
+	line.KeyValue = &ini.KeyValue{
+		Key:           key,
+		Value:         trimmedLine[valueStart:],
+		Separator:     trimmedLine[len(key):valueStart],
+		ValuePosition: &ini.ValuePosition{Start: valueStart, End: len(trimmedLine)},
+	}
+
+	return line, nil
+}
+
+// ParseDesktopContent parses the lines of a desktop file, checking that every
+// key belongs to a group and that groups and keys are not repeated
+func ParseDesktopContent(content string) ([]*ini.Line, error) {
+	lines := []*ini.Line{}
+	groups := map[string]bool{}
+	var keys map[string]bool
+
+	for idx, lineString := range strings.Split(content, "\n") {
+		line, err := parseLine(idx+1, lineString)
+		if err != nil {
+			return nil, err
+		}
+
+		switch line.ContentType {
+		case ini.SectionLineType:
+			name := line.SectionLine.SectionName
+			if groups[name] {
+				return nil, errors.New(fmt.Sprintf("Invalid desktop entry on line %d: group %s is repeated", line.LineNumber, name))
+			}
+			groups[name] = true
+			keys = map[string]bool{}
+		case ini.KeyValueType:
+			if keys == nil {
+				return nil, errors.New(fmt.Sprintf("Invalid desktop entry on line %d: %s is not in a group", line.LineNumber, line.KeyValue.Key))
+			}
+			if keys[line.KeyValue.Key] {
+				return nil, errors.New(fmt.Sprintf("Invalid desktop entry on line %d: %s is repeated", line.LineNumber, line.KeyValue.Key))
+			}
+			keys[line.KeyValue.Key] = true
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+func GetParsedDesktopFile(filePath string) (DesktopConfiguration, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return DesktopConfiguration{}, err
+	}
+
+	lines, err := ParseDesktopContent(content)
+	if err != nil {
+		return DesktopConfiguration{}, err
+	}
+
+	return DesktopConfiguration{lines, filePath}, nil
+}
@@ -0,0 +1,81 @@
+package desktop
+
+import (
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+// Group is a [Group Name] header and the lines following it
+type Group struct {
+	Name   string
+	Header *ini.Line
+	Lines  []*ini.Line
+}
+
+type DesktopConfiguration struct {
+	Lines    []*ini.Line
+	FilePath string
+}
+
+func (config *DesktopConfiguration) OutputFile(outputType core.OutputType) (string, error) {
+	return OutputConfigFile(config, outputType), nil
+}
+
+func (config *DesktopConfiguration) WriteToFile(filepath string, outputType core.OutputType) error {
+	output, err := config.OutputFile(outputType)
+	if err != nil {
+		return err
+	}
+
+	return io.WriteFileContents(filepath, output)
+}
+
+// DesktopConfigurator handles freedesktop .desktop files. Locale selects the
+// localized variant of the keys (Name[fr]) when they do not specify one.
+type DesktopConfigurator struct {
+	Locale string
+}
+
+func (configurator DesktopConfigurator) GetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (string, error) {
+	return GetParameterFromPath(notationStyle, filePath, key, configurator.Locale)
+}
+
+func (configurator DesktopConfigurator) SetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, configurator.Locale, false))
+}
+
+// AddParameter adds an item to a list key (Categories, MimeType...)
+func (configurator DesktopConfigurator) AddParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, configurator.Locale, true))
+}
+
+func (configurator DesktopConfigurator) UnsetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (core.Configuration, error) {
+	return toConfiguration(RemoveFromConfigFile(notationStyle, filePath, key, configurator.Locale))
+}
+
+func toConfiguration(config *DesktopConfiguration, err error) (core.Configuration, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,24 @@
+package editorconfig
+
+import (
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+var documentOptions = ini.DocumentOptions{
+	Overrides: func(key string) bool {
+		return true
+	},
+}
+
+// ReadDocument returns the document tree of the .editorconfig file: the
+// preamble is at the root and sections are section nodes named after their
+// glob, the sections with the same glob being merged
+func (configurator EditorConfigConfigurator) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	config, err := GetParsedEditorConfigFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return ini.BuildDocument(config.GlobalSection, config.Sections, documentOptions, losses), nil
+}
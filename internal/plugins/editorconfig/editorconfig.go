@@ -0,0 +1,319 @@
+package editorconfig
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+var (
+	bracketsKeyRegexp = regexp.MustCompile(`^(.*)\[([^\[\]]+)\]$`)
+	positiveRegexp    = regexp.MustCompile(`^[1-9][0-9]*$`)
+)
+
+// knownProperties are the values allowed for the properties defined by the
+// EditorConfig specification, "number" meaning a positive integer. Any
+// property can also be "unset".
+var knownProperties = map[string][]string{
+	"indent_style":             {"tab", "space"},
+	"indent_size":              {"number", "tab"},
+	"tab_width":                {"number"},
+	"end_of_line":              {"lf", "cr", "crlf"},
+	"charset":                  {"latin1", "utf-8", "utf-8-bom", "utf-16be", "utf-16le"},
+	"spelling_language":        {},
+	"trim_trailing_whitespace": {"true", "false"},
+	"insert_final_newline":     {"true", "false"},
+	"max_line_length":          {"number", "off"},
+	"root":                     {"true", "false"},
+}
+
+func OutputConfigFile(config *EditorConfigConfiguration, outputType core.OutputType) string {
+	lines := []string{}
+	for _, line := range config.lines() {
+		if outputType == core.MeaningFullOutput && line.ContentType == ini.OtherType {
+			continue
+		}
+		lines = append(lines, line.ToString())
+	}
+
+	if outputType == core.MeaningFullOutput {
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (config *EditorConfigConfiguration) lines() []*ini.Line {
+	lines := append([]*ini.Line{}, config.GlobalSection.Lines...)
+	for _, section := range config.Sections {
+		lines = append(lines, section.Lines...)
+	}
+
+	return lines
+}
+
+// decomposeKey returns the glob of the section and the property of a key.
+// Globs contain dots, so the property is what follows the last dot
+// ("*.{js,ts}.indent_size"), unless the glob is quoted as listed
+// ("*.{js,ts}".indent_size), or the last brackets ("*.{js,ts}[indent_size]").
+// In pointer notation, globs can be written as is ("/*.{js,ts}/indent_size").
+// A key without a section is a property of the preamble.
+func decomposeKey(notationStyle core.NotationStyle, key string) (string, string, error) {
+	section, property := "", key
+	if notationStyle == core.DotNotation && (strings.HasPrefix(key, `"`) || strings.HasPrefix(key, "'")) {
+		parts, err := core.DecomposeKeyWithDotNotation(key)
+		if err != nil {
+			return "", "", errors.New(fmt.Sprintf("Invalid key %s: %s", key, err.Error()))
+		}
+		switch len(parts) {
+		case 1:
+			property = parts[0]
+		case 2:
+			section, property = parts[0], parts[1]
+		default:
+			property = ""
+		}
+	} else if notationStyle == core.DotNotation {
+		if dot := strings.LastIndexByte(key, '.'); dot != -1 {
+			section, property = key[:dot], key[dot+1:]
+		}
+	} else if notationStyle == core.PointerNotation {
+		parts, err := core.DecomposeKey(notationStyle, key)
+		if err != nil {
+			return "", "", err
+		}
+		switch len(parts) {
+		case 1:
+			property = parts[0]
+		case 2:
+			section, property = parts[0], parts[1]
+		default:
+			property = ""
+		}
+	} else if matches := bracketsKeyRegexp.FindStringSubmatch(key); matches != nil {
+		section, property = matches[1], matches[2]
+	}
+
+	if property == "" || strings.ContainsAny(property, "=[] \t") || strings.ContainsAny(section, "\r\n") {
+		return "", "", errors.New(fmt.Sprintf("Invalid key %s: expected section.property (e.g. *.{js,ts}.indent_size)", key))
+	}
+
+	// Property names are case insensitive
+	return section, strings.ToLower(property), nil
+}
+
+func validateValue(section string, property string, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return errors.New("Values cannot contain newlines")
+	}
+	if strings.TrimSpace(value) != value || value == "" {
+		return errors.New("Values cannot be empty or start or end with spaces")
+	}
+	if property == "root" && section != "" {
+		return errors.New("root can only be set in the preamble, before the first section")
+	}
+
+	allowed, known := knownProperties[property]
+	if !known || len(allowed) == 0 || strings.EqualFold(value, "unset") {
+		return nil
+	}
+
+	for _, allowedValue := range allowed {
+		if strings.EqualFold(value, allowedValue) || allowedValue == "number" && positiveRegexp.MatchString(value) {
+			return nil
+		}
+	}
+
+	return errors.New(fmt.Sprintf("%q is not a valid value for %s, expected one of %s", value, property, strings.Join(allowed, ", ")))
+}
+
+// getKeyLines returns the lines of a property in the sections with the given
+// glob, property names being case insensitive
+func (config *EditorConfigConfiguration) getKeyLines(sectionName string, property string) []*ini.Line {
+	sectionsLines := [][]*ini.Line{}
+	if sectionName == "" {
+		sectionsLines = append(sectionsLines, config.GlobalSection.Lines)
+	}
+	for _, section := range config.Sections {
+		if sectionName != "" && section.Name == sectionName {
+			sectionsLines = append(sectionsLines, section.Lines)
+		}
+	}
+
+	lines := []*ini.Line{}
+	for _, sectionLines := range sectionsLines {
+		for _, line := range sectionLines {
+			if line.ContentType == ini.KeyValueType && strings.EqualFold(line.KeyValue.Key, property) {
+				lines = append(lines, line)
+			}
+		}
+	}
+
+	return lines
+}
+
+// GetParameterFromPath returns the value of a property in a section, the
+// last one being used if the section or the property is repeated
+func GetParameterFromPath(notationStyle core.NotationStyle, filePath string, key string) (string, error) {
+	config, err := GetParsedEditorConfigFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	sectionName, property, err := decomposeKey(notationStyle, key)
+	if err != nil {
+		return "", err
+	}
+
+	lines := config.getKeyLines(sectionName, property)
+	if len(lines) == 0 {
+		return "", errors.New("Key not found")
+	}
+
+	return lines[len(lines)-1].KeyValue.Value, nil
+}
+
+// EditConfigFile sets a property of a section, adding the section at the end
+// of the file if needed
+func EditConfigFile(notationStyle core.NotationStyle, filePath string, key string, value string) (*EditorConfigConfiguration, error) {
+	config, err := GetParsedEditorConfigFile(filePath)
+	if err != nil {
+		return &EditorConfigConfiguration{}, err
+	}
+
+	sectionName, property, err := decomposeKey(notationStyle, key)
+	if err != nil {
+		return &EditorConfigConfiguration{}, err
+	}
+	if err := validateValue(sectionName, property, value); err != nil {
+		return &EditorConfigConfiguration{}, err
+	}
+
+	if lines := config.getKeyLines(sectionName, property); len(lines) > 0 {
+		// The last occurrence is the one in use
+		lines[len(lines)-1].SetValue(value)
+
+		return &config, nil
+	}
+
+	config.addProperty(config.getOrAddSection(sectionName), property, value)
+
+	return &config, nil
+}
+
+// RemoveFromConfigFile removes a property from all the sections with the
+// given glob
+func RemoveFromConfigFile(notationStyle core.NotationStyle, filePath string, key string) (*EditorConfigConfiguration, error) {
+	config, err := GetParsedEditorConfigFile(filePath)
+	if err != nil {
+		return &EditorConfigConfiguration{}, err
+	}
+
+	sectionName, property, err := decomposeKey(notationStyle, key)
+	if err != nil {
+		return &EditorConfigConfiguration{}, err
+	}
+
+	lines := config.getKeyLines(sectionName, property)
+	if len(lines) == 0 {
+		return &EditorConfigConfiguration{}, errors.New("Key not found")
+	}
+
+	removed := map[*ini.Line]bool{}
+	for _, line := range lines {
+		removed[line] = true
+	}
+	config.GlobalSection.Lines = withoutLines(config.GlobalSection.Lines, removed)
+	for _, section := range config.Sections {
+		section.Lines = withoutLines(section.Lines, removed)
+	}
+
+	return &config, nil
+}
+
+func withoutLines(lines []*ini.Line, removed map[*ini.Line]bool) []*ini.Line {
+	kept := []*ini.Line{}
+	for _, line := range lines {
+		if !removed[line] {
+			kept = append(kept, line)
+		}
+	}
+
+	return kept
+}
+
+func isEmptyLine(line *ini.Line) bool {
+	return line.ContentType == ini.OtherType && strings.TrimSpace(line.StringContent) == ""
+}
+
+// getOrAddSection returns the lines of the last section with the given glob,
+// or of the preamble, adding the section at the end of the file if needed
+func (config *EditorConfigConfiguration) getOrAddSection(name string) *[]*ini.Line {
+	if name == "" {
+		return &config.GlobalSection.Lines
+	}
+
+	for idx := len(config.Sections) - 1; idx >= 0; idx-- {
+		if config.Sections[idx].Name == name {
+			return &config.Sections[idx].Lines
+		}
+	}
+
+	last := &config.GlobalSection.Lines
+	if len(config.Sections) > 0 {
+		last = &config.Sections[len(config.Sections)-1].Lines
+	}
+
+	// Keep the newline at the end of the file after the new section
+	trailing := []*ini.Line{}
+	if len(*last) > 0 && (*last)[len(*last)-1].StringContent == "" && (*last)[len(*last)-1].ContentType == ini.OtherType {
+		trailing = (*last)[len(*last)-1:]
+		*last = (*last)[:len(*last)-1]
+	} else if len(config.lines()) == 0 {
+		trailing = []*ini.Line{{Status: ini.Original, ContentType: ini.OtherType}}
+	}
+
+	section := &ini.Section{Name: name}
+	if len(*last) > 0 && !isEmptyLine((*last)[len(*last)-1]) {
+		section.Lines = append(section.Lines, &ini.Line{Status: ini.Original, ContentType: ini.OtherType})
+	}
+	section.Lines = append(section.Lines, &ini.Line{
+		Status:      ini.Changed,
+		ContentType: ini.SectionLineType,
+		SectionLine: &ini.SectionLine{SectionName: name},
+	})
+	section.Lines = append(section.Lines, trailing...)
+	config.Sections = append(config.Sections, section)
+
+	return &section.Lines
+}
+
+// addProperty adds a property after the last property of the section, with
+// the same spacing around "="
+func (config *EditorConfigConfiguration) addProperty(lines *[]*ini.Line, property string, value string) {
+	line := &ini.Line{
+		Status:      ini.Changed,
+		ContentType: ini.KeyValueType,
+		KeyValue:    &ini.KeyValue{Key: property, Value: value, Separator: " = "},
+	}
+
+	position := 0
+	for idx, other := range *lines {
+		switch other.ContentType {
+		case ini.SectionLineType:
+			position = idx + 1
+		case ini.KeyValueType:
+			position = idx + 1
+			line.SpacePrefix = other.SpacePrefix
+			line.KeyValue.Separator = other.KeyValue.Separator
+		}
+	}
+
+	newLines := append([]*ini.Line{}, (*lines)[:position]...)
+	newLines = append(newLines, line)
+	*lines = append(newLines, (*lines)[position:]...)
+}
@@ -0,0 +1,243 @@
+package editorconfig
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/testutil"
+)
+
+const EDITORCONFIG_DIRECTORY = "../../../data/editorconfig"
+const EDITORCONFIG_FILE_PATH = EDITORCONFIG_DIRECTORY + "/.editorconfig"
+
+func TestGetParameter(t *testing.T) {
+	cases := map[string]string{
+		"root":                                  "true",
+		"*.indent_size":                         "4",
+		"*.{js,ts,json}.indent_size":            "2",
+		"Makefile.indent_style":                 "tab",
+		"Makefile.INDENT_STYLE":                 "tab",
+		"docs/**.md.trim_trailing_whitespace":   "false",
+		`"*.{js,ts,json}".indent_size`:          "2",
+		`'docs/**.md'.trim_trailing_whitespace`: "false",
+		`"root"`:                                "true",
+	}
+
+	for key, expectedValue := range cases {
+		t.Run("it gets existing parameter "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, EDITORCONFIG_FILE_PATH, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value != expectedValue {
+				t.Fatal(fmt.Sprintf("Expected %s got %s", expectedValue, value))
+			}
+		})
+	}
+
+	t.Run("it gets a parameter with the brackets notation", func(t *testing.T) {
+		value, err := GetParameterFromPath(core.BracketsNotation, EDITORCONFIG_FILE_PATH, "*.{js,ts,json}[indent_size]")
+		if err != nil || value != "2" {
+			t.Fatal(fmt.Sprintf("Expected 2 got %s (%v)", value, err))
+		}
+	})
+
+	for _, key := range []string{"indent_size", "*.js.indent_size", "Makefile.tab_width", "*.", `"*.{js,ts,json}.indent_size`, `"*".a.b`} {
+		t.Run("it does not get "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, EDITORCONFIG_FILE_PATH, key)
+			if err == nil {
+				t.Error("Should fail. Got " + value + " instead")
+			}
+		})
+	}
+}
+
+func TestEditParameter(t *testing.T) {
+	cases := map[string][]string{
+		"*.indent_size":              {"2", "indent_size = 4\n\n;", "indent_size = 2\n\n;"},
+		"*.{js,ts,json}.indent_size": {"4", "indent_size = 2\n\n[Makefile]", "indent_size = 4\n\n[Makefile]"},
+		"Makefile.tab_width":         {"8", "indent_style = tab\n", "indent_style = tab\ntab_width = 8\n"},
+		"root":                       {"false", "root = true", "root = false"},
+		"*.md.max_line_length":       {"off", "indent_size = 8\n", "indent_size = 8\n\n[*.md]\nmax_line_length = off\n"},
+		"*.py.indent_size":           {"unset", "indent_size = 8\n", "indent_size = 8\n\n[*.py]\nindent_size = unset\n"},
+		"*.custom_property":          {"Anything", "indent_size = 4\n\n;", "indent_size = 4\ncustom_property = Anything\n\n;"},
+	}
+
+	for key, element := range cases {
+		t.Run("it sets "+key, func(t *testing.T) {
+			config, err := EditConfigFile(core.DotNotation, EDITORCONFIG_FILE_PATH, key, element[0])
+			testutil.AssertEditedOutput(t, EDITORCONFIG_FILE_PATH, config, err, element[1], element[2])
+		})
+	}
+
+	errorCases := map[string]string{
+		"*.indent_style":    "tabs",
+		"*.indent_size":     "0",
+		"*.end_of_line":     "\n",
+		"*.charset":         "",
+		"*.root":            "true",
+		"*.invalid key":     "true",
+		"*.max_line_length": "-1",
+	}
+	for key, value := range errorCases {
+		t.Run("it refuses to set "+key+" to "+value, func(t *testing.T) {
+			if _, err := EditConfigFile(core.DotNotation, EDITORCONFIG_FILE_PATH, key, value); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestUnsetParameter(t *testing.T) {
+	cases := map[string][]string{
+		"*.charset":                  {"charset = utf-8\n", ""},
+		"*.{js,ts,json}.indent_size": {"[*.{js,ts,json}]\nindent_size = 2\n", "[*.{js,ts,json}]\n"},
+	}
+
+	for key, replacement := range cases {
+		t.Run("it unsets "+key, func(t *testing.T) {
+			config, err := RemoveFromConfigFile(core.DotNotation, EDITORCONFIG_FILE_PATH, key)
+			testutil.AssertEditedOutput(t, EDITORCONFIG_FILE_PATH, config, err, replacement[0], replacement[1])
+		})
+	}
+
+	t.Run("it does not unset a missing property", func(t *testing.T) {
+		if _, err := RemoveFromConfigFile(core.DotNotation, EDITORCONFIG_FILE_PATH, "*.tab_width"); err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
+
+func TestMatchSection(t *testing.T) {
+	type MatchTestElement struct {
+		glob     string
+		filePath string
+		matches  bool
+	}
+
+	cases := []MatchTestElement{
+		{"*", "/project/a.txt", true},
+		{"*", "/project/src/deep/a.txt", true},
+		{"*.js", "/project/src/app.js", true},
+		{"*.js", "/project/src/app.jsx", false},
+		{"*.{js,ts}", "/project/app.ts", true},
+		{"*.{js,ts}", "/project/app.rb", false},
+		{"{package.json,.travis.yml}", "/project/sub/package.json", true},
+		{"Makefile", "/project/sub/Makefile", true},
+		{"Makefile", "/project/Makefile.am", false},
+		{"lib/**.js", "/project/lib/a/b.js", true},
+		{"lib/**.js", "/project/src/lib/b.js", false},
+		{"/lib/*.js", "/project/lib/b.js", true},
+		{"lib/*.js", "/project/lib/a/b.js", false},
+		{"a/**/b.txt", "/project/a/b.txt", true},
+		{"a/**/b.txt", "/project/a/x/y/b.txt", true},
+		{"?.txt", "/project/a.txt", true},
+		{"?.txt", "/project/ab.txt", false},
+		{"[abc].txt", "/project/b.txt", true},
+		{"[!abc].txt", "/project/b.txt", false},
+		{"[!abc].txt", "/project/d.txt", true},
+		{"file{1..3}.txt", "/project/file2.txt", true},
+		{"file{1..3}.txt", "/project/file4.txt", false},
+		{"file{-1..1}.txt", "/project/file-1.txt", true},
+		{"{single}.txt", "/project/{single}.txt", true},
+		{"{single}.txt", "/project/single.txt", false},
+		{"*.{js,{md,txt}}", "/project/README.md", true},
+		{`\*.txt`, "/project/*.txt", true},
+		{`\*.txt`, "/project/a.txt", false},
+	}
+
+	for _, element := range cases {
+		t.Run(fmt.Sprintf("it matches %s against %s", element.glob, element.filePath), func(t *testing.T) {
+			if MatchSection(element.glob, "/project", element.filePath) != element.matches {
+				t.Fatal(fmt.Sprintf("Expected %t", element.matches))
+			}
+		})
+	}
+}
+
+func TestResolveProperties(t *testing.T) {
+	root, err := filepath.Abs(EDITORCONFIG_FILE_PATH)
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := filepath.Join(filepath.Dir(root), "src", ConfigFileName)
+
+	cases := map[string]map[string]string{
+		"src/app.php": {
+			"charset":              "utf-8 [*] in " + root,
+			"end_of_line":          "lf [*] in " + root,
+			"insert_final_newline": "true [*] in " + root,
+			"indent_style":         "space [*] in " + root,
+			"indent_size":          "2 [*.php] in " + src,
+			"tab_width":            "8 [*.php] in " + src,
+		},
+		"src/vendor/lib.php": {
+			"charset":              "utf-8 [*] in " + root,
+			"end_of_line":          "lf [*] in " + root,
+			"insert_final_newline": "true [*] in " + root,
+			"indent_style":         "space [*] in " + root,
+			"tab_width":            "8 [*.php] in " + src,
+		},
+		"Makefile": {
+			"charset":              "utf-8 [*] in " + root,
+			"end_of_line":          "lf [*] in " + root,
+			"insert_final_newline": "true [*] in " + root,
+			"indent_style":         "tab [Makefile] in " + root,
+			"indent_size":          "4 [*] in " + root,
+			"tab_width":            "4 derived from indent_size",
+		},
+		"file3.txt": {
+			"charset":              "utf-8 [*] in " + root,
+			"end_of_line":          "lf [*] in " + root,
+			"insert_final_newline": "true [*] in " + root,
+			"indent_style":         "space [*] in " + root,
+			"indent_size":          "8 [file{1..3}.txt] in " + root,
+			"tab_width":            "8 derived from indent_size",
+		},
+	}
+
+	for file, expected := range cases {
+		t.Run("it resolves the properties of "+file, func(t *testing.T) {
+			properties, err := ResolveProperties(filepath.Join(EDITORCONFIG_DIRECTORY, file))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			resolved := map[string]string{}
+			for _, property := range properties {
+				resolved[property.Name] = property.Value + " " + property.Source()
+			}
+			if fmt.Sprint(resolved) != fmt.Sprint(expected) {
+				t.Fatal(fmt.Sprintf("Expected:\n%v\nGot:\n%v", expected, resolved))
+			}
+		})
+	}
+}
+
+func TestParseInvalidEditorConfig(t *testing.T) {
+	cases := []string{
+		"[*.js",
+		"[]",
+		"[*]\nindent_size",
+		"[*]\n= 2",
+	}
+
+	for _, content := range cases {
+		t.Run("it rejects "+content, func(t *testing.T) {
+			if _, err := ParseEditorConfigContent(content); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestReadDocument(t *testing.T) {
+	testutil.AssertDocumentValues(t, EditorConfigConfigurator{}, EDITORCONFIG_FILE_PATH, map[string]string{
+		"*.indent_style":                 "space",
+		`"*.{js,ts,json}".indent_size`:   "2",
+		`"lib/**/*.php".max_line_length`: "120",
+	})
+}
@@ -0,0 +1,187 @@
+package editorconfig
+
+import (
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var numericRangeRegexp = regexp.MustCompile(`^([+-]?[0-9]+)\.\.([+-]?[0-9]+)$`)
+
+// numericRange is a {num1..num2} part of a glob, matched by a capturing group
+// whose value is checked once the regexp matches
+type numericRange struct {
+	Min int
+	Max int
+}
+
+// closingIndex returns the index of the character closing the one at start,
+// skipping escaped characters and nested pairs, or -1
+func closingIndex(glob string, start int, opening byte, closing byte) int {
+	depth := 0
+	for i := start; i < len(glob); i++ {
+		switch glob[i] {
+		case '\\':
+			i++
+		case opening:
+			depth++
+		case closing:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return -1
+}
+
+// splitAlternatives splits the content of braces on the commas which are
+// not nested in other braces
+func splitAlternatives(content string) []string {
+	alternatives := []string{}
+
+	depth := 0
+	start := 0
+	for i := 0; i < len(content); i++ {
+		switch content[i] {
+		case '\\':
+			i++
+		case '{':
+			depth++
+		case '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				alternatives = append(alternatives, content[start:i])
+				start = i + 1
+			}
+		}
+	}
+
+	return append(alternatives, content[start:])
+}
+
+// translateGlob translates an EditorConfig glob into a regular expression:
+// "*" matches any string without "/", "**" any string, "?" any character but
+// "/", "[name]" and "[!name]" a character of name or not, "{s1,s2}" one of
+// the strings and "{num1..num2}" an integer between the two numbers
+func translateGlob(glob string, ranges *[]numericRange) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(glob); i++ {
+		c := glob[i]
+		switch c {
+		case '\\':
+			if i+1 < len(glob) {
+				i++
+				builder.WriteString(regexp.QuoteMeta(glob[i : i+1]))
+			} else {
+				builder.WriteString(`\\`)
+			}
+		case '*':
+			if i+1 < len(glob) && glob[i+1] == '*' {
+				builder.WriteString(`.*`)
+				i++
+			} else {
+				builder.WriteString(`[^/]*`)
+			}
+		case '?':
+			builder.WriteString(`[^/]`)
+		case '/':
+			// "a/**/b" also matches "a/b"
+			if strings.HasPrefix(glob[i:], "/**/") {
+				builder.WriteString(`(?:/|/.*/)`)
+				i += 3
+			} else {
+				builder.WriteString(`/`)
+			}
+		case '[':
+			end := strings.IndexByte(glob[i+1:], ']')
+			if end == -1 || strings.Contains(glob[i+1:i+1+end], "/") {
+				builder.WriteString(`\[`)
+				continue
+			}
+			content := glob[i+1 : i+1+end]
+			i += end + 1
+
+			builder.WriteString("[")
+			if strings.HasPrefix(content, "!") || strings.HasPrefix(content, "^") {
+				builder.WriteString("^")
+				content = content[1:]
+			}
+			builder.WriteString(strings.NewReplacer(`\`, `\\`, `[`, `\[`, `^`, `\^`).Replace(content))
+			builder.WriteString("]")
+		case '{':
+			end := closingIndex(glob, i, '{', '}')
+			if end == -1 {
+				builder.WriteString(`\{`)
+				continue
+			}
+			content := glob[i+1 : end]
+			i = end
+
+			if matches := numericRangeRegexp.FindStringSubmatch(content); matches != nil {
+				min, _ := strconv.Atoi(matches[1])
+				max, _ := strconv.Atoi(matches[2])
+				if min > max {
+					min, max = max, min
+				}
+				*ranges = append(*ranges, numericRange{min, max})
+				builder.WriteString(`([+-]?[0-9]+)`)
+				continue
+			}
+
+			alternatives := splitAlternatives(content)
+			if len(alternatives) == 1 {
+				// Braces without a comma are literal
+				builder.WriteString(`\{` + translateGlob(content, ranges) + `\}`)
+				continue
+			}
+
+			translated := []string{}
+			for _, alternative := range alternatives {
+				translated = append(translated, translateGlob(alternative, ranges))
+			}
+			builder.WriteString(`(?:` + strings.Join(translated, "|") + `)`)
+		default:
+			builder.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+
+	return builder.String()
+}
+
+// MatchSection tells if the glob of a section of the .editorconfig file in
+// directory matches the file. A glob without "/" matches files in any
+// subdirectory, otherwise it is relative to the directory.
+func MatchSection(glob string, directory string, filePath string) bool {
+	directory = strings.TrimSuffix(path.Clean(directory), "/")
+
+	prefix := regexp.QuoteMeta(directory) + `/(?:.*/)?`
+	if strings.Contains(glob, "/") {
+		prefix = regexp.QuoteMeta(directory) + `/`
+		glob = strings.TrimPrefix(glob, "/")
+	}
+
+	ranges := []numericRange{}
+	pattern, err := regexp.Compile(`^` + prefix + translateGlob(glob, &ranges) + `$`)
+	if err != nil {
+		return false
+	}
+
+	matches := pattern.FindStringSubmatch(filePath)
+	if matches == nil {
+		return false
+	}
+
+	for idx, numericRange := range ranges {
+		value, err := strconv.Atoi(matches[idx+1])
+		if err != nil || value < numericRange.Min || value > numericRange.Max {
+			return false
+		}
+	}
+
+	return true
+}
@@ -0,0 +1,88 @@
+package editorconfig
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+func isComment(trimmedLine string) bool {
+	return strings.HasPrefix(trimmedLine, "#") || strings.HasPrefix(trimmedLine, ";")
+}
+
+// ParseEditorConfigContent parses an .editorconfig file into INI lines.
+// Comments start with "#" or ";" and take the whole line, values can contain
+// "=", "#" and ";".
+func ParseEditorConfigContent(content string) ([]*ini.Line, error) {
+	lines := []*ini.Line{}
+
+	for idx, lineString := range strings.Split(content, "\n") {
+		lineNumber := idx + 1
+		trimmedLine := strings.TrimSpace(lineString)
+		spacePrefix := lineString[:len(lineString)-len(strings.TrimLeft(lineString, " \t"))]
+
+		line := &ini.Line{
+			LineNumber:    lineNumber,
+			StringContent: lineString,
+			SpacePrefix:   spacePrefix,
+			Status:        ini.Original,
+			ContentType:   ini.OtherType,
+		}
+
+		switch {
+		case trimmedLine == "" || isComment(trimmedLine):
+		case strings.HasPrefix(trimmedLine, "["):
+			if !strings.HasSuffix(trimmedLine, "]") || len(trimmedLine) == 2 {
+				return nil, errors.New(fmt.Sprintf("Invalid EditorConfig on line %d: invalid section header %s", lineNumber, trimmedLine))
+			}
+			line.ContentType = ini.SectionLineType
+			line.SectionLine = &ini.SectionLine{SectionName: trimmedLine[1 : len(trimmedLine)-1]}
+		default:
+			separator := strings.Index(lineString, "=")
+			if separator == -1 {
+				return nil, errors.New(fmt.Sprintf("Invalid EditorConfig on line %d: missing \"=\"", lineNumber))
+			}
+			key := strings.TrimSpace(lineString[:separator])
+			if key == "" {
+				return nil, errors.New(fmt.Sprintf("Invalid EditorConfig on line %d: missing key", lineNumber))
+			}
+
+			valueEnd := len(strings.TrimRight(lineString, " \t\r"))
+			valueStart := separator + 1
+			for valueStart < valueEnd && (lineString[valueStart] == ' ' || lineString[valueStart] == '\t') {
+				valueStart++
+			}
+
+			line.ContentType = ini.KeyValueType
+			line.KeyValue = &ini.KeyValue{
+				Key:           key,
+				Value:         lineString[valueStart:valueEnd],
+				Separator:     lineString[len(spacePrefix)+len(key) : valueStart],
+				ValuePosition: &ini.ValuePosition{Start: valueStart, End: valueEnd},
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+func GetParsedEditorConfigFile(filePath string) (EditorConfigConfiguration, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return EditorConfigConfiguration{}, err
+	}
+
+	lines, err := ParseEditorConfigContent(content)
+	if err != nil {
+		return EditorConfigConfiguration{}, err
+	}
+
+	globalSection, sections := ini.GetSections(lines)
+
+	return EditorConfigConfiguration{globalSection, sections, filePath}, nil
+}
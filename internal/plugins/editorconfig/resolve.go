@@ -0,0 +1,166 @@
+package editorconfig
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+const ConfigFileName = ".editorconfig"
+
+// Source describes where a property comes from
+func (property Property) Source() string {
+	if property.DerivedFrom != "" {
+		return "derived from " + property.DerivedFrom
+	}
+
+	return "[" + property.Section + "] in " + property.File
+}
+
+// ConfigFiles returns the .editorconfig files applying to a file, from the
+// closest one to the one with root = true (or the one at the root of the
+// file system)
+func ConfigFiles(filePath string) ([]string, error) {
+	absolutePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	files := []string{}
+	directory := filepath.Dir(absolutePath)
+	for {
+		candidate := filepath.Join(directory, ConfigFileName)
+		if _, err := os.Stat(candidate); err == nil {
+			files = append(files, candidate)
+
+			config, err := GetParsedEditorConfigFile(candidate)
+			if err != nil {
+				return nil, err
+			}
+			if config.isRoot() {
+				break
+			}
+		} else if !errors.Is(err, os.ErrNotExist) {
+			return nil, err
+		}
+
+		parent := filepath.Dir(directory)
+		if parent == directory {
+			break
+		}
+		directory = parent
+	}
+
+	return files, nil
+}
+
+func (config *EditorConfigConfiguration) isRoot() bool {
+	lines := config.getKeyLines("", "root")
+
+	return len(lines) > 0 && strings.EqualFold(lines[len(lines)-1].KeyValue.Value, "true")
+}
+
+// ResolveProperties returns the properties applying to a file: the sections
+// of the .editorconfig files matching it are applied from the farthest file
+// to the closest one, the last section setting a property winning
+func ResolveProperties(filePath string) ([]Property, error) {
+	absolutePath, err := filepath.Abs(filePath)
+	if err != nil {
+		return nil, err
+	}
+	slashedPath := filepath.ToSlash(absolutePath)
+
+	files, err := ConfigFiles(absolutePath)
+	if err != nil {
+		return nil, err
+	}
+
+	properties := []Property{}
+	indexes := map[string]int{}
+	for idx := len(files) - 1; idx >= 0; idx-- {
+		config, err := GetParsedEditorConfigFile(files[idx])
+		if err != nil {
+			return nil, err
+		}
+
+		directory := filepath.ToSlash(filepath.Dir(files[idx]))
+		for _, section := range config.Sections {
+			if !MatchSection(section.Name, directory, slashedPath) {
+				continue
+			}
+
+			for _, line := range section.Lines {
+				if line.ContentType != ini.KeyValueType {
+					continue
+				}
+
+				property := Property{
+					Name:    strings.ToLower(line.KeyValue.Key),
+					Value:   line.KeyValue.Value,
+					File:    files[idx],
+					Section: section.Name,
+				}
+				// The values of the known properties are case insensitive
+				if _, known := knownProperties[property.Name]; known {
+					property.Value = strings.ToLower(property.Value)
+				}
+
+				if position, ok := indexes[property.Name]; ok {
+					properties[position] = property
+				} else {
+					indexes[property.Name] = len(properties)
+					properties = append(properties, property)
+				}
+			}
+		}
+	}
+
+	return applyDefaults(removeUnset(properties)), nil
+}
+
+// removeUnset removes the properties whose value is "unset"
+func removeUnset(properties []Property) []Property {
+	kept := []Property{}
+	for _, property := range properties {
+		if property.Value != "unset" {
+			kept = append(kept, property)
+		}
+	}
+
+	return kept
+}
+
+// applyDefaults adds the properties implied by others, as EditorConfig cores
+// do: indent_size is "tab" for tab indentation, and tab_width defaults to
+// indent_size
+func applyDefaults(properties []Property) []Property {
+	values := map[string]string{}
+	for _, property := range properties {
+		values[property.Name] = property.Value
+	}
+
+	indentSize, hasIndentSize := values["indent_size"]
+	if values["indent_style"] == "tab" && !hasIndentSize {
+		indentSize, hasIndentSize = "tab", true
+		properties = append(properties, Property{Name: "indent_size", Value: "tab", DerivedFrom: "indent_style"})
+	}
+
+	tabWidth, hasTabWidth := values["tab_width"]
+	if hasIndentSize && indentSize != "tab" && !hasTabWidth {
+		properties = append(properties, Property{Name: "tab_width", Value: indentSize, DerivedFrom: "indent_size"})
+	}
+
+	if indentSize == "tab" && hasTabWidth {
+		for idx := range properties {
+			if properties[idx].Name == "indent_size" {
+				properties[idx].Value = tabWidth
+				properties[idx].DerivedFrom = "tab_width"
+			}
+		}
+	}
+
+	return properties
+}
@@ -0,0 +1,77 @@
+package editorconfig
+
+import (
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+// EditorConfigConfiguration is an .editorconfig file, parsed with the INI
+// line model. Section names are globs and the global section is the
+// preamble, holding the root property.
+type EditorConfigConfiguration struct {
+	GlobalSection *ini.GlobalSection
+	Sections      []*ini.Section
+	FilePath      string
+}
+
+// Property is an effective property of a file, with the section and the
+// .editorconfig file it comes from
+type Property struct {
+	Name    string
+	Value   string
+	File    string
+	Section string
+	// DerivedFrom is the property the value was computed from, when the
+	// property is not set but implied by another one (tab_width from
+	// indent_size)
+	DerivedFrom string
+}
+
+func (config *EditorConfigConfiguration) OutputFile(outputType core.OutputType) (string, error) {
+	return OutputConfigFile(config, outputType), nil
+}
+
+func (config *EditorConfigConfiguration) WriteToFile(filepath string, outputType core.OutputType) error {
+	output, err := config.OutputFile(outputType)
+	if err != nil {
+		return err
+	}
+
+	return io.WriteFileContents(filepath, output)
+}
+
+type EditorConfigConfigurator struct{}
+
+func (configurator EditorConfigConfigurator) GetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (string, error) {
+	return GetParameterFromPath(notationStyle, filePath, key)
+}
+
+func (configurator EditorConfigConfigurator) SetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value))
+}
+
+func (configurator EditorConfigConfigurator) UnsetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (core.Configuration, error) {
+	return toConfiguration(RemoveFromConfigFile(notationStyle, filePath, key))
+}
+
+func toConfiguration(config *EditorConfigConfiguration, err error) (core.Configuration, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,133 @@
+package flat
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+// MatchesKeyPatterns marks the paths of the tree as the keys of the file
+func (configurator FlatConfigurator) MatchesKeyPatterns() {}
+
+// ReadDocument returns the document tree of the configuration, the last
+// occurrence of a key which is not repeated being the one in use
+func (configurator FlatConfigurator) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	config, err := GetParsedFlatFile(configurator.Dialect, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildDocument(config, losses), nil
+}
+
+func buildDocument(config FlatConfiguration, losses *core.Losses) *core.Node {
+	dialect := config.Dialect
+	tree := core.NewMapNode()
+	tree.Path = []string{}
+
+	comments := []string{}
+	for _, line := range config.Lines {
+		trimmedLine := strings.TrimSpace(line.StringContent)
+
+		if line.ContentType != ini.KeyValueType {
+			if trimmedLine == "" {
+				comments = []string{}
+			} else {
+				losses.Add("comments are not converted")
+				comments = append(comments, dialect.stripComment(trimmedLine))
+			}
+			continue
+		}
+
+		position := line.KeyValue.ValuePosition
+		if position != nil {
+			if inline := strings.TrimSpace(line.StringContent[position.End:]); inline != "" {
+				losses.Add("comments are not converted")
+				comments = append(comments, dialect.stripComment(inline))
+			}
+		}
+
+		key := line.KeyValue.Key
+		value := core.NewScalarNode(core.UntypedScalar, dialect.decodeValue(line.KeyValue.Value))
+		value.Path = []string{key}
+		value.Span = core.Span{Start: line.LineNumber, End: line.LineNumber}
+		value.Comments = comments
+		comments = []string{}
+
+		if dialect.isRepeated(key) {
+			tree.Add(key, value)
+			continue
+		}
+		if tree.Get(key) != nil {
+			losses.Add("%s is set several times, only the last value is kept", key)
+		}
+		tree.Set(key, value)
+	}
+
+	return tree
+}
+
+// WriteTree writes a map as a flat file. Nested maps are flattened into
+// dotted keys and lists are repeated keys when the dialect allows it.
+func (configurator FlatConfigurator) WriteTree(tree *core.Node, losses *core.Losses) (string, error) {
+	if !tree.IsMap() {
+		return "", errors.New(fmt.Sprintf("Only maps can be written as %s files", configurator.Dialect.Name))
+	}
+
+	var builder strings.Builder
+	configurator.Dialect.writeMap(&builder, "", tree, losses)
+
+	return builder.String(), nil
+}
+
+func (dialect Dialect) writeMap(builder *strings.Builder, prefix string, node *core.Node, losses *core.Losses) {
+	for _, key := range node.Keys {
+		child := node.Get(key)
+		if child.IsMap() {
+			losses.Add("nested maps are written as dotted keys")
+			dialect.writeMap(builder, prefix+key+".", child, losses)
+			continue
+		}
+		dialect.writeEntry(builder, prefix+key, child, losses)
+	}
+}
+
+// writeEntry writes a scalar, or a list as a repeated key
+func (dialect Dialect) writeEntry(builder *strings.Builder, key string, node *core.Node, losses *core.Losses) {
+	if key == "" || strings.ContainsAny(key, " \t\r\n=#;'\"") {
+		losses.Add("%s is not a valid key, it is not written", strconv.Quote(key))
+		return
+	}
+
+	items := []*core.Node{node}
+	if node.Kind == core.ListNode {
+		items = node.Items
+		if !dialect.isRepeated(key) && len(items) > 1 {
+			losses.Add("%s is not a repeated key, only the last item of its list is written", key)
+			items = items[len(items)-1:]
+		}
+	}
+
+	for _, item := range items {
+		if item.Kind != core.ScalarNode {
+			losses.Add("%s contains nested values, which are not written", key)
+			continue
+		}
+		value, err := dialect.encodeValue(item.Value, "")
+		if err != nil {
+			losses.Add("%s is not written: %s", key, err.Error())
+			continue
+		}
+		losses.TypedValue(item)
+		builder.WriteString(key + dialect.Separator + value + "\n")
+	}
+}
+
+// stripComment returns the text of a comment, without its comment prefix
+func (dialect Dialect) stripComment(comment string) string {
+	return strings.TrimSpace(strings.TrimLeft(comment, dialect.CommentPrefixes))
+}
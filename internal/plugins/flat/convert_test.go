@@ -0,0 +1,70 @@
+package flat
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+func TestWriteTree(t *testing.T) {
+	tree := core.NewMapNode()
+	tree.Set("port", core.NewScalarNode(core.IntegerScalar, "6379"))
+	tree.Add("save", core.NewScalarNode(core.UntypedScalar, "3600 1"))
+	tree.Add("save", core.NewScalarNode(core.UntypedScalar, "300 100"))
+	tree.Add("bind", core.NewScalarNode(core.UntypedScalar, "127.0.0.1"))
+	tree.Add("bind", core.NewScalarNode(core.UntypedScalar, "::1"))
+	tree.Set("motd", core.NewScalarNode(core.StringScalar, "a\nb"))
+
+	losses := &core.Losses{}
+	output, err := FlatConfigurator{Dialect: Redis}.WriteTree(tree, losses)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "port 6379\nsave 3600 1\nsave 300 100\nbind ::1\n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	expectedLosses := []string{
+		"integer values are written as strings",
+		"bind is not a repeated key, only the last item of its list is written",
+		"motd is not written: Values cannot contain newlines",
+	}
+	if !reflect.DeepEqual(losses.Messages, expectedLosses) {
+		t.Errorf("Expected losses %v, got %v", expectedLosses, losses.Messages)
+	}
+}
+
+func TestWriteTreeFlattensMaps(t *testing.T) {
+	forwarding := core.NewMapNode()
+	forwarding.Set("ip_forward", core.NewScalarNode(core.IntegerScalar, "1"))
+	ipv4 := core.NewMapNode()
+	ipv4.Set("ipv4", forwarding)
+	tree := core.NewMapNode()
+	tree.Set("net", ipv4)
+	tree.Set("log_line_prefix", core.NewScalarNode(core.StringScalar, "%m [%p] "))
+
+	output, err := FlatConfigurator{Dialect: Sysctl}.WriteTree(tree, &core.Losses{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "net.ipv4.ip_forward = 1\nlog_line_prefix = %m [%p] \n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	output, err = FlatConfigurator{Dialect: PostgreSQL}.WriteTree(tree, &core.Losses{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected = "net.ipv4.ip_forward = 1\nlog_line_prefix = '%m [%p] '\n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+
+	if _, err := (FlatConfigurator{Dialect: Sysctl}).WriteTree(core.NewListNode(), &core.Losses{}); err == nil {
+		t.Error("Expected an error when writing a list")
+	}
+}
@@ -0,0 +1,292 @@
+package flat
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+var (
+	unquotedValueRegexp = regexp.MustCompile(`^[A-Za-z0-9_.+\-]+$`)
+	unitValueRegexp     = regexp.MustCompile(`^-?[0-9]+(?:\.[0-9]+)?\s*([A-Za-z]+)$`)
+)
+
+func OutputConfigFile(config *FlatConfiguration, outputType core.OutputType) string {
+	if outputType == core.MeaningFullOutput {
+		output := ""
+		for _, line := range config.Lines {
+			if line.ContentType == ini.KeyValueType {
+				output += line.ToString() + "\n"
+			}
+		}
+
+		return output
+	}
+
+	lines := []string{}
+	for _, line := range config.Lines {
+		lines = append(lines, line.ToString())
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// parseKey returns the name of a key. Flat files have no sections, so a dot
+// notation key is the name as is (net.ipv4.ip_forward), unless it is quoted
+// as listed ("net.ipv4.ip_forward"); other notations have a single segment.
+func parseKey(notationStyle core.NotationStyle, key string) (string, error) {
+	if notationStyle == core.DotNotation && !strings.HasPrefix(key, `"`) && !strings.HasPrefix(key, "'") {
+		return key, nil
+	}
+
+	parts, err := core.DecomposeKey(notationStyle, key)
+	if err != nil {
+		return "", err
+	}
+	if len(parts) != 1 {
+		return "", errors.New(fmt.Sprintf("Invalid key %s: flat files have no sections", key))
+	}
+
+	return parts[0], nil
+}
+
+func (dialect Dialect) normalizeKey(key string) string {
+	if dialect.SlashSeparatedKeys {
+		key = strings.ReplaceAll(key, "/", ".")
+	}
+	if dialect.CaseInsensitive {
+		key = strings.ToLower(key)
+	}
+
+	return key
+}
+
+func (dialect Dialect) isRepeated(key string) bool {
+	for _, repeated := range dialect.RepeatedKeys {
+		if dialect.normalizeKey(repeated) == dialect.normalizeKey(key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decodeValue returns the value without its quotes
+func (dialect Dialect) decodeValue(raw string) string {
+	if !dialect.SingleQuotes || len(raw) < 2 || raw[0] != '\'' {
+		return raw
+	}
+
+	value := raw[1 : len(raw)-1]
+	value = strings.ReplaceAll(value, "''", "'")
+
+	return strings.ReplaceAll(value, `\'`, "'")
+}
+
+// encodeValue returns the value as written in the file, quoted if the
+// previous value was quoted or if it cannot be written without quotes
+func (dialect Dialect) encodeValue(value string, previous string) (string, error) {
+	if strings.ContainsAny(value, "\r\n") {
+		return "", errors.New("Values cannot contain newlines")
+	}
+	if strings.TrimSpace(value) == "" && strings.TrimSpace(dialect.Separator) == "" {
+		return "", errors.New("Values cannot be empty")
+	}
+
+	if len(dialect.Units) > 0 {
+		if matches := unitValueRegexp.FindStringSubmatch(value); matches != nil && !dialect.isUnit(matches[1]) {
+			return "", errors.New(fmt.Sprintf(
+				"Invalid unit %s in %s, expected one of %s",
+				matches[1], value, strings.Join(dialect.Units, ", "),
+			))
+		}
+	}
+
+	if dialect.SingleQuotes && (strings.HasPrefix(previous, "'") || !unquotedValueRegexp.MatchString(value)) {
+		return "'" + strings.ReplaceAll(value, "'", "''") + "'", nil
+	}
+
+	return value, nil
+}
+
+func (dialect Dialect) isUnit(unit string) bool {
+	for _, allowed := range dialect.Units {
+		if allowed == unit {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (config *FlatConfiguration) getKeyLines(key string) []*ini.Line {
+	lines := []*ini.Line{}
+	for _, line := range config.Lines {
+		if line.ContentType == ini.KeyValueType && config.Dialect.normalizeKey(line.KeyValue.Key) == config.Dialect.normalizeKey(key) {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+func (config *FlatConfiguration) getDefaultLine(key string) *ini.Line {
+	for _, line := range config.Lines {
+		if line.ContentType == ini.OtherType && line.KeyValue != nil && config.Dialect.normalizeKey(line.KeyValue.Key) == config.Dialect.normalizeKey(key) {
+			return line
+		}
+	}
+
+	return nil
+}
+
+// GetParameterFromPath returns the value in use: the last one, or all of
+// them, one per line, for repeated keys
+func GetParameterFromPath(dialect Dialect, filePath string, key string) (string, error) {
+	config, err := GetParsedFlatFile(dialect, filePath)
+	if err != nil {
+		return "", err
+	}
+
+	lines := config.getKeyLines(key)
+	if len(lines) == 0 {
+		return "", errors.New("Key not found")
+	}
+	if !dialect.isRepeated(key) {
+		lines = lines[len(lines)-1:]
+	}
+
+	values := []string{}
+	for _, line := range lines {
+		values = append(values, dialect.decodeValue(line.KeyValue.Value))
+	}
+
+	return strings.Join(values, "\n"), nil
+}
+
+// EditConfigFile sets the value of a key, uncommenting its commented default
+// or adding it at the end of the file if it does not exist yet. With add, a
+// new occurrence of a repeated key is added.
+func EditConfigFile(dialect Dialect, filePath string, key string, value string, add bool) (*FlatConfiguration, error) {
+	config, err := GetParsedFlatFile(dialect, filePath)
+	if err != nil {
+		return &FlatConfiguration{}, err
+	}
+
+	existing := config.getKeyLines(key)
+	repeated := dialect.isRepeated(key)
+
+	switch {
+	case add && !repeated && len(existing) > 0:
+		return &FlatConfiguration{}, errors.New(fmt.Sprintf("%s cannot be repeated, use set to change its value", key))
+	case len(existing) == 0 && config.getDefaultLine(key) != nil:
+		err = config.uncomment(config.getDefaultLine(key), value)
+	case add || len(existing) == 0:
+		err = config.addLine(existing, key, value)
+	case repeated && len(existing) > 1:
+		return &FlatConfiguration{}, errors.New(fmt.Sprintf(
+			"%s is set %d times, use add to add a value or unset to remove them",
+			key, len(existing),
+		))
+	default:
+		// The last occurrence is the one in use
+		err = config.setValue(existing[len(existing)-1], value)
+	}
+	if err != nil {
+		return &FlatConfiguration{}, err
+	}
+
+	return config.validate(key)
+}
+
+// RemoveFromConfigFile removes all the occurrences of a key
+func RemoveFromConfigFile(dialect Dialect, filePath string, key string) (*FlatConfiguration, error) {
+	config, err := GetParsedFlatFile(dialect, filePath)
+	if err != nil {
+		return &FlatConfiguration{}, err
+	}
+
+	if len(config.getKeyLines(key)) == 0 {
+		return &FlatConfiguration{}, errors.New("Key not found")
+	}
+
+	lines := []*ini.Line{}
+	for _, line := range config.Lines {
+		if line.ContentType != ini.KeyValueType || dialect.normalizeKey(line.KeyValue.Key) != dialect.normalizeKey(key) {
+			lines = append(lines, line)
+		}
+	}
+	config.Lines = lines
+
+	return &config, nil
+}
+
+func (config *FlatConfiguration) validate(key string) (*FlatConfiguration, error) {
+	if _, err := ParseFlatContent(config.Dialect, OutputConfigFile(config, core.FullOutput)); err != nil {
+		return &FlatConfiguration{}, errors.New(fmt.Sprintf("Refusing to set %s: %s", key, err.Error()))
+	}
+
+	return config, nil
+}
+
+func (config *FlatConfiguration) setValue(line *ini.Line, value string) error {
+	encoded, err := config.Dialect.encodeValue(value, line.KeyValue.Value)
+	if err != nil {
+		return err
+	}
+	line.SetValue(encoded)
+
+	return nil
+}
+
+// uncomment removes the comment character of a commented default and sets
+// its value
+func (config *FlatConfiguration) uncomment(line *ini.Line, value string) error {
+	commentPosition := len(line.SpacePrefix)
+	line.StringContent = line.StringContent[:commentPosition] + line.StringContent[commentPosition+1:]
+	line.KeyValue.ValuePosition.Start--
+	line.KeyValue.ValuePosition.End--
+	line.KeyValue.Commented = false
+	line.ContentType = ini.KeyValueType
+
+	return config.setValue(line, value)
+}
+
+// addLine adds a line after the last occurrence of the key, or at the end of
+// the file
+func (config *FlatConfiguration) addLine(existing []*ini.Line, key string, value string) error {
+	encoded, err := config.Dialect.encodeValue(value, "")
+	if err != nil {
+		return err
+	}
+
+	line := &ini.Line{
+		Status:      ini.Changed,
+		ContentType: ini.KeyValueType,
+		KeyValue:    &ini.KeyValue{Key: key, Value: encoded, Separator: config.Dialect.Separator},
+	}
+
+	position := len(config.Lines)
+	if len(existing) > 0 {
+		line.KeyValue.Key = existing[0].KeyValue.Key
+		line.SpacePrefix = existing[0].SpacePrefix
+		for idx, other := range config.Lines {
+			if other == existing[len(existing)-1] {
+				position = idx + 1
+			}
+		}
+	} else if position > 0 && config.Lines[position-1].StringContent == "" && config.Lines[position-1].Status == ini.Original {
+		// Keep the newline at the end of the file
+		position--
+	}
+
+	lines := append([]*ini.Line{}, config.Lines[:position]...)
+	lines = append(lines, line)
+	config.Lines = append(lines, config.Lines[position:]...)
+
+	return nil
+}
@@ -0,0 +1,194 @@
+package flat
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/testutil"
+)
+
+const REDIS_FILE_PATH = "../../../data/flat/redis.conf"
+const POSTGRESQL_FILE_PATH = "../../../data/flat/postgresql.conf"
+const SYSCTL_FILE_PATH = "../../../data/flat/sysctl.conf"
+
+var filePaths = map[string]string{
+	"redis":      REDIS_FILE_PATH,
+	"postgresql": POSTGRESQL_FILE_PATH,
+	"sysctl":     SYSCTL_FILE_PATH,
+}
+
+func TestGetParameter(t *testing.T) {
+	type GetTestElement struct {
+		dialect  Dialect
+		key      string
+		expected string
+	}
+
+	cases := []GetTestElement{
+		{Redis, "port", "6379"},
+		{Redis, "PORT", "6379"},
+		{Redis, "bind", "127.0.0.1 -::1"},
+		{Redis, "save", "3600 1\n300 100\n60 10000"},
+		{Redis, "include", "/etc/redis/common.conf"},
+		{Redis, "requirepass", `"foo bar"`},
+		{PostgreSQL, "listen_addresses", "localhost"},
+		{PostgreSQL, "port", "5433"},
+		{PostgreSQL, "shared_buffers", "128MB"},
+		{PostgreSQL, "log_line_prefix", "%m [%p] %q%u@%d "},
+		{PostgreSQL, "search_path", `"$user", public`},
+		{PostgreSQL, "auto_explain.log_min_duration", "250ms"},
+		{Sysctl, "net.ipv4.ip_forward", "1"},
+		{Sysctl, "net/ipv4/ip_forward", "1"},
+		{Sysctl, "net.ipv6.conf.all.forwarding", "1"},
+	}
+
+	for _, element := range cases {
+		t.Run("it gets "+element.dialect.Name+" "+element.key, func(t *testing.T) {
+			value, err := GetParameterFromPath(element.dialect, filePaths[element.dialect.Name], element.key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value != element.expected {
+				t.Fatal(fmt.Sprintf("Expected %s got %s", element.expected, value))
+			}
+		})
+	}
+
+	errorCases := map[string]Dialect{"maxmemory": Redis, "work_mem": PostgreSQL, "kernel.printk": Sysctl, "net.ipv4": Sysctl}
+	for key, dialect := range errorCases {
+		t.Run("it does not get "+dialect.Name+" "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(dialect, filePaths[dialect.Name], key)
+			if err == nil {
+				t.Error("Should fail. Got " + value + " instead")
+			}
+		})
+	}
+}
+
+func TestGetParameterInNotations(t *testing.T) {
+	configurator := FlatConfigurator{Dialect: Sysctl}
+	cases := map[core.NotationStyle][]string{
+		core.DotNotation:      {"vm.swappiness", `"vm.swappiness"`, "'vm.swappiness'"},
+		core.PointerNotation:  {"/vm.swappiness"},
+		core.BracketsNotation: {"vm.swappiness"},
+	}
+	for notationStyle, keys := range cases {
+		for _, key := range keys {
+			t.Run("it gets "+key, func(t *testing.T) {
+				value, err := configurator.GetParameter(notationStyle, SYSCTL_FILE_PATH, key)
+				if err != nil || value != "10" {
+					t.Errorf("Expected 10 got %s (%v)", value, err)
+				}
+			})
+		}
+	}
+
+	for _, key := range []string{"/vm/swappiness", "/vm.swappiness~2"} {
+		if value, err := configurator.GetParameter(core.PointerNotation, SYSCTL_FILE_PATH, key); err == nil {
+			t.Error("Should fail. Got " + value + " instead")
+		}
+	}
+	if value, err := configurator.GetParameter(core.DotNotation, SYSCTL_FILE_PATH, `"vm".swappiness`); err == nil {
+		t.Error("Should fail. Got " + value + " instead")
+	}
+}
+
+func TestEditParameter(t *testing.T) {
+	type EditTestElement struct {
+		dialect Dialect
+		key     string
+		value   string
+		add     bool
+		old     string
+		new     string
+	}
+
+	cases := []EditTestElement{
+		{Redis, "port", "6380", false, "port 6379", "port 6380"},
+		{Redis, "maxmemory", "2gb", false, "rename-command CONFIG \"\"\n", "rename-command CONFIG \"\"\nmaxmemory 2gb\n"},
+		{Redis, "save", "900 1", true, "save 60 10000\n", "save 60 10000\nsave 900 1\n"},
+		{Redis, "include", "/etc/redis/local.conf", false, "include /etc/redis/common.conf", "include /etc/redis/local.conf"},
+		{PostgreSQL, "listen_addresses", "*", false, "listen_addresses = 'localhost'\t\t# what", "listen_addresses = '*'\t\t# what"},
+		{PostgreSQL, "max_connections", "200", false, "max_connections = 100\t\t\t# (change", "max_connections = 200\t\t\t# (change"},
+		{PostgreSQL, "port", "5434", false, "port = 5433", "port = 5434"},
+		{PostgreSQL, "shared_buffers", "1GB", false, "shared_buffers = 128MB\t\t\t# min", "shared_buffers = 1GB\t\t\t# min"},
+		{PostgreSQL, "work_mem", "16MB", false, "#work_mem = 4MB\t\t\t\t# min 64kB", "work_mem = 16MB\t\t\t\t# min 64kB"},
+		{PostgreSQL, "dynamic_shared_memory_type", "sysv", false, "= posix\t", "= sysv\t"},
+		{PostgreSQL, "log_timezone", "Europe/Paris", false, "'Etc/UTC'", "'Europe/Paris'"},
+		{PostgreSQL, "data_directory", "/var/lib/postgresql/data", false, "port = 5433\n", "port = 5433\ndata_directory = '/var/lib/postgresql/data'\n"},
+		{PostgreSQL, "application_name", "it's me", false, "port = 5433\n", "port = 5433\napplication_name = 'it''s me'\n"},
+		{Sysctl, "net.ipv4.ip_forward", "0", false, "net.ipv4.ip_forward=1", "net.ipv4.ip_forward=0"},
+		{Sysctl, "vm/swappiness", "60", false, "vm.swappiness = 10", "vm.swappiness = 60"},
+		{Sysctl, "fs.file-max", "2097152", false, "vm.swappiness = 10\n", "vm.swappiness = 10\nfs.file-max = 2097152\n"},
+	}
+
+	for _, element := range cases {
+		t.Run("it sets "+element.dialect.Name+" "+element.key, func(t *testing.T) {
+			filePath := filePaths[element.dialect.Name]
+			config, err := EditConfigFile(element.dialect, filePath, element.key, element.value, element.add)
+			testutil.AssertEditedOutput(t, filePath, config, err, element.old, element.new)
+		})
+	}
+
+	type ErrorTestElement struct {
+		dialect Dialect
+		key     string
+		value   string
+	}
+	errorCases := []ErrorTestElement{
+		{Redis, "save", "900 1"},
+		{Redis, "port", ""},
+		{Redis, "port", "6379\nreplicaof 10.0.0.1 6379"},
+		{PostgreSQL, "shared_buffers", "1gb"},
+		{PostgreSQL, "work_mem", "64 Mb"},
+	}
+	for _, element := range errorCases {
+		t.Run("it refuses to set "+element.dialect.Name+" "+element.key+" to "+element.value, func(t *testing.T) {
+			if _, err := EditConfigFile(element.dialect, filePaths[element.dialect.Name], element.key, element.value, false); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+
+	t.Run("it refuses to add a key which cannot be repeated", func(t *testing.T) {
+		if _, err := EditConfigFile(Redis, REDIS_FILE_PATH, "port", "6380", true); err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
+
+func TestUnsetParameter(t *testing.T) {
+	config, err := RemoveFromConfigFile(Redis, REDIS_FILE_PATH, "save")
+	testutil.AssertEditedOutput(t, REDIS_FILE_PATH, config, err, "save 3600 1\nsave 300 100\nsave 60 10000\n", "")
+
+	config, err = RemoveFromConfigFile(Sysctl, SYSCTL_FILE_PATH, "net/ipv4/conf/all/accept_redirects")
+	testutil.AssertEditedOutput(t, SYSCTL_FILE_PATH, config, err, "net.ipv4.conf.all.accept_redirects = 0\n", "")
+
+	if _, err := RemoveFromConfigFile(PostgreSQL, POSTGRESQL_FILE_PATH, "work_mem"); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
+func TestParseInvalidFlat(t *testing.T) {
+	type ParseTestElement struct {
+		dialect Dialect
+		content string
+	}
+
+	cases := []ParseTestElement{
+		{Redis, "port"},
+		{PostgreSQL, "listen_addresses = 'localhost"},
+		{PostgreSQL, "port = 5432 5433"},
+		{Sysctl, "vm.swappiness 10"},
+	}
+
+	for _, element := range cases {
+		t.Run("it rejects "+element.content, func(t *testing.T) {
+			if _, err := ParseFlatContent(element.dialect, element.content); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,158 @@
+package flat
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+// commentedKeyRegexp is what a key must look like for a comment to be a
+// commented default ("#work_mem = 4MB"), other comments being sentences
+var commentedKeyRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.\-]*$`)
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r'
+}
+
+// parseKeyValue parses the key and the value found at start, the value
+// position being kept for minimal diff rendering
+func parseKeyValue(dialect Dialect, lineString string, start int) (*ini.KeyValue, error) {
+	pos := start
+	for pos < len(lineString) && !isSpace(lineString[pos]) && lineString[pos] != '=' {
+		pos++
+	}
+	key := lineString[start:pos]
+	if key == "" {
+		return nil, errors.New("missing key")
+	}
+
+	separatorStart := pos
+	for pos < len(lineString) && isSpace(lineString[pos]) {
+		pos++
+	}
+	equals := pos < len(lineString) && lineString[pos] == '='
+	if equals {
+		pos++
+		for pos < len(lineString) && isSpace(lineString[pos]) {
+			pos++
+		}
+	}
+	if dialect.EqualsRequired && !equals {
+		return nil, errors.New(fmt.Sprintf("missing \"=\" after %s", key))
+	}
+	if !equals && (pos == separatorStart || pos == len(lineString)) {
+		return nil, errors.New(fmt.Sprintf("missing value for %s", key))
+	}
+
+	valueStart := pos
+	switch {
+	case dialect.SingleQuotes && pos < len(lineString) && lineString[pos] == '\'':
+		pos++
+		for {
+			if pos >= len(lineString) {
+				return nil, errors.New(fmt.Sprintf("unterminated quoted value for %s", key))
+			}
+			if lineString[pos] == '\\' {
+				pos += 2
+				continue
+			}
+			if lineString[pos] == '\'' {
+				if pos+1 < len(lineString) && lineString[pos+1] == '\'' {
+					pos += 2
+					continue
+				}
+				pos++
+				break
+			}
+			pos++
+		}
+	case dialect.InlineComments:
+		for pos < len(lineString) && !isSpace(lineString[pos]) && lineString[pos] != '#' {
+			pos++
+		}
+	default:
+		pos = len(strings.TrimRight(lineString, " \t\r"))
+	}
+	valueEnd := pos
+
+	rest := strings.TrimSpace(lineString[valueEnd:])
+	if rest != "" && !(dialect.InlineComments && strings.HasPrefix(rest, "#")) {
+		return nil, errors.New(fmt.Sprintf("unexpected %q after the value of %s", rest, key))
+	}
+
+	return &ini.KeyValue{
+		Key:           key,
+		Value:         lineString[valueStart:valueEnd],
+		Separator:     dialect.Separator,
+		ValuePosition: &ini.ValuePosition{Start: valueStart, End: valueEnd},
+	}, nil
+}
+
+func parseLine(dialect Dialect, lineNumber int, lineString string) (*ini.Line, error) {
+	trimmedLine := strings.TrimSpace(lineString)
+	spacePrefix := lineString[:len(lineString)-len(strings.TrimLeft(lineString, " \t"))]
+
+	line := &ini.Line{
+		LineNumber:    lineNumber,
+		StringContent: lineString,
+		SpacePrefix:   spacePrefix,
+		Status:        ini.Original,
+		ContentType:   ini.OtherType,
+	}
+
+	if trimmedLine == "" {
+		return line, nil
+	}
+
+	if strings.ContainsRune(dialect.CommentPrefixes, rune(trimmedLine[0])) {
+		if dialect.CommentedDefaults {
+			keyValue, err := parseKeyValue(dialect, lineString, len(spacePrefix)+1)
+			if err == nil && commentedKeyRegexp.MatchString(keyValue.Key) {
+				keyValue.Commented = true
+				line.KeyValue = keyValue
+			}
+		}
+
+		return line, nil
+	}
+
+	keyValue, err := parseKeyValue(dialect, lineString, len(spacePrefix))
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid %s configuration on line %d: %s", dialect.Name, lineNumber, err.Error()))
+	}
+	line.ContentType = ini.KeyValueType
+	line.KeyValue = keyValue
+
+	return line, nil
+}
+
+func ParseFlatContent(dialect Dialect, content string) ([]*ini.Line, error) {
+	lines := []*ini.Line{}
+	for idx, lineString := range strings.Split(content, "\n") {
+		line, err := parseLine(dialect, idx+1, lineString)
+		if err != nil {
+			return nil, err
+		}
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+func GetParsedFlatFile(dialect Dialect, filePath string) (FlatConfiguration, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return FlatConfiguration{}, err
+	}
+
+	lines, err := ParseFlatContent(dialect, content)
+	if err != nil {
+		return FlatConfiguration{}, err
+	}
+
+	return FlatConfiguration{lines, dialect, filePath}, nil
+}
@@ -0,0 +1,153 @@
+package flat
+
+import (
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+// Dialect describes a flat "key value" or "key = value" configuration file,
+// without sections
+type Dialect struct {
+	Name string
+	// Separator is written between the key and the value of a new line
+	Separator string
+	// EqualsRequired is true if keys and values must be separated by "=",
+	// false if whitespace is enough
+	EqualsRequired bool
+	// CommentPrefixes start a comment line
+	CommentPrefixes string
+	// InlineComments allows "# comments" after a value
+	InlineComments bool
+	// SingleQuotes allows values between single quotes, a quote being
+	// escaped by doubling it
+	SingleQuotes bool
+	// CommentedDefaults allows to uncomment "#key = value" lines instead of
+	// adding a new line
+	CommentedDefaults bool
+	CaseInsensitive   bool
+	// SlashSeparatedKeys allows to write net/ipv4/ip_forward for
+	// net.ipv4.ip_forward
+	SlashSeparatedKeys bool
+	// RepeatedKeys are keys whose occurrences are all used, the last
+	// occurrence of other keys overriding the previous ones
+	RepeatedKeys []string
+	// Units are the unit suffixes allowed after a number
+	Units []string
+}
+
+var Redis = Dialect{
+	Name:            "redis",
+	Separator:       " ",
+	CommentPrefixes: "#",
+	CaseInsensitive: true,
+	RepeatedKeys: []string{
+		"include", "loadmodule", "rename-command", "save", "user", "sentinel",
+	},
+}
+
+var PostgreSQL = Dialect{
+	Name:              "postgresql",
+	Separator:         " = ",
+	CommentPrefixes:   "#",
+	InlineComments:    true,
+	SingleQuotes:      true,
+	CommentedDefaults: true,
+	CaseInsensitive:   true,
+	Units:             []string{"B", "kB", "MB", "GB", "TB", "us", "ms", "s", "min", "h", "d"},
+}
+
+var Sysctl = Dialect{
+	Name:               "sysctl",
+	Separator:          " = ",
+	EqualsRequired:     true,
+	CommentPrefixes:    "#;",
+	SlashSeparatedKeys: true,
+}
+
+type FlatConfiguration struct {
+	Lines    []*ini.Line
+	Dialect  Dialect
+	FilePath string
+}
+
+func (config *FlatConfiguration) OutputFile(outputType core.OutputType) (string, error) {
+	return OutputConfigFile(config, outputType), nil
+}
+
+func (config *FlatConfiguration) WriteToFile(filepath string, outputType core.OutputType) error {
+	output, err := config.OutputFile(outputType)
+	if err != nil {
+		return err
+	}
+
+	return io.WriteFileContents(filepath, output)
+}
+
+// FlatConfigurator handles the files of a dialect. Keys are never split:
+// dots are part of them (net.ipv4.ip_forward).
+type FlatConfigurator struct {
+	Dialect Dialect
+}
+
+func (configurator FlatConfigurator) GetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (string, error) {
+	name, err := parseKey(notationStyle, key)
+	if err != nil {
+		return "", err
+	}
+
+	return GetParameterFromPath(configurator.Dialect, filePath, name)
+}
+
+func (configurator FlatConfigurator) SetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	name, err := parseKey(notationStyle, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return toConfiguration(EditConfigFile(configurator.Dialect, filePath, name, value, false))
+}
+
+func (configurator FlatConfigurator) AddParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	name, err := parseKey(notationStyle, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return toConfiguration(EditConfigFile(configurator.Dialect, filePath, name, value, true))
+}
+
+func (configurator FlatConfigurator) UnsetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (core.Configuration, error) {
+	name, err := parseKey(notationStyle, key)
+	if err != nil {
+		return nil, err
+	}
+
+	return toConfiguration(RemoveFromConfigFile(configurator.Dialect, filePath, name))
+}
+
+func toConfiguration(config *FlatConfiguration, err error) (core.Configuration, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,115 @@
+package hcl
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+// ReadDocument returns the document tree of the configuration: blocks are
+// sections nested under their type and labels (resource.aws_instance.web),
+// the blocks with the same labels being a list, and tuples and objects are
+// lists and maps. Expressions (references, function calls...) are strings
+// as written.
+func (configurator HclConfigurator) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	config, err := GetParsedHclFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Comments) > 0 {
+		losses.Add("comments are not converted")
+	}
+	document := source.NewDocument(config.Content, config.Comments, "#/*")
+	document.Closing = "*/"
+
+	tree := core.NewMapNode()
+	tree.Path = []string{}
+
+	return config.readBody(document, config.Root, tree, losses), nil
+}
+
+func childPath(path []string, name string) []string {
+	return append(append([]string{}, path...), name)
+}
+
+func (config *HclConfiguration) readBody(document *source.Document, body *Body, node *core.Node, losses *core.Losses) *core.Node {
+	attributes, blocks := body.Attributes, body.Blocks
+	for len(attributes) > 0 || len(blocks) > 0 {
+		if len(blocks) == 0 || len(attributes) > 0 && attributes[0].NameSpan.Start < blocks[0].Start {
+			attribute := attributes[0]
+			attributes = attributes[1:]
+
+			span := source.Span{Start: attribute.NameSpan.Start, End: attribute.Value.Span.End}
+			value := config.readExpression(document, attribute.Value, childPath(node.Path, attribute.Name), losses)
+			value.Span = document.Lines(span)
+			value.Comments = document.AttachedComments(span)
+			node.Add(attribute.Name, value)
+			continue
+		}
+
+		block := blocks[0]
+		blocks = blocks[1:]
+
+		parent := node
+		names := append([]string{block.Type}, block.Labels...)
+		for _, name := range names[:len(names)-1] {
+			child := parent.Get(name)
+			if child == nil || !child.IsMap() {
+				child = core.NewMapNode()
+				child.Path = childPath(parent.Path, name)
+				parent.Add(name, child)
+			}
+			parent = child
+		}
+
+		span := source.Span{Start: block.Start, End: block.Close + 1}
+		section := core.NewMapNode()
+		section.Kind = core.SectionNode
+		section.Path = childPath(parent.Path, names[len(names)-1])
+		section.Span = document.Lines(span)
+		section.Comments = document.AttachedComments(span)
+		parent.Add(names[len(names)-1], config.readBody(document, block.Body, section, losses))
+	}
+
+	return node
+}
+
+func (config *HclConfiguration) readExpression(document *source.Document, expression *Expression, path []string, losses *core.Losses) *core.Node {
+	var node *core.Node
+	raw := config.Content[expression.Span.Start:expression.Span.End]
+
+	switch expression.Kind {
+	case TupleKind:
+		node = core.NewListNode()
+		for idx, element := range expression.Elements {
+			node.Items = append(node.Items, config.readExpression(document, element, childPath(path, strconv.Itoa(idx)), losses))
+		}
+	case ObjectKind:
+		node = core.NewMapNode()
+		for _, item := range expression.Items {
+			node.Set(item.Key, config.readExpression(document, item.Value, childPath(path, item.Key), losses))
+		}
+	case StringKind, HeredocKind:
+		node = core.NewScalarNode(core.StringScalar, toString(config.Content, expression))
+	case NumberKind:
+		node = core.NewScalarNode(core.IntegerScalar, raw)
+		if strings.ContainsAny(raw, ".eE") {
+			node.Type = core.FloatScalar
+		}
+	case BoolKind:
+		node = core.NewScalarNode(core.BoolScalar, raw)
+	case NullKind:
+		losses.Add("null values are converted to empty strings")
+		node = core.NewScalarNode(core.UntypedScalar, "")
+	default:
+		losses.Add("expressions (references, function calls...) are converted to strings")
+		node = core.NewScalarNode(core.UntypedScalar, raw)
+	}
+	node.Path = path
+	node.Span = document.Lines(expression.Span)
+
+	return node
+}
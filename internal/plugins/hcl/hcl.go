@@ -0,0 +1,459 @@
+package hcl
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+// resolution is the result of resolving a key. Depth is the number of parts
+// of the key which were found: the key exists when it is the length of the
+// key, otherwise Body or Value is the last existing element.
+type resolution struct {
+	Depth     int
+	Body      *Body
+	Block     *Block
+	Attribute *Attribute
+	// Value is the value found, Parent the tuple or object containing it
+	Value  *Expression
+	Parent *Expression
+}
+
+func (body *Body) attribute(name string) *Attribute {
+	for _, attribute := range body.Attributes {
+		if attribute.Name == name {
+			return attribute
+		}
+	}
+
+	return nil
+}
+
+func labelsMatch(labels []string, path []string) bool {
+	if len(labels) > len(path) {
+		return false
+	}
+
+	for i, label := range labels {
+		if path[i] != label {
+			return false
+		}
+	}
+
+	return true
+}
+
+// findBlock returns the block of the given type whose labels are the next
+// parts of the path, and the number of parts used
+func (body *Body) findBlock(blockType string, path []string) (*Block, int, error) {
+	candidates := []*Block{}
+	for _, block := range body.Blocks {
+		if block.Type == blockType && labelsMatch(block.Labels, path) {
+			candidates = append(candidates, block)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, 0, errors.New("Key not found")
+	}
+	if len(candidates) == 1 {
+		return candidates[0], len(candidates[0].Labels), nil
+	}
+
+	// Blocks with the same labels are selected by their index
+	used := len(candidates[0].Labels)
+	for _, candidate := range candidates {
+		if len(candidate.Labels) != used {
+			return nil, 0, errors.New(fmt.Sprintf("Several %s blocks match, add their labels to the key", blockType))
+		}
+	}
+	if used < len(path) {
+		if index, err := strconv.Atoi(path[used]); err == nil && index >= 0 && index < len(candidates) {
+			return candidates[index], used + 1, nil
+		}
+	}
+
+	return nil, 0, errors.New(fmt.Sprintf(
+		"%d %s blocks match, add their index to the key (e.g. %s.0)",
+		len(candidates), blockType, strings.Join(append([]string{blockType}, path[:used]...), "."),
+	))
+}
+
+func resolveValue(result resolution, path []string) (resolution, error) {
+	for result.Depth < len(path) {
+		part := path[result.Depth]
+		value := result.Value
+
+		switch value.Kind {
+		case ObjectKind:
+			var found *ObjectItem
+			for _, item := range value.Items {
+				if item.Key == part {
+					found = item
+				}
+			}
+			if found == nil {
+				return result, nil
+			}
+			result.Parent, result.Value = value, found.Value
+		case TupleKind:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 {
+				return result, errors.New(fmt.Sprintf("%s is not a valid index", part))
+			}
+			if index >= len(value.Elements) {
+				return result, nil
+			}
+			result.Parent, result.Value = value, value.Elements[index]
+		default:
+			return result, errors.New(fmt.Sprintf("%s is not a list or a map", strings.Join(path[:result.Depth], ".")))
+		}
+
+		result.Depth++
+	}
+
+	return result, nil
+}
+
+func resolvePath(root *Body, path []string) (resolution, error) {
+	result := resolution{Body: root}
+
+	for result.Depth < len(path) {
+		part := path[result.Depth]
+
+		if attribute := result.Body.attribute(part); attribute != nil {
+			result.Attribute = attribute
+			result.Value = attribute.Value
+			result.Depth++
+
+			return resolveValue(result, path)
+		}
+
+		block, used, err := result.Body.findBlock(part, path[result.Depth+1:])
+		if err != nil {
+			if err.Error() == "Key not found" {
+				return result, nil
+			}
+			return result, err
+		}
+
+		result.Depth += used + 1
+		if result.Depth == len(path) {
+			result.Block = block
+			return result, nil
+		}
+		result.Body = block.Body
+	}
+
+	return result, nil
+}
+
+func OutputConfigFile(config *HclConfiguration, outputType core.OutputType) string {
+	if outputType != core.MeaningFullOutput {
+		return config.Content
+	}
+
+	return source.RemoveComments(config.Content, config.Comments)
+}
+
+func GetParameterFromPath(notationStyle core.NotationStyle, filePath string, key string) (string, error) {
+	config, err := GetParsedHclFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := core.DecomposeKey(notationStyle, key)
+	if err != nil {
+		return "", err
+	}
+	result, err := resolvePath(config.Root, path)
+	if err != nil {
+		return "", err
+	}
+	if result.Depth != len(path) {
+		return "", errors.New("Key not found")
+	}
+	if result.Block != nil {
+		return "", errors.New(fmt.Sprintf("%s is a block, not a value", key))
+	}
+
+	return toString(config.Content, result.Value), nil
+}
+
+// EditConfigFile sets an existing value, keeping its type unless valueType
+// is given, or adds a new attribute, map item or list element
+func EditConfigFile(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+	valueType string,
+) (*HclConfiguration, error) {
+	config, err := GetParsedHclFile(filePath)
+	if err != nil {
+		return &HclConfiguration{}, err
+	}
+
+	path, err := core.DecomposeKey(notationStyle, key)
+	if err != nil {
+		return &HclConfiguration{}, err
+	}
+	result, err := resolvePath(config.Root, path)
+	if err != nil {
+		return &HclConfiguration{}, err
+	}
+
+	encoded := ""
+	switch {
+	case valueType != "":
+		kind, err := getValueKind(valueType)
+		if err != nil {
+			return &HclConfiguration{}, err
+		}
+		encoded, err = encodeValue(kind, value)
+		if err != nil {
+			return &HclConfiguration{}, err
+		}
+	case result.Depth == len(path) && result.Value != nil:
+		encoded, err = encodeValue(result.Value.Kind, value)
+		if err != nil {
+			return &HclConfiguration{}, err
+		}
+	default:
+		encoded = inferValue(value)
+	}
+
+	switch {
+	case result.Block != nil:
+		return &HclConfiguration{}, errors.New(fmt.Sprintf("%s is a block, set its attributes instead", key))
+	case result.Depth == len(path):
+		config.replace(result.Value.Span, encoded)
+	case result.Depth < len(path)-1:
+		return &HclConfiguration{}, errors.New("Key not found")
+	case result.Value == nil:
+		config.insertAttribute(result.Body, path[len(path)-1], encoded)
+	case result.Value.Kind == ObjectKind:
+		config.insertItem(result.Value, path[len(path)-1], encoded)
+	default:
+		// An index equal to the length of the list appends an element
+		if index, err := strconv.Atoi(path[len(path)-1]); err != nil || index != len(result.Value.Elements) {
+			return &HclConfiguration{}, errors.New("Key not found")
+		}
+		config.appendElement(result.Value, encoded)
+	}
+
+	return config.validate(key)
+}
+
+// RemoveFromConfigFile removes an attribute, a block, a map item or a list
+// element
+func RemoveFromConfigFile(notationStyle core.NotationStyle, filePath string, key string) (*HclConfiguration, error) {
+	config, err := GetParsedHclFile(filePath)
+	if err != nil {
+		return &HclConfiguration{}, err
+	}
+
+	path, err := core.DecomposeKey(notationStyle, key)
+	if err != nil {
+		return &HclConfiguration{}, err
+	}
+	result, err := resolvePath(config.Root, path)
+	if err != nil {
+		return &HclConfiguration{}, err
+	}
+	if result.Depth != len(path) {
+		return &HclConfiguration{}, errors.New("Key not found")
+	}
+
+	switch {
+	case result.Block != nil:
+		config.removeLines(source.Span{Start: result.Block.Start, End: result.Block.Close + 1})
+	case result.Parent == nil:
+		config.removeLines(source.Span{Start: result.Attribute.NameSpan.Start, End: result.Value.Span.End})
+	case result.Parent.Kind == ObjectKind:
+		for _, item := range result.Parent.Items {
+			if item.Value == result.Value {
+				config.removeFromCollection(result.Parent, source.Span{Start: item.KeySpan.Start, End: item.Value.Span.End})
+			}
+		}
+	default:
+		config.removeFromCollection(result.Parent, result.Value.Span)
+	}
+
+	return config.validate(key)
+}
+
+func (config *HclConfiguration) validate(key string) (*HclConfiguration, error) {
+	root, comments, err := ParseHclContent(config.Content)
+	if err != nil {
+		return &HclConfiguration{}, errors.New(fmt.Sprintf("Refusing to set %s: %s", key, err.Error()))
+	}
+	config.Root = root
+	config.Comments = comments
+
+	return config, nil
+}
+
+func (config *HclConfiguration) replace(span source.Span, replacement string) {
+	config.Content = config.Content[:span.Start] + replacement + config.Content[span.End:]
+}
+
+func (config *HclConfiguration) lineStart(position int) int {
+	return strings.LastIndexByte(config.Content[:position], '\n') + 1
+}
+
+func (config *HclConfiguration) lineEnd(position int) int {
+	end := strings.IndexByte(config.Content[position:], '\n')
+	if end == -1 {
+		return len(config.Content)
+	}
+
+	return position + end
+}
+
+func (config *HclConfiguration) indentOf(position int) string {
+	line := config.Content[config.lineStart(position):]
+
+	return line[:len(line)-len(strings.TrimLeft(line, " \t"))]
+}
+
+// isAlone tells if nothing but whitespace, commas and comments surrounds the
+// span on its lines
+func (config *HclConfiguration) isAlone(span source.Span) bool {
+	before := strings.TrimSpace(config.Content[config.lineStart(span.Start):span.Start])
+	after := strings.TrimSpace(config.Content[span.End:config.lineEnd(span.End)])
+	after = strings.TrimSpace(strings.TrimPrefix(after, ","))
+
+	return before == "" && (after == "" || strings.HasPrefix(after, "#") || strings.HasPrefix(after, "//"))
+}
+
+// removeLines removes the whole lines of the span, with their newline
+func (config *HclConfiguration) removeLines(span source.Span) {
+	end := config.lineEnd(span.End)
+	if end < len(config.Content) {
+		end++
+	}
+
+	config.replace(source.Span{Start: config.lineStart(span.Start), End: end}, "")
+}
+
+// removeFromCollection removes an element of a tuple or an item of an object,
+// with its separating comma
+func (config *HclConfiguration) removeFromCollection(collection *Expression, span source.Span) {
+	if config.isAlone(span) {
+		config.removeLines(span)
+		return
+	}
+
+	rest := config.Content[span.End:collection.Span.End]
+	trimmed := strings.TrimLeft(rest, " \t")
+	if strings.HasPrefix(trimmed, ",") {
+		// Remove the comma following the element and the spaces after it
+		end := span.End + len(rest) - len(trimmed) + 1
+		end += len(config.Content[end:]) - len(strings.TrimLeft(config.Content[end:], " \t"))
+		config.replace(source.Span{Start: span.Start, End: end}, "")
+		return
+	}
+
+	// Last element: remove the comma preceding it
+	before := strings.TrimRight(config.Content[collection.Span.Start:span.Start], " \t")
+	start := span.Start
+	if strings.HasSuffix(before, ",") {
+		start = collection.Span.Start + len(before) - 1
+	}
+	config.replace(source.Span{Start: start, End: span.End}, "")
+}
+
+// alignedName pads a name so that its "=" is aligned with the one of the
+// previous line, as terraform fmt does
+func alignedName(name string, previousNameStart int, previousEquals int) string {
+	width := previousEquals - previousNameStart
+	if width > len(name)+1 {
+		return name + strings.Repeat(" ", width-len(name))
+	}
+
+	return name + " "
+}
+
+func (config *HclConfiguration) insertAttribute(body *Body, name string, value string) {
+	if len(body.Attributes) > 0 {
+		last := body.Attributes[len(body.Attributes)-1]
+		end := config.lineEnd(last.Value.Span.End)
+		line := config.indentOf(last.NameSpan.Start) + alignedName(name, last.NameSpan.Start, last.Equals) + "= " + value
+		config.replace(source.Span{Start: end, End: end}, "\n"+line)
+		return
+	}
+
+	line := name + " = " + value
+	if body.Block == nil {
+		if config.Content != "" && !strings.HasSuffix(config.Content, "\n") {
+			line = "\n" + line
+		}
+		config.replace(source.Span{Start: len(config.Content), End: len(config.Content)}, line+"\n")
+		return
+	}
+
+	block := body.Block
+	indent := config.indentOf(block.Start)
+	if strings.TrimSpace(config.Content[block.Open+1:block.Close]) == "" {
+		config.replace(source.Span{Start: block.Open + 1, End: block.Close}, "\n"+indent+"  "+line+"\n"+indent)
+		return
+	}
+
+	end := config.lineEnd(block.Open)
+	config.replace(source.Span{Start: end, End: end}, "\n"+indent+"  "+line)
+}
+
+func (config *HclConfiguration) isSingleLine(expression *Expression) bool {
+	return !strings.Contains(config.Content[expression.Span.Start:expression.Span.End], "\n")
+}
+
+func (config *HclConfiguration) insertItem(object *Expression, key string, value string) {
+	key = encodeKey(key)
+
+	if len(object.Items) == 0 {
+		config.replace(source.Span{Start: object.Span.Start, End: object.Span.End}, "{ "+key+" = "+value+" }")
+		return
+	}
+
+	last := object.Items[len(object.Items)-1]
+	if config.isSingleLine(object) {
+		config.replace(source.Span{Start: last.Value.Span.End, End: last.Value.Span.End}, ", "+key+" = "+value)
+		return
+	}
+
+	// Keep the trailing commas if the items use them
+	position := last.Value.Span.End
+	separator := ""
+	if strings.HasPrefix(strings.TrimLeft(config.Content[position:], " \t"), ",") {
+		position = strings.IndexByte(config.Content[position:], ',') + position + 1
+		separator = ","
+	}
+	line := config.indentOf(last.KeySpan.Start) + alignedName(key, last.KeySpan.Start, last.Equals) + "= " + value + separator
+	config.replace(source.Span{Start: position, End: position}, "\n"+line)
+}
+
+func (config *HclConfiguration) appendElement(tuple *Expression, value string) {
+	if len(tuple.Elements) == 0 {
+		config.replace(source.Span{Start: tuple.Span.Start, End: tuple.Span.End}, "["+value+"]")
+		return
+	}
+
+	last := tuple.Elements[len(tuple.Elements)-1]
+	if config.isSingleLine(tuple) {
+		config.replace(source.Span{Start: last.Span.End, End: last.Span.End}, ", "+value)
+		return
+	}
+
+	indent := config.indentOf(last.Span.Start)
+	if strings.HasPrefix(strings.TrimLeft(config.Content[last.Span.End:], " \t"), ",") {
+		position := strings.IndexByte(config.Content[last.Span.End:], ',') + last.Span.End + 1
+		config.replace(source.Span{Start: position, End: position}, "\n"+indent+value+",")
+		return
+	}
+	config.replace(source.Span{Start: last.Span.End, End: last.Span.End}, ",\n"+indent+value)
+}
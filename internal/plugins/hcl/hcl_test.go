@@ -0,0 +1,203 @@
+package hcl
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/testutil"
+)
+
+const MAIN_FILE_PATH = "../../../data/hcl/main.tf"
+const TFVARS_FILE_PATH = "../../../data/hcl/terraform.tfvars"
+
+func TestGetParameter(t *testing.T) {
+	cases := map[string]map[string]string{
+		MAIN_FILE_PATH: {
+			"variable.region.default":                                  "eu-west-3",
+			"variable.instance_count.default":                          "2",
+			"provider.aws.region":                                      "var.region",
+			"resource.aws_instance.web.monitoring":                     "true",
+			"resource.aws_instance.web.tags.Environment":               "production",
+			"resource.aws_instance.web.security_groups.1":              "ssh",
+			"resource.aws_instance.web.security_groups":                `["web", "ssh"]`,
+			"resource.aws_instance.web.ebs_block_device.1.volume_size": "20",
+			"resource.aws_instance.web.user_data":                      "#!/bin/bash\necho \"Hello\"",
+			"locals.zones.0":                                           "eu-west-3a",
+			"locals.settings.retries":                                  "3",
+		},
+		TFVARS_FILE_PATH: {
+			"region":          "eu-west-3",
+			"enable_backup":   "true",
+			"allowed_cidrs.0": "10.0.0.0/8",
+		},
+	}
+
+	for filePath, fileCases := range cases {
+		for key, expectedValue := range fileCases {
+			t.Run("it gets existing parameter "+key, func(t *testing.T) {
+				value, err := GetParameterFromPath(core.DotNotation, filePath, key)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if value != expectedValue {
+					t.Fatal(fmt.Sprintf("Expected %s got %s", expectedValue, value))
+				}
+			})
+		}
+	}
+
+	errorCases := []string{
+		"variable.region",
+		"variable.zone.default",
+		"resource.aws_instance.web.ebs_block_device.volume_size",
+		"resource.aws_instance.web.ebs_block_device.2.volume_size",
+		"resource.aws_instance.web.ami.id",
+		"locals.zones.2",
+		"locals.settings.timeout",
+	}
+	for _, key := range errorCases {
+		t.Run("it does not get "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, MAIN_FILE_PATH, key)
+			if err == nil {
+				t.Error("Should fail. Got " + value + " instead")
+			}
+		})
+	}
+}
+
+func TestEditParameter(t *testing.T) {
+	type EditTestElement struct {
+		filePath  string
+		key       string
+		value     string
+		valueType string
+		old       string
+		new       string
+	}
+
+	cases := []EditTestElement{
+		{MAIN_FILE_PATH, "variable.region.default", "us-east-1", "", `default     = "eu-west-3"`, `default     = "us-east-1"`},
+		{MAIN_FILE_PATH, "variable.instance_count.default", "4", "", "default = 2 // two", "default = 4 // two"},
+		{MAIN_FILE_PATH, "resource.aws_instance.web.monitoring", "FALSE", "", "monitoring    = true", "monitoring    = false"},
+		{MAIN_FILE_PATH, "provider.aws.region", `"us-east-1"`, "expression", "region = var.region", `region = "us-east-1"`},
+		{MAIN_FILE_PATH, "resource.aws_instance.web.tags.Environment", "staging", "", `Environment = "production"`, `Environment = "staging"`},
+		{MAIN_FILE_PATH, "resource.aws_instance.web.tags.Team", "ops", "", "Environment = \"production\"\n", "Environment = \"production\"\n    Team        = \"ops\"\n"},
+		{MAIN_FILE_PATH, "resource.aws_instance.web.security_groups.0", "http", "", `["web", "ssh"]`, `["http", "ssh"]`},
+		{MAIN_FILE_PATH, "resource.aws_instance.web.security_groups.2", "https", "", `["web", "ssh"]`, `["web", "ssh", "https"]`},
+		{MAIN_FILE_PATH, "resource.aws_instance.web.ebs_block_device.0.volume_size", "50", "", "volume_size = 10", "volume_size = 50"},
+		{MAIN_FILE_PATH, "variable.region.sensitive", "false", "", "default     = \"eu-west-3\"\n", "default     = \"eu-west-3\"\n  sensitive   = false\n"},
+		{MAIN_FILE_PATH, "locals.zones.2", "eu-west-3c", "", "\"eu-west-3b\",\n", "\"eu-west-3b\",\n    \"eu-west-3c\",\n"},
+		{MAIN_FILE_PATH, "locals.settings.retries", "5", "", "retries = 3", "retries = 5"},
+		{MAIN_FILE_PATH, "locals.settings.retries", "five", "string", "retries = 3", `retries = "five"`},
+		{TFVARS_FILE_PATH, "instance_count", "5", "", "instance_count = 3", "instance_count = 5"},
+		{TFVARS_FILE_PATH, "owner", "ops team", "", "\"192.168.0.0/16\"]\n", "\"192.168.0.0/16\"]\nowner          = \"ops team\"\n"},
+		{TFVARS_FILE_PATH, "allowed_cidrs", `["0.0.0.0/0"]`, "", `["10.0.0.0/8", "192.168.0.0/16"]`, `["0.0.0.0/0"]`},
+	}
+
+	for _, element := range cases {
+		t.Run("it sets "+element.key+" to "+element.value, func(t *testing.T) {
+			config, err := EditConfigFile(core.DotNotation, element.filePath, element.key, element.value, element.valueType)
+			testutil.AssertEditedOutput(t, element.filePath, config, err, element.old, element.new)
+		})
+	}
+
+	type ErrorTestElement struct {
+		key       string
+		value     string
+		valueType string
+	}
+
+	errorCases := []ErrorTestElement{
+		{"variable.instance_count.default", "two", ""},
+		{"resource.aws_instance.web.monitoring", "yes", ""},
+		{"resource.aws_instance.web.security_groups", "web", ""},
+		{"provider.aws.region", "var.", "expression"},
+		{"provider.aws.region", "us-east-1", "date"},
+		{"variable.region", "us-east-1", ""},
+		{"variable.zone.default", "us-east-1", ""},
+		{"locals.zones.5", "eu-west-3d", ""},
+		{"resource.aws_instance.web.ami.id", "ami-1", ""},
+	}
+	for _, element := range errorCases {
+		t.Run("it refuses to set "+element.key+" to "+element.value, func(t *testing.T) {
+			if _, err := EditConfigFile(core.DotNotation, MAIN_FILE_PATH, element.key, element.value, element.valueType); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestUnsetParameter(t *testing.T) {
+	cases := map[string][]string{
+		"variable.instance_count.type":                 {"  type    = number\n", ""},
+		"resource.aws_instance.web.tags.Name":          {"    Name        = \"web\"\n", ""},
+		"resource.aws_instance.web.security_groups.0":  {`["web", "ssh"]`, `["ssh"]`},
+		"resource.aws_instance.web.security_groups.1":  {`["web", "ssh"]`, `["web"]`},
+		"resource.aws_instance.web.ebs_block_device.1": {"  ebs_block_device {\n    device_name = \"/dev/sdc\"\n    volume_size = 20\n  }\n", ""},
+		"resource.aws_instance.web.user_data":          {"  user_data = <<-EOT\n    #!/bin/bash\n    echo \"Hello\"\n  EOT\n", ""},
+		"locals.zones.0":                               {"    \"eu-west-3a\",\n", ""},
+		"provider.aws":                                 {"provider \"aws\" {\n  region = var.region\n}\n", ""},
+	}
+
+	for key, replacement := range cases {
+		t.Run("it unsets "+key, func(t *testing.T) {
+			config, err := RemoveFromConfigFile(core.DotNotation, MAIN_FILE_PATH, key)
+			testutil.AssertEditedOutput(t, MAIN_FILE_PATH, config, err, replacement[0], replacement[1])
+		})
+	}
+
+	for _, key := range []string{"variable.zone", "resource.aws_instance.web.ebs_block_device", "locals.zones.2"} {
+		t.Run("it does not unset "+key, func(t *testing.T) {
+			if _, err := RemoveFromConfigFile(core.DotNotation, MAIN_FILE_PATH, key); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestMeaningFullOutput(t *testing.T) {
+	config, err := GetParsedHclFile(TFVARS_FILE_PATH)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output := OutputConfigFile(&config, core.MeaningFullOutput)
+	if strings.Contains(output, "#") || !strings.HasPrefix(output, "region") {
+		t.Fatal("Unexpected output:\n" + output)
+	}
+}
+
+func TestParseInvalidHcl(t *testing.T) {
+	cases := []string{
+		"region = ",
+		"region = \"eu-west-3",
+		"variable \"region\" {",
+		"}",
+		"tags = { Name = \"web\"",
+		"zones = [\"a\" \"b\"]",
+		"= 3",
+		"user_data = <<EOT\nfoo\n",
+		"/* unterminated",
+	}
+
+	for _, content := range cases {
+		t.Run("it rejects "+content, func(t *testing.T) {
+			if _, _, err := ParseHclContent(content); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestReadDocument(t *testing.T) {
+	testutil.AssertDocumentValues(t, HclConfigurator{}, MAIN_FILE_PATH, map[string]string{
+		"variable.region.default":                                  "eu-west-3",
+		"resource.aws_instance.web.tags.Environment":               "production",
+		"resource.aws_instance.web.ebs_block_device.1.volume_size": "20",
+		"resource.aws_instance.web.count":                          "var.instance_count",
+		"locals.zones.1":                                           "eu-west-3b",
+	})
+}
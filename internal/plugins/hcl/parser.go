@@ -0,0 +1,741 @@
+package hcl
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+type tokenType int
+
+const (
+	identToken tokenType = iota
+	numberToken
+	stringToken
+	heredocToken
+	punctToken
+	newlineToken
+	endToken
+)
+
+type token struct {
+	Type tokenType
+	Raw  string
+	Span source.Span
+}
+
+var operators = []string{"==", "!=", "<=", ">=", "&&", "||", "=>", "...", "=", ":", ",", "[", "]", "{", "}", "(", ")", ".", "?", "+", "-", "*", "/", "%", "<", ">", "!"}
+
+var binaryOperators = map[string]bool{
+	"==": true, "!=": true, "<=": true, ">=": true, "&&": true, "||": true,
+	"+": true, "-": true, "*": true, "/": true, "%": true, "<": true, ">": true,
+}
+
+type lexer struct {
+	content  string
+	pos      int
+	comments []source.Span
+}
+
+func lineError(content string, pos int, format string, args ...interface{}) error {
+	line := strings.Count(content[:pos], "\n") + 1
+	message := fmt.Sprintf(format, args...)
+
+	return errors.New(fmt.Sprintf("Invalid HCL on line %d: %s", line, message))
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c) || c == '-'
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// skipTemplate skips a "${ ... }" or "%{ ... }" sequence of a string,
+// starting after its opening brace
+func (l *lexer) skipTemplate(start int) error {
+	depth := 1
+	for l.pos < len(l.content) {
+		switch c := l.content[l.pos]; c {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				l.pos++
+				return nil
+			}
+		case '"':
+			if err := l.skipString(l.pos); err != nil {
+				return err
+			}
+			continue
+		case '\n':
+			return lineError(l.content, start, "unterminated template sequence")
+		}
+		l.pos++
+	}
+
+	return lineError(l.content, start, "unterminated template sequence")
+}
+
+// skipString skips a quoted string starting at start
+func (l *lexer) skipString(start int) error {
+	l.pos = start + 1
+	for l.pos < len(l.content) {
+		c := l.content[l.pos]
+		switch {
+		case c == '\\':
+			l.pos += 2
+		case c == '"':
+			l.pos++
+			return nil
+		case c == '\n':
+			return lineError(l.content, start, "unterminated string")
+		case strings.HasPrefix(l.content[l.pos:], "$${") || strings.HasPrefix(l.content[l.pos:], "%%{"):
+			l.pos += 3
+		case strings.HasPrefix(l.content[l.pos:], "${") || strings.HasPrefix(l.content[l.pos:], "%{"):
+			l.pos += 2
+			if err := l.skipTemplate(start); err != nil {
+				return err
+			}
+		default:
+			l.pos++
+		}
+	}
+
+	return lineError(l.content, start, "unterminated string")
+}
+
+func (l *lexer) skipHeredoc(start int) error {
+	l.pos = start + 2
+	if l.pos < len(l.content) && l.content[l.pos] == '-' {
+		l.pos++
+	}
+	markerStart := l.pos
+	for l.pos < len(l.content) && isIdentChar(l.content[l.pos]) {
+		l.pos++
+	}
+	marker := l.content[markerStart:l.pos]
+	if marker == "" || l.pos >= len(l.content) || l.content[l.pos] != '\n' {
+		return lineError(l.content, start, "invalid heredoc marker")
+	}
+
+	for l.pos < len(l.content) {
+		lineStart := l.pos + 1
+		lineEnd := strings.IndexByte(l.content[lineStart:], '\n')
+		if lineEnd == -1 {
+			lineEnd = len(l.content)
+		} else {
+			lineEnd += lineStart
+		}
+		l.pos = lineEnd
+		if strings.TrimSpace(l.content[lineStart:lineEnd]) == marker {
+			return nil
+		}
+	}
+
+	return lineError(l.content, start, "unterminated heredoc %s", marker)
+}
+
+func (l *lexer) next() (token, error) {
+	for l.pos < len(l.content) {
+		c := l.content[l.pos]
+		switch {
+		case c == ' ' || c == '\t' || c == '\r':
+			l.pos++
+			continue
+		case c == '#' || strings.HasPrefix(l.content[l.pos:], "//"):
+			start := l.pos
+			for l.pos < len(l.content) && l.content[l.pos] != '\n' {
+				l.pos++
+			}
+			l.comments = append(l.comments, source.Span{Start: start, End: l.pos})
+			continue
+		case strings.HasPrefix(l.content[l.pos:], "/*"):
+			end := strings.Index(l.content[l.pos+2:], "*/")
+			if end == -1 {
+				return token{}, lineError(l.content, l.pos, "unterminated comment")
+			}
+			l.comments = append(l.comments, source.Span{Start: l.pos, End: l.pos + end + 4})
+			l.pos += end + 4
+			continue
+		}
+		break
+	}
+
+	start := l.pos
+	if l.pos >= len(l.content) {
+		return token{endToken, "", source.Span{Start: start, End: start}}, nil
+	}
+
+	c := l.content[l.pos]
+	tokenType := punctToken
+	switch {
+	case c == '\n':
+		l.pos++
+		tokenType = newlineToken
+	case isIdentStart(c):
+		for l.pos < len(l.content) && isIdentChar(l.content[l.pos]) {
+			l.pos++
+		}
+		tokenType = identToken
+	case isDigit(c):
+		for l.pos < len(l.content) && isDigit(l.content[l.pos]) {
+			l.pos++
+		}
+		if l.pos+1 < len(l.content) && l.content[l.pos] == '.' && isDigit(l.content[l.pos+1]) {
+			l.pos++
+			for l.pos < len(l.content) && isDigit(l.content[l.pos]) {
+				l.pos++
+			}
+		}
+		if l.pos < len(l.content) && (l.content[l.pos] == 'e' || l.content[l.pos] == 'E') {
+			l.pos++
+			if l.pos < len(l.content) && (l.content[l.pos] == '+' || l.content[l.pos] == '-') {
+				l.pos++
+			}
+			for l.pos < len(l.content) && isDigit(l.content[l.pos]) {
+				l.pos++
+			}
+		}
+		tokenType = numberToken
+	case c == '"':
+		if err := l.skipString(start); err != nil {
+			return token{}, err
+		}
+		tokenType = stringToken
+	case strings.HasPrefix(l.content[l.pos:], "<<") && l.pos+2 < len(l.content) && (isIdentStart(l.content[l.pos+2]) || l.content[l.pos+2] == '-'):
+		if err := l.skipHeredoc(start); err != nil {
+			return token{}, err
+		}
+		tokenType = heredocToken
+	default:
+		for _, operator := range operators {
+			if strings.HasPrefix(l.content[l.pos:], operator) {
+				l.pos += len(operator)
+				break
+			}
+		}
+		if l.pos == start {
+			return token{}, lineError(l.content, start, "unexpected character %q", c)
+		}
+	}
+
+	return token{tokenType, l.content[start:l.pos], source.Span{Start: start, End: l.pos}}, nil
+}
+
+type parser struct {
+	content string
+	tokens  []token
+	pos     int
+	lastEnd int
+}
+
+func (p *parser) errorfAt(pos int, format string, args ...interface{}) error {
+	return lineError(p.content, pos, format, args...)
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) peekAt(offset int) token {
+	if p.pos+offset >= len(p.tokens) {
+		return p.tokens[len(p.tokens)-1]
+	}
+
+	return p.tokens[p.pos+offset]
+}
+
+func (p *parser) advance() token {
+	tok := p.tokens[p.pos]
+	if tok.Type != endToken {
+		p.pos++
+		p.lastEnd = tok.Span.End
+	}
+
+	return tok
+}
+
+func (p *parser) is(tok token, punct string) bool {
+	return tok.Type == punctToken && tok.Raw == punct
+}
+
+func (p *parser) skipNewlines() {
+	for p.peek().Type == newlineToken {
+		p.advance()
+	}
+}
+
+func (p *parser) expect(punct string) (token, error) {
+	tok := p.peek()
+	if !p.is(tok, punct) {
+		return tok, p.errorfAt(tok.Span.Start, "expected %q, got %q", punct, tok.Raw)
+	}
+
+	return p.advance(), nil
+}
+
+// expectEndOfLine checks that an attribute or a block is followed by a
+// newline, or by the closing brace of a single line block
+func (p *parser) expectEndOfLine(closing bool) error {
+	tok := p.peek()
+	switch {
+	case tok.Type == newlineToken:
+		p.advance()
+	case tok.Type == endToken:
+	case closing && p.is(tok, "}"):
+	default:
+		return p.errorfAt(tok.Span.Start, "expected a newline, got %q", tok.Raw)
+	}
+
+	return nil
+}
+
+func (p *parser) parseBody(block *Block) (*Body, error) {
+	body := &Body{Block: block}
+
+	for {
+		tok := p.peek()
+		switch {
+		case tok.Type == newlineToken:
+			p.advance()
+		case tok.Type == endToken:
+			if block != nil {
+				return nil, p.errorfAt(block.Start, "unclosed block %s", block.Type)
+			}
+			return body, nil
+		case p.is(tok, "}"):
+			if block == nil {
+				return nil, p.errorfAt(tok.Span.Start, "unexpected \"}\"")
+			}
+			block.Close = p.advance().Span.Start
+			return body, nil
+		case tok.Type == identToken && p.is(p.peekAt(1), "="):
+			attribute, err := p.parseAttribute(block != nil)
+			if err != nil {
+				return nil, err
+			}
+			body.Attributes = append(body.Attributes, attribute)
+		case tok.Type == identToken:
+			child, err := p.parseBlock(block != nil)
+			if err != nil {
+				return nil, err
+			}
+			body.Blocks = append(body.Blocks, child)
+		default:
+			return nil, p.errorfAt(tok.Span.Start, "expected an attribute or a block, got %q", tok.Raw)
+		}
+	}
+}
+
+func (p *parser) parseAttribute(closing bool) (*Attribute, error) {
+	name := p.advance()
+	equals := p.advance()
+
+	value, err := p.parseExpression(false)
+	if err != nil {
+		return nil, err
+	}
+
+	attribute := &Attribute{name.Raw, name.Span, equals.Span.Start, value}
+
+	return attribute, p.expectEndOfLine(closing)
+}
+
+func (p *parser) parseBlock(closing bool) (*Block, error) {
+	blockType := p.advance()
+	block := &Block{Type: blockType.Raw, Start: blockType.Span.Start}
+
+	for {
+		tok := p.peek()
+		if tok.Type == identToken {
+			block.Labels = append(block.Labels, p.advance().Raw)
+			continue
+		}
+		if tok.Type == stringToken {
+			block.Labels = append(block.Labels, decodeString(p.advance().Raw))
+			continue
+		}
+		break
+	}
+
+	open, err := p.expect("{")
+	if err != nil {
+		return nil, err
+	}
+	block.Open = open.Span.Start
+
+	body, err := p.parseBody(block)
+	if err != nil {
+		return nil, err
+	}
+	block.Body = body
+
+	return block, p.expectEndOfLine(closing)
+}
+
+// parseExpression parses an expression. Newlines end it, unless it is
+// between brackets or parentheses (multiline).
+func (p *parser) parseExpression(multiline bool) (*Expression, error) {
+	start := p.peek().Span.Start
+
+	expression, err := p.parseUnary(multiline)
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		if multiline {
+			p.skipNewlines()
+		}
+
+		tok := p.peek()
+		switch {
+		case tok.Type == punctToken && binaryOperators[tok.Raw]:
+			p.advance()
+			if multiline {
+				p.skipNewlines()
+			}
+			if _, err := p.parseUnary(multiline); err != nil {
+				return nil, err
+			}
+		case p.is(tok, "?"):
+			p.advance()
+			if _, err := p.parseExpression(multiline); err != nil {
+				return nil, err
+			}
+			if multiline {
+				p.skipNewlines()
+			}
+			if _, err := p.expect(":"); err != nil {
+				return nil, err
+			}
+			if _, err := p.parseExpression(multiline); err != nil {
+				return nil, err
+			}
+		default:
+			return expression, nil
+		}
+
+		expression = &Expression{Kind: OtherKind, Span: source.Span{Start: start, End: p.lastEnd}}
+	}
+}
+
+func (p *parser) parseUnary(multiline bool) (*Expression, error) {
+	tok := p.peek()
+	if !p.is(tok, "-") && !p.is(tok, "!") {
+		return p.parsePostfix()
+	}
+
+	p.advance()
+	operand, err := p.parseUnary(multiline)
+	if err != nil {
+		return nil, err
+	}
+
+	kind := OtherKind
+	if tok.Raw == "-" && operand.Kind == NumberKind {
+		kind = NumberKind
+	}
+
+	return &Expression{Kind: kind, Span: source.Span{Start: tok.Span.Start, End: p.lastEnd}}, nil
+}
+
+func (p *parser) parsePostfix() (*Expression, error) {
+	expression, err := p.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		tok := p.peek()
+		switch {
+		case p.is(tok, "."):
+			p.advance()
+			attribute := p.advance()
+			if attribute.Type != identToken && attribute.Type != numberToken && !p.is(attribute, "*") {
+				return nil, p.errorfAt(attribute.Span.Start, "expected an attribute name after \".\", got %q", attribute.Raw)
+			}
+		case p.is(tok, "["):
+			p.advance()
+			p.skipNewlines()
+			if p.is(p.peek(), "*") {
+				p.advance()
+			} else if _, err := p.parseExpression(true); err != nil {
+				return nil, err
+			}
+			p.skipNewlines()
+			if _, err := p.expect("]"); err != nil {
+				return nil, err
+			}
+		case p.is(tok, "(") && expression.Kind == OtherKind:
+			if err := p.parseArguments(); err != nil {
+				return nil, err
+			}
+		default:
+			return expression, nil
+		}
+
+		expression = &Expression{Kind: OtherKind, Span: source.Span{Start: expression.Span.Start, End: p.lastEnd}}
+	}
+}
+
+func (p *parser) parseArguments() error {
+	p.advance()
+	for {
+		p.skipNewlines()
+		if p.is(p.peek(), ")") {
+			p.advance()
+			return nil
+		}
+
+		if _, err := p.parseExpression(true); err != nil {
+			return err
+		}
+		p.skipNewlines()
+
+		tok := p.peek()
+		switch {
+		case p.is(tok, ","):
+			p.advance()
+		case p.is(tok, "..."):
+			p.advance()
+		case p.is(tok, ")"):
+		default:
+			return p.errorfAt(tok.Span.Start, "expected \",\" or \")\", got %q", tok.Raw)
+		}
+	}
+}
+
+// skipBalanced skips a for expression, up to the closing bracket matching
+// the opening one
+func (p *parser) skipBalanced(open token) error {
+	depth := 0
+	for {
+		tok := p.advance()
+		switch {
+		case tok.Type == endToken:
+			return p.errorfAt(open.Span.Start, "unclosed %q", open.Raw)
+		case p.is(tok, "[") || p.is(tok, "{") || p.is(tok, "("):
+			depth++
+		case p.is(tok, "]") || p.is(tok, "}") || p.is(tok, ")"):
+			depth--
+			if depth == 0 {
+				return nil
+			}
+		}
+	}
+}
+
+func (p *parser) parsePrimary() (*Expression, error) {
+	tok := p.peek()
+	expression := &Expression{Span: source.Span{Start: tok.Span.Start, End: tok.Span.End}}
+
+	switch {
+	case tok.Type == numberToken:
+		expression.Kind = NumberKind
+	case tok.Type == stringToken:
+		expression.Kind = StringKind
+	case tok.Type == heredocToken:
+		expression.Kind = HeredocKind
+	case tok.Type == identToken && (tok.Raw == "true" || tok.Raw == "false"):
+		expression.Kind = BoolKind
+	case tok.Type == identToken && tok.Raw == "null":
+		expression.Kind = NullKind
+	case tok.Type == identToken:
+		expression.Kind = OtherKind
+	case p.is(tok, "[") && p.peekAt(1).Type == identToken && p.peekAt(1).Raw == "for",
+		p.is(tok, "{") && p.peekAt(1).Type == identToken && p.peekAt(1).Raw == "for":
+		if err := p.skipBalanced(tok); err != nil {
+			return nil, err
+		}
+		return &Expression{Kind: OtherKind, Span: source.Span{Start: tok.Span.Start, End: p.lastEnd}}, nil
+	case p.is(tok, "["):
+		return p.parseTuple()
+	case p.is(tok, "{"):
+		return p.parseObject()
+	case p.is(tok, "("):
+		p.advance()
+		p.skipNewlines()
+		if _, err := p.parseExpression(true); err != nil {
+			return nil, err
+		}
+		p.skipNewlines()
+		if _, err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		return &Expression{Kind: OtherKind, Span: source.Span{Start: tok.Span.Start, End: p.lastEnd}}, nil
+	case tok.Type == newlineToken || tok.Type == endToken:
+		return nil, p.errorfAt(tok.Span.Start, "missing value")
+	default:
+		return nil, p.errorfAt(tok.Span.Start, "unexpected %q", tok.Raw)
+	}
+
+	p.advance()
+
+	return expression, nil
+}
+
+func (p *parser) parseTuple() (*Expression, error) {
+	open := p.advance()
+	tuple := &Expression{Kind: TupleKind}
+
+	for {
+		p.skipNewlines()
+		if p.is(p.peek(), "]") {
+			break
+		}
+
+		element, err := p.parseExpression(true)
+		if err != nil {
+			return nil, err
+		}
+		tuple.Elements = append(tuple.Elements, element)
+		p.skipNewlines()
+
+		tok := p.peek()
+		if p.is(tok, ",") {
+			p.advance()
+			continue
+		}
+		if !p.is(tok, "]") {
+			return nil, p.errorfAt(tok.Span.Start, "expected \",\" or \"]\", got %q", tok.Raw)
+		}
+	}
+
+	p.advance()
+	tuple.Span = source.Span{Start: open.Span.Start, End: p.lastEnd}
+
+	return tuple, nil
+}
+
+func (p *parser) parseObject() (*Expression, error) {
+	open := p.advance()
+	object := &Expression{Kind: ObjectKind}
+
+	for {
+		p.skipNewlines()
+		if p.is(p.peek(), "}") {
+			break
+		}
+
+		key := p.peek()
+		item := &ObjectItem{KeySpan: key.Span}
+		switch {
+		case key.Type == identToken || key.Type == numberToken:
+			item.Key = p.advance().Raw
+		case key.Type == stringToken:
+			item.Key = decodeString(p.advance().Raw)
+		case p.is(key, "("):
+			keyExpression, err := p.parsePrimary()
+			if err != nil {
+				return nil, err
+			}
+			item.KeySpan = keyExpression.Span
+			item.Key = p.content[keyExpression.Span.Start:keyExpression.Span.End]
+		default:
+			return nil, p.errorfAt(key.Span.Start, "expected an object key, got %q", key.Raw)
+		}
+
+		separator := p.peek()
+		if !p.is(separator, "=") && !p.is(separator, ":") {
+			return nil, p.errorfAt(separator.Span.Start, "expected \"=\" after %s, got %q", item.Key, separator.Raw)
+		}
+		item.Equals = p.advance().Span.Start
+
+		value, err := p.parseExpression(false)
+		if err != nil {
+			return nil, err
+		}
+		item.Value = value
+		object.Items = append(object.Items, item)
+
+		tok := p.peek()
+		switch {
+		case p.is(tok, ","):
+			p.advance()
+		case tok.Type == newlineToken, p.is(tok, "}"):
+		default:
+			return nil, p.errorfAt(tok.Span.Start, "expected a newline, \",\" or \"}\", got %q", tok.Raw)
+		}
+	}
+
+	p.advance()
+	object.Span = source.Span{Start: open.Span.Start, End: p.lastEnd}
+
+	return object, nil
+}
+
+func tokenize(content string) ([]token, []source.Span, error) {
+	l := &lexer{content: content}
+	tokens := []token{}
+	for {
+		tok, err := l.next()
+		if err != nil {
+			return nil, nil, err
+		}
+		tokens = append(tokens, tok)
+		if tok.Type == endToken {
+			return tokens, l.comments, nil
+		}
+	}
+}
+
+func ParseHclContent(content string) (*Body, []source.Span, error) {
+	tokens, comments, err := tokenize(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	p := &parser{content: content, tokens: tokens}
+	root, err := p.parseBody(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return root, comments, nil
+}
+
+// ParseValue parses a single expression, such as a value given on the
+// command line
+func ParseValue(raw string) (*Expression, error) {
+	tokens, _, err := tokenize(raw)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{content: raw, tokens: tokens}
+	expression, err := p.parseExpression(false)
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().Type != endToken {
+		return nil, p.errorfAt(p.peek().Span.Start, "unexpected %q after the value", p.peek().Raw)
+	}
+
+	return expression, nil
+}
+
+func GetParsedHclFile(filePath string) (HclConfiguration, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return HclConfiguration{}, err
+	}
+
+	root, comments, err := ParseHclContent(content)
+	if err != nil {
+		return HclConfiguration{}, err
+	}
+
+	return HclConfiguration{content, root, comments, filePath}, nil
+}
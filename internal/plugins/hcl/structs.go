@@ -0,0 +1,129 @@
+package hcl
+
+import (
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+type ExpressionKind int
+
+const (
+	StringKind ExpressionKind = iota
+	HeredocKind
+	NumberKind
+	BoolKind
+	NullKind
+	TupleKind
+	ObjectKind
+	// OtherKind is any other expression: references, function calls,
+	// operations...
+	OtherKind
+)
+
+// Expression is the value of an attribute. Only the elements of tuples and
+// the items of objects are parsed, other expressions are kept as written.
+type Expression struct {
+	Kind     ExpressionKind
+	Span     source.Span
+	Elements []*Expression
+	Items    []*ObjectItem
+}
+
+type ObjectItem struct {
+	Key     string
+	KeySpan source.Span
+	// Equals is the position of the "=" or ":" between the key and the value
+	Equals int
+	Value  *Expression
+}
+
+type Attribute struct {
+	Name     string
+	NameSpan source.Span
+	Equals   int
+	Value    *Expression
+}
+
+// Block is a block such as variable "region" { ... }. Open and Close are the
+// positions of its braces.
+type Block struct {
+	Type   string
+	Labels []string
+	Body   *Body
+	Start  int
+	Open   int
+	Close  int
+}
+
+// Body is the content of the file or of a block
+type Body struct {
+	Attributes []*Attribute
+	Blocks     []*Block
+	Block      *Block
+}
+
+type HclConfiguration struct {
+	Content  string
+	Root     *Body
+	Comments []source.Span
+	FilePath string
+}
+
+func (config *HclConfiguration) OutputFile(outputType core.OutputType) (string, error) {
+	return OutputConfigFile(config, outputType), nil
+}
+
+func (config *HclConfiguration) WriteToFile(filepath string, outputType core.OutputType) error {
+	output, err := config.OutputFile(outputType)
+	if err != nil {
+		return err
+	}
+
+	return io.WriteFileContents(filepath, output)
+}
+
+type HclConfigurator struct{}
+
+func (configurator HclConfigurator) GetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (string, error) {
+	return GetParameterFromPath(notationStyle, filePath, key)
+}
+
+func (configurator HclConfigurator) SetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return configurator.SetTypedParameter(notationStyle, filePath, key, value, "")
+}
+
+func (configurator HclConfigurator) SetTypedParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+	valueType string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, valueType))
+}
+
+func (configurator HclConfigurator) UnsetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (core.Configuration, error) {
+	return toConfiguration(RemoveFromConfigFile(notationStyle, filePath, key))
+}
+
+func toConfiguration(config *HclConfiguration, err error) (core.Configuration, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
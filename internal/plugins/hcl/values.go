@@ -0,0 +1,190 @@
+package hcl
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	numberRegexp     = regexp.MustCompile(`^-?[0-9]+(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+	identifierRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_\-]*$`)
+)
+
+var valueTypes = map[string]ExpressionKind{
+	"string":     StringKind,
+	"number":     NumberKind,
+	"bool":       BoolKind,
+	"boolean":    BoolKind,
+	"list":       TupleKind,
+	"tuple":      TupleKind,
+	"map":        ObjectKind,
+	"object":     ObjectKind,
+	"expression": OtherKind,
+}
+
+func getValueKind(valueType string) (ExpressionKind, error) {
+	kind, ok := valueTypes[strings.ToLower(valueType)]
+	if !ok {
+		return 0, errors.New(fmt.Sprintf("Unknown HCL type: %s", valueType))
+	}
+
+	return kind, nil
+}
+
+func (kind ExpressionKind) String() string {
+	switch kind {
+	case StringKind, HeredocKind:
+		return "string"
+	case NumberKind:
+		return "number"
+	case BoolKind:
+		return "bool"
+	case NullKind:
+		return "null"
+	case TupleKind:
+		return "list"
+	case ObjectKind:
+		return "map"
+	default:
+		return "expression"
+	}
+}
+
+// decodeString returns the content of a quoted string. Template sequences
+// (${var.name}) are kept as written.
+func decodeString(raw string) string {
+	content := raw[1 : len(raw)-1]
+
+	var builder strings.Builder
+	for i := 0; i < len(content); i++ {
+		if content[i] != '\\' || i+1 == len(content) {
+			builder.WriteByte(content[i])
+			continue
+		}
+
+		i++
+		switch content[i] {
+		case 'n':
+			builder.WriteByte('\n')
+		case 't':
+			builder.WriteByte('\t')
+		case 'r':
+			builder.WriteByte('\r')
+		case 'u':
+			if i+4 < len(content) {
+				if code, err := strconv.ParseUint(content[i+1:i+5], 16, 32); err == nil {
+					builder.WriteRune(rune(code))
+					i += 4
+					continue
+				}
+			}
+			builder.WriteString(`\u`)
+		default:
+			builder.WriteByte(content[i])
+		}
+	}
+
+	return builder.String()
+}
+
+// decodeHeredoc returns the lines between the markers of a heredoc, without
+// their common indentation for an indented heredoc (<<-EOT)
+func decodeHeredoc(raw string) string {
+	lines := strings.Split(raw, "\n")
+	lines = lines[1 : len(lines)-1]
+
+	if strings.HasPrefix(raw, "<<-") {
+		indent := -1
+		for _, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			lineIndent := len(line) - len(strings.TrimLeft(line, " \t"))
+			if indent == -1 || lineIndent < indent {
+				indent = lineIndent
+			}
+		}
+		for i, line := range lines {
+			if len(line) >= indent && indent > 0 {
+				lines[i] = line[indent:]
+			}
+		}
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func EncodeString(content string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\t", `\t`, "\r", `\r`)
+
+	return `"` + replacer.Replace(content) + `"`
+}
+
+// encodeKey returns an object key, quoted if it is not an identifier
+func encodeKey(key string) string {
+	if identifierRegexp.MatchString(key) {
+		return key
+	}
+
+	return EncodeString(key)
+}
+
+// toString returns the value as it should be displayed: strings are decoded,
+// any other value is returned as written in the file
+func toString(content string, expression *Expression) string {
+	raw := content[expression.Span.Start:expression.Span.End]
+
+	switch expression.Kind {
+	case StringKind:
+		return decodeString(raw)
+	case HeredocKind:
+		return decodeHeredoc(raw)
+	default:
+		return raw
+	}
+}
+
+func encodeValue(kind ExpressionKind, value string) (string, error) {
+	invalid := func() (string, error) {
+		return "", errors.New(fmt.Sprintf("%q is not a valid HCL %s, use --type to change the type of the value", value, kind))
+	}
+
+	switch kind {
+	case StringKind, HeredocKind:
+		return EncodeString(value), nil
+	case NumberKind:
+		if !numberRegexp.MatchString(value) {
+			return invalid()
+		}
+	case BoolKind:
+		lowered := strings.ToLower(value)
+		if lowered != "true" && lowered != "false" {
+			return invalid()
+		}
+		return lowered, nil
+	case NullKind:
+		return inferValue(value), nil
+	default:
+		parsed, err := ParseValue(value)
+		if err != nil || (kind != OtherKind && parsed.Kind != kind) {
+			return invalid()
+		}
+	}
+
+	return value, nil
+}
+
+// inferValue encodes a value whose type is not known, e.g. for a new
+// attribute: anything which is not a literal value, a list or a map is
+// considered as a string
+func inferValue(value string) string {
+	parsed, err := ParseValue(value)
+	if err != nil || parsed.Kind == OtherKind {
+		return EncodeString(value)
+	}
+
+	return value
+}
@@ -0,0 +1,86 @@
+package ini
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+func unquote(value string) string {
+	if len(value) >= 2 && strings.HasPrefix(value, `"`) && strings.HasSuffix(value, `"`) {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
+
+// quote quotes the values which would not be read back as written
+func quote(value string) string {
+	if strings.ContainsAny(value, `;"=`) || strings.TrimSpace(value) != value {
+		return `"` + value + `"`
+	}
+
+	return value
+}
+
+// WriteTree writes a tree as an INI file: the scalars of the root are global
+// keys, its maps are sections. Nested maps are flattened into dotted keys.
+func (configurator IniConfigurator) WriteTree(tree *core.Node, losses *core.Losses) (string, error) {
+	if !tree.IsMap() {
+		return "", errors.New("Only maps can be written as INI files")
+	}
+
+	var builder strings.Builder
+	sections := []string{}
+	for _, key := range tree.Keys {
+		if tree.Get(key).IsMap() {
+			sections = append(sections, key)
+			continue
+		}
+		writeEntry(&builder, key, tree.Get(key), losses)
+	}
+
+	for _, name := range sections {
+		if builder.Len() > 0 {
+			builder.WriteString("\n")
+		}
+		builder.WriteString("[" + name + "]\n")
+		writeMap(&builder, "", tree.Get(name), losses)
+	}
+
+	return builder.String(), nil
+}
+
+func writeMap(builder *strings.Builder, prefix string, node *core.Node, losses *core.Losses) {
+	for _, key := range node.Keys {
+		child := node.Get(key)
+		if child.IsMap() {
+			losses.Add("nested maps are written as dotted keys")
+			writeMap(builder, prefix+key+".", child, losses)
+			continue
+		}
+		writeEntry(builder, prefix+key, child, losses)
+	}
+}
+
+// writeEntry writes a scalar, or a list as a repeated key
+func writeEntry(builder *strings.Builder, key string, node *core.Node, losses *core.Losses) {
+	items := []*core.Node{node}
+	if node.Kind == core.ListNode {
+		items = node.Items
+	}
+
+	for _, item := range items {
+		if item.Kind != core.ScalarNode {
+			losses.Add("%s contains nested values, which are not written", key)
+			continue
+		}
+		if strings.ContainsAny(item.Value, "\r\n") {
+			losses.Add("%s is a multiline value, which is not written", key)
+			continue
+		}
+		losses.TypedValue(item)
+		builder.WriteString(key + " = " + quote(item.Value) + "\n")
+	}
+}
@@ -0,0 +1,84 @@
+package ini
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/json"
+)
+
+func TestReadDocumentLosses(t *testing.T) {
+	losses := &core.Losses{}
+	tree, err := IniConfigurator{}.ReadDocument(INI_FILE_PATH, losses)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedKeys := []string{"orphan_key", "user", "core", "alias", "push"}
+	if !reflect.DeepEqual(tree.Keys, expectedKeys) {
+		t.Errorf("Expected keys %v, got %v", expectedKeys, tree.Keys)
+	}
+	if value := tree.Get("core").Get("editor"); value == nil || value.Value != "vim" {
+		t.Errorf("Expected core.editor to be vim, got %v", value)
+	}
+	if !reflect.DeepEqual(losses.Messages, []string{"comments are not converted"}) {
+		t.Errorf("Unexpected losses %v", losses.Messages)
+	}
+
+	output, err := json.JsonFormat{}.WriteTree(tree.Get("push"), losses)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "{\n  \"default\": \"simple\"\n}\n"
+	if output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
+
+func TestWriteTree(t *testing.T) {
+	tree := core.NewMapNode()
+	tree.Set("name", core.NewScalarNode(core.StringScalar, "edicon"))
+	tree.Add("include", core.NewScalarNode(core.UntypedScalar, "a.ini"))
+	tree.Add("include", core.NewScalarNode(core.UntypedScalar, "b.ini"))
+
+	server := core.NewMapNode()
+	server.Set("port", core.NewScalarNode(core.IntegerScalar, "8080"))
+	server.Set("filter", core.NewScalarNode(core.StringScalar, "a=b; c"))
+	tls := core.NewMapNode()
+	tls.Set("enabled", core.NewScalarNode(core.BoolScalar, "true"))
+	server.Set("tls", tls)
+	tree.Set("server", server)
+
+	losses := &core.Losses{}
+	output, err := IniConfigurator{}.WriteTree(tree, losses)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `name = edicon
+include = a.ini
+include = b.ini
+
+[server]
+port = 8080
+filter = "a=b; c"
+tls.enabled = true
+`
+	if output != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+
+	expectedLosses := []string{
+		"integer values are written as strings",
+		"nested maps are written as dotted keys",
+		"boolean values are written as strings",
+	}
+	if !reflect.DeepEqual(losses.Messages, expectedLosses) {
+		t.Errorf("Expected losses %v, got %v", expectedLosses, losses.Messages)
+	}
+
+	if _, err := (IniConfigurator{}).WriteTree(core.NewListNode(), losses); err == nil {
+		t.Error("Expected an error when writing a list")
+	}
+}
@@ -0,0 +1,130 @@
+package ini
+
+import (
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+// DocumentOptions are the rules of a format parsed with the INI line model
+// (systemd units, desktop entries...) for its document tree
+type DocumentOptions struct {
+	// Value returns the value of a key value line, its raw value if nil
+	Value func(keyValue *KeyValue) string
+	// Overrides tells if the last assignment of a key overrides the previous
+	// ones, the assignments of a key being a list if nil
+	Overrides func(key string) bool
+}
+
+var iniDocumentOptions = DocumentOptions{
+	Value: func(keyValue *KeyValue) string {
+		return unquote(keyValue.Value)
+	},
+}
+
+// MatchesKeyPatterns marks the section.key paths of the tree as the keys of
+// the file
+func (configurator IniConfigurator) MatchesKeyPatterns() {}
+
+// ReadDocument returns the document tree of the configuration: global keys
+// are at the root, sections are section nodes and repeated keys (or PHP
+// "key[]" arrays) are lists
+func (configurator IniConfigurator) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	config, err := GetParsedIniFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return BuildDocument(config.GlobalSection, config.Sections, iniDocumentOptions, losses), nil
+}
+
+// BuildDocument returns the document tree of a file parsed with the INI line
+// model, the keys of the sections repeated being merged
+func BuildDocument(globalSection *GlobalSection, sections []*Section, options DocumentOptions, losses *core.Losses) *core.Node {
+	tree := core.NewMapNode()
+	tree.Path = []string{}
+	comments := options.readLines(tree, globalSection.Lines, []string{}, losses)
+
+	for _, section := range sections {
+		node := tree.Get(section.Name)
+		if node != nil && !node.IsMap() {
+			losses.Add("%s is both a key and a section, only the section is kept", section.Name)
+			node = nil
+		}
+		if node == nil {
+			node = core.NewMapNode()
+			node.Kind = core.SectionNode
+			node.Path = []string{section.Name}
+			tree.Set(section.Name, node)
+		}
+
+		header := section.Lines[0]
+		if node.Span.Start == 0 {
+			node.Span.Start = header.LineNumber
+		}
+		node.Comments = append(node.Comments, comments...)
+		node.Span.End = lastLine(section.Lines)
+		comments = options.readLines(node, section.Lines[1:], node.Path, losses)
+	}
+
+	return tree
+}
+
+// readLines adds the keys of a section to its node, attaching to each key
+// the comments right above it. The comments following the last key are
+// returned, to be attached to the next section.
+func (options DocumentOptions) readLines(node *core.Node, lines []*Line, path []string, losses *core.Losses) []string {
+	comments := []string{}
+
+	for _, line := range lines {
+		trimmedLine := strings.TrimSpace(line.StringContent)
+
+		switch {
+		case line.ContentType == KeyValueType:
+			key := strings.TrimSuffix(line.KeyValue.Key, "[]")
+			existing := node.Get(key)
+			if existing != nil && existing.IsMap() {
+				losses.Add("%s is both a key and a section, only the section is kept", key)
+				continue
+			}
+
+			rawValue := line.KeyValue.Value
+			if options.Value != nil {
+				rawValue = options.Value(line.KeyValue)
+			}
+			value := core.NewScalarNode(core.UntypedScalar, rawValue)
+			value.Path = append(append([]string{}, path...), key)
+			value.Span = core.Span{Start: line.LineNumber, End: line.LineNumber + strings.Count(line.StringContent, "\n")}
+			value.Comments = comments
+			comments = []string{}
+
+			if options.Overrides != nil && options.Overrides(key) {
+				if existing != nil {
+					losses.Add("%s is set several times, only the last value is kept", key)
+				}
+				node.Set(key, value)
+				continue
+			}
+			node.Add(key, value)
+		case trimmedLine == "":
+			comments = []string{}
+		default:
+			losses.Add("comments are not converted")
+			comments = append(comments, strings.TrimSpace(strings.TrimLeft(trimmedLine, ";#")))
+		}
+	}
+
+	return comments
+}
+
+// lastLine returns the number of the last non-empty line
+func lastLine(lines []*Line) int {
+	last := lines[0].LineNumber
+	for _, line := range lines {
+		if strings.TrimSpace(line.StringContent) != "" {
+			last = line.LineNumber + strings.Count(line.StringContent, "\n")
+		}
+	}
+
+	return last
+}
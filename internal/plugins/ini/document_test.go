@@ -0,0 +1,81 @@
+package ini
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+func TestReadDocument(t *testing.T) {
+	document, err := IniConfigurator{}.ReadDocument(INI_FILE_PATH, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	section := document.Get("core")
+	if section.Kind != core.SectionNode {
+		t.Errorf("Expected core to be a section, got %v", section.Kind)
+	}
+	if section.Span != (core.Span{Start: 7, End: 12}) {
+		t.Errorf("Expected core to span lines 7 to 12, got %v", section.Span)
+	}
+
+	editor := section.Get("editor")
+	if !reflect.DeepEqual(editor.Path, []string{"core", "editor"}) {
+		t.Errorf("Expected the path [core editor], got %v", editor.Path)
+	}
+	if editor.Span != (core.Span{Start: 9, End: 9}) {
+		t.Errorf("Expected editor to be on line 9, got %v", editor.Span)
+	}
+	if !reflect.DeepEqual(editor.Comments, []string{"the best editor ever"}) {
+		t.Errorf("Expected the comment of editor, got %v", editor.Comments)
+	}
+
+	keys := []string{}
+	document.Walk(func(node *core.Node) error {
+		if node.Kind == core.ScalarNode {
+			keys = append(keys, core.ComposeKey(core.DotNotation, node.Path))
+		}
+		return nil
+	})
+	expectedKeys := []string{
+		"orphan_key", "user.name", "user.email",
+		"core.editor", "core.autocrlf", "core.fileMode", "core.ignoreCase",
+		"alias.co", "alias.br", "alias.ci", "alias.st", "push.default",
+	}
+	if !reflect.DeepEqual(keys, expectedKeys) {
+		t.Errorf("Expected the keys %v, got %v", expectedKeys, keys)
+	}
+}
+
+func TestFindAll(t *testing.T) {
+	document, err := IniConfigurator{}.ReadDocument(INI_FILE_PATH, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataProvider := map[string][]string{
+		"alias.*":          {"alias.co", "alias.br", "alias.ci", "alias.st"},
+		"*.{name,editor}":  {"user.name", "core.editor"},
+		"**.re:[a-z]+Case": {"core.ignoreCase"},
+		"*.missing":        {},
+	}
+
+	for key, expected := range dataProvider {
+		t.Run("it finds "+key, func(t *testing.T) {
+			pattern, err := core.ParseKeyPattern(core.DotNotation, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			actual := []string{}
+			for _, match := range pattern.FindAll(document) {
+				actual = append(actual, core.ComposeKey(core.DotNotation, match.Path))
+			}
+			if !reflect.DeepEqual(actual, expected) {
+				t.Errorf("Expected %v, got %v", expected, actual)
+			}
+		})
+	}
+}
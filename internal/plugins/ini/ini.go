@@ -3,6 +3,7 @@ package ini
 import (
 	"errors"
 	"fmt"
+	"strings"
 
 	"github.com/einenlum/edicon/internal/core"
 )
@@ -52,6 +53,12 @@ func OutputConfigFile(iniFile *IniConfiguration, outputType OutputType) string {
 		}
 	}
 
+	if outputType == FullOutput {
+		// Lines were split on newlines: the last one is what follows the last
+		// newline of the file
+		output = strings.TrimSuffix(output, "\n")
+	}
+
 	return output
 }
 
@@ -61,7 +68,7 @@ func GetParsedIniFile(filePath string) (IniConfiguration, error) {
 		return IniConfiguration{}, err
 	}
 
-	globalSection, sections := getSections(parsedLines)
+	globalSection, sections := GetSections(parsedLines)
 	return IniConfiguration{globalSection, sections, filePath}, nil
 }
 
@@ -94,34 +101,43 @@ func getKeyLineBySectionName(sections []*Section, sectionName string, key string
 	return getKeyLine(section.Lines, key)
 }
 
+// getKeyLineFromPath returns the line of a key, resolving the dots of the
+// path between the section name and the key
+func getKeyLineFromPath(iniFile *IniConfiguration, notationStyle core.NotationStyle, key string) (*Line, error) {
+	getLine := func(candidate core.SectionKey) *Line {
+		if candidate.Global {
+			return getKeyLine(iniFile.GlobalSection.Lines, candidate.Key)
+		}
+
+		return getKeyLineBySectionName(iniFile.Sections, candidate.Section, candidate.Key)
+	}
+
+	sectionKey, err := core.ResolveSectionKey(notationStyle, key, func(candidate core.SectionKey) bool {
+		return getLine(candidate) != nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return getLine(sectionKey), nil
+}
+
 func EditConfigFile(
 	notationStyle core.NotationStyle,
 	filePath string,
 	key string,
 	value string,
 ) (*IniConfiguration, error) {
-	decomposedKey := core.DecomposeKey(notationStyle, key)
-
 	iniFile, err := GetParsedIniFile(filePath)
 	if err != nil {
 		return &IniConfiguration{}, err
 	}
 
-	if len(decomposedKey) == 1 {
-		keyLine := getKeyLine(iniFile.GlobalSection.Lines, decomposedKey[0])
-		if keyLine == nil {
-			return &IniConfiguration{}, errors.New("Key not found")
-		}
-
-		keyLine.SetValue(value)
-	} else {
-		keyLine := getKeyLineBySectionName(iniFile.Sections, decomposedKey[0], decomposedKey[1])
-		if keyLine == nil {
-			return &IniConfiguration{}, errors.New("Key not found")
-		}
-
-		keyLine.SetValue(value)
+	keyLine, err := getKeyLineFromPath(&iniFile, notationStyle, key)
+	if err != nil {
+		return &IniConfiguration{}, err
 	}
+	keyLine.SetValue(value)
 
 	return &iniFile, nil
 }
@@ -132,20 +148,10 @@ func GetParameterFromPath(notationStyle core.NotationStyle, filePath string, key
 		return "", err
 	}
 
-	decomposedKey := core.DecomposeKey(notationStyle, key)
-	if len(decomposedKey) == 1 {
-		keyLine := getKeyLine(iniFile.GlobalSection.Lines, decomposedKey[0])
-		if keyLine == nil {
-			return "", errors.New("Key not found")
-		}
-
-		return keyLine.KeyValue.Value, nil
-	} else {
-		keyLine := getKeyLineBySectionName(iniFile.Sections, decomposedKey[0], decomposedKey[1])
-		if keyLine == nil {
-			return "", errors.New("Key not found")
-		}
-
-		return keyLine.KeyValue.Value, nil
+	keyLine, err := getKeyLineFromPath(&iniFile, notationStyle, key)
+	if err != nil {
+		return "", err
 	}
+
+	return keyLine.KeyValue.Value, nil
 }
@@ -18,6 +18,7 @@ const (
 	INI_FILE_PATH                 = "../../../data/ini/ini.ini"
 	PHP_KEY_VALUES_ONLY_FILE_PATH = "../../../data/ini/php_key_values_only.ini"
 	INI_KEY_VALUES_ONLY_FILE_PATH = "../../../data/ini/ini_key_values_only.ini"
+	DOTTED_FILE_PATH              = "../../../data/ini/dotted.ini"
 
 	// A fake section name to test the global section more easily
 	GLOBAL_SECTION_NAME = "test_global"
@@ -329,6 +330,71 @@ func TestGetParameter(t *testing.T) {
 	}
 }
 
+func TestGetDottedParameter(t *testing.T) {
+	validDotCases := map[string]string{
+		"Session.session.save_path": "/var/lib/php/sessions",
+		"Session.session.name":      "PHPSESSID",
+		"a.d.e":                     "only in a",
+		`"a.b".c`:                   "from a.b",
+		`a."b.c"`:                   "from a",
+		`"log".level`:               "debug",
+		`log."level"`:               "debug",
+		`"log.level"`:               "info",
+		`log\.level`:                "info",
+		`Session.session\.name`:     "PHPSESSID",
+	}
+	for key, expectedValue := range validDotCases {
+		t.Run("it gets existing parameter "+key, func(t *testing.T) {
+			testGetExistingParameter(t, core.DotNotation, DOTTED_FILE_PATH, key, expectedValue)
+		})
+	}
+
+	errorCases := map[string]string{
+		"a.b.c":     `Ambiguous key a.b.c, it matches "a.b".c and a."b.c"`,
+		"log.level": `Ambiguous key log.level, it matches "log".level and "log.level"`,
+		"a.b.d":     "Key not found",
+		`"a".b.c`:   `Invalid key "a".b.c, expected a section and a key`,
+		`"a".b`:     "Key not found",
+	}
+	for key, expectedError := range errorCases {
+		t.Run("it fails to get "+key, func(t *testing.T) {
+			_, err := GetParameterFromPath(core.DotNotation, DOTTED_FILE_PATH, key)
+			if err == nil || err.Error() != expectedError {
+				t.Errorf("Expected the error %q, got %v", expectedError, err)
+			}
+		})
+	}
+
+	for _, key := range []string{"a.b.c", "log.level"} {
+		_, err := GetParameterFromPath(core.DotNotation, DOTTED_FILE_PATH, key)
+		_, candidates, _ := strings.Cut(err.Error(), "it matches ")
+		for _, candidate := range strings.Split(candidates, " and ") {
+			t.Run("it gets the suggested candidate "+candidate, func(t *testing.T) {
+				if _, err := GetParameterFromPath(core.DotNotation, DOTTED_FILE_PATH, candidate); err != nil {
+					t.Error(err)
+				}
+			})
+		}
+	}
+
+	testGetExistingParameter(t, core.PointerNotation, DOTTED_FILE_PATH, "/log/level", "debug")
+	testGetExistingParameter(t, core.PointerNotation, DOTTED_FILE_PATH, "/a.b/c", "from a.b")
+	_, err := GetParameterFromPath(core.PointerNotation, DOTTED_FILE_PATH, "/a/b/c")
+	expectedError := "Invalid key /a/b/c, expected a section and a key"
+	if err == nil || err.Error() != expectedError {
+		t.Errorf("Expected the error %q, got %v", expectedError, err)
+	}
+
+	config, err := EditConfigFile(core.PointerNotation, DOTTED_FILE_PATH, "/a/b.c", "edited")
+	if err != nil {
+		t.Fatal(err)
+	}
+	output := OutputConfigFile(config, FullOutput)
+	if !strings.Contains(output, "b.c=edited\n") || !strings.Contains(output, "c = from a.b\n") {
+		t.Errorf("Expected b.c to be edited in [a], got:\n%s", output)
+	}
+}
+
 func TestEditParameter(t *testing.T) {
 	type EditTestElement struct {
 		sectionName string
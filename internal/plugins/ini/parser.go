@@ -72,7 +72,7 @@ func parseLineString(lineNumber int, lineString string) Line {
 			spacePrefix,
 			Original,
 			KeyValueType,
-			&KeyValue{key, value, false},
+			&KeyValue{Key: key, Value: value},
 			nil,
 		}
 	}
@@ -112,7 +112,9 @@ func ParseIniFile(file string) ([]*Line, error) {
 	return parsedLines, nil
 }
 
-func getSections(parsedLines []*Line) (*GlobalSection, []*Section) {
+// GetSections groups the lines by section, the lines before the first section
+// being in the global section
+func GetSections(parsedLines []*Line) (*GlobalSection, []*Section) {
 	globalSection := &GlobalSection{[]*Line{}}
 	var currentSection *Section = nil
 	sections := []*Section{}
@@ -18,11 +18,19 @@ func TestDecomposeKeyWithDotNotation(t *testing.T) {
 		{"key", []string{"key"}},
 		{"key.foo", []string{"key", "foo"}},
 		{"key.foo.bar", []string{"key", "foo", "bar"}},
+		{`key\.foo.bar`, []string{"key.foo", "bar"}},
+		{`key\\.foo`, []string{`key\`, "foo"}},
+		{`'CLI Server'."cli_server.color"`, []string{"CLI Server", "cli_server.color"}},
+		{`"say \"hi\"".it's`, []string{`say "hi"`, "it's"}},
+		{`''.key`, []string{"", "key"}},
 	}
 
 	for _, element := range dataProvider {
 		t.Run("it decomposes "+element.inputKey, func(t *testing.T) {
-			actual := core.DecomposeKeyWithDotNotation(element.inputKey)
+			actual, err := core.DecomposeKeyWithDotNotation(element.inputKey)
+			if err != nil {
+				t.Fatal(err)
+			}
 
 			if !reflect.DeepEqual(element.expected, actual) {
 				t.Error(fmt.Sprintf("Expected %s, got %s", element.expected, actual))
@@ -36,12 +44,16 @@ func TestDecomposeKeyWithBracketNotation(t *testing.T) {
 		{"key", []string{"key"}},
 		{"key[foo]", []string{"key", "foo"}},
 		{"key[foo][bar]", []string{"key", "foo", "bar"}},
+		{"[foo][bar]", []string{"foo", "bar"}},
+		{`key[foo\[0\]][bar.baz]`, []string{"key", "foo[0]", "bar.baz"}},
 	}
 
 	for _, element := range dataProvider {
 		t.Run("it decomposes "+element.inputKey, func(t *testing.T) {
-			actual := core.DecomposeKeyWithBracketNotation(element.inputKey)
-			t.Log(actual)
+			actual, err := core.DecomposeKeyWithBracketNotation(element.inputKey)
+			if err != nil {
+				t.Fatal(err)
+			}
 
 			if !reflect.DeepEqual(element.expected, actual) {
 				t.Error(fmt.Sprintf("Expected %s, got %s", element.expected, actual))
@@ -49,3 +61,182 @@ func TestDecomposeKeyWithBracketNotation(t *testing.T) {
 		})
 	}
 }
+
+func TestDecomposeKeyWithPointerNotation(t *testing.T) {
+	dataProvider := []TestElement{
+		{"/key", []string{"key"}},
+		{"/CLI Server/cli_server.color", []string{"CLI Server", "cli_server.color"}},
+		{"/a~1b/c~0d/~01", []string{"a/b", "c~d", "~1"}},
+		{"/key/", []string{"key", ""}},
+	}
+
+	for _, element := range dataProvider {
+		t.Run("it decomposes "+element.inputKey, func(t *testing.T) {
+			actual, err := core.DecomposeKeyWithPointerNotation(element.inputKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(element.expected, actual) {
+				t.Error(fmt.Sprintf("Expected %s, got %s", element.expected, actual))
+			}
+		})
+	}
+}
+
+func TestDecomposeKeyWithPredicates(t *testing.T) {
+	dataProvider := []TestElement{
+		{"http.server[1].listen", []string{"http", "server[1]", "listen"}},
+		{"server[server_name=example.com].root", []string{"server[server_name=example.com]", "root"}},
+		{`location[~ \.php$].fastcgi_pass`, []string{`location[~ \.php$]`, "fastcgi_pass"}},
+		{`dependency[name="a].b"].version`, []string{`dependency[name="a].b"]`, "version"}},
+		{`properties."java.version"`, []string{"properties", "java.version"}},
+		{`'Desktop Entry'."Name"[fr]`, []string{"Desktop Entry", "Name[fr]"}},
+		{`nas\.lan.ip`, []string{"nas.lan", "ip"}},
+	}
+
+	for _, element := range dataProvider {
+		t.Run("it decomposes "+element.inputKey, func(t *testing.T) {
+			actual, err := core.DecomposeKeyWithPredicates(element.inputKey)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !reflect.DeepEqual(element.expected, actual) {
+				t.Error(fmt.Sprintf("Expected %s, got %s", element.expected, actual))
+			}
+		})
+	}
+
+	errorProvider := map[string]string{
+		"server[1.root":   `Invalid key server[1.root: missing "]"`,
+		"server]":         `Invalid key server]: unexpected "]"`,
+		"http..root":      "Invalid key http..root: empty segment",
+		`"http.root`:      `Invalid key "http.root: missing closing "`,
+		`"http"x.root`:    `Invalid key "http"x.root: expected "." or "[" after a quoted segment`,
+		`server[name='a]`: `Invalid key server[name='a]: missing closing '`,
+		`http.root\`:      `Invalid key http.root\: trailing backslash`,
+	}
+	for key, expectedError := range errorProvider {
+		t.Run("it fails to decompose "+key, func(t *testing.T) {
+			_, err := core.DecomposeKeyKeepingPredicates(core.DotNotation, key)
+			if err == nil || err.Error() != expectedError {
+				t.Errorf("Expected the error %q, got %v", expectedError, err)
+			}
+		})
+	}
+}
+
+func TestDecomposeMalformedKey(t *testing.T) {
+	dataProvider := []struct {
+		notationStyle core.NotationStyle
+		inputKey      string
+		expectedError string
+	}{
+		{core.DotNotation, "", "Invalid key : empty segment"},
+		{core.DotNotation, "key..foo", "Invalid key key..foo: empty segment"},
+		{core.DotNotation, "key.", "Invalid key key.: empty segment"},
+		{core.DotNotation, `key\`, `Invalid key key\: trailing backslash`},
+		{core.DotNotation, `'CLI Server.key`, `Invalid key 'CLI Server.key: missing closing '`},
+		{core.DotNotation, `"CLI"Server.key`, `Invalid key "CLI"Server.key: expected "." after a quoted segment`},
+		{core.BracketsNotation, "key[foo", `Invalid key key[foo: missing "]"`},
+		{core.BracketsNotation, "key[foo]bar", `Invalid key key[foo]bar: expected "[" after "]"`},
+		{core.BracketsNotation, "key[]", "Invalid key key[]: empty segment"},
+		{core.BracketsNotation, "key]", `Invalid key key]: unexpected "]", escape it with a backslash`},
+		{core.PointerNotation, "key", `Invalid key key: expected a key starting with "/"`},
+		{core.PointerNotation, "/key~2", `Invalid key /key~2: "~" must be followed by 0 or 1`},
+	}
+
+	for _, element := range dataProvider {
+		t.Run("it fails to decompose "+element.inputKey, func(t *testing.T) {
+			_, err := core.DecomposeKey(element.notationStyle, element.inputKey)
+			if err == nil || err.Error() != element.expectedError {
+				t.Errorf("Expected the error %q, got %v", element.expectedError, err)
+			}
+		})
+	}
+}
+
+func TestComposeKey(t *testing.T) {
+	path := []string{"CLI Server", "cli_server.color", "a[0]/b"}
+	dataProvider := map[core.NotationStyle]string{
+		core.DotNotation:      `CLI Server."cli_server.color".a[0]/b`,
+		core.BracketsNotation: `CLI Server[cli_server.color][a\[0\]/b]`,
+		core.PointerNotation:  "/CLI Server/cli_server.color/a[0]~1b",
+	}
+
+	for notationStyle, expected := range dataProvider {
+		actual := core.ComposeKey(notationStyle, path)
+		if actual != expected {
+			t.Errorf("Expected %s, got %s", expected, actual)
+		}
+
+		decomposed, err := core.DecomposeKey(notationStyle, actual)
+		if err != nil || !reflect.DeepEqual(decomposed, path) {
+			t.Errorf("Expected %s to decompose into %v, got %v (%v)", actual, path, decomposed, err)
+		}
+	}
+}
+
+func TestKeyPattern(t *testing.T) {
+	dataProvider := []struct {
+		pattern  string
+		path     []string
+		expected bool
+	}{
+		{"www.memory_limit", []string{"www", "memory_limit"}, true},
+		{"*.memory_limit", []string{"www", "memory_limit"}, true},
+		{"*.memory_limit", []string{"memory_limit"}, false},
+		{"*.memory_limit", []string{"a", "b", "memory_limit"}, false},
+		{"**.memory_limit", []string{"memory_limit"}, true},
+		{"**.memory_limit", []string{"a", "b", "memory_limit"}, true},
+		{"alias.**", []string{"alias", "co"}, true},
+		{"{www,api}.pm", []string{"api", "pm"}, true},
+		{"{www,api}.pm", []string{"admin", "pm"}, false},
+		{`www."pm.*"`, []string{"www", "pm.max_children"}, false},
+		{`www."pm.*"`, []string{"www", "pm.*"}, true},
+		{`'*'.memory_limit`, []string{"www", "memory_limit"}, false},
+		{`www.pm\.*`, []string{"www", "pm.*"}, true},
+		{`www.pm\.*`, []string{"www", "pm.max_children"}, false},
+		{"www.pm_?", []string{"www", "pm_a"}, true},
+		{`www.re:pm_[0-9]+`, []string{"www", "pm_12"}, true},
+		{`www.re:pm_[0-9]+`, []string{"www", "pm_12a"}, false},
+		{`**.re:max_.*`, []string{"PHP", "max_execution_time"}, true},
+		{`**.re:max_\w+`, []string{"PHP", "max_execution_time"}, true},
+		{`**.re:max_\w+`, []string{"PHP", "max_execution.time"}, false},
+		{`www.re:pm\.max_.*`, []string{"www", "pm.max_children"}, true},
+		{`www.re:pm\.max_.*`, []string{"www", "pm_max_children"}, false},
+		{`www.re:pm.max`, []string{"www", "pm", "max"}, false},
+	}
+
+	for _, element := range dataProvider {
+		t.Run("it matches "+element.pattern, func(t *testing.T) {
+			pattern, err := core.ParseKeyPattern(core.DotNotation, element.pattern)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if pattern.Match(element.path) != element.expected {
+				t.Errorf("Expected %s matching %v to be %v", element.pattern, element.path, element.expected)
+			}
+		})
+	}
+
+	for _, key := range []string{"www.memory_limit", `"*".memory_limit`} {
+		literal, _ := core.ParseKeyPattern(core.DotNotation, key)
+		if !literal.IsLiteral() {
+			t.Errorf("Expected %s to be a literal key", key)
+		}
+	}
+
+	pointer, err := core.ParseKeyPattern(core.PointerNotation, "/www/pm.*")
+	if err != nil || !pointer.Match([]string{"www", "pm.max_children"}) {
+		t.Errorf("Expected /www/pm.* to match www.pm.max_children (%v)", err)
+	}
+
+	for _, invalid := range []string{"www.{a,b", "www.re:(a"} {
+		if _, err := core.ParseKeyPattern(core.DotNotation, invalid); err == nil {
+			t.Errorf("Expected %s to be an invalid pattern", invalid)
+		}
+	}
+}
@@ -33,6 +33,18 @@ type KeyValue struct {
 	Key       string
 	Value     string
 	Commented bool
+	// Separator is written between the key and the value of a new line, "="
+	// if empty
+	Separator string
+	// ValuePosition is the position of the value in StringContent. When set,
+	// only the value is replaced when the line changes, keeping the spacing
+	// and inline comments of the line.
+	ValuePosition *ValuePosition
+}
+
+type ValuePosition struct {
+	Start int
+	End   int
 }
 
 type SectionLine struct {
@@ -72,7 +84,17 @@ func (line *Line) ToString() string {
 	var result string
 
 	if line.ContentType == KeyValueType {
-		result = line.SpacePrefix + line.KeyValue.Key + "=" + line.KeyValue.Value
+		position := line.KeyValue.ValuePosition
+		if position != nil && !line.KeyValue.Commented {
+			return line.StringContent[:position.Start] + line.KeyValue.Value + line.StringContent[position.End:]
+		}
+
+		separator := line.KeyValue.Separator
+		if separator == "" {
+			separator = "="
+		}
+
+		result = line.SpacePrefix + line.KeyValue.Key + separator + line.KeyValue.Value
 		// prepend comment symbol if line is commented
 		if line.KeyValue.Commented {
 			result = ";" + result
@@ -131,3 +153,9 @@ func (configurator IniConfigurator) SetParameter(
 
 	return config, nil
 }
+
+// SetsExistingKeysOnly tells that a key missing from an INI file cannot be
+// set, only changed
+func (configurator IniConfigurator) SetsExistingKeysOnly() bool {
+	return true
+}
@@ -0,0 +1,178 @@
+package json
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+)
+
+var jsonNumberRegexp = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// JsonFormat converts configurations from and to JSON, keeping the order of
+// the keys
+type JsonFormat struct{}
+
+func (format JsonFormat) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(content))
+	decoder.UseNumber()
+
+	tree, err := readJSONValue(decoder, losses)
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid JSON: %s", err.Error()))
+	}
+	if decoder.More() {
+		return nil, errors.New("Invalid JSON: unexpected data after the value")
+	}
+
+	return tree, nil
+}
+
+func readJSONValue(decoder *json.Decoder, losses *core.Losses) (*core.Node, error) {
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch value := token.(type) {
+	case json.Delim:
+		if value == '[' {
+			list := core.NewListNode()
+			for decoder.More() {
+				item, err := readJSONValue(decoder, losses)
+				if err != nil {
+					return nil, err
+				}
+				list.Items = append(list.Items, item)
+			}
+			_, err = decoder.Token()
+
+			return list, err
+		}
+
+		object := core.NewMapNode()
+		for decoder.More() {
+			key, err := decoder.Token()
+			if err != nil {
+				return nil, err
+			}
+			child, err := readJSONValue(decoder, losses)
+			if err != nil {
+				return nil, err
+			}
+			if object.Get(key.(string)) != nil {
+				losses.Add("%s is defined several times, only the last value is kept", key)
+			}
+			object.Set(key.(string), child)
+		}
+		_, err = decoder.Token()
+
+		return object, err
+	case string:
+		return core.NewScalarNode(core.StringScalar, value), nil
+	case json.Number:
+		if strings.ContainsAny(value.String(), ".eE") {
+			return core.NewScalarNode(core.FloatScalar, value.String()), nil
+		}
+		return core.NewScalarNode(core.IntegerScalar, value.String()), nil
+	case bool:
+		return core.NewScalarNode(core.BoolScalar, strconv.FormatBool(value)), nil
+	default:
+		losses.Add("null values are converted to empty strings")
+		return core.NewScalarNode(core.UntypedScalar, ""), nil
+	}
+}
+
+func (format JsonFormat) WriteTree(tree *core.Node, losses *core.Losses) (string, error) {
+	var builder strings.Builder
+	writeJSONValue(&builder, tree, "", losses)
+	builder.WriteString("\n")
+
+	return builder.String(), nil
+}
+
+// Quote returns a string as a JSON string
+func Quote(value string) string {
+	buffer := &bytes.Buffer{}
+	encoder := json.NewEncoder(buffer)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(value)
+
+	return strings.TrimSuffix(buffer.String(), "\n")
+}
+
+// jsonNumber returns a number as written in JSON, if it can be
+func jsonNumber(value string) (string, bool) {
+	if jsonNumberRegexp.MatchString(value) {
+		return value, true
+	}
+
+	number, err := strconv.ParseFloat(strings.ReplaceAll(value, "_", ""), 64)
+	if err != nil || math.IsInf(number, 0) || math.IsNaN(number) {
+		return "", false
+	}
+
+	return strconv.FormatFloat(number, 'g', -1, 64), true
+}
+
+func writeJSONValue(builder *strings.Builder, node *core.Node, indent string, losses *core.Losses) {
+	switch node.Kind {
+	case core.MapNode, core.SectionNode:
+		if len(node.Keys) == 0 {
+			builder.WriteString("{}")
+			return
+		}
+		builder.WriteString("{\n")
+		for idx, key := range node.Keys {
+			builder.WriteString(indent + "  " + Quote(key) + ": ")
+			writeJSONValue(builder, node.Children[key], indent+"  ", losses)
+			if idx < len(node.Keys)-1 {
+				builder.WriteString(",")
+			}
+			builder.WriteString("\n")
+		}
+		builder.WriteString(indent + "}")
+	case core.ListNode:
+		if len(node.Items) == 0 {
+			builder.WriteString("[]")
+			return
+		}
+		builder.WriteString("[\n")
+		for idx, item := range node.Items {
+			builder.WriteString(indent + "  ")
+			writeJSONValue(builder, item, indent+"  ", losses)
+			if idx < len(node.Items)-1 {
+				builder.WriteString(",")
+			}
+			builder.WriteString("\n")
+		}
+		builder.WriteString(indent + "]")
+	default:
+		switch node.Type {
+		case core.IntegerScalar, core.FloatScalar:
+			if number, ok := jsonNumber(node.Value); ok {
+				builder.WriteString(number)
+				return
+			}
+			losses.Add("%s cannot be written as a JSON number and is written as a string", node.Value)
+		case core.BoolScalar:
+			builder.WriteString(node.Value)
+			return
+		case core.DateTimeScalar:
+			losses.TypedValue(node)
+		}
+		builder.WriteString(Quote(node.Value))
+	}
+}
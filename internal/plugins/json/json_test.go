@@ -0,0 +1,86 @@
+package json
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+func TestReadDocument(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "config.json")
+	content := `{"name": "edicon", "port": 8080, "ratio": 0.5, "debug": true, "tags": ["a", "b"], "name": "other", "empty": null}`
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	losses := &core.Losses{}
+	tree, err := JsonFormat{}.ReadDocument(filePath, losses)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedKeys := []string{"name", "port", "ratio", "debug", "tags", "empty"}
+	if !reflect.DeepEqual(tree.Keys, expectedKeys) {
+		t.Errorf("Expected keys %v, got %v", expectedKeys, tree.Keys)
+	}
+	if name := tree.Get("name"); name.Value != "other" || name.Type != core.StringScalar {
+		t.Errorf("Expected name to be the string other, got %v", name)
+	}
+	if port := tree.Get("port"); port.Type != core.IntegerScalar {
+		t.Errorf("Expected port to be an integer, got %v", port.Type)
+	}
+	if ratio := tree.Get("ratio"); ratio.Type != core.FloatScalar {
+		t.Errorf("Expected ratio to be a float, got %v", ratio.Type)
+	}
+	if tags := tree.Get("tags"); tags.Kind != core.ListNode || len(tags.Items) != 2 {
+		t.Errorf("Expected tags to be a list of 2 items, got %v", tags)
+	}
+
+	expectedLosses := []string{
+		"name is defined several times, only the last value is kept",
+		"null values are converted to empty strings",
+	}
+	if !reflect.DeepEqual(losses.Messages, expectedLosses) {
+		t.Errorf("Expected losses %v, got %v", expectedLosses, losses.Messages)
+	}
+}
+
+func TestWriteTree(t *testing.T) {
+	tree := core.NewMapNode()
+	tree.Set("name", core.NewScalarNode(core.StringScalar, "<edicon>"))
+	tree.Set("port", core.NewScalarNode(core.IntegerScalar, "8_080"))
+	tree.Set("port_max", core.NewScalarNode(core.IntegerScalar, "0x1F"))
+	tree.Set("debug", core.NewScalarNode(core.BoolScalar, "true"))
+	tree.Set("empty", core.NewMapNode())
+	tags := core.NewListNode()
+	tags.Items = append(tags.Items, core.NewScalarNode(core.UntypedScalar, "a"))
+	tree.Set("tags", tags)
+
+	losses := &core.Losses{}
+	output, err := JsonFormat{}.WriteTree(tree, losses)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{
+  "name": "<edicon>",
+  "port": 8080,
+  "port_max": "0x1F",
+  "debug": true,
+  "empty": {},
+  "tags": [
+    "a"
+  ]
+}
+`
+	if output != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+	expectedLosses := []string{"0x1F cannot be written as a JSON number and is written as a string"}
+	if !reflect.DeepEqual(losses.Messages, expectedLosses) {
+		t.Errorf("Expected losses %v, got %v", expectedLosses, losses.Messages)
+	}
+}
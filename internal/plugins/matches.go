@@ -0,0 +1,92 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+)
+
+// ParseKeyPattern returns the pattern of a key, or nil when the key
+// designates a single key: it has no pattern, or the configurator does not
+// match key patterns and takes it literally
+func ParseKeyPattern(configurator core.Configurator, notationStyle core.NotationStyle, key string) (core.KeyPattern, error) {
+	if _, ok := configurator.(core.KeyPatternMatcher); !ok {
+		return nil, nil
+	}
+
+	pattern, err := core.ParseKeyPattern(notationStyle, key)
+	if err != nil {
+		return nil, err
+	}
+	if pattern.IsLiteral() {
+		return nil, nil
+	}
+
+	return pattern, nil
+}
+
+// FindMatches returns the values of a file whose key matches a pattern,
+// from the document tree of the file
+func FindMatches(configurator core.Configurator, filePath string, pattern core.KeyPattern) ([]*core.Node, error) {
+	reader, ok := configurator.(core.KeyPatternMatcher)
+	if !ok {
+		return nil, errors.New("Key patterns are not supported for this configuration type")
+	}
+
+	document, err := reader.ReadDocument(filePath, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return pattern.FindAll(document), nil
+}
+
+// SetAllMatches sets the value of each match, one after the other on a
+// temporary copy of the file, and returns the configuration with all the
+// changes
+func SetAllMatches(
+	configurator core.Configurator,
+	filePath string,
+	matches []*core.Node,
+	value string,
+	valueType string,
+) (core.Configuration, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return nil, err
+	}
+	tempFilePath, err := io.GenerateRandomTempFilePath()
+	if err != nil {
+		return nil, err
+	}
+	defer io.RemoveFile(tempFilePath)
+	if err := io.WriteFileContents(tempFilePath, content); err != nil {
+		return nil, err
+	}
+
+	var config core.Configuration
+	for _, match := range matches {
+		key := core.ComposeKey(core.PointerNotation, match.Path)
+
+		if valueType == "" {
+			config, err = configurator.SetParameter(core.PointerNotation, tempFilePath, key, value)
+		} else {
+			typedConfigurator, ok := configurator.(core.TypedConfigurator)
+			if !ok {
+				return nil, errors.New("The --type flag is not supported for this configuration type")
+			}
+			config, err = typedConfigurator.SetTypedParameter(core.PointerNotation, tempFilePath, key, value, valueType)
+		}
+		if err != nil {
+			return nil, errors.New(fmt.Sprintf("Cannot set %s: %s", key, err.Error()))
+		}
+
+		if err := config.WriteToFile(tempFilePath, core.FullOutput); err != nil {
+			return nil, err
+		}
+	}
+
+	return config, nil
+}
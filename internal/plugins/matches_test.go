@@ -0,0 +1,75 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+func TestParseKeyPatternKeepsLiteralKeys(t *testing.T) {
+	dataProvider := []struct {
+		configuratorType string
+		filePath         string
+		key              string
+		expected         string
+	}{
+		{"apache", "../../data/apache/httpd.conf", "VirtualHost[*:443].DocumentRoot", "/var/www/example"},
+		{"ssh", "../../data/ssh/ssh_config", "Host[*].Compression", "yes"},
+		{"editorconfig", "../../data/editorconfig/.editorconfig", "*.indent_style", "space"},
+		{"editorconfig", "../../data/editorconfig/.editorconfig", "*.{js,ts,json}.indent_size", "2"},
+		{"php", "../../data/ini/php.ini", "'*'.engine", ""},
+		{"php", "../../data/ini/php.ini", `PHP.\*`, ""},
+	}
+
+	for _, element := range dataProvider {
+		t.Run("it takes "+element.key+" literally", func(t *testing.T) {
+			configurator, err := GetConfigurator(element.configuratorType)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			pattern, err := ParseKeyPattern(configurator, core.DotNotation, element.key)
+			if err != nil || pattern != nil {
+				t.Fatalf("Expected no pattern, got %v (%v)", pattern, err)
+			}
+
+			value, err := configurator.GetParameter(core.DotNotation, element.filePath, element.key)
+			if element.expected == "" {
+				if err == nil {
+					t.Errorf("Expected the literal key not to be found, got %s", value)
+				}
+			} else if err != nil || value != element.expected {
+				t.Errorf("Expected %s, got %s (%v)", element.expected, value, err)
+			}
+		})
+	}
+}
+
+func TestParseKeyPattern(t *testing.T) {
+	configurator, err := GetConfigurator("php")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	pattern, err := ParseKeyPattern(configurator, core.DotNotation, "*.engine")
+	if err != nil || pattern == nil {
+		t.Fatalf("Expected a pattern, got %v (%v)", pattern, err)
+	}
+	matches, err := FindMatches(configurator, "../../data/ini/php.ini", pattern)
+	if err != nil || len(matches) != 1 || matches[0].Value != "On" {
+		t.Errorf("Expected *.engine to match PHP.engine, got %v (%v)", matches, err)
+	}
+
+	pattern, err = ParseKeyPattern(configurator, core.DotNotation, `**.re:cli_\w+\..*`)
+	if err != nil || pattern == nil {
+		t.Fatalf("Expected a pattern, got %v (%v)", pattern, err)
+	}
+	matches, err = FindMatches(configurator, "../../data/ini/php.ini", pattern)
+	if err != nil || len(matches) != 1 || matches[0].Value != "On" {
+		t.Errorf("Expected a regexp with dots and backslashes to match cli_server.color, got %v (%v)", matches, err)
+	}
+
+	if _, err := ParseKeyPattern(configurator, core.DotNotation, "www.{a,b"); err == nil {
+		t.Error("Expected www.{a,b to be an invalid pattern")
+	}
+}
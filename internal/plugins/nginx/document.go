@@ -0,0 +1,49 @@
+package nginx
+
+import (
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+// ReadDocument returns the document tree of the configuration: blocks are
+// maps of their directives and the occurrences of a repeated directive are a
+// list, designated by their position (http.server[2].listen). The arguments
+// of the blocks (location /api) are not part of the tree.
+func (configurator NginxConfigurator) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	config, err := GetParsedNginxFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Comments) > 0 {
+		losses.Add("comments are not converted")
+	}
+	document := source.NewDocument(config.Content, config.Comments, "#")
+
+	return config.readBlock(document, config.Main, []string{}, losses), nil
+}
+
+func (config *NginxConfiguration) readBlock(document *source.Document, block *Block, path []string, losses *core.Losses) *core.Node {
+	node := core.NewMapNode()
+	node.Path = path
+
+	for _, directive := range block.Directives {
+		childPath := append(append([]string{}, path...), directive.Name)
+
+		var child *core.Node
+		if directive.Block != nil {
+			if len(directive.Arguments) > 0 {
+				losses.Add("the arguments of blocks (%s %s) are not converted", directive.Name, directive.ArgumentsText())
+			}
+			child = config.readBlock(document, directive.Block, childPath, losses)
+		} else {
+			child = core.NewScalarNode(core.UntypedScalar, directive.ArgumentsText())
+			child.Path = childPath
+		}
+		child.Span = document.Lines(directive.Span)
+		child.Comments = document.AttachedComments(directive.Span)
+		node.AddIndexed(directive.Name, child)
+	}
+
+	return node
+}
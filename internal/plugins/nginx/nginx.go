@@ -0,0 +1,318 @@
+package nginx
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/blockpath"
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+var (
+	indentRegexp = regexp.MustCompile(`[ \t]*$`)
+	resolver     = blockpath.Resolver{
+		SameName:  func(name string, other string) bool { return name == other },
+		BlockKind: "block",
+	}
+)
+
+func (directive *Directive) DirectiveName() string {
+	return directive.Name
+}
+
+func (directive *Directive) ArgumentValues() []string {
+	values := []string{}
+	for _, argument := range directive.Arguments {
+		values = append(values, argument.Value())
+	}
+
+	return values
+}
+
+func (directive *Directive) Children() []blockpath.Directive {
+	if directive.Block == nil {
+		return nil
+	}
+
+	return directive.Block.children()
+}
+
+func (block *Block) children() []blockpath.Directive {
+	children := []blockpath.Directive{}
+	for _, directive := range block.Directives {
+		children = append(children, directive)
+	}
+
+	return children
+}
+
+// Resolve returns the directives matching the last segment of the key, and
+// the block containing them
+func Resolve(main *Block, segments []blockpath.Segment) ([]*Directive, *Block, error) {
+	matching, parent, err := resolver.Resolve(main.children(), segments)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	block := main
+	if parent != nil {
+		block = parent.(*Directive).Block
+	}
+	directives := []*Directive{}
+	for _, directive := range matching {
+		directives = append(directives, directive.(*Directive))
+	}
+
+	return directives, block, nil
+}
+
+func OutputConfigFile(config *NginxConfiguration, outputType core.OutputType) string {
+	if outputType != core.MeaningFullOutput {
+		return config.Content
+	}
+
+	return source.RemoveComments(config.Content, config.Comments)
+}
+
+// GetParameterFromPath returns the arguments of the matching directives, one
+// directive per line. The arguments are unquoted unless they need their
+// quotes, as in the values of ReadDocument.
+func GetParameterFromPath(notationStyle core.NotationStyle, filePath string, key string) (string, error) {
+	config, err := GetParsedNginxFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	segments, err := blockpath.DecomposeKey(notationStyle, key)
+	if err != nil {
+		return "", err
+	}
+
+	directives, _, err := Resolve(config.Main, segments)
+	if err != nil {
+		return "", err
+	}
+	if len(directives) == 0 {
+		return "", errors.New("Key not found")
+	}
+
+	values := []string{}
+	for _, directive := range directives {
+		values = append(values, directive.ArgumentsText())
+	}
+
+	return strings.Join(values, "\n"), nil
+}
+
+// EditConfigFile sets the arguments of a directive, or adds a new directive
+// if it does not exist yet or if add is true
+func EditConfigFile(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+	add bool,
+) (*NginxConfiguration, error) {
+	config, err := GetParsedNginxFile(filePath)
+	if err != nil {
+		return &NginxConfiguration{}, err
+	}
+
+	segments, err := blockpath.DecomposeKey(notationStyle, key)
+	if err != nil {
+		return &NginxConfiguration{}, err
+	}
+
+	directives, block, err := Resolve(config.Main, segments)
+	if err != nil {
+		return &NginxConfiguration{}, err
+	}
+
+	if err := validateArguments(value); err != nil {
+		return &NginxConfiguration{}, err
+	}
+
+	last := segments[len(segments)-1]
+	switch {
+	case add || len(directives) == 0:
+		if !add && len(last.Predicates) > 0 {
+			return &NginxConfiguration{}, errors.New("Key not found")
+		}
+		if len(last.Predicates) > 0 {
+			return &NginxConfiguration{}, errors.New(fmt.Sprintf("Cannot add %s: the last part of the key cannot have a predicate", key))
+		}
+		config.insertDirective(block, last.Name, value)
+	case len(directives) > 1:
+		return &NginxConfiguration{}, errors.New(fmt.Sprintf(
+			"%d directives match %s, use a predicate to choose one (e.g. %s[1]) or add to add a new one",
+			len(directives), key, last.Name,
+		))
+	default:
+		config.setArguments(directives[0], value)
+	}
+
+	return config.validate(key)
+}
+
+// RemoveFromConfigFile removes a directive, or a whole block
+func RemoveFromConfigFile(notationStyle core.NotationStyle, filePath string, key string) (*NginxConfiguration, error) {
+	config, err := GetParsedNginxFile(filePath)
+	if err != nil {
+		return &NginxConfiguration{}, err
+	}
+
+	segments, err := blockpath.DecomposeKey(notationStyle, key)
+	if err != nil {
+		return &NginxConfiguration{}, err
+	}
+
+	directives, _, err := Resolve(config.Main, segments)
+	if err != nil {
+		return &NginxConfiguration{}, err
+	}
+	if len(directives) == 0 {
+		return &NginxConfiguration{}, errors.New("Key not found")
+	}
+	if len(directives) > 1 {
+		return &NginxConfiguration{}, errors.New(fmt.Sprintf(
+			"%d directives match %s, use a predicate to choose one (e.g. %s[1])",
+			len(directives), key, segments[len(segments)-1].Name,
+		))
+	}
+
+	config.removeDirective(directives[0])
+
+	return config.validate(key)
+}
+
+// validateArguments checks that a value only contains arguments, and no
+// semicolons or braces which would change the structure of the file
+func validateArguments(value string) error {
+	p := &parser{content: value}
+
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return err
+		}
+
+		switch tok.Type {
+		case endToken:
+			if len(p.comments) > 0 {
+				return errors.New(fmt.Sprintf("Invalid value %q: comments are not allowed", value))
+			}
+
+			return nil
+		case wordToken:
+			continue
+		default:
+			return errors.New(fmt.Sprintf("Invalid value %q: %q is not allowed outside of quotes", value, tok.Raw))
+		}
+	}
+}
+
+func (config *NginxConfiguration) validate(key string) (*NginxConfiguration, error) {
+	main, comments, err := ParseNginxContent(config.Content)
+	if err != nil {
+		return &NginxConfiguration{}, errors.New(fmt.Sprintf("Refusing to edit %s: %s", key, err.Error()))
+	}
+	config.Main = main
+	config.Comments = comments
+
+	return config, nil
+}
+
+func (config *NginxConfiguration) replace(span source.Span, replacement string) {
+	config.Content = config.Content[:span.Start] + replacement + config.Content[span.End:]
+}
+
+func (config *NginxConfiguration) setArguments(directive *Directive, value string) {
+	if len(directive.Arguments) == 0 {
+		position := directive.Span.Start + len(directive.Name)
+		config.replace(source.Span{Start: position, End: position}, " "+value)
+
+		return
+	}
+
+	first := directive.Arguments[0].Span
+	last := directive.Arguments[len(directive.Arguments)-1].Span
+	config.replace(source.Span{Start: first.Start, End: last.End}, value)
+}
+
+func (config *NginxConfiguration) indentOf(position int) string {
+	return indentRegexp.FindString(config.Content[:position])
+}
+
+// insertDirective adds a directive after the last directive with the same
+// name in the block, or at the end of the block
+func (config *NginxConfiguration) insertDirective(block *Block, name string, value string) {
+	directive := name
+	if value != "" {
+		directive += " " + value
+	}
+	directive += ";"
+
+	var reference *Directive
+	for _, existing := range block.Directives {
+		if existing.Name == name {
+			reference = existing
+		}
+	}
+	if reference == nil && len(block.Directives) > 0 {
+		reference = block.Directives[len(block.Directives)-1]
+	}
+
+	if reference != nil {
+		indent := config.indentOf(reference.Span.Start)
+		config.replace(source.Span{Start: reference.Span.End, End: reference.Span.End}, "\n"+indent+directive)
+
+		return
+	}
+
+	if block.Parent == nil {
+		if config.Content != "" && !strings.HasSuffix(config.Content, "\n") {
+			directive = "\n" + directive
+		}
+		config.replace(source.Span{Start: len(config.Content), End: len(config.Content)}, directive+"\n")
+
+		return
+	}
+
+	parentIndent := config.indentOf(block.Parent.Span.Start)
+	indent := parentIndent + "    "
+	if strings.HasPrefix(parentIndent, "\t") {
+		indent = parentIndent + "\t"
+	}
+
+	insertion := "\n" + indent + directive
+	if !strings.Contains(config.Content[block.Span.Start:block.Span.End], "\n") {
+		insertion += "\n" + parentIndent
+	}
+	config.replace(source.Span{Start: block.Span.Start, End: block.Span.Start}, insertion)
+}
+
+// removeDirective removes a directive, and its line if nothing else is on it
+func (config *NginxConfiguration) removeDirective(directive *Directive) {
+	start := directive.Span.Start - len(config.indentOf(directive.Span.Start))
+	end := directive.Span.End
+
+	rest := config.Content[end:]
+	lineEnd := strings.IndexByte(rest, '\n')
+	if lineEnd == -1 {
+		lineEnd = len(rest)
+	}
+
+	if (start == 0 || config.Content[start-1] == '\n') && strings.TrimSpace(rest[:lineEnd]) == "" {
+		end += lineEnd
+		if end < len(config.Content) {
+			end++
+		}
+	} else {
+		start = directive.Span.Start
+	}
+
+	config.replace(source.Span{Start: start, End: end}, "")
+}
@@ -0,0 +1,150 @@
+package nginx
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/testutil"
+)
+
+const NGINX_FILE_PATH = "../../../data/nginx/nginx.conf"
+
+func TestGetParameter(t *testing.T) {
+	cases := map[string]string{
+		"worker_processes":                                     "4",
+		"events.worker_connections":                            "768",
+		"http.include":                                         "/etc/nginx/mime.types",
+		"http.log_format":                                      `main '$remote_addr - "$request"'`,
+		"http.server[1].listen":                                "80\n[::]:80",
+		"http.server[1].listen[2]":                             "[::]:80",
+		`http.server[1]."root"`:                                "/var/www/example",
+		`'http'.server[1].listen[1]`:                           "80",
+		"http.server[server_name=example.com].root":            "/var/www/example",
+		"http.server[server_name=admin.example.com].listen":    "443 ssl",
+		"http.server[listen=443].server_name":                  "admin.example.com",
+		"http.server[1].location[/api].proxy_pass":             "http://127.0.0.1:8080",
+		"http.server[1].location[/api].proxy_set_header":       "Host $host",
+		"http.server[2].location":                              `~ \.php$`,
+		`http.server[2].location[~ \.php$].fastcgi_pass`:       "unix:/run/php/php8.2-fpm.sock",
+		"http.server[server_name=www.example.com].location[/]": "/",
+	}
+
+	for key, expectedValue := range cases {
+		t.Run("it gets existing parameter "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, NGINX_FILE_PATH, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value != expectedValue {
+				t.Fatal(fmt.Sprintf("Expected %s got %s", expectedValue, value))
+			}
+		})
+	}
+
+	errorCases := []string{"pid", "http.server.listen", "http.server[3].listen", "worker_processes.foo", "http.server[1].location[/missing].root", "http.server[1.root", "http..root", `http."server`, `http."server"x.root`}
+	for _, key := range errorCases {
+		t.Run("it does not get "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, NGINX_FILE_PATH, key)
+			if err == nil {
+				t.Error("Should fail. Got " + value + " instead")
+			}
+		})
+	}
+}
+
+func TestEditParameter(t *testing.T) {
+	type EditTestElement struct {
+		key   string
+		value string
+		add   bool
+		old   string
+		new   string
+	}
+
+	cases := []EditTestElement{
+		{"worker_processes", "auto", false, "worker_processes 4;  # one per core", "worker_processes auto;  # one per core"},
+		{"http.client_max_body_size", "64m", false, "client_max_body_size 1m;", "client_max_body_size 64m;"},
+		{"http.server[server_name=example.com].listen[1]", "8080", false, "listen 80;", "listen 8080;"},
+		{"http.server[1].location[/api].proxy_pass", "http://backend", false, "proxy_pass http://127.0.0.1:8080;", "proxy_pass http://backend;"},
+		{"pid", "/run/nginx.pid", false, "        }\n    }\n}\n", "        }\n    }\n}\npid /run/nginx.pid;\n"},
+		{"events.multi_accept", "on", false, "worker_connections 768;\n", "worker_connections 768;\n    multi_accept on;\n"},
+		{"http.server[1].listen", "443 ssl", true, "listen [::]:80;\n", "listen [::]:80;\n        listen 443 ssl;\n"},
+		{"http.server[2].location[~ \\.php$].include", "fastcgi_params", false, "fastcgi_pass unix:/run/php/php8.2-fpm.sock;\n", "fastcgi_pass unix:/run/php/php8.2-fpm.sock;\n            include fastcgi_params;\n"},
+	}
+
+	for _, element := range cases {
+		t.Run("it sets "+element.key, func(t *testing.T) {
+			config, err := EditConfigFile(core.DotNotation, NGINX_FILE_PATH, element.key, element.value, element.add)
+			testutil.AssertEditedOutput(t, NGINX_FILE_PATH, config, err, element.old, element.new)
+		})
+	}
+
+	errorCases := map[string]string{
+		"http.server[1].listen":     "81",
+		"http.server.root":          "/tmp",
+		"worker_processes":          "4; user root",
+		"http.client_max_body_size": "{",
+	}
+	for key, value := range errorCases {
+		t.Run("it refuses to set "+key+" to "+value, func(t *testing.T) {
+			if _, err := EditConfigFile(core.DotNotation, NGINX_FILE_PATH, key, value, false); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestUnsetParameter(t *testing.T) {
+	cases := map[string][]string{
+		"user":                                 {"user www-data;\n", ""},
+		"http.server[1].listen[2]":             {"        listen [::]:80;\n", ""},
+		"http.server[1].location[/]":           {"        location / {\n            try_files $uri $uri/ =404;\n        }\n", ""},
+		"http.server[listen=443 ssl].location": {"        location ~ \\.php$ {\n            fastcgi_pass unix:/run/php/php8.2-fpm.sock;\n        }\n", ""},
+	}
+
+	for key, replacement := range cases {
+		t.Run("it unsets "+key, func(t *testing.T) {
+			config, err := RemoveFromConfigFile(core.DotNotation, NGINX_FILE_PATH, key)
+			testutil.AssertEditedOutput(t, NGINX_FILE_PATH, config, err, replacement[0], replacement[1])
+		})
+	}
+
+	for _, key := range []string{"pid", "http.server[1].listen"} {
+		t.Run("it does not unset "+key, func(t *testing.T) {
+			if _, err := RemoveFromConfigFile(core.DotNotation, NGINX_FILE_PATH, key); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestParseInvalidNginx(t *testing.T) {
+	cases := []string{
+		"worker_processes 4",
+		"http {",
+		"}",
+		"http { server { }",
+		"log_format \"unterminated;",
+		"{ listen 80; }",
+	}
+
+	for _, content := range cases {
+		t.Run("it rejects "+content, func(t *testing.T) {
+			if _, _, err := ParseNginxContent(content); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestReadDocument(t *testing.T) {
+	testutil.AssertDocumentValues(t, NginxConfigurator{}, NGINX_FILE_PATH, map[string]string{
+		"events.worker_connections":             "768",
+		"http.server[1].listen[2]":              "[::]:80",
+		"http.server[1].location[2].proxy_pass": "http://127.0.0.1:8080",
+		"http.log_format":                       `main '$remote_addr - "$request"'`,
+		"http.server[2].server_name":            "admin.example.com",
+	})
+}
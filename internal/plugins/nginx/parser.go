@@ -0,0 +1,240 @@
+package nginx
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+type tokenType int
+
+const (
+	wordToken tokenType = iota
+	semicolonToken
+	openBraceToken
+	closeBraceToken
+	endToken
+)
+
+type token struct {
+	Type   tokenType
+	Raw    string
+	Quoted bool
+	Span   source.Span
+}
+
+type parser struct {
+	content  string
+	pos      int
+	comments []source.Span
+}
+
+func (p *parser) errorfAt(pos int, format string, args ...interface{}) error {
+	line := strings.Count(p.content[:pos], "\n") + 1
+	message := fmt.Sprintf(format, args...)
+
+	return errors.New(fmt.Sprintf("Invalid nginx configuration on line %d: %s", line, message))
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func (p *parser) next() (token, error) {
+	for p.pos < len(p.content) {
+		c := p.content[p.pos]
+		if isSpace(c) {
+			p.pos++
+			continue
+		}
+
+		if c == '#' {
+			start := p.pos
+			for p.pos < len(p.content) && p.content[p.pos] != '\n' {
+				p.pos++
+			}
+			p.comments = append(p.comments, source.Span{Start: start, End: p.pos})
+			continue
+		}
+
+		break
+	}
+
+	start := p.pos
+	if p.pos >= len(p.content) {
+		return token{Type: endToken, Span: source.Span{Start: start, End: start}}, nil
+	}
+
+	c := p.content[p.pos]
+	switch c {
+	case ';':
+		p.pos++
+		return token{Type: semicolonToken, Raw: ";", Span: source.Span{Start: start, End: p.pos}}, nil
+	case '{':
+		p.pos++
+		return token{Type: openBraceToken, Raw: "{", Span: source.Span{Start: start, End: p.pos}}, nil
+	case '}':
+		p.pos++
+		return token{Type: closeBraceToken, Raw: "}", Span: source.Span{Start: start, End: p.pos}}, nil
+	case '"', '\'':
+		p.pos++
+		for p.pos < len(p.content) && p.content[p.pos] != c {
+			if p.content[p.pos] == '\\' {
+				p.pos++
+			}
+			p.pos++
+		}
+		if p.pos >= len(p.content) {
+			return token{}, p.errorfAt(start, "unterminated string")
+		}
+		p.pos++
+
+		return token{Type: wordToken, Raw: p.content[start:p.pos], Quoted: true, Span: source.Span{Start: start, End: p.pos}}, nil
+	}
+
+	for p.pos < len(p.content) {
+		c := p.content[p.pos]
+		if isSpace(c) || c == ';' || c == '{' || c == '}' || c == '"' || c == '\'' {
+			break
+		}
+
+		// Variables can be written as ${name}
+		if c == '$' && p.pos+1 < len(p.content) && p.content[p.pos+1] == '{' {
+			end := strings.IndexByte(p.content[p.pos:], '}')
+			if end == -1 {
+				return token{}, p.errorfAt(p.pos, "unterminated variable")
+			}
+			p.pos += end + 1
+			continue
+		}
+
+		if c == '\\' {
+			p.pos++
+		}
+		p.pos++
+	}
+
+	return token{Type: wordToken, Raw: p.content[start:p.pos], Span: source.Span{Start: start, End: p.pos}}, nil
+}
+
+func (p *parser) parseBlock(parent *Directive) (*Block, error) {
+	block := &Block{Parent: parent}
+	block.Span.Start = p.pos
+
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok.Type {
+		case endToken:
+			if parent != nil {
+				return nil, p.errorfAt(parent.Span.Start, "unclosed block %s", parent.Name)
+			}
+			block.Span.End = tok.Span.Start
+
+			return block, nil
+		case closeBraceToken:
+			if parent == nil {
+				return nil, p.errorfAt(tok.Span.Start, "unexpected \"}\"")
+			}
+			block.Span.End = tok.Span.Start
+
+			return block, nil
+		case semicolonToken, openBraceToken:
+			return nil, p.errorfAt(tok.Span.Start, "unexpected %q", tok.Raw)
+		}
+
+		directive, err := p.parseDirective(tok, block)
+		if err != nil {
+			return nil, err
+		}
+		block.Directives = append(block.Directives, directive)
+	}
+}
+
+func (p *parser) parseDirective(name token, parent *Block) (*Directive, error) {
+	directive := &Directive{Name: name.Raw, Parent: parent}
+	directive.Span.Start = name.Span.Start
+
+	for {
+		tok, err := p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		switch tok.Type {
+		case wordToken:
+			directive.Arguments = append(directive.Arguments, &Argument{tok.Raw, tok.Quoted, tok.Span})
+		case semicolonToken:
+			directive.Span.End = tok.Span.End
+
+			return directive, nil
+		case openBraceToken:
+			block, err := p.parseBlock(directive)
+			if err != nil {
+				return nil, err
+			}
+			directive.Block = block
+			// parseBlock stops right after the closing brace
+			directive.Span.End = p.pos
+
+			return directive, nil
+		default:
+			return nil, p.errorfAt(directive.Span.Start, "directive %s is not terminated by \";\"", directive.Name)
+		}
+	}
+}
+
+func ParseNginxContent(content string) (*Block, []source.Span, error) {
+	p := &parser{content: content}
+
+	main, err := p.parseBlock(nil)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return main, p.comments, nil
+}
+
+func GetParsedNginxFile(filePath string) (NginxConfiguration, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return NginxConfiguration{}, err
+	}
+
+	main, comments, err := ParseNginxContent(content)
+	if err != nil {
+		return NginxConfiguration{}, err
+	}
+
+	return NginxConfiguration{content, main, comments, filePath}, nil
+}
+
+func unescape(value string) string {
+	var builder strings.Builder
+
+	for i := 0; i < len(value); i++ {
+		if value[i] == '\\' && i+1 < len(value) {
+			i++
+			switch value[i] {
+			case 'n':
+				builder.WriteByte('\n')
+			case 't':
+				builder.WriteByte('\t')
+			case 'r':
+				builder.WriteByte('\r')
+			default:
+				builder.WriteByte(value[i])
+			}
+			continue
+		}
+		builder.WriteByte(value[i])
+	}
+
+	return builder.String()
+}
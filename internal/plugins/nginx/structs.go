@@ -0,0 +1,125 @@
+package nginx
+
+import (
+	"strings"
+
+	"github.com/einenlum/edicon/internal/blockpath"
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+type Argument struct {
+	Raw    string
+	Quoted bool
+	Span   source.Span
+}
+
+// Value returns the argument without its quotes
+func (argument *Argument) Value() string {
+	if !argument.Quoted {
+		return argument.Raw
+	}
+
+	return unescape(argument.Raw[1 : len(argument.Raw)-1])
+}
+
+// Directive is a simple directive (worker_processes 4;) or a block directive
+// (server { ... }). Span goes from its name to the semicolon or the closing
+// brace.
+type Directive struct {
+	Name      string
+	Arguments []*Argument
+	Block     *Block
+	Parent    *Block
+	Span      source.Span
+}
+
+// Block is the content of the file or of a block directive. Span is the
+// position of the content between the braces.
+type Block struct {
+	Directives []*Directive
+	Parent     *Directive
+	Span       source.Span
+}
+
+// Text returns the argument as printed by get: without its quotes unless it
+// needs them
+func (argument *Argument) Text() string {
+	return blockpath.ArgumentText(argument.Raw, argument.Value())
+}
+
+// ArgumentsText returns the arguments as printed by get, separated by spaces
+// even when they are written on several lines
+func (directive *Directive) ArgumentsText() string {
+	texts := []string{}
+	for _, argument := range directive.Arguments {
+		texts = append(texts, argument.Text())
+	}
+
+	return strings.Join(texts, " ")
+}
+
+type NginxConfiguration struct {
+	Content  string
+	Main     *Block
+	Comments []source.Span
+	FilePath string
+}
+
+func (config *NginxConfiguration) OutputFile(outputType core.OutputType) (string, error) {
+	return OutputConfigFile(config, outputType), nil
+}
+
+func (config *NginxConfiguration) WriteToFile(filepath string, outputType core.OutputType) error {
+	output, err := config.OutputFile(outputType)
+	if err != nil {
+		return err
+	}
+
+	return io.WriteFileContents(filepath, output)
+}
+
+type NginxConfigurator struct{}
+
+func (configurator NginxConfigurator) GetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (string, error) {
+	return GetParameterFromPath(notationStyle, filePath, key)
+}
+
+func (configurator NginxConfigurator) SetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, false))
+}
+
+func (configurator NginxConfigurator) AddParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, true))
+}
+
+func (configurator NginxConfigurator) UnsetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (core.Configuration, error) {
+	return toConfiguration(RemoveFromConfigFile(notationStyle, filePath, key))
+}
+
+func toConfiguration(config *NginxConfiguration, err error) (core.Configuration, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
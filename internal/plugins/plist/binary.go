@@ -0,0 +1,428 @@
+package plist
+
+import (
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+	"unicode/utf16"
+)
+
+// Binary property lists are made of objects, each one starting with a marker
+// whose high nibble is its type and low nibble its size, followed by a table
+// of the offsets of the objects and a trailer of 32 bytes
+const (
+	boolMarker   = 0x0
+	intMarker    = 0x1
+	realMarker   = 0x2
+	dateMarker   = 0x3
+	dataMarker   = 0x4
+	asciiMarker  = 0x5
+	utf16Marker  = 0x6
+	uidMarker    = 0x8
+	arrayMarker  = 0xA
+	dictMarker   = 0xD
+	trailerSize  = 32
+	maxInfoValue = 0xF
+)
+
+type binaryReader struct {
+	data     []byte
+	offsets  []uint64
+	refSize  int
+	visiting map[uint64]bool
+}
+
+func invalidBinary(format string, args ...interface{}) error {
+	return errors.New("Invalid binary property list: " + fmt.Sprintf(format, args...))
+}
+
+func readUint(bytes []byte) uint64 {
+	var value uint64
+	for _, b := range bytes {
+		value = value<<8 | uint64(b)
+	}
+
+	return value
+}
+
+func putUint(bytes []byte, value uint64, size int) []byte {
+	for shift := (size - 1) * 8; shift >= 0; shift -= 8 {
+		bytes = append(bytes, byte(value>>uint(shift)))
+	}
+
+	return bytes
+}
+
+// sizeFor returns the number of bytes needed to write a value up to max
+func sizeFor(max uint64) int {
+	switch {
+	case max <= math.MaxUint8:
+		return 1
+	case max <= math.MaxUint16:
+		return 2
+	case max <= math.MaxUint32:
+		return 4
+	default:
+		return 8
+	}
+}
+
+func (r *binaryReader) bytes(start uint64, length uint64) ([]byte, error) {
+	if start > uint64(len(r.data)) || length > uint64(len(r.data))-start {
+		return nil, invalidBinary("object out of bounds")
+	}
+
+	return r.data[start : start+length], nil
+}
+
+// readCount returns the count of an object and the position of its content:
+// counts of 15 or more are written as an integer object after the marker
+func (r *binaryReader) readCount(position uint64, info byte) (uint64, uint64, error) {
+	if info != maxInfoValue {
+		return uint64(info), position + 1, nil
+	}
+
+	marker, err := r.bytes(position+1, 1)
+	if err != nil {
+		return 0, 0, err
+	}
+	if marker[0]>>4 != intMarker {
+		return 0, 0, invalidBinary("invalid count")
+	}
+	size := uint64(1) << (marker[0] & 0xF)
+	count, err := r.bytes(position+2, size)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	return readUint(count), position + 2 + size, nil
+}
+
+func (r *binaryReader) readRefs(position uint64, count uint64) ([]uint64, error) {
+	if count > uint64(len(r.data)) {
+		return nil, invalidBinary("object out of bounds")
+	}
+	bytes, err := r.bytes(position, count*uint64(r.refSize))
+	if err != nil {
+		return nil, err
+	}
+
+	refs := []uint64{}
+	for idx := 0; idx < len(bytes); idx += r.refSize {
+		refs = append(refs, readUint(bytes[idx:idx+r.refSize]))
+	}
+
+	return refs, nil
+}
+
+func (r *binaryReader) readObject(ref uint64) (*Value, error) {
+	if ref >= uint64(len(r.offsets)) {
+		return nil, invalidBinary("invalid object reference %d", ref)
+	}
+	if r.visiting[ref] {
+		return nil, invalidBinary("object %d contains itself", ref)
+	}
+	r.visiting[ref] = true
+	defer delete(r.visiting, ref)
+
+	position := r.offsets[ref]
+	markerBytes, err := r.bytes(position, 1)
+	if err != nil {
+		return nil, err
+	}
+	marker, info := markerBytes[0]>>4, markerBytes[0]&0xF
+
+	switch marker {
+	case boolMarker:
+		if info != 0x8 && info != 0x9 {
+			return nil, invalidBinary("unsupported object 0x%02x", markerBytes[0])
+		}
+		return &Value{Kind: BoolKind, Scalar: strconv.FormatBool(info == 0x9)}, nil
+	case intMarker:
+		size := uint64(1) << info
+		bytes, err := r.bytes(position+1, size)
+		if err != nil {
+			return nil, err
+		}
+		switch size {
+		case 8:
+			return &Value{Kind: IntegerKind, Scalar: strconv.FormatInt(int64(readUint(bytes)), 10)}, nil
+		case 16:
+			// Unsigned integers above the maximum signed 64 bits integer
+			return &Value{Kind: IntegerKind, Scalar: strconv.FormatUint(readUint(bytes[8:]), 10)}, nil
+		default:
+			return &Value{Kind: IntegerKind, Scalar: strconv.FormatUint(readUint(bytes), 10)}, nil
+		}
+	case realMarker, dateMarker:
+		size := uint64(1) << info
+		if size != 4 && size != 8 || marker == dateMarker && size != 8 {
+			return nil, invalidBinary("invalid real size %d", size)
+		}
+		bytes, err := r.bytes(position+1, size)
+		if err != nil {
+			return nil, err
+		}
+		if marker == dateMarker {
+			seconds := math.Float64frombits(readUint(bytes))
+			return &Value{Kind: DateKind, Scalar: toTime(seconds).Format(dateFormat)}, nil
+		}
+		if size == 4 {
+			number := math.Float32frombits(uint32(readUint(bytes)))
+			return &Value{Kind: RealKind, Scalar: strconv.FormatFloat(float64(number), 'g', -1, 32)}, nil
+		}
+		number := math.Float64frombits(readUint(bytes))
+		return &Value{Kind: RealKind, Scalar: strconv.FormatFloat(number, 'g', -1, 64)}, nil
+	case dataMarker, asciiMarker, utf16Marker:
+		count, start, err := r.readCount(position, info)
+		if err != nil {
+			return nil, err
+		}
+		if marker == utf16Marker {
+			count *= 2
+		}
+		bytes, err := r.bytes(start, count)
+		if err != nil {
+			return nil, err
+		}
+		switch marker {
+		case dataMarker:
+			return &Value{Kind: DataKind, Scalar: base64.StdEncoding.EncodeToString(bytes)}, nil
+		case utf16Marker:
+			units := make([]uint16, len(bytes)/2)
+			for idx := range units {
+				units[idx] = binary.BigEndian.Uint16(bytes[2*idx:])
+			}
+			return &Value{Kind: StringKind, Scalar: string(utf16.Decode(units))}, nil
+		default:
+			return &Value{Kind: StringKind, Scalar: string(bytes)}, nil
+		}
+	case arrayMarker, dictMarker:
+		count, start, err := r.readCount(position, info)
+		if err != nil {
+			return nil, err
+		}
+		if marker == arrayMarker {
+			refs, err := r.readRefs(start, count)
+			if err != nil {
+				return nil, err
+			}
+			value := &Value{Kind: ArrayKind, Items: []*Value{}}
+			for _, itemRef := range refs {
+				item, err := r.readObject(itemRef)
+				if err != nil {
+					return nil, err
+				}
+				value.Items = append(value.Items, item)
+			}
+			return value, nil
+		}
+
+		refs, err := r.readRefs(start, 2*count)
+		if err != nil {
+			return nil, err
+		}
+		value := &Value{Kind: DictKind, Entries: []*Entry{}}
+		for idx := uint64(0); idx < count; idx++ {
+			key, err := r.readObject(refs[idx])
+			if err != nil {
+				return nil, err
+			}
+			if key.Kind != StringKind {
+				return nil, invalidBinary("dict keys must be strings")
+			}
+			entryValue, err := r.readObject(refs[count+idx])
+			if err != nil {
+				return nil, err
+			}
+			value.Entries = append(value.Entries, &Entry{Key: key.Scalar, Value: entryValue})
+		}
+		return value, nil
+	case uidMarker:
+		return nil, invalidBinary("UIDs of keyed archives are not supported")
+	default:
+		return nil, invalidBinary("unsupported object 0x%02x", markerBytes[0])
+	}
+}
+
+func decodeBinary(data []byte) (*Value, error) {
+	if len(data) < len(binaryMagic)+trailerSize {
+		return nil, invalidBinary("file too short")
+	}
+
+	trailer := data[len(data)-trailerSize:]
+	offsetSize, refSize := int(trailer[6]), int(trailer[7])
+	objectCount, topObject, tableOffset := readUint(trailer[8:16]), readUint(trailer[16:24]), readUint(trailer[24:32])
+	for _, size := range []int{offsetSize, refSize} {
+		if size != 1 && size != 2 && size != 4 && size != 8 {
+			return nil, invalidBinary("invalid trailer")
+		}
+	}
+
+	r := &binaryReader{data: data[:len(data)-trailerSize], refSize: refSize, visiting: map[uint64]bool{}}
+	if objectCount > uint64(len(data)) {
+		return nil, invalidBinary("invalid trailer")
+	}
+	table, err := r.bytes(tableOffset, objectCount*uint64(offsetSize))
+	if err != nil {
+		return nil, err
+	}
+	for idx := 0; idx < len(table); idx += offsetSize {
+		r.offsets = append(r.offsets, readUint(table[idx:idx+offsetSize]))
+	}
+
+	return r.readObject(topObject)
+}
+
+type binaryWriter struct {
+	objects [][]byte
+	refSize int
+}
+
+func countObjects(value *Value) uint64 {
+	count := uint64(1)
+	for _, item := range value.Items {
+		count += countObjects(item)
+	}
+	for _, entry := range value.Entries {
+		count += 1 + countObjects(entry.Value)
+	}
+
+	return count
+}
+
+func objectHeader(marker byte, count int) []byte {
+	if count < maxInfoValue {
+		return []byte{marker<<4 | byte(count)}
+	}
+
+	size := sizeFor(uint64(count))
+	header := []byte{marker<<4 | maxInfoValue, intMarker<<4 | byte(math.Log2(float64(size)))}
+
+	return putUint(header, uint64(count), size)
+}
+
+func encodeInteger(scalar string) []byte {
+	if number, err := strconv.ParseInt(scalar, 10, 64); err == nil {
+		if number < 0 {
+			return putUint([]byte{intMarker<<4 | 3}, uint64(number), 8)
+		}
+		size := sizeFor(uint64(number))
+		return putUint([]byte{intMarker<<4 | byte(math.Log2(float64(size)))}, uint64(number), size)
+	}
+
+	number, _ := strconv.ParseUint(scalar, 10, 64)
+	object := putUint([]byte{intMarker<<4 | 4}, 0, 8)
+
+	return putUint(object, number, 8)
+}
+
+func encodeString(scalar string) []byte {
+	ascii := true
+	for _, r := range scalar {
+		if r > 0x7F {
+			ascii = false
+		}
+	}
+	if ascii {
+		return append(objectHeader(asciiMarker, len(scalar)), scalar...)
+	}
+
+	units := utf16.Encode([]rune(scalar))
+	object := objectHeader(utf16Marker, len(units))
+	for _, unit := range units {
+		object = putUint(object, uint64(unit), 2)
+	}
+
+	return object
+}
+
+// add adds the objects of a value, its own object first, and returns its
+// reference
+func (w *binaryWriter) add(value *Value) (uint64, error) {
+	ref := uint64(len(w.objects))
+	w.objects = append(w.objects, nil)
+
+	var object []byte
+	switch value.Kind {
+	case StringKind:
+		object = encodeString(value.Scalar)
+	case IntegerKind:
+		object = encodeInteger(value.Scalar)
+	case RealKind:
+		number, _ := strconv.ParseFloat(value.Scalar, 64)
+		object = putUint([]byte{realMarker<<4 | 3}, math.Float64bits(number), 8)
+	case BoolKind:
+		object = []byte{0x08}
+		if value.Scalar == "true" {
+			object = []byte{0x09}
+		}
+	case DateKind:
+		date, err := time.Parse(dateFormat, value.Scalar)
+		if err != nil {
+			return 0, err
+		}
+		object = putUint([]byte{dateMarker<<4 | 3}, math.Float64bits(fromTime(date)), 8)
+	case DataKind:
+		data, err := base64.StdEncoding.DecodeString(value.Scalar)
+		if err != nil {
+			return 0, err
+		}
+		object = append(objectHeader(dataMarker, len(data)), data...)
+	case ArrayKind:
+		object = objectHeader(arrayMarker, len(value.Items))
+		for _, item := range value.Items {
+			itemRef, err := w.add(item)
+			if err != nil {
+				return 0, err
+			}
+			object = putUint(object, itemRef, w.refSize)
+		}
+	case DictKind:
+		object = objectHeader(dictMarker, len(value.Entries))
+		for _, entry := range value.Entries {
+			keyRef, _ := w.add(&Value{Kind: StringKind, Scalar: entry.Key})
+			object = putUint(object, keyRef, w.refSize)
+		}
+		for _, entry := range value.Entries {
+			valueRef, err := w.add(entry.Value)
+			if err != nil {
+				return 0, err
+			}
+			object = putUint(object, valueRef, w.refSize)
+		}
+	}
+	w.objects[ref] = object
+
+	return ref, nil
+}
+
+func encodeBinary(root *Value) ([]byte, error) {
+	count := countObjects(root)
+	w := &binaryWriter{refSize: sizeFor(count)}
+	if _, err := w.add(root); err != nil {
+		return nil, err
+	}
+
+	data := []byte(binaryMagic)
+	offsets := []uint64{}
+	for _, object := range w.objects {
+		offsets = append(offsets, uint64(len(data)))
+		data = append(data, object...)
+	}
+
+	tableOffset := uint64(len(data))
+	offsetSize := sizeFor(tableOffset)
+	for _, offset := range offsets {
+		data = putUint(data, offset, offsetSize)
+	}
+
+	data = append(data, 0, 0, 0, 0, 0, 0, byte(offsetSize), byte(w.refSize))
+	data = putUint(data, count, 8)
+	data = putUint(data, 0, 8)
+
+	return putUint(data, tableOffset, 8), nil
+}
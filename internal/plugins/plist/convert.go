@@ -0,0 +1,55 @@
+package plist
+
+import "github.com/einenlum/edicon/internal/core"
+
+var scalarTypes = map[ValueKind]core.ScalarType{
+	StringKind:  core.StringScalar,
+	IntegerKind: core.IntegerScalar,
+	RealKind:    core.FloatScalar,
+	BoolKind:    core.BoolScalar,
+	DateKind:    core.DateTimeScalar,
+	DataKind:    core.StringScalar,
+}
+
+var treeKinds = map[core.ScalarType]ValueKind{
+	core.IntegerScalar:  IntegerKind,
+	core.FloatScalar:    RealKind,
+	core.BoolScalar:     BoolKind,
+	core.DateTimeScalar: DateKind,
+}
+
+// WriteTree writes a tree as an XML property list. Untyped values are
+// strings.
+func (configurator PlistConfigurator) WriteTree(tree *core.Node, losses *core.Losses) (string, error) {
+	return serializeXML(fromNode(tree, losses)), nil
+}
+
+func fromNode(node *core.Node, losses *core.Losses) *Value {
+	switch node.Kind {
+	case core.MapNode, core.SectionNode:
+		value := &Value{Kind: DictKind}
+		for _, key := range node.Keys {
+			value.Entries = append(value.Entries, &Entry{Key: key, Value: fromNode(node.Get(key), losses)})
+		}
+		return value
+	case core.ListNode:
+		value := &Value{Kind: ArrayKind}
+		for _, item := range node.Items {
+			value.Items = append(value.Items, fromNode(item, losses))
+		}
+		return value
+	}
+
+	kind, typed := treeKinds[node.Type]
+	if !typed {
+		return &Value{Kind: StringKind, Scalar: node.Value}
+	}
+
+	scalar, err := normalizeScalar(kind, node.Value)
+	if err != nil {
+		losses.Add("%s is not a valid plist %s and is written as a string", node.Value, kind)
+		return &Value{Kind: StringKind, Scalar: node.Value}
+	}
+
+	return &Value{Kind: kind, Scalar: scalar}
+}
@@ -0,0 +1,109 @@
+package plist
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/xml"
+)
+
+// MatchesKeyPatterns marks the paths of the tree as the keys of the property
+// list
+func (configurator PlistConfigurator) MatchesKeyPatterns() {}
+
+// ReadDocument returns the document tree of the property list, data being
+// base64 strings. Nodes of binary property lists have no span.
+func (configurator PlistConfigurator) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	config, err := GetParsedPlistFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildDocument(config, losses), nil
+}
+
+type documentBuilder struct {
+	// document is nil for binary property lists
+	document *xml.XmlConfiguration
+	losses   *core.Losses
+}
+
+func buildDocument(config PlistConfiguration, losses *core.Losses) *core.Node {
+	if config.Document != nil && len(config.Document.Comments) > 0 {
+		losses.Add("comments are not converted")
+	}
+
+	builder := documentBuilder{document: config.Document, losses: losses}
+
+	return builder.toNode(config.Root, []string{})
+}
+
+func (builder documentBuilder) span(element *xml.Element) core.Span {
+	if builder.document == nil || element == nil {
+		return core.Span{}
+	}
+
+	content := builder.document.Content
+	return core.Span{
+		Start: strings.Count(content[:element.Span.Start], "\n") + 1,
+		End:   strings.Count(content[:element.Span.End], "\n") + 1,
+	}
+}
+
+// comments returns the comments between two positions of the file
+func (builder documentBuilder) comments(start int, end int) []string {
+	comments := []string{}
+	if builder.document == nil {
+		return comments
+	}
+
+	for _, comment := range builder.document.Comments {
+		if comment.Start >= start && comment.End <= end {
+			text := builder.document.Content[comment.Start:comment.End]
+			text = strings.TrimSuffix(strings.TrimPrefix(text, "<!--"), "-->")
+			comments = append(comments, strings.TrimSpace(text))
+		}
+	}
+
+	return comments
+}
+
+func (builder documentBuilder) toNode(value *Value, path []string) *core.Node {
+	var node *core.Node
+
+	switch value.Kind {
+	case DictKind:
+		node = core.NewMapNode()
+		previous := 0
+		if value.Element != nil {
+			previous = value.Element.Content.Start
+		}
+		for _, entry := range value.Entries {
+			child := builder.toNode(entry.Value, append(append([]string{}, path...), entry.Key))
+			if entry.KeyElement != nil {
+				child.Span.Start = builder.span(entry.KeyElement).Start
+				child.Comments = builder.comments(previous, entry.KeyElement.Span.Start)
+				previous = entry.Value.Element.Span.End
+			}
+			node.Set(entry.Key, child)
+		}
+	case ArrayKind:
+		node = core.NewListNode()
+		for idx, item := range value.Items {
+			node.Items = append(node.Items, builder.toNode(item, append(append([]string{}, path...), strconv.Itoa(idx))))
+		}
+	case DataKind:
+		builder.losses.Add("data values are converted to base64 strings")
+		fallthrough
+	default:
+		node = core.NewScalarNode(scalarTypes[value.Kind], value.Scalar)
+	}
+
+	node.Path = path
+	if value.Element != nil {
+		node.Span = builder.span(value.Element)
+	}
+
+	return node
+}
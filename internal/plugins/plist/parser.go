@@ -0,0 +1,158 @@
+package plist
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/plugins/xml"
+)
+
+const binaryMagic = "bplist00"
+
+func lineOf(content string, element *xml.Element) int {
+	return strings.Count(content[:element.Span.Start], "\n") + 1
+}
+
+// buildValue builds the value of an element of an XML property list
+func buildValue(content string, element *xml.Element) (*Value, error) {
+	invalid := func(format string, args ...interface{}) (*Value, error) {
+		message := fmt.Sprintf(format, args...)
+
+		return nil, errors.New(fmt.Sprintf("Invalid property list on line %d: %s", lineOf(content, element), message))
+	}
+
+	value := &Value{Element: element}
+	switch element.Name {
+	case "dict":
+		value.Kind = DictKind
+		value.Entries = []*Entry{}
+		children := element.Children
+		if len(children)%2 != 0 {
+			return invalid("a <key> without value in <dict>")
+		}
+		for idx := 0; idx < len(children); idx += 2 {
+			if children[idx].Name != "key" {
+				return invalid("expected <key>, found <%s>", children[idx].Name)
+			}
+			key, err := children[idx].Text(content)
+			if err != nil {
+				return invalid(err.Error())
+			}
+			for _, entry := range value.Entries {
+				if entry.Key == key {
+					return invalid("duplicate key %s", key)
+				}
+			}
+
+			entryValue, err := buildValue(content, children[idx+1])
+			if err != nil {
+				return nil, err
+			}
+			value.Entries = append(value.Entries, &Entry{key, children[idx], entryValue})
+		}
+	case "array":
+		value.Kind = ArrayKind
+		value.Items = []*Value{}
+		for _, child := range element.Children {
+			item, err := buildValue(content, child)
+			if err != nil {
+				return nil, err
+			}
+			value.Items = append(value.Items, item)
+		}
+	case "true", "false":
+		if len(element.Children) > 0 {
+			return invalid("<%s> cannot have child elements", element.Name)
+		}
+		value.Kind = BoolKind
+		value.Scalar = element.Name
+	default:
+		kind := StringKind
+		for tagKind, tag := range tags {
+			if tag == element.Name {
+				kind = tagKind
+			}
+		}
+		if tags[kind] != element.Name {
+			return invalid("unknown element <%s>", element.Name)
+		}
+
+		text, err := element.Text(content)
+		if err != nil {
+			return invalid(err.Error())
+		}
+		if kind == DataKind {
+			text = strings.Join(strings.Fields(text), "")
+			if _, err := base64.StdEncoding.DecodeString(text); err != nil {
+				return invalid("invalid base64 data")
+			}
+		} else if kind != StringKind {
+			if _, err := normalizeScalar(kind, text); err != nil {
+				return invalid("invalid %s %s", kind, text)
+			}
+		}
+		value.Kind = kind
+		value.Scalar = text
+	}
+
+	return value, nil
+}
+
+func parseXMLContent(content string) (*xml.XmlConfiguration, *Value, error) {
+	root, comments, err := xml.ParseXmlContent(content)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if root.Name != "plist" || len(root.Children) != 1 {
+		return nil, nil, errors.New("Invalid property list: expected a <plist> element with a single value")
+	}
+
+	value, err := buildValue(content, root.Children[0])
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return &xml.XmlConfiguration{Content: content, Root: root, Comments: comments}, value, nil
+}
+
+// ParsePlistContent parses a property list, binary if it starts with the
+// bplist00 magic number and XML otherwise
+func ParsePlistContent(content string) (PlistConfiguration, error) {
+	if strings.HasPrefix(content, binaryMagic) {
+		root, err := decodeBinary([]byte(content))
+		if err != nil {
+			return PlistConfiguration{}, err
+		}
+
+		return PlistConfiguration{Format: BinaryFormat, Root: root}, nil
+	}
+
+	document, root, err := parseXMLContent(content)
+	if err != nil {
+		return PlistConfiguration{}, err
+	}
+
+	return PlistConfiguration{Format: XMLFormat, Document: document, Root: root}, nil
+}
+
+func GetParsedPlistFile(filePath string) (PlistConfiguration, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return PlistConfiguration{}, err
+	}
+
+	config, err := ParsePlistContent(content)
+	if err != nil {
+		return PlistConfiguration{}, err
+	}
+	config.FilePath = filePath
+	if config.Document != nil {
+		config.Document.FilePath = filePath
+	}
+
+	return config, nil
+}
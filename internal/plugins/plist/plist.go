@@ -0,0 +1,339 @@
+package plist
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/xml"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+const xmlHeader = `<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+`
+
+// resolution is the value a key points to. A missing last key of a dict, or
+// the index following the last item of an array, is resolved to its parent
+// so that it can be added.
+type resolution struct {
+	Value   *Value
+	Parent  *Value
+	Index   int
+	Key     string
+	Missing bool
+}
+
+func OutputConfigFile(config *PlistConfiguration, outputType core.OutputType) string {
+	if config.Format == BinaryFormat {
+		return serializeXML(config.Root)
+	}
+
+	return xml.OutputConfigFile(config.Document, outputType)
+}
+
+// resolve follows the parts of a key: keys of dicts, and indexes of arrays
+// starting at 0
+func resolve(root *Value, parts []string) (resolution, error) {
+	current := resolution{Value: root}
+
+	for idx, part := range parts {
+		last := idx == len(parts)-1
+		value := current.Value
+
+		switch value.Kind {
+		case DictKind:
+			found := -1
+			for entryIdx, entry := range value.Entries {
+				if entry.Key == part {
+					found = entryIdx
+				}
+			}
+			if found == -1 {
+				if !last {
+					return resolution{}, errors.New("Key not found")
+				}
+				return resolution{Parent: value, Index: len(value.Entries), Key: part, Missing: true}, nil
+			}
+			current = resolution{Value: value.Entries[found].Value, Parent: value, Index: found, Key: part}
+		case ArrayKind:
+			index, err := strconv.Atoi(part)
+			if err != nil || index < 0 {
+				return resolution{}, errors.New(fmt.Sprintf("Key not found: %s is not an array index", part))
+			}
+			if index == len(value.Items) && last {
+				return resolution{Parent: value, Index: index, Key: part, Missing: true}, nil
+			}
+			if index >= len(value.Items) {
+				return resolution{}, errors.New("Key not found")
+			}
+			current = resolution{Value: value.Items[index], Parent: value, Index: index, Key: part}
+		default:
+			return resolution{}, errors.New("Key not found")
+		}
+	}
+
+	return current, nil
+}
+
+func GetParameterFromPath(notationStyle core.NotationStyle, filePath string, key string) (string, error) {
+	config, err := GetParsedPlistFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := core.DecomposeKey(notationStyle, key)
+	if err != nil {
+		return "", err
+	}
+	target, err := resolve(config.Root, path)
+	if err != nil {
+		return "", err
+	}
+	if target.Missing {
+		return "", errors.New("Key not found")
+	}
+
+	value := target.Value
+	if value.Kind == DictKind {
+		return "", errors.New(fmt.Sprintf("%s is a dict, use one of its keys", key))
+	}
+	if value.Kind == ArrayKind {
+		// Arrays of scalars are printed one item per line
+		items := []string{}
+		for _, item := range value.Items {
+			if !isScalar(item.Kind) {
+				return "", errors.New(fmt.Sprintf("%s is an array of %ss, use one of its indexes", key, item.Kind))
+			}
+			items = append(items, item.Scalar)
+		}
+
+		return strings.Join(items, "\n"), nil
+	}
+
+	return value.Scalar, nil
+}
+
+// EditConfigFile sets a scalar value, keeping its type unless valueType is
+// given. A missing key of a dict is added at its end, and an index equal to
+// the length of an array appends an item. New values are strings by default.
+func EditConfigFile(notationStyle core.NotationStyle, filePath string, key string, value string, valueType string) (*PlistConfiguration, error) {
+	config, err := GetParsedPlistFile(filePath)
+	if err != nil {
+		return &PlistConfiguration{}, err
+	}
+
+	path, err := core.DecomposeKey(notationStyle, key)
+	if err != nil {
+		return &PlistConfiguration{}, err
+	}
+	target, err := resolve(config.Root, path)
+	if err != nil {
+		return &PlistConfiguration{}, err
+	}
+
+	kind := StringKind
+	if valueType != "" {
+		kind, err = getValueKind(valueType)
+		if err != nil {
+			return &PlistConfiguration{}, err
+		}
+	} else if !target.Missing {
+		if !isScalar(target.Value.Kind) {
+			return &PlistConfiguration{}, errors.New(fmt.Sprintf("%s is a %s, use --type to replace it with a value", key, target.Value.Kind))
+		}
+		kind = target.Value.Kind
+	}
+
+	scalar, err := normalizeScalar(kind, value)
+	if err != nil {
+		return &PlistConfiguration{}, err
+	}
+
+	if config.Format == BinaryFormat {
+		newValue := &Value{Kind: kind, Scalar: scalar}
+		switch {
+		case !target.Missing:
+			*target.Value = *newValue
+		case target.Parent.Kind == DictKind:
+			target.Parent.Entries = append(target.Parent.Entries, &Entry{Key: target.Key, Value: newValue})
+		default:
+			target.Parent.Items = append(target.Parent.Items, newValue)
+		}
+
+		return &config, nil
+	}
+
+	if target.Missing {
+		config.insert(target, formatScalar(kind, scalar))
+	} else {
+		config.set(target.Value, kind, scalar)
+	}
+
+	return config.validate(key)
+}
+
+// RemoveFromConfigFile removes a key of a dict, or an item of an array
+func RemoveFromConfigFile(notationStyle core.NotationStyle, filePath string, key string) (*PlistConfiguration, error) {
+	config, err := GetParsedPlistFile(filePath)
+	if err != nil {
+		return &PlistConfiguration{}, err
+	}
+
+	path, err := core.DecomposeKey(notationStyle, key)
+	if err != nil {
+		return &PlistConfiguration{}, err
+	}
+	target, err := resolve(config.Root, path)
+	if err != nil {
+		return &PlistConfiguration{}, err
+	}
+	if target.Missing || target.Parent == nil {
+		return &PlistConfiguration{}, errors.New("Key not found")
+	}
+
+	parent := target.Parent
+	if config.Format == BinaryFormat {
+		if parent.Kind == DictKind {
+			parent.Entries = append(parent.Entries[:target.Index], parent.Entries[target.Index+1:]...)
+		} else {
+			parent.Items = append(parent.Items[:target.Index], parent.Items[target.Index+1:]...)
+		}
+
+		return &config, nil
+	}
+
+	start := target.Value.Element.Span.Start
+	if parent.Kind == DictKind {
+		start = parent.Entries[target.Index].KeyElement.Span.Start
+	}
+	content := config.Document.Content
+	if indent := lineIndent(content, start); start-len(indent) > 0 && content[start-len(indent)-1] == '\n' {
+		// Remove the whole lines of the value
+		start -= len(indent) + 1
+	}
+	config.replace(source.Span{Start: start, End: target.Value.Element.Span.End}, "")
+
+	return config.validate(key)
+}
+
+func (config *PlistConfiguration) validate(key string) (*PlistConfiguration, error) {
+	document, root, err := parseXMLContent(config.Document.Content)
+	if err != nil {
+		return &PlistConfiguration{}, errors.New(fmt.Sprintf("Refusing to set %s: %s", key, err.Error()))
+	}
+	document.FilePath = config.FilePath
+	config.Document = document
+	config.Root = root
+
+	return config, nil
+}
+
+func (config *PlistConfiguration) replace(span source.Span, replacement string) {
+	content := config.Document.Content
+	config.Document.Content = content[:span.Start] + replacement + content[span.End:]
+}
+
+// lineIndent returns the whitespace before position on its line, or an empty
+// string if something else comes before it
+func lineIndent(content string, position int) string {
+	start := position
+	for start > 0 && (content[start-1] == ' ' || content[start-1] == '\t') {
+		start--
+	}
+	if start > 0 && content[start-1] != '\n' {
+		return ""
+	}
+
+	return content[start:position]
+}
+
+// set replaces the text of a value of the same kind, keeping the whitespace
+// around it (e.g. for <data> spanning several lines), or the whole element
+func (config *PlistConfiguration) set(value *Value, kind ValueKind, scalar string) {
+	element := value.Element
+	if kind != value.Kind || kind == BoolKind || element.SelfClosing {
+		config.replace(element.Span, formatScalar(kind, scalar))
+		return
+	}
+
+	content := config.Document.Content
+	text := content[element.Content.Start:element.Content.End]
+	trimmed := strings.TrimSpace(text)
+	start := element.Content.Start + len(text) - len(strings.TrimLeft(text, " \t\r\n"))
+	if trimmed == "" {
+		start = element.Content.Start
+	}
+	config.replace(source.Span{Start: start, End: start + len(trimmed)}, escapeText(scalar))
+}
+
+// insert adds a value after the last key of a dict or the last item of an
+// array, with the same indentation
+func (config *PlistConfiguration) insert(target resolution, element string) {
+	parent := target.Parent
+	if parent.Kind == DictKind {
+		element = "<key>" + escapeText(target.Key) + "</key>\n{indent}" + element
+	}
+
+	content := config.Document.Content
+	var last *xml.Element
+	var indent string
+	if parent.Kind == DictKind && len(parent.Entries) > 0 {
+		entry := parent.Entries[len(parent.Entries)-1]
+		last, indent = entry.Value.Element, lineIndent(content, entry.KeyElement.Span.Start)
+	} else if parent.Kind == ArrayKind && len(parent.Items) > 0 {
+		last = parent.Items[len(parent.Items)-1].Element
+		indent = lineIndent(content, last.Span.Start)
+	}
+
+	if last != nil {
+		element = strings.ReplaceAll(element, "{indent}", indent)
+		config.replace(source.Span{Start: last.Span.End, End: last.Span.End}, "\n"+indent+element)
+		return
+	}
+
+	// An empty <dict/> or <array/>
+	parentIndent := lineIndent(content, parent.Element.Span.Start)
+	indent = parentIndent + "\t"
+	element = strings.ReplaceAll(element, "{indent}", indent)
+	tag := parent.Element.Name
+	config.replace(parent.Element.Span, "<"+tag+">\n"+indent+element+"\n"+parentIndent+"</"+tag+">")
+}
+
+// serializeXML returns a property list as written by Apple tools, indented
+// with tabs
+func serializeXML(root *Value) string {
+	var builder strings.Builder
+	builder.WriteString(xmlHeader)
+	writeValue(&builder, root, "")
+	builder.WriteString("</plist>\n")
+
+	return builder.String()
+}
+
+func writeValue(builder *strings.Builder, value *Value, indent string) {
+	switch {
+	case value.Kind == DictKind && len(value.Entries) > 0:
+		builder.WriteString(indent + "<dict>\n")
+		for _, entry := range value.Entries {
+			builder.WriteString(indent + "\t<key>" + escapeText(entry.Key) + "</key>\n")
+			writeValue(builder, entry.Value, indent+"\t")
+		}
+		builder.WriteString(indent + "</dict>\n")
+	case value.Kind == ArrayKind && len(value.Items) > 0:
+		builder.WriteString(indent + "<array>\n")
+		for _, item := range value.Items {
+			writeValue(builder, item, indent+"\t")
+		}
+		builder.WriteString(indent + "</array>\n")
+	case !isScalar(value.Kind):
+		builder.WriteString(indent + "<" + tags[value.Kind] + "/>\n")
+	case value.Kind == DataKind:
+		builder.WriteString(indent + "<data>\n" + indent + value.Scalar + "\n" + indent + "</data>\n")
+	default:
+		builder.WriteString(indent + formatScalar(value.Kind, value.Scalar) + "\n")
+	}
+}
@@ -0,0 +1,248 @@
+package plist
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/testutil"
+)
+
+const XML_FILE_PATH = "../../../data/plist/Info.plist"
+const BINARY_FILE_PATH = "../../../data/plist/Info.bplist"
+
+func TestGetParameter(t *testing.T) {
+	cases := map[string]string{
+		"CFBundleShortVersionString":              "1.2.0",
+		"CFBundleVersion":                         "42",
+		"NSHighResolutionCapable":                 "true",
+		"UIScale":                                 "1.5",
+		"BuildDate":                               "2024-01-15T10:30:00Z",
+		"Signature":                               "3q2+7w==",
+		"CFBundleURLTypes.0.CFBundleURLSchemes":   "example\nexample-dev",
+		"CFBundleURLTypes.0.CFBundleURLSchemes.1": "example-dev",
+		"CFBundleCopyright":                       "© 2024 Example & Co",
+	}
+
+	for _, filePath := range []string{XML_FILE_PATH, BINARY_FILE_PATH} {
+		for key, expectedValue := range cases {
+			t.Run("it gets existing parameter "+key+" of "+filePath, func(t *testing.T) {
+				value, err := GetParameterFromPath(core.DotNotation, filePath, key)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if value != expectedValue {
+					t.Fatal(fmt.Sprintf("Expected %s got %s", expectedValue, value))
+				}
+			})
+		}
+
+		t.Run("it gets a parameter with the brackets notation of "+filePath, func(t *testing.T) {
+			key := "NSAppTransportSecurity[NSExceptionDomains][example.com][NSIncludesSubdomains]"
+			value, err := GetParameterFromPath(core.BracketsNotation, filePath, key)
+			if err != nil || value != "true" {
+				t.Fatal(fmt.Sprintf("Expected true got %s (%v)", value, err))
+			}
+		})
+	}
+
+	errorCases := []string{
+		"Missing",
+		"CFBundleURLTypes",
+		"NSAppTransportSecurity",
+		"CFBundleURLTypes.1",
+		"CFBundleURLTypes.first",
+		"CFBundleVersion.foo",
+	}
+	for _, key := range errorCases {
+		t.Run("it does not get "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, XML_FILE_PATH, key)
+			if err == nil {
+				t.Error("Should fail. Got " + value + " instead")
+			}
+		})
+	}
+}
+
+type EditTestElement struct {
+	key       string
+	value     string
+	valueType string
+	old       string
+	new       string
+}
+
+func TestEditParameter(t *testing.T) {
+	cases := []EditTestElement{
+		{"CFBundleShortVersionString", "1.3.0", "", "<string>1.2.0</string>", "<string>1.3.0</string>"},
+		{"CFBundleVersion", "43", "", "<integer>42</integer>", "<integer>43</integer>"},
+		{"NSHighResolutionCapable", "FALSE", "", "<true/>\n\t<key>UIScale", "<false/>\n\t<key>UIScale"},
+		{"UIScale", "2", "", "<real>1.5</real>", "<real>2</real>"},
+		{"BuildDate", "2024-02-01T08:00:00+01:00", "", "2024-01-15T10:30:00Z", "2024-02-01T07:00:00Z"},
+		{"Signature", "AAEC", "", "\t3q2+7w==\n", "\tAAEC\n"},
+		{"CFBundleURLTypes.0.CFBundleURLSchemes.1", "example-beta", "", "<string>example-dev</string>", "<string>example-beta</string>"},
+		{
+			"CFBundleURLTypes.0.CFBundleURLSchemes.2", "example-qa", "",
+			"<string>example-dev</string>",
+			"<string>example-dev</string>\n\t\t\t\t<string>example-qa</string>",
+		},
+		{
+			"NSCameraUsageDescription", "Scan codes", "",
+			"<string>© 2024 Example &amp; Co</string>\n",
+			"<string>© 2024 Example &amp; Co</string>\n\t<key>NSCameraUsageDescription</key>\n\t<string>Scan codes</string>\n",
+		},
+		{"LSEnvironment.PATH", "/usr/bin", "", "<dict/>", "<dict>\n\t\t<key>PATH</key>\n\t\t<string>/usr/bin</string>\n\t</dict>"},
+		{"CFBundleCopyright", "A < B", "", "<string>© 2024 Example &amp; Co</string>", "<string>A &lt; B</string>"},
+		{"CFBundleVersion", "43", "string", "<integer>42</integer>", "<string>43</string>"},
+		{"NSHighResolutionCapable", "1", "integer", "<true/>\n\t<key>UIScale", "<integer>1</integer>\n\t<key>UIScale"},
+	}
+
+	for _, element := range cases {
+		t.Run("it sets "+element.key+" to "+element.value, func(t *testing.T) {
+			config, err := EditConfigFile(core.DotNotation, XML_FILE_PATH, element.key, element.value, element.valueType)
+			testutil.AssertEditedOutput(t, XML_FILE_PATH, config, err, element.old, element.new)
+		})
+	}
+
+	errorCases := []EditTestElement{
+		{"CFBundleVersion", "abc", "", "", ""},
+		{"UIScale", "fast", "", "", ""},
+		{"NSHighResolutionCapable", "yes", "", "", ""},
+		{"BuildDate", "yesterday", "", "", ""},
+		{"Signature", "not base64!", "", "", ""},
+		{"CFBundleURLTypes", "x", "", "", ""},
+		{"Missing.Key", "x", "", "", ""},
+		{"CFBundleVersion", "x", "dict", "", ""},
+	}
+	for _, element := range errorCases {
+		t.Run("it refuses to set "+element.key+" to "+element.value, func(t *testing.T) {
+			if _, err := EditConfigFile(core.DotNotation, XML_FILE_PATH, element.key, element.value, element.valueType); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestUnsetParameter(t *testing.T) {
+	cases := []EditTestElement{
+		{"CFBundleVersion", "", "", "\n\t<key>CFBundleVersion</key>\n\t<integer>42</integer>", ""},
+		{"CFBundleURLTypes.0.CFBundleURLSchemes.0", "", "", "\n\t\t\t\t<string>example</string>", ""},
+		{"Signature", "", "", "\n\t<key>Signature</key>\n\t<data>\n\t3q2+7w==\n\t</data>", ""},
+	}
+
+	for _, element := range cases {
+		t.Run("it unsets "+element.key, func(t *testing.T) {
+			config, err := RemoveFromConfigFile(core.DotNotation, XML_FILE_PATH, element.key)
+			testutil.AssertEditedOutput(t, XML_FILE_PATH, config, err, element.old, element.new)
+		})
+	}
+
+	for _, key := range []string{"Missing", "CFBundleURLTypes.3"} {
+		t.Run("it does not unset "+key, func(t *testing.T) {
+			if _, err := RemoveFromConfigFile(core.DotNotation, XML_FILE_PATH, key); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestBinaryPlist(t *testing.T) {
+	t.Run("it prints a binary property list as XML", func(t *testing.T) {
+		config, err := GetParsedPlistFile(BINARY_FILE_PATH)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		original, _ := io.GetFileContents(XML_FILE_PATH)
+		expected := strings.Replace(original, "\t<!-- Minimum macOS version -->\n", "", 1)
+		if output := OutputConfigFile(&config, core.FullOutput); output != expected {
+			t.Fatal(fmt.Sprintf("Expected:\n%s\nGot:\n%s", expected, output))
+		}
+	})
+
+	writeAndGet := func(t *testing.T, config *PlistConfiguration, err error, key string, expectedValue string) {
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		filePath, err := io.GenerateRandomTempFilePath()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err = config.WriteToFile(filePath, core.FullOutput); err != nil {
+			t.Fatal(err)
+		}
+
+		written, err := GetParsedPlistFile(filePath)
+		if err != nil || written.Format != BinaryFormat {
+			t.Fatal(fmt.Sprintf("Expected a binary property list (%v)", err))
+		}
+		for checkedKey, checkedValue := range map[string]string{key: expectedValue, "BuildDate": "2024-01-15T10:30:00Z"} {
+			value, err := GetParameterFromPath(core.DotNotation, filePath, checkedKey)
+			if err != nil || value != checkedValue {
+				t.Fatal(fmt.Sprintf("Expected %s got %s (%v)", checkedValue, value, err))
+			}
+		}
+	}
+
+	cases := []EditTestElement{
+		{"CFBundleShortVersionString", "1.3.0", "", "", ""},
+		{"CFBundleCopyright", "© 2025 Example", "", "", ""},
+		{"CFBundleVersion", "-1", "", "", ""},
+		{"CFBundleVersion", "18446744073709551615", "", "", ""},
+		{"UIScale", "0.25", "", "", ""},
+		{"NSCameraUsageDescription", "Scan codes", "", "", ""},
+		{"CFBundleURLTypes.0.CFBundleURLSchemes.2", "example-qa", "", "", ""},
+		{"LSEnvironment.PATH", "/usr/bin", "", "", ""},
+	}
+	for _, element := range cases {
+		t.Run("it writes "+element.key+" to a binary property list", func(t *testing.T) {
+			config, err := EditConfigFile(core.DotNotation, BINARY_FILE_PATH, element.key, element.value, element.valueType)
+			writeAndGet(t, config, err, element.key, element.value)
+		})
+	}
+
+	t.Run("it unsets a value of a binary property list", func(t *testing.T) {
+		config, err := RemoveFromConfigFile(core.DotNotation, BINARY_FILE_PATH, "CFBundleURLTypes.0.CFBundleURLSchemes.0")
+		writeAndGet(t, config, err, "CFBundleURLTypes.0.CFBundleURLSchemes", "example-dev")
+	})
+
+	t.Run("it keeps the order of the keys", func(t *testing.T) {
+		config, err := EditConfigFile(core.DotNotation, BINARY_FILE_PATH, "NSCameraUsageDescription", "Scan codes", "")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		original, _ := GetParsedPlistFile(BINARY_FILE_PATH)
+		ending := "</dict>\n</plist>\n"
+		expected := strings.TrimSuffix(serializeXML(original.Root), ending) +
+			"\t<key>NSCameraUsageDescription</key>\n\t<string>Scan codes</string>\n" + ending
+		if output := serializeXML(config.Root); output != expected {
+			t.Fatal(fmt.Sprintf("Expected:\n%s\nGot:\n%s", expected, output))
+		}
+	})
+}
+
+func TestParseInvalidPlist(t *testing.T) {
+	cases := []string{
+		"<plist><dict><key>a</key></dict></plist>",
+		"<plist><dict><string>a</string><string>b</string></dict></plist>",
+		"<plist><dict><key>a</key><true/><key>a</key><false/></dict></plist>",
+		"<plist><integer>x</integer></plist>",
+		"<plist><data>!!</data></plist>",
+		"<plist><foo/></plist>",
+		"<dict/>",
+		"bplist00garbage",
+	}
+
+	for _, content := range cases {
+		t.Run("it rejects "+content, func(t *testing.T) {
+			if _, err := ParsePlistContent(content); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,124 @@
+package plist
+
+import (
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/plugins/xml"
+)
+
+// Format is the encoding of the property list, kept when writing it back
+type Format int
+
+const (
+	XMLFormat Format = iota
+	BinaryFormat
+)
+
+type ValueKind int
+
+const (
+	StringKind ValueKind = iota
+	IntegerKind
+	RealKind
+	BoolKind
+	DateKind
+	DataKind
+	ArrayKind
+	DictKind
+)
+
+type Value struct {
+	Kind ValueKind
+	// Scalar is the value of strings, numbers, booleans ("true" or "false"),
+	// dates (ISO 8601, in UTC) and data (base64)
+	Scalar  string
+	Items   []*Value
+	Entries []*Entry
+	// Element is the element of the value in XML property lists
+	Element *xml.Element
+}
+
+// Entry is a key of a dict, in the order of the file
+type Entry struct {
+	Key        string
+	KeyElement *xml.Element
+	Value      *Value
+}
+
+type PlistConfiguration struct {
+	Format Format
+	// Document is the XML document of XML property lists, edited in place
+	// to keep its formatting
+	Document *xml.XmlConfiguration
+	Root     *Value
+	FilePath string
+}
+
+// OutputFile returns the XML property list. Binary property lists are
+// printed as XML, and only written in binary.
+func (config *PlistConfiguration) OutputFile(outputType core.OutputType) (string, error) {
+	return OutputConfigFile(config, outputType), nil
+}
+
+func (config *PlistConfiguration) WriteToFile(filepath string, outputType core.OutputType) error {
+	if config.Format == BinaryFormat {
+		content, err := encodeBinary(config.Root)
+		if err != nil {
+			return err
+		}
+
+		return io.WriteFileContents(filepath, string(content))
+	}
+
+	output, err := config.OutputFile(outputType)
+	if err != nil {
+		return err
+	}
+
+	return io.WriteFileContents(filepath, output)
+}
+
+type PlistConfigurator struct{}
+
+func (configurator PlistConfigurator) GetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (string, error) {
+	return GetParameterFromPath(notationStyle, filePath, key)
+}
+
+func (configurator PlistConfigurator) SetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, ""))
+}
+
+func (configurator PlistConfigurator) SetTypedParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+	valueType string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, valueType))
+}
+
+func (configurator PlistConfigurator) UnsetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (core.Configuration, error) {
+	return toConfiguration(RemoveFromConfigFile(notationStyle, filePath, key))
+}
+
+func toConfiguration(config *PlistConfiguration, err error) (core.Configuration, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,126 @@
+package plist
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const dateFormat = "2006-01-02T15:04:05Z"
+
+// referenceDate is the origin of the dates of binary property lists
+var referenceDate = time.Date(2001, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+var valueKinds = map[string]ValueKind{
+	"string":  StringKind,
+	"integer": IntegerKind,
+	"int":     IntegerKind,
+	"real":    RealKind,
+	"float":   RealKind,
+	"bool":    BoolKind,
+	"boolean": BoolKind,
+	"date":    DateKind,
+	"data":    DataKind,
+}
+
+// tags are the XML elements of the scalar kinds, booleans being <true/> or
+// <false/>
+var tags = map[ValueKind]string{
+	StringKind:  "string",
+	IntegerKind: "integer",
+	RealKind:    "real",
+	DateKind:    "date",
+	DataKind:    "data",
+	ArrayKind:   "array",
+	DictKind:    "dict",
+}
+
+func (kind ValueKind) String() string {
+	if kind == BoolKind {
+		return "bool"
+	}
+
+	return tags[kind]
+}
+
+func getValueKind(valueType string) (ValueKind, error) {
+	kind, ok := valueKinds[strings.ToLower(valueType)]
+	if !ok {
+		return 0, errors.New(fmt.Sprintf("Unknown plist type: %s, expected string, integer, real, bool, date or data", valueType))
+	}
+
+	return kind, nil
+}
+
+func isScalar(kind ValueKind) bool {
+	return kind != ArrayKind && kind != DictKind
+}
+
+// normalizeScalar checks a value against its kind and returns it as stored in
+// Value.Scalar
+func normalizeScalar(kind ValueKind, value string) (string, error) {
+	invalid := func() (string, error) {
+		return "", errors.New(fmt.Sprintf("%q is not a valid plist %s, use --type to change the type of the value", value, kind))
+	}
+
+	switch kind {
+	case IntegerKind:
+		if number, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return strconv.FormatInt(number, 10), nil
+		}
+		if number, err := strconv.ParseUint(value, 10, 64); err == nil {
+			return strconv.FormatUint(number, 10), nil
+		}
+		return invalid()
+	case RealKind:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return invalid()
+		}
+	case BoolKind:
+		lowered := strings.ToLower(value)
+		if lowered != "true" && lowered != "false" {
+			return invalid()
+		}
+		return lowered, nil
+	case DateKind:
+		date, err := time.Parse(time.RFC3339, value)
+		if err != nil {
+			return invalid()
+		}
+		return date.UTC().Format(dateFormat), nil
+	case DataKind:
+		data, err := base64.StdEncoding.DecodeString(strings.Join(strings.Fields(value), ""))
+		if err != nil {
+			return invalid()
+		}
+		return base64.StdEncoding.EncodeToString(data), nil
+	}
+
+	return value, nil
+}
+
+func escapeText(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+// formatScalar returns the XML element of a scalar value
+func formatScalar(kind ValueKind, scalar string) string {
+	if kind == BoolKind {
+		return "<" + scalar + "/>"
+	}
+
+	return "<" + tags[kind] + ">" + escapeText(scalar) + "</" + tags[kind] + ">"
+}
+
+// toTime and fromTime convert dates from and to the seconds since the
+// reference date used by binary property lists
+func toTime(seconds float64) time.Time {
+	return referenceDate.Add(time.Duration(seconds * float64(time.Second)))
+}
+
+func fromTime(date time.Time) float64 {
+	return float64(date.Unix()-referenceDate.Unix()) + float64(date.Nanosecond())/1e9
+}
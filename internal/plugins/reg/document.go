@@ -0,0 +1,127 @@
+package reg
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+// ReadDocument returns the document tree of the values in effect once the
+// file is imported: the registry keys are the keys of the tree
+// (HKEY_...\Path), their values being keyed by name, or by @ for the default
+// value. Numbers are integers and multi_string values lists.
+func (configurator RegConfigurator) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	config, err := GetParsedRegFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := core.NewMapNode()
+	tree.Path = []string{}
+
+	for _, section := range config.Sections {
+		if section.Deleted || config.findSection(KeyPath{Path: section.Path}) == nil {
+			losses.Add("deleted keys and values are not converted")
+			continue
+		}
+
+		path := normalizePath(section.Path)
+		sectionNode := findKey(tree, path)
+		if sectionNode == nil {
+			sectionNode = core.NewMapNode()
+			sectionNode.Kind = core.SectionNode
+			sectionNode.Path = []string{path}
+			sectionNode.Span = core.Span{Start: section.Line + 1, End: section.Line + 1}
+			sectionNode.Comments = config.commentsAbove(section.Line)
+			tree.Set(path, sectionNode)
+		}
+
+		for _, value := range section.Values {
+			if value.Type == DeleteType {
+				losses.Add("deleted keys and values are not converted")
+				continue
+			}
+
+			keyPath := KeyPath{Path: section.Path, Name: value.Name, Default: value.Default}
+			if config.findValue(keyPath) != value {
+				continue
+			}
+
+			name := value.Name
+			if value.Default {
+				name = "@"
+			}
+			node, err := config.readValue(value, []string{path, name}, losses)
+			if err != nil {
+				return nil, err
+			}
+			node.Comments = config.commentsAbove(value.Start)
+			sectionNode.Set(name, node)
+			if value.End > sectionNode.Span.End {
+				sectionNode.Span.End = value.End
+			}
+		}
+	}
+
+	return tree, nil
+}
+
+// findKey returns the node of a registry key, key names being case
+// insensitive
+func findKey(tree *core.Node, path string) *core.Node {
+	for _, key := range tree.Keys {
+		if strings.EqualFold(key, path) {
+			return tree.Get(key)
+		}
+	}
+
+	return nil
+}
+
+func (config *RegConfiguration) readValue(value *Value, path []string, losses *core.Losses) (*core.Node, error) {
+	data, err := decodeData(value, config.isUnicode())
+	if err != nil {
+		return nil, err
+	}
+
+	var node *core.Node
+	switch value.Type {
+	case StringType, ExpandStringType:
+		node = core.NewScalarNode(core.StringScalar, data)
+	case DwordType, QwordType:
+		node = core.NewScalarNode(core.IntegerScalar, data)
+	case MultiStringType:
+		node = core.NewListNode()
+		if data != "" {
+			for idx, itemValue := range strings.Split(data, "\n") {
+				item := core.NewScalarNode(core.StringScalar, itemValue)
+				item.Path = append(append([]string{}, path...), strconv.Itoa(idx))
+				item.Span = core.Span{Start: value.Start + 1, End: value.End}
+				node.Items = append(node.Items, item)
+			}
+		}
+	default:
+		losses.Add("binary values are converted to their bytes in hexadecimal")
+		node = core.NewScalarNode(core.UntypedScalar, data)
+	}
+	node.Path = path
+	node.Span = core.Span{Start: value.Start + 1, End: value.End}
+
+	return node, nil
+}
+
+// commentsAbove returns the comment lines right above the line at index
+// lineIndex
+func (config *RegConfiguration) commentsAbove(lineIndex int) []string {
+	comments := []string{}
+	for idx := lineIndex - 1; idx >= 0; idx-- {
+		trimmedLine := strings.TrimSpace(config.Lines[idx])
+		if !strings.HasPrefix(trimmedLine, ";") {
+			break
+		}
+		comments = append([]string{strings.TrimSpace(trimmedLine[1:])}, comments...)
+	}
+
+	return comments
+}
@@ -0,0 +1,63 @@
+package reg
+
+import (
+	"encoding/binary"
+	"errors"
+	"unicode/utf16"
+)
+
+var (
+	utf8BOM    = "\xef\xbb\xbf"
+	utf16LEBOM = "\xff\xfe"
+)
+
+// decodeContent returns the content of the file as UTF-8, and its encoding.
+// regedit exports UTF-16LE files with a BOM, UTF-8 files are also accepted.
+func decodeContent(raw string) (string, Encoding, error) {
+	switch {
+	case len(raw) >= 2 && raw[:2] == utf16LEBOM:
+		units, err := toUTF16(raw[2:])
+		if err != nil {
+			return "", UTF16LE, err
+		}
+
+		return string(utf16.Decode(units)), UTF16LE, nil
+	case len(raw) >= 3 && raw[:3] == utf8BOM:
+		return raw[3:], UTF8BOM, nil
+	default:
+		return raw, UTF8, nil
+	}
+}
+
+func encodeContent(content string, encoding Encoding) string {
+	switch encoding {
+	case UTF16LE:
+		return utf16LEBOM + string(fromUTF16(utf16.Encode([]rune(content))))
+	case UTF8BOM:
+		return utf8BOM + content
+	default:
+		return content
+	}
+}
+
+func toUTF16(raw string) ([]uint16, error) {
+	if len(raw)%2 != 0 {
+		return nil, errors.New("Invalid UTF-16 content: odd number of bytes")
+	}
+
+	units := make([]uint16, len(raw)/2)
+	for i := range units {
+		units[i] = binary.LittleEndian.Uint16([]byte(raw[2*i : 2*i+2]))
+	}
+
+	return units, nil
+}
+
+func fromUTF16(units []uint16) []byte {
+	bytes := make([]byte, 2*len(units))
+	for i, unit := range units {
+		binary.LittleEndian.PutUint16(bytes[2*i:], unit)
+	}
+
+	return bytes
+}
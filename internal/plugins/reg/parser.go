@@ -0,0 +1,195 @@
+package reg
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/io"
+)
+
+var (
+	dwordRegexp     = regexp.MustCompile(`^dword:([0-9a-fA-F]{1,8})$`)
+	hexPrefixRegexp = regexp.MustCompile(`^hex(?:\(([0-9a-fA-F]{1,8})\))?:`)
+	hexDataRegexp   = regexp.MustCompile(`^([0-9a-fA-F]{1,2}(,[0-9a-fA-F]{1,2})*)?$`)
+)
+
+// parseQuoted parses the quoted string starting at start, where only "\\" and
+// "\"" are escaped, and returns its content and the position following it
+func parseQuoted(lineString string, start int) (string, int, error) {
+	var builder strings.Builder
+	for pos := start + 1; pos < len(lineString); pos++ {
+		switch lineString[pos] {
+		case '\\':
+			if pos+1 < len(lineString) {
+				pos++
+			}
+			builder.WriteByte(lineString[pos])
+		case '"':
+			return builder.String(), pos + 1, nil
+		default:
+			builder.WriteByte(lineString[pos])
+		}
+	}
+
+	return "", 0, errors.New("unterminated string")
+}
+
+// parseValue parses the value starting on the line at index start, and the
+// continuation lines of hexadecimal values
+func parseValue(lines []string, start int) (*Value, error) {
+	lineString := strings.TrimLeft(lines[start], " \t")
+	value := &Value{Start: start, End: start + 1}
+
+	var rest string
+	switch {
+	case strings.HasPrefix(lineString, "@"):
+		value.Default = true
+		rest = lineString[1:]
+	case strings.HasPrefix(lineString, `"`):
+		name, end, err := parseQuoted(lineString, 0)
+		if err != nil {
+			return nil, err
+		}
+		value.Name = name
+		rest = lineString[end:]
+	default:
+		return nil, errors.New(fmt.Sprintf("unexpected %q", strings.TrimSpace(lineString)))
+	}
+
+	rest = strings.TrimLeft(rest, " \t")
+	if !strings.HasPrefix(rest, "=") {
+		return nil, errors.New("missing \"=\" after the value name")
+	}
+	rest = strings.TrimSpace(rest[1:])
+
+	switch {
+	case rest == "-":
+		value.Type = DeleteType
+	case strings.HasPrefix(rest, `"`):
+		data, end, err := parseQuoted(rest, 0)
+		if err != nil {
+			return nil, err
+		}
+		if strings.TrimSpace(rest[end:]) != "" {
+			return nil, errors.New(fmt.Sprintf("unexpected %q after the string", strings.TrimSpace(rest[end:])))
+		}
+		value.Type = StringType
+		value.Data = data
+	case dwordRegexp.MatchString(rest):
+		value.Type = DwordType
+		value.Data = dwordRegexp.FindStringSubmatch(rest)[1]
+	case hexPrefixRegexp.MatchString(rest):
+		matches := hexPrefixRegexp.FindStringSubmatch(rest)
+		value.Code = 3
+		if matches[1] != "" {
+			code, _ := strconv.ParseUint(matches[1], 16, 32)
+			value.Code = int(code)
+		}
+		value.Type = typeOfCode(value.Code)
+
+		data := rest[len(matches[0]):]
+		for strings.HasSuffix(data, `\`) {
+			if value.End == len(lines) {
+				return nil, errors.New("unterminated hexadecimal value")
+			}
+			data = data[:len(data)-1] + strings.TrimSpace(lines[value.End])
+			value.End++
+		}
+		data = strings.TrimSuffix(strings.Join(strings.Fields(data), ""), ",")
+		if !hexDataRegexp.MatchString(data) {
+			return nil, errors.New(fmt.Sprintf("invalid hexadecimal data %s", data))
+		}
+		value.Data = data
+	default:
+		return nil, errors.New(fmt.Sprintf("invalid data %s", rest))
+	}
+
+	return value, nil
+}
+
+// ParseRegContent parses a .reg file exported by regedit, already decoded
+// to UTF-8
+func ParseRegContent(content string) (RegConfiguration, error) {
+	config := RegConfiguration{LineEnding: "\n", Sections: []*Section{}}
+	if strings.Contains(content, "\r\n") {
+		config.LineEnding = "\r\n"
+	}
+	config.Lines = strings.Split(content, config.LineEnding)
+
+	var section *Section
+	for idx := 0; idx < len(config.Lines); idx++ {
+		lineNumber := idx + 1
+		trimmedLine := strings.TrimSpace(config.Lines[idx])
+
+		if config.Header == "" {
+			if trimmedLine == "" {
+				continue
+			}
+			if trimmedLine != Version5Header && trimmedLine != Version4Header {
+				return RegConfiguration{}, errors.New(fmt.Sprintf(
+					"Invalid registry file on line %d: expected the %q header", lineNumber, Version5Header,
+				))
+			}
+			config.Header = trimmedLine
+			continue
+		}
+
+		switch {
+		case trimmedLine == "" || strings.HasPrefix(trimmedLine, ";"):
+		case strings.HasPrefix(trimmedLine, "["):
+			if !strings.HasSuffix(trimmedLine, "]") {
+				return RegConfiguration{}, errors.New(fmt.Sprintf("Invalid registry file on line %d: missing \"]\"", lineNumber))
+			}
+			path := trimmedLine[1 : len(trimmedLine)-1]
+			section = &Section{Path: strings.TrimPrefix(path, "-"), Deleted: strings.HasPrefix(path, "-"), Line: idx, Values: []*Value{}}
+			if !isValidRoot(section.Path) {
+				return RegConfiguration{}, errors.New(fmt.Sprintf("Invalid registry file on line %d: unknown root key in %s", lineNumber, section.Path))
+			}
+			config.Sections = append(config.Sections, section)
+		default:
+			if section == nil {
+				return RegConfiguration{}, errors.New(fmt.Sprintf("Invalid registry file on line %d: value outside of a key", lineNumber))
+			}
+			if section.Deleted {
+				return RegConfiguration{}, errors.New(fmt.Sprintf("Invalid registry file on line %d: value in a deleted key", lineNumber))
+			}
+
+			value, err := parseValue(config.Lines, idx)
+			if err != nil {
+				return RegConfiguration{}, errors.New(fmt.Sprintf("Invalid registry file on line %d: %s", lineNumber, err.Error()))
+			}
+			section.Values = append(section.Values, value)
+			idx = value.End - 1
+		}
+	}
+
+	if config.Header == "" {
+		return RegConfiguration{}, errors.New(fmt.Sprintf("Invalid registry file: missing the %q header", Version5Header))
+	}
+
+	return config, nil
+}
+
+func GetParsedRegFile(filePath string) (RegConfiguration, error) {
+	raw, err := io.GetFileContents(filePath)
+	if err != nil {
+		return RegConfiguration{}, err
+	}
+
+	content, encoding, err := decodeContent(raw)
+	if err != nil {
+		return RegConfiguration{}, err
+	}
+
+	config, err := ParseRegContent(content)
+	if err != nil {
+		return RegConfiguration{}, err
+	}
+	config.Encoding = encoding
+	config.FilePath = filePath
+
+	return config, nil
+}
@@ -0,0 +1,335 @@
+package reg
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+// maxLineLength is the length at which regedit wraps hexadecimal values,
+// backslash included
+const maxLineLength = 80
+
+var rootKeys = map[string]string{
+	"HKLM": "HKEY_LOCAL_MACHINE",
+	"HKCU": "HKEY_CURRENT_USER",
+	"HKCR": "HKEY_CLASSES_ROOT",
+	"HKU":  "HKEY_USERS",
+	"HKCC": "HKEY_CURRENT_CONFIG",
+}
+
+// KeyPath is a value of a registry key: HKEY_...\Path.Name, or
+// HKEY_...\Path.@ for its default value
+type KeyPath struct {
+	Path    string
+	Name    string
+	Default bool
+}
+
+func OutputConfigFile(config *RegConfiguration, outputType core.OutputType) string {
+	if outputType == core.MeaningFullOutput {
+		lines := []string{config.Header}
+		for _, line := range config.Lines {
+			trimmedLine := strings.TrimSpace(line)
+			if trimmedLine != "" && !strings.HasPrefix(trimmedLine, ";") && trimmedLine != config.Header {
+				lines = append(lines, line)
+			}
+		}
+
+		return strings.Join(lines, config.LineEnding) + config.LineEnding
+	}
+
+	return strings.Join(config.Lines, config.LineEnding)
+}
+
+// normalizePath expands the abbreviated root key of a path (HKLM\Software)
+func normalizePath(path string) string {
+	root, rest, found := strings.Cut(path, `\`)
+	if full, ok := rootKeys[strings.ToUpper(root)]; ok {
+		root = full
+	}
+	if !found {
+		return root
+	}
+
+	return root + `\` + rest
+}
+
+func isValidRoot(path string) bool {
+	root, _, _ := strings.Cut(normalizePath(path), `\`)
+	for _, full := range rootKeys {
+		if strings.EqualFold(root, full) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isSubPath returns whether path is parent or one of its subkeys, key names
+// being case insensitive
+func isSubPath(path string, parent string) bool {
+	path = strings.ToLower(normalizePath(path))
+	parent = strings.ToLower(normalizePath(parent))
+
+	return path == parent || strings.HasPrefix(path, parent+`\`)
+}
+
+func (path KeyPath) matches(value *Value) bool {
+	if path.Default || value.Default {
+		return path.Default == value.Default
+	}
+
+	return strings.EqualFold(path.Name, value.Name)
+}
+
+func (path KeyPath) String() string {
+	if path.Default {
+		return path.Path + ".@"
+	}
+
+	return path.Path + "." + path.Name
+}
+
+// parseKey splits a key into the path of a registry key and a value name.
+// As key paths can contain dots, the longest path of a section of the file
+// followed by a dot is used, and the first dot after the last backslash
+// otherwise. A quoted path ("HKEY_...\\Path".Name, as listed) is taken as is.
+func parseKey(config *RegConfiguration, notationStyle core.NotationStyle, key string) (KeyPath, error) {
+	normalized := normalizePath(key)
+	path := KeyPath{}
+
+	if notationStyle == core.BracketsNotation {
+		open := strings.LastIndex(normalized, "[")
+		if open <= 0 || !strings.HasSuffix(normalized, "]") {
+			return KeyPath{}, errors.New(fmt.Sprintf("Invalid key %s, expected HKEY_...\\Path[Name]", key))
+		}
+		path.Path, path.Name = normalized[:open], normalized[open+1:len(normalized)-1]
+	} else if notationStyle == core.PointerNotation {
+		parts, err := core.DecomposeKey(notationStyle, key)
+		if err != nil || len(parts) != 2 {
+			return KeyPath{}, errors.New(fmt.Sprintf("Invalid key %s, expected /HKEY_...\\Path/Name", key))
+		}
+		path.Path, path.Name = normalizePath(parts[0]), parts[1]
+	} else if strings.HasPrefix(key, `"`) || strings.HasPrefix(key, "'") {
+		parts, err := core.DecomposeKeyWithDotNotation(key)
+		if err != nil || len(parts) != 2 {
+			return KeyPath{}, errors.New(fmt.Sprintf("Invalid key %s, expected \"HKEY_...\\Path\".Name", key))
+		}
+		path.Path, path.Name = normalizePath(parts[0]), parts[1]
+	} else {
+		for _, section := range config.Sections {
+			sectionPath := normalizePath(section.Path)
+			if len(normalized) > len(sectionPath)+1 && len(sectionPath) > len(path.Path) &&
+				strings.EqualFold(normalized[:len(sectionPath)], sectionPath) && normalized[len(sectionPath)] == '.' {
+				path.Path, path.Name = normalized[:len(sectionPath)], normalized[len(sectionPath)+1:]
+			}
+		}
+
+		if path.Path == "" {
+			slash := strings.LastIndex(normalized, `\`)
+			dot := strings.Index(normalized[slash+1:], ".")
+			if dot == -1 {
+				return KeyPath{}, errors.New(fmt.Sprintf("Missing value name in %s, use %s.@ for the default value", key, key))
+			}
+			path.Path, path.Name = normalized[:slash+1+dot], normalized[slash+2+dot:]
+		}
+	}
+
+	if path.Name == "" {
+		return KeyPath{}, errors.New(fmt.Sprintf("Missing value name in %s", key))
+	}
+	if !isValidRoot(path.Path) {
+		return KeyPath{}, errors.New(fmt.Sprintf("Unknown root key in %s, expected HKEY_LOCAL_MACHINE, HKEY_CURRENT_USER...", key))
+	}
+	path.Default = path.Name == "@"
+
+	return path, nil
+}
+
+// findSection returns the last section of the key which is not deleted by
+// a later section of the file
+func (config *RegConfiguration) findSection(path KeyPath) *Section {
+	var found *Section
+	for _, section := range config.Sections {
+		if section.Deleted && isSubPath(path.Path, section.Path) {
+			found = nil
+		} else if !section.Deleted && isSubPath(path.Path, section.Path) && isSubPath(section.Path, path.Path) {
+			found = section
+		}
+	}
+
+	return found
+}
+
+// findValue returns the value in effect once the file is imported: sections
+// are applied in order, a deleted key removing its values and the ones of
+// its subkeys
+func (config *RegConfiguration) findValue(path KeyPath) *Value {
+	var found *Value
+	for _, section := range config.Sections {
+		if section.Deleted && isSubPath(path.Path, section.Path) {
+			found = nil
+			continue
+		}
+		if section.Deleted || !isSubPath(path.Path, section.Path) || !isSubPath(section.Path, path.Path) {
+			continue
+		}
+
+		for _, value := range section.Values {
+			if path.matches(value) {
+				found = value
+			}
+		}
+	}
+
+	return found
+}
+
+func (config *RegConfiguration) isUnicode() bool {
+	return config.Header == Version5Header
+}
+
+func GetParameterFromPath(notationStyle core.NotationStyle, filePath string, key string) (string, error) {
+	config, err := GetParsedRegFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := parseKey(&config, notationStyle, key)
+	if err != nil {
+		return "", err
+	}
+
+	value := config.findValue(path)
+	if value == nil {
+		return "", errors.New("Key not found")
+	}
+
+	return decodeData(value, config.isUnicode())
+}
+
+// EditConfigFile sets a value, keeping its type unless valueType is given.
+// A new value is a string by default, added at the end of its key, or in a
+// new section at the end of the file.
+func EditConfigFile(notationStyle core.NotationStyle, filePath string, key string, value string, valueType string) (*RegConfiguration, error) {
+	config, err := GetParsedRegFile(filePath)
+	if err != nil {
+		return &RegConfiguration{}, err
+	}
+
+	path, err := parseKey(&config, notationStyle, key)
+	if err != nil {
+		return &RegConfiguration{}, err
+	}
+
+	existing := config.findValue(path)
+
+	newType, code := StringType, 0
+	if valueType != "" {
+		newType, code, err = getValueType(valueType)
+		if err != nil {
+			return &RegConfiguration{}, err
+		}
+	} else if existing != nil && existing.Type != DeleteType {
+		newType, code = existing.Type, existing.Code
+	}
+
+	data, err := encodeData(newType, code, value, config.isUnicode())
+	if err != nil {
+		return &RegConfiguration{}, err
+	}
+	lines := formatValue(path, data)
+
+	switch section := config.findSection(path); {
+	case existing != nil:
+		config.replaceLines(existing.Start, existing.End, lines)
+	case section != nil:
+		position := section.Line + 1
+		if len(section.Values) > 0 {
+			position = section.Values[len(section.Values)-1].End
+		}
+		config.replaceLines(position, position, lines)
+	default:
+		position := len(config.Lines)
+		for position > 0 && strings.TrimSpace(config.Lines[position-1]) == "" {
+			position--
+		}
+		config.replaceLines(position, position, append([]string{"", "[" + path.Path + "]"}, lines...))
+	}
+
+	return config.validate(key)
+}
+
+// RemoveFromConfigFile removes the lines of a value. To delete it from the
+// registry when importing the file, set it with --type delete instead.
+func RemoveFromConfigFile(notationStyle core.NotationStyle, filePath string, key string) (*RegConfiguration, error) {
+	config, err := GetParsedRegFile(filePath)
+	if err != nil {
+		return &RegConfiguration{}, err
+	}
+
+	path, err := parseKey(&config, notationStyle, key)
+	if err != nil {
+		return &RegConfiguration{}, err
+	}
+
+	value := config.findValue(path)
+	if value == nil {
+		return &RegConfiguration{}, errors.New("Key not found")
+	}
+	config.replaceLines(value.Start, value.End, []string{})
+
+	return config.validate(key)
+}
+
+func (config *RegConfiguration) validate(key string) (*RegConfiguration, error) {
+	parsed, err := ParseRegContent(OutputConfigFile(config, core.FullOutput))
+	if err != nil {
+		return &RegConfiguration{}, errors.New(fmt.Sprintf("Refusing to set %s: %s", key, err.Error()))
+	}
+	config.Sections = parsed.Sections
+
+	return config, nil
+}
+
+func (config *RegConfiguration) replaceLines(start int, end int, lines []string) {
+	newLines := append([]string{}, config.Lines[:start]...)
+	newLines = append(newLines, lines...)
+	config.Lines = append(newLines, config.Lines[end:]...)
+}
+
+// formatValue returns the lines of a value, hexadecimal data being wrapped
+// as regedit does: lines end with a backslash and continuation lines are
+// indented with two spaces
+func formatValue(path KeyPath, data string) []string {
+	name := "@"
+	if !path.Default {
+		name = encodeString(path.Name)
+	}
+
+	line := name + "=" + data
+	if !strings.HasPrefix(data, "hex") || len(line) <= maxLineLength {
+		return []string{line}
+	}
+
+	prefix, bytes, _ := strings.Cut(data, ":")
+	items := strings.Split(bytes, ",")
+
+	lines := []string{}
+	line = name + "=" + prefix + ":"
+	for idx, item := range items {
+		if idx < len(items)-1 {
+			item += ","
+		}
+		if idx > 0 && len(line)+len(item) > maxLineLength-1 {
+			lines = append(lines, line+`\`)
+			line = "  "
+		}
+		line += item
+	}
+
+	return append(lines, line)
+}
@@ -0,0 +1,238 @@
+package reg
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/testutil"
+)
+
+const SETTINGS_FILE_PATH = "../../../data/reg/settings.reg"
+const POLICIES_FILE_PATH = "../../../data/reg/policies.reg"
+
+const LOG_PATH_LINES = `"LogPath"=hex(2):25,00,54,00,45,00,4d,00,50,00,25,00,5c,00,65,00,78,00,61,00,\
+  6d,00,70,00,6c,00,65,00,2e,00,6c,00,6f,00,67,00,00,00`
+
+func TestGetParameter(t *testing.T) {
+	cases := map[string]string{
+		`HKEY_LOCAL_MACHINE\SOFTWARE\Example.@`:    "Example Application",
+		`HKLM\SOFTWARE\Example.InstallDir`:         `C:\Program Files\Example`,
+		`HKLM\Software\Example.title`:              `Say "hello"`,
+		`HKLM\SOFTWARE\Example.Version`:            "10",
+		`HKLM\SOFTWARE\Example.MaxSize`:            "1073741824",
+		`HKLM\SOFTWARE\Example.LogPath`:            `%TEMP%\example.log`,
+		`HKLM\SOFTWARE\Example.Servers`:            "alpha\nbeta",
+		`HKLM\SOFTWARE\Example.Key`:                "de,ad,be,ef",
+		`HKLM\SOFTWARE\Example\Plugins.d.Enabled`:  "1",
+		`HKEY_CURRENT_USER\Software\Example.Theme`: "dark",
+	}
+
+	for key, expectedValue := range cases {
+		t.Run("it gets existing parameter "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, SETTINGS_FILE_PATH, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value != expectedValue {
+				t.Fatal(fmt.Sprintf("Expected %s got %s", expectedValue, value))
+			}
+		})
+	}
+
+	t.Run("it gets a parameter with the brackets notation", func(t *testing.T) {
+		value, err := GetParameterFromPath(core.BracketsNotation, SETTINGS_FILE_PATH, `HKLM\SOFTWARE\Example\Plugins.d[Enabled]`)
+		if err != nil || value != "1" {
+			t.Fatal(fmt.Sprintf("Expected 1 got %s (%v)", value, err))
+		}
+	})
+
+	t.Run("it gets a parameter of an UTF-16 file", func(t *testing.T) {
+		value, err := GetParameterFromPath(core.DotNotation, POLICIES_FILE_PATH, `HKCU\Software\Policies\Example.Message`)
+		if err != nil || value != "Grüße" {
+			t.Fatal(fmt.Sprintf("Expected Grüße got %s (%v)", value, err))
+		}
+	})
+
+	errorCases := []string{
+		`HKLM\SOFTWARE\Example.Legacy`,
+		`HKCU\Software\Example\Cache.Size`,
+		`HKLM\SOFTWARE\Example.Missing`,
+		`HKLM\SOFTWARE\Example`,
+		`HKEY_FOO\SOFTWARE\Example.Version`,
+	}
+	for _, key := range errorCases {
+		t.Run("it does not get "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, SETTINGS_FILE_PATH, key)
+			if err == nil {
+				t.Error("Should fail. Got " + value + " instead")
+			}
+		})
+	}
+}
+
+type EditTestElement struct {
+	key       string
+	value     string
+	valueType string
+	old       string
+	new       string
+}
+
+func TestEditParameter(t *testing.T) {
+	cases := []EditTestElement{
+		{`HKLM\SOFTWARE\Example.InstallDir`, `D:\Apps\Example`, "", `"InstallDir"="C:\\Program Files\\Example"`, `"InstallDir"="D:\\Apps\\Example"`},
+		{`HKLM\SOFTWARE\Example.Title`, `a "b"`, "", `"Title"="Say \"hello\""`, `"Title"="a \"b\""`},
+		{`HKLM\SOFTWARE\Example.@`, "Demo", "", `@="Example Application"`, `@="Demo"`},
+		{`HKLM\SOFTWARE\Example.Version`, "42", "", "dword:0000000a", "dword:0000002a"},
+		{`HKLM\SOFTWARE\Example.Version`, "0x20", "", "dword:0000000a", "dword:00000020"},
+		{`HKLM\SOFTWARE\Example.MaxSize`, "2147483648", "", "hex(b):00,00,00,40,00,00,00,00", "hex(b):00,00,00,80,00,00,00,00"},
+		{`HKLM\SOFTWARE\Example.LogPath`, `%TMP%\a`, "", LOG_PATH_LINES, `"LogPath"=hex(2):25,00,54,00,4d,00,50,00,25,00,5c,00,61,00,00,00`},
+		{
+			`HKLM\SOFTWARE\Example.Servers`, "alpha\nbeta\ngamma", "",
+			"74,00,61,00,\\\n  00,00,00,00",
+			"74,00,61,00,\\\n  00,00,67,00,61,00,6d,00,6d,00,61,00,00,00,00,00",
+		},
+		{`HKLM\SOFTWARE\Example.Key`, "cafe", "", "hex:de,ad,be,ef", "hex:ca,fe"},
+		{`HKLM\SOFTWARE\Example.Key`, "01,02", "", "hex:de,ad,be,ef", "hex:01,02"},
+		{`HKLM\SOFTWARE\Example.Legacy`, "back", "", `"Legacy"=-`, `"Legacy"="back"`},
+		{`HKLM\SOFTWARE\Example.Version`, "yes", "string", "dword:0000000a", `"yes"`},
+		{`HKLM\SOFTWARE\Example.Timeout`, "30", "dword", "\"Legacy\"=-\n", "\"Legacy\"=-\n\"Timeout\"=dword:0000001e\n"},
+		{`HKCU\Software\Example.Theme`, "", "delete", `"Theme"="dark"`, `"Theme"=-`},
+		{`HKCU\Software\Other.Name`, "value", "", "\"Theme\"=\"dark\"\n", "\"Theme\"=\"dark\"\n\n[HKEY_CURRENT_USER\\Software\\Other]\n\"Name\"=\"value\"\n"},
+		{
+			`HKCU\Software\Example\Cache.Size`, "1", "dword",
+			"\"Theme\"=\"dark\"\n",
+			"\"Theme\"=\"dark\"\n\n[HKEY_CURRENT_USER\\Software\\Example\\Cache]\n\"Size\"=dword:00000001\n",
+		},
+	}
+
+	for _, element := range cases {
+		t.Run("it sets "+element.key+" to "+element.value, func(t *testing.T) {
+			config, err := EditConfigFile(core.DotNotation, SETTINGS_FILE_PATH, element.key, element.value, element.valueType)
+			testutil.AssertEditedOutput(t, SETTINGS_FILE_PATH, config, err, element.old, element.new)
+		})
+	}
+
+	errorCases := []EditTestElement{
+		{`HKLM\SOFTWARE\Example.Version`, "abc", "", "", ""},
+		{`HKLM\SOFTWARE\Example.Version`, "4294967296", "", "", ""},
+		{`HKLM\SOFTWARE\Example.MaxSize`, "-1", "", "", ""},
+		{`HKLM\SOFTWARE\Example.Key`, "xyz", "", "", ""},
+		{`HKLM\SOFTWARE\Example.InstallDir`, "a\nb", "", "", ""},
+		{`HKLM\SOFTWARE\Example.InstallDir`, "1.5", "float", "", ""},
+		{`HKEY_FOO\SOFTWARE\Example.Version`, "1", "", "", ""},
+	}
+	for _, element := range errorCases {
+		t.Run("it refuses to set "+element.key+" to "+element.value, func(t *testing.T) {
+			if _, err := EditConfigFile(core.DotNotation, SETTINGS_FILE_PATH, element.key, element.value, element.valueType); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestUnsetParameter(t *testing.T) {
+	cases := []EditTestElement{
+		{`HKLM\SOFTWARE\Example.Title`, "", "", "\"Title\"=\"Say \\\"hello\\\"\"\n", ""},
+		{`HKLM\SOFTWARE\Example.LogPath`, "", "", LOG_PATH_LINES + "\n", ""},
+		{`HKLM\SOFTWARE\Example.Legacy`, "", "", "\"Legacy\"=-\n", ""},
+	}
+
+	for _, element := range cases {
+		t.Run("it unsets "+element.key, func(t *testing.T) {
+			config, err := RemoveFromConfigFile(core.DotNotation, SETTINGS_FILE_PATH, element.key)
+			testutil.AssertEditedOutput(t, SETTINGS_FILE_PATH, config, err, element.old, element.new)
+		})
+	}
+
+	t.Run("it does not unset a missing value", func(t *testing.T) {
+		if _, err := RemoveFromConfigFile(core.DotNotation, SETTINGS_FILE_PATH, `HKCU\Software\Example\Cache.Size`); err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
+
+func TestWriteUTF16File(t *testing.T) {
+	config, err := EditConfigFile(core.DotNotation, POLICIES_FILE_PATH, `HKCU\Software\Policies\Example.Enabled`, "0", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	filePath, err := io.GenerateRandomTempFilePath()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err = config.WriteToFile(filePath, core.FullOutput); err != nil {
+		t.Fatal(err)
+	}
+
+	original, _ := io.GetFileContents(POLICIES_FILE_PATH)
+	written, _ := io.GetFileContents(filePath)
+
+	decoded, _, _ := decodeContent(original)
+	expected := encodeContent(strings.Replace(decoded, "dword:00000001", "dword:00000000", 1), UTF16LE)
+	if written != expected {
+		t.Fatal(fmt.Sprintf("Expected %q got %q", expected, written))
+	}
+	if !strings.HasPrefix(written, "\xff\xfe") || !strings.Contains(written, "\r\x00\n\x00") {
+		t.Fatal("Expected an UTF-16LE file with a BOM and CRLF line endings")
+	}
+}
+
+func TestRegedit4Strings(t *testing.T) {
+	data, err := encodeData(ExpandStringType, 2, "ab", false)
+	if err != nil || data != "hex(2):61,62,00" {
+		t.Fatal(fmt.Sprintf("Expected hex(2):61,62,00 got %s (%v)", data, err))
+	}
+
+	config, err := ParseRegContent("REGEDIT4\n\n[HKEY_CURRENT_USER\\Example]\n\"Path\"=hex(2):61,62,00\n")
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := decodeData(config.Sections[0].Values[0], config.isUnicode())
+	if err != nil || value != "ab" {
+		t.Fatal(fmt.Sprintf("Expected ab got %s (%v)", value, err))
+	}
+}
+
+func TestParseInvalidReg(t *testing.T) {
+	cases := []string{
+		"[HKEY_CURRENT_USER\\Example]\n\"Name\"=\"value\"",
+		"Windows Registry Editor Version 5.00\n\n\"Name\"=\"value\"",
+		"Windows Registry Editor Version 5.00\n\n[HKEY_CURRENT_USER\\Example]\n\"Name\"=dword:xyz",
+		"Windows Registry Editor Version 5.00\n\n[HKEY_CURRENT_USER\\Example]\n\"Name\"=\"value",
+		"Windows Registry Editor Version 5.00\n\n[HKEY_CURRENT_USER\\Example]\n\"Name\"=hex:01,\\",
+		"Windows Registry Editor Version 5.00\n\n[HKEY_FOO\\Example]",
+		"Windows Registry Editor Version 5.00\n\n[-HKEY_CURRENT_USER\\Example]\n\"Name\"=\"value\"",
+		"Windows Registry Editor Version 5.00\n\n[HKEY_CURRENT_USER\\Example\n",
+	}
+
+	for _, content := range cases {
+		t.Run("it rejects "+content, func(t *testing.T) {
+			if _, err := ParseRegContent(content); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestReadDocument(t *testing.T) {
+	testutil.AssertDocumentValues(t, RegConfigurator{}, SETTINGS_FILE_PATH, map[string]string{
+		`"HKEY_LOCAL_MACHINE\\SOFTWARE\\Example".@`:         "Example Application",
+		`"HKEY_LOCAL_MACHINE\\SOFTWARE\\Example".MaxSize`:   "1073741824",
+		`"HKEY_LOCAL_MACHINE\\SOFTWARE\\Example".Servers.1`: "beta",
+		`"HKEY_CURRENT_USER\\Software\\Example".Theme`:      "dark",
+	})
+
+	document, err := RegConfigurator{}.ReadDocument(SETTINGS_FILE_PATH, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if document.Get(`HKEY_CURRENT_USER\Software\Example\Cache`) != nil {
+		t.Error("Expected the deleted key not to be in the document")
+	}
+}
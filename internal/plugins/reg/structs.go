@@ -0,0 +1,130 @@
+package reg
+
+import (
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+)
+
+const (
+	Version5Header = "Windows Registry Editor Version 5.00"
+	Version4Header = "REGEDIT4"
+)
+
+// Encoding is the encoding of the file, kept when writing it back
+type Encoding int
+
+const (
+	UTF8 Encoding = iota
+	UTF8BOM
+	UTF16LE
+)
+
+type ValueType int
+
+const (
+	StringType ValueType = iota
+	DwordType
+	QwordType
+	ExpandStringType
+	MultiStringType
+	BinaryType
+	// HexType is any other hex(N) value, kept as bytes
+	HexType
+	// DeleteType is a value deleted by the file ("Name"=-)
+	DeleteType
+)
+
+// Value is a value of a key. A value written in hexadecimal can span several
+// lines ending with a backslash: Start and End are the indexes of its first
+// line and of the line following it.
+type Value struct {
+	Name string
+	// Default is true for the default value of the key (@)
+	Default bool
+	Type    ValueType
+	// Code is the number of the hex(N) prefix of hexadecimal values
+	Code int
+	// Data is the value as written after the "=", without its type prefix
+	// and line continuations
+	Data  string
+	Start int
+	End   int
+}
+
+// Section is a [HKEY_...\Path] section, or a [-HKEY_...\Path] section deleting
+// a key and its subkeys
+type Section struct {
+	Path    string
+	Deleted bool
+	Line    int
+	Values  []*Value
+}
+
+type RegConfiguration struct {
+	Header   string
+	Lines    []string
+	Sections []*Section
+	Encoding Encoding
+	// LineEnding is "\r\n" for files written by regedit, "\n" otherwise
+	LineEnding string
+	FilePath   string
+}
+
+func (config *RegConfiguration) OutputFile(outputType core.OutputType) (string, error) {
+	return OutputConfigFile(config, outputType), nil
+}
+
+// WriteToFile writes the file with its original encoding
+func (config *RegConfiguration) WriteToFile(filepath string, outputType core.OutputType) error {
+	output, err := config.OutputFile(outputType)
+	if err != nil {
+		return err
+	}
+
+	return io.WriteFileContents(filepath, encodeContent(output, config.Encoding))
+}
+
+type RegConfigurator struct{}
+
+func (configurator RegConfigurator) GetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (string, error) {
+	return GetParameterFromPath(notationStyle, filePath, key)
+}
+
+func (configurator RegConfigurator) SetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, ""))
+}
+
+func (configurator RegConfigurator) SetTypedParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+	valueType string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, valueType))
+}
+
+func (configurator RegConfigurator) UnsetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (core.Configuration, error) {
+	return toConfiguration(RemoveFromConfigFile(notationStyle, filePath, key))
+}
+
+func toConfiguration(config *RegConfiguration, err error) (core.Configuration, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,270 @@
+package reg
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf16"
+)
+
+var hexTypeRegexp = regexp.MustCompile(`^hex\(([0-9a-f]{1,8})\)$`)
+
+var valueTypes = map[string]ValueType{
+	"string":        StringType,
+	"reg_sz":        StringType,
+	"dword":         DwordType,
+	"reg_dword":     DwordType,
+	"qword":         QwordType,
+	"reg_qword":     QwordType,
+	"expand_string": ExpandStringType,
+	"reg_expand_sz": ExpandStringType,
+	"multi_string":  MultiStringType,
+	"reg_multi_sz":  MultiStringType,
+	"binary":        BinaryType,
+	"reg_binary":    BinaryType,
+	"hex":           BinaryType,
+	"delete":        DeleteType,
+}
+
+// typeCodes are the numbers of the hex(N) prefixes of the types written in
+// hexadecimal
+var typeCodes = map[ValueType]int{
+	ExpandStringType: 2,
+	BinaryType:       3,
+	MultiStringType:  7,
+	QwordType:        11,
+}
+
+func (valueType ValueType) String() string {
+	switch valueType {
+	case StringType:
+		return "string"
+	case DwordType:
+		return "dword"
+	case QwordType:
+		return "qword"
+	case ExpandStringType:
+		return "expand_string"
+	case MultiStringType:
+		return "multi_string"
+	case BinaryType:
+		return "binary"
+	case DeleteType:
+		return "delete"
+	default:
+		return "hex"
+	}
+}
+
+func typeOfCode(code int) ValueType {
+	for valueType, typeCode := range typeCodes {
+		if typeCode == code {
+			return valueType
+		}
+	}
+
+	return HexType
+}
+
+// getValueType returns the type given with --type, and the number of its
+// hex(N) prefix
+func getValueType(name string) (ValueType, int, error) {
+	lowered := strings.ToLower(name)
+	if valueType, ok := valueTypes[lowered]; ok {
+		return valueType, typeCodes[valueType], nil
+	}
+	if matches := hexTypeRegexp.FindStringSubmatch(lowered); matches != nil {
+		code, _ := strconv.ParseUint(matches[1], 16, 32)
+		return typeOfCode(int(code)), int(code), nil
+	}
+
+	return 0, 0, errors.New(fmt.Sprintf(
+		"Unknown registry type: %s, expected string, dword, qword, expand_string, multi_string, binary, hex(N) or delete", name,
+	))
+}
+
+func typePrefix(valueType ValueType, code int) string {
+	if valueType == BinaryType {
+		return "hex"
+	}
+
+	return fmt.Sprintf("hex(%x)", code)
+}
+
+func encodeString(value string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`)
+
+	return `"` + replacer.Replace(value) + `"`
+}
+
+// parseBytes parses normalized hexadecimal data ("61,00,62,00")
+func parseBytes(data string) []byte {
+	bytes := []byte{}
+	if data == "" {
+		return bytes
+	}
+
+	for _, item := range strings.Split(data, ",") {
+		number, _ := strconv.ParseUint(item, 16, 8)
+		bytes = append(bytes, byte(number))
+	}
+
+	return bytes
+}
+
+func formatBytes(bytes []byte) string {
+	items := []string{}
+	for _, b := range bytes {
+		items = append(items, fmt.Sprintf("%02x", b))
+	}
+
+	return strings.Join(items, ",")
+}
+
+// parseHexInput parses bytes given on the command line, separated by commas
+// or not ("de,ad,be,ef" or "deadbeef")
+func parseHexInput(value string) ([]byte, bool) {
+	data := strings.ToLower(strings.Join(strings.Fields(value), ""))
+	if !strings.Contains(data, ",") {
+		if len(data)%2 != 0 {
+			return nil, false
+		}
+		items := []string{}
+		for i := 0; i < len(data); i += 2 {
+			items = append(items, data[i:i+2])
+		}
+		data = strings.Join(items, ",")
+	}
+	if !hexDataRegexp.MatchString(data) {
+		return nil, false
+	}
+
+	return parseBytes(data), true
+}
+
+// parseNumber parses a decimal number, or an hexadecimal one starting with 0x
+func parseNumber(value string, bitSize int) (uint64, error) {
+	lowered := strings.ToLower(value)
+	if strings.HasPrefix(lowered, "0x") {
+		return strconv.ParseUint(lowered[2:], 16, bitSize)
+	}
+
+	return strconv.ParseUint(value, 10, bitSize)
+}
+
+// decodeStrings decodes null-terminated strings, written in UTF-16LE by
+// version 5 files and in ANSI by REGEDIT4 files
+func decodeStrings(bytes []byte, unicode bool) ([]string, error) {
+	text := string(bytes)
+	if unicode {
+		units, err := toUTF16(text)
+		if err != nil {
+			return nil, err
+		}
+		text = string(utf16.Decode(units))
+	}
+
+	items := strings.Split(text, "\x00")
+	for len(items) > 0 && items[len(items)-1] == "" {
+		items = items[:len(items)-1]
+	}
+
+	return items, nil
+}
+
+func encodeStrings(items []string, unicode bool) []byte {
+	text := ""
+	for _, item := range items {
+		text += item + "\x00"
+	}
+
+	if unicode {
+		return fromUTF16(utf16.Encode([]rune(text)))
+	}
+
+	return []byte(text)
+}
+
+// decodeData returns the value as it should be displayed: numbers in decimal,
+// strings decoded, one line per string of a multi_string value and bytes for
+// binary values
+func decodeData(value *Value, unicode bool) (string, error) {
+	bytes := parseBytes(value.Data)
+
+	switch value.Type {
+	case StringType:
+		return value.Data, nil
+	case DwordType:
+		number, _ := strconv.ParseUint(value.Data, 16, 32)
+		return strconv.FormatUint(number, 10), nil
+	case QwordType:
+		if len(bytes) != 8 {
+			return "", errors.New(fmt.Sprintf("Invalid qword value %s", value.Data))
+		}
+		return strconv.FormatUint(binary.LittleEndian.Uint64(bytes), 10), nil
+	case ExpandStringType, MultiStringType:
+		items, err := decodeStrings(bytes, unicode)
+		if err != nil {
+			return "", err
+		}
+		if value.Type == ExpandStringType && len(items) > 1 {
+			items = items[:1]
+		}
+		return strings.Join(items, "\n"), nil
+	case DeleteType:
+		return "", errors.New("The value is deleted by this file")
+	default:
+		return value.Data, nil
+	}
+}
+
+// encodeData returns the data of a value as written after the "=", on a
+// single line
+func encodeData(valueType ValueType, code int, value string, unicode bool) (string, error) {
+	invalid := func() (string, error) {
+		return "", errors.New(fmt.Sprintf("%q is not a valid registry %s value, use --type to change the type of the value", value, valueType))
+	}
+
+	var bytes []byte
+	switch valueType {
+	case StringType:
+		if strings.ContainsAny(value, "\r\n") {
+			return "", errors.New("Strings cannot contain newlines, use --type multi_string for a list of strings")
+		}
+		return encodeString(value), nil
+	case DwordType:
+		number, err := parseNumber(value, 32)
+		if err != nil {
+			return invalid()
+		}
+		return fmt.Sprintf("dword:%08x", number), nil
+	case QwordType:
+		number, err := parseNumber(value, 64)
+		if err != nil {
+			return invalid()
+		}
+		bytes = make([]byte, 8)
+		binary.LittleEndian.PutUint64(bytes, number)
+	case ExpandStringType:
+		bytes = encodeStrings([]string{value}, unicode)
+	case MultiStringType:
+		items := []string{}
+		if value != "" {
+			items = strings.Split(value, "\n")
+		}
+		bytes = append(encodeStrings(items, unicode), encodeStrings([]string{""}, unicode)...)
+	case DeleteType:
+		return "-", nil
+	default:
+		parsed, ok := parseHexInput(value)
+		if !ok {
+			return invalid()
+		}
+		bytes = parsed
+	}
+
+	return typePrefix(valueType, code) + ":" + formatBytes(bytes), nil
+}
@@ -0,0 +1,263 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+// SearchCriteria are the regular expressions the parameters must match to be
+// found, nil matching anything. Global parameters are in the "" section.
+type SearchCriteria struct {
+	Key     *regexp.Regexp
+	Value   *regexp.Regexp
+	Section *regexp.Regexp
+}
+
+type SearchFile struct {
+	Path string
+	Type string
+}
+
+type SearchMatch struct {
+	Line int
+	// Key is the full key of the parameter, in dot notation
+	Key   string
+	Value string
+}
+
+type SearchResult struct {
+	File    SearchFile
+	Matches []SearchMatch
+	Err     error
+}
+
+// systemdUnitSuffixes are the suffixes of the systemd unit files
+var systemdUnitSuffixes = []string{
+	".service", ".socket", ".timer", ".target", ".mount", ".automount",
+	".swap", ".path", ".slice",
+}
+
+// DetectType returns the configuration type of a file from its name, or ""
+// if it is not known
+func DetectType(filePath string) string {
+	name := strings.ToLower(filepath.Base(filePath))
+	parent := strings.ToLower(filepath.Base(filepath.Dir(filePath)))
+	isConf := strings.HasSuffix(name, ".conf")
+	isDropIn := isConf && strings.HasSuffix(parent, ".d") && hasSystemdUnitSuffix(strings.TrimSuffix(parent, ".d"))
+
+	switch {
+	case strings.HasSuffix(name, ".ini"):
+		return "ini"
+	case strings.HasSuffix(name, ".toml"):
+		return "toml"
+	case strings.HasSuffix(name, ".plist"):
+		return "plist"
+	case strings.HasSuffix(name, ".xml"):
+		return "xml"
+	case strings.HasSuffix(name, ".reg"):
+		return "reg"
+	case strings.HasSuffix(name, ".desktop"):
+		return "desktop"
+	case name == ".editorconfig":
+		return "editorconfig"
+	case strings.HasSuffix(name, ".tf") || strings.HasSuffix(name, ".tfvars") || strings.HasSuffix(name, ".hcl"):
+		return "hcl"
+	case strings.HasPrefix(name, "redis") && isConf:
+		return "redis"
+	case name == "postgresql.conf" || name == "postgresql.auto.conf":
+		return "postgresql"
+	case name == "sysctl.conf" || (parent == "sysctl.d" && isConf):
+		return "sysctl"
+	case name == "nginx.conf" || (isConf && inDirectory(filePath, "nginx")):
+		return "nginx"
+	case name == "httpd.conf" || name == "apache2.conf" || name == ".htaccess" ||
+		(isConf && (inDirectory(filePath, "httpd") || inDirectory(filePath, "apache2"))):
+		return "apache"
+	case name == "sshd_config" || name == "ssh_config" ||
+		(isConf && (parent == "sshd_config.d" || parent == "ssh_config.d")):
+		return "ssh"
+	case hasSystemdUnitSuffix(name) || isDropIn:
+		return "systemd"
+	case name == "hosts":
+		return "hosts"
+	case name == "fstab":
+		return "fstab"
+	case name == "crontab" || parent == "cron.d":
+		return "crontab"
+	default:
+		return ""
+	}
+}
+
+func hasSystemdUnitSuffix(name string) bool {
+	for _, suffix := range systemdUnitSuffixes {
+		if strings.HasSuffix(name, suffix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// inDirectory tells if a file is below a directory of the given name, e.g.
+// /etc/nginx/sites-enabled/default is below nginx
+func inDirectory(filePath string, directory string) bool {
+	for _, part := range strings.Split(filepath.ToSlash(filepath.Dir(filePath)), "/") {
+		if strings.EqualFold(part, directory) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// hasType returns true if a type is one of the given types or one of their
+// aliases (php for ini...)
+func hasType(types []string, ctype string) bool {
+	for _, candidate := range types {
+		if canonicalType(candidate) == canonicalType(ctype) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// CollectSearchFiles returns the files to search among the given paths,
+// walking directories recursively. Only the files of the given types are
+// kept if there are some; the type of the files whose type is not detected
+// defaults to the only type given. Paths which cannot be read are returned
+// as errors.
+func CollectSearchFiles(paths []string, types []string) ([]SearchFile, []error) {
+	files := []SearchFile{}
+	errs := []error{}
+
+	addFile := func(filePath string, explicit bool) {
+		ctype := DetectType(filePath)
+		switch {
+		case ctype == "" && len(types) == 1:
+			ctype = types[0]
+		case ctype == "" && explicit:
+			errs = append(errs, errors.New(fmt.Sprintf("%s: unknown configuration type, use --type", filePath)))
+			return
+		case ctype == "" || (len(types) > 0 && !hasType(types, ctype)):
+			return
+		}
+		files = append(files, SearchFile{filePath, ctype})
+	}
+
+	for _, path := range paths {
+		err := filepath.WalkDir(path, func(filePath string, entry fs.DirEntry, err error) error {
+			if err != nil {
+				errs = append(errs, err)
+				return nil
+			}
+			if entry.Type().IsRegular() || (filePath == path && !entry.IsDir()) {
+				addFile(filePath, filePath == path)
+			}
+
+			return nil
+		})
+		if err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return files, errs
+}
+
+// SearchFiles parses and searches files with a pool of workers, calling fn
+// with the result of each file in the order of the files
+func SearchFiles(files []SearchFile, criteria SearchCriteria, workers int, fn func(result SearchResult)) {
+	results := make([]chan SearchResult, len(files))
+	for idx := range results {
+		results[idx] = make(chan SearchResult, 1)
+	}
+
+	jobs := make(chan int)
+	var waitGroup sync.WaitGroup
+	for worker := 0; worker < workers; worker++ {
+		waitGroup.Add(1)
+		go func() {
+			defer waitGroup.Done()
+			for idx := range jobs {
+				matches, err := searchFile(files[idx], criteria)
+				results[idx] <- SearchResult{files[idx], matches, err}
+			}
+		}()
+	}
+
+	go func() {
+		for idx := range files {
+			jobs <- idx
+		}
+		close(jobs)
+	}()
+
+	for _, result := range results {
+		fn(<-result)
+	}
+	waitGroup.Wait()
+}
+
+// searchFile returns the parameters of a file matching the criteria. The
+// occurrences of a repeated key are all returned with the key, without
+// their index.
+func searchFile(file SearchFile, criteria SearchCriteria) ([]SearchMatch, error) {
+	reader, err := GetDocumentReader(file.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	document, err := reader.ReadDocument(file.Path, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	matches := []SearchMatch{}
+	var search func(node *core.Node, path []string, section []string)
+	search = func(node *core.Node, path []string, section []string) {
+		switch {
+		case node.IsMap():
+			if node.Kind == core.SectionNode {
+				section = path
+			}
+			for _, key := range node.Keys {
+				search(node.Get(key), append(append([]string{}, path...), key), section)
+			}
+		case node.Kind == core.ListNode:
+			for idx, item := range node.Items {
+				if item.Kind == core.ScalarNode {
+					search(item, path, section)
+					continue
+				}
+				search(item, append(append([]string{}, path...), strconv.Itoa(idx)), section)
+			}
+		default:
+			if criteria.matches(path, section, node.Value) {
+				matches = append(matches, SearchMatch{node.Span.Start, core.ComposeKey(core.DotNotation, path), node.Value})
+			}
+		}
+	}
+	search(document, []string{}, []string{})
+
+	return matches, nil
+}
+
+func (criteria SearchCriteria) matches(path []string, section []string, value string) bool {
+	if len(path) == 0 {
+		return false
+	}
+
+	return (criteria.Key == nil || criteria.Key.MatchString(path[len(path)-1])) &&
+		(criteria.Section == nil || criteria.Section.MatchString(core.ComposeKey(core.DotNotation, section))) &&
+		(criteria.Value == nil || criteria.Value.MatchString(value))
+}
@@ -0,0 +1,145 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"regexp"
+	"testing"
+)
+
+func TestDetectType(t *testing.T) {
+	dataProvider := map[string]string{
+		"/etc/php/8.3/fpm/php.ini":                          "ini",
+		"Cargo.toml":                                        "toml",
+		"Info.plist":                                        "plist",
+		"/etc/redis/redis-6379.conf":                        "redis",
+		"postgresql.auto.conf":                              "postgresql",
+		"/etc/sysctl.conf":                                  "sysctl",
+		"/etc/sysctl.d/99-net.conf":                         "sysctl",
+		"/etc/nginx/nginx.conf":                             "nginx",
+		"/etc/nginx/conf.d/default.conf":                    "nginx",
+		"/etc/nginx/sites-enabled/default":                  "",
+		"/etc/httpd/conf/httpd.conf":                        "apache",
+		"/etc/apache2/sites-available/000-default.conf":     "apache",
+		"/var/www/.htaccess":                                "apache",
+		"/etc/ssh/sshd_config":                              "ssh",
+		"/etc/ssh/ssh_config.d/50-redhat.conf":              "ssh",
+		"/lib/systemd/system/nginx.service":                 "systemd",
+		"/etc/systemd/system/nginx.service.d/override.conf": "systemd",
+		"/etc/systemd/system/backup.timer":                  "systemd",
+		"/usr/share/applications/firefox.desktop":           "desktop",
+		".editorconfig":                                     "editorconfig",
+		"main.tf":                                           "hcl",
+		"terraform.tfvars":                                  "hcl",
+		"config.hcl":                                        "hcl",
+		"settings.reg":                                      "reg",
+		"pom.xml":                                           "xml",
+		"/etc/hosts":                                        "hosts",
+		"/etc/fstab":                                        "fstab",
+		"/etc/crontab":                                      "crontab",
+		"/etc/cron.d/logrotate":                             "crontab",
+		"/etc/conf.d/net.conf":                              "",
+		"README.md":                                         "",
+	}
+
+	for filePath, expected := range dataProvider {
+		if actual := DetectType(filePath); actual != expected {
+			t.Errorf("Expected %s to be detected as %q, got %q", filePath, expected, actual)
+		}
+	}
+}
+
+func TestCollectSearchFiles(t *testing.T) {
+	files, errs := CollectSearchFiles([]string{"../../data/toml", "../../data/flat/redis.conf"}, []string{})
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	expected := []SearchFile{
+		{"../../data/toml/cargo.toml", "toml"},
+		{"../../data/toml/pyproject.toml", "toml"},
+		{"../../data/flat/redis.conf", "redis"},
+	}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected %v, got %v", expected, files)
+	}
+
+	files, _ = CollectSearchFiles([]string{"../../data/ini"}, []string{"php"})
+	if len(files) != 5 {
+		t.Errorf("Expected the 5 INI files to be searched as php files, got %v", files)
+	}
+
+	files, errs = CollectSearchFiles([]string{"../../data/toml", "../../data/ssh/sshd_config"}, []string{"ini"})
+	if len(files) != 0 || len(errs) != 0 {
+		t.Errorf("Expected no INI file to be searched, got %v %v", files, errs)
+	}
+
+	files, errs = CollectSearchFiles([]string{"../../data/nginx", "../../data/ssh"}, []string{"nginx"})
+	expected = []SearchFile{{"../../data/nginx/nginx.conf", "nginx"}}
+	if !reflect.DeepEqual(files, expected) || len(errs) != 0 {
+		t.Errorf("Expected %v, got %v %v", expected, files, errs)
+	}
+
+	dir := t.TempDir()
+	for _, name := range []string{"default", "php.ini"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte("listen 80;\n"), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	files, errs = CollectSearchFiles([]string{dir}, []string{"nginx"})
+	expected = []SearchFile{{filepath.Join(dir, "default"), "nginx"}}
+	if !reflect.DeepEqual(files, expected) || len(errs) != 0 {
+		t.Errorf("Expected the undetected files of a directory to be searched as nginx files, got %v %v", files, errs)
+	}
+
+	files, _ = CollectSearchFiles([]string{dir}, []string{})
+	expected = []SearchFile{{filepath.Join(dir, "php.ini"), "ini"}}
+	if !reflect.DeepEqual(files, expected) {
+		t.Errorf("Expected the undetected files of a directory to be skipped without --type, got %v", files)
+	}
+
+	_, errs = CollectSearchFiles([]string{filepath.Join(dir, "default"), "../../data/missing"}, []string{})
+	if len(errs) != 2 {
+		t.Errorf("Expected 2 errors, got %v", errs)
+	}
+}
+
+func TestSearchFiles(t *testing.T) {
+	files := []SearchFile{
+		{"../../data/ini/ini.ini", "ini"},
+		{"../../data/ini/missing.ini", "ini"},
+		{"../../data/flat/redis.conf", "redis"},
+		{"../../data/toml/cargo.toml", "toml"},
+	}
+	criteria := SearchCriteria{
+		Key:     regexp.MustCompile("^(save|name|editor)$"),
+		Value:   regexp.MustCompile("^[a-z0-9 ]+$"),
+		Section: regexp.MustCompile(`^(|core|package|bin\.0)$`),
+	}
+
+	actual := map[string][]SearchMatch{}
+	failed := []string{}
+	SearchFiles(files, criteria, 2, func(result SearchResult) {
+		if result.Err != nil {
+			failed = append(failed, result.File.Path)
+			return
+		}
+		actual[result.File.Path] = result.Matches
+	})
+
+	expected := map[string][]SearchMatch{
+		"../../data/ini/ini.ini": {{9, "core.editor", "vim"}},
+		"../../data/flat/redis.conf": {
+			{11, "save", "3600 1"},
+			{12, "save", "300 100"},
+			{13, "save", "60 10000"},
+		},
+		"../../data/toml/cargo.toml": {{3, "package.name", "edicon"}, {23, "bin.0.name", "edicon"}},
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("Expected %v, got %v", expected, actual)
+	}
+	if !reflect.DeepEqual(failed, []string{"../../data/ini/missing.ini"}) {
+		t.Errorf("Expected missing.ini to fail, got %v", failed)
+	}
+}
@@ -0,0 +1,63 @@
+package ssh
+
+import (
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+// ReadDocument returns the document tree of the configuration: the global
+// scope is at the root and the occurrences of the Match and Host blocks are
+// lists, designated by their position (Host[2].User). The criteria of the
+// blocks are not part of the tree. Cumulative keywords (Port...) are lists,
+// the first value of the other keywords being the one in use.
+func (configurator SshConfigurator) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	config, err := GetParsedSshFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Comments) > 0 {
+		losses.Add("comments are not converted")
+	}
+	document := source.NewDocument(config.Content, config.Comments, "#")
+
+	tree := readBlock(document, config.Global, core.NewMapNode(), []string{}, losses)
+	for _, block := range config.Blocks {
+		keyword := block.Header.Keyword
+		losses.Add("the criteria of the blocks (%s %s) are not converted", keyword, strings.Join(block.Header.Arguments, " "))
+
+		node := readBlock(document, block, core.NewMapNode(), []string{keyword}, losses)
+		node.Span = document.Lines(block.Header.Line)
+		if len(block.Directives) > 0 {
+			node.Span.End = document.Lines(block.Directives[len(block.Directives)-1].Line).End
+		}
+		node.Comments = document.AttachedComments(block.Header.Line)
+		tree.AddIndexed(keyword, node)
+	}
+
+	return tree, nil
+}
+
+func readBlock(document *source.Document, block *Block, node *core.Node, path []string, losses *core.Losses) *core.Node {
+	node.Path = path
+
+	for _, directive := range block.Directives {
+		value := core.NewScalarNode(core.UntypedScalar, document.Content[directive.Value.Start:directive.Value.End])
+		value.Path = append(append([]string{}, path...), directive.Keyword)
+		value.Span = document.Lines(directive.Line)
+		value.Comments = document.AttachedComments(directive.Line)
+
+		switch {
+		case isCumulative(directive.Keyword):
+			node.Add(directive.Keyword, value)
+		case node.Get(directive.Keyword) != nil:
+			losses.Add("%s is set several times, only the first value is kept", directive.Keyword)
+		default:
+			node.Set(directive.Keyword, value)
+		}
+	}
+
+	return node
+}
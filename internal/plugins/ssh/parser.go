@@ -0,0 +1,213 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+var keywordRegexp = regexp.MustCompile(`^[A-Za-z][A-Za-z0-9]*$`)
+
+type parser struct {
+	content  string
+	comments []source.Span
+}
+
+func (p *parser) errorfAt(pos int, format string, args ...interface{}) error {
+	line := strings.Count(p.content[:pos], "\n") + 1
+	message := fmt.Sprintf(format, args...)
+
+	return errors.New(fmt.Sprintf("Invalid SSH configuration on line %d: %s", line, message))
+}
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r'
+}
+
+// parseDirective parses the directive starting at start. Commented
+// directives which cannot be parsed are regular comments, nil is returned.
+func (p *parser) parseDirective(lineStart int, start int, end int, commented bool) (*Directive, error) {
+	pos := start
+	for pos < end && !isSpace(p.content[pos]) && p.content[pos] != '=' {
+		pos++
+	}
+	keyword := p.content[start:pos]
+	if !keywordRegexp.MatchString(keyword) {
+		if commented {
+			return nil, nil
+		}
+
+		return nil, p.errorfAt(lineStart, "invalid keyword %q", keyword)
+	}
+
+	// Keywords and arguments are separated by whitespace or a single "="
+	for pos < end && isSpace(p.content[pos]) {
+		pos++
+	}
+	if pos < end && p.content[pos] == '=' {
+		pos++
+	}
+
+	directive := &Directive{
+		Keyword:      keyword,
+		Line:         source.Span{Start: lineStart, End: end},
+		KeywordStart: start,
+		Commented:    commented,
+	}
+	for pos < end {
+		c := p.content[pos]
+		if isSpace(c) {
+			pos++
+			continue
+		}
+
+		argumentStart := pos
+		if c == '"' {
+			pos++
+			for pos < end && p.content[pos] != '"' {
+				pos++
+			}
+			if pos >= end {
+				if commented {
+					return nil, nil
+				}
+
+				return nil, p.errorfAt(lineStart, "unterminated quote for %s", keyword)
+			}
+			pos++
+			directive.Arguments = append(directive.Arguments, p.content[argumentStart+1:pos-1])
+		} else {
+			for pos < end && !isSpace(p.content[pos]) {
+				pos++
+			}
+			directive.Arguments = append(directive.Arguments, p.content[argumentStart:pos])
+		}
+
+		if len(directive.Arguments) == 1 {
+			directive.Value.Start = argumentStart
+		}
+		directive.Value.End = pos
+	}
+
+	if len(directive.Arguments) == 0 {
+		if commented {
+			return nil, nil
+		}
+
+		return nil, p.errorfAt(lineStart, "missing argument for %s", keyword)
+	}
+
+	return directive, nil
+}
+
+func (p *parser) parse() (*Block, []*Block, error) {
+	global := &Block{}
+	blocks := []*Block{}
+	current := global
+	// Defaults found after a commented block header (#Match User anoncvs)
+	// belong to that example block, not to the current one
+	defaultsEnded := false
+
+	pos := 0
+	for pos < len(p.content) {
+		lineStart := pos
+		lineEnd := strings.IndexByte(p.content[pos:], '\n')
+		if lineEnd == -1 {
+			lineEnd = len(p.content)
+			pos = lineEnd
+		} else {
+			lineEnd += pos
+			pos = lineEnd + 1
+		}
+		if lineEnd > lineStart && p.content[lineEnd-1] == '\r' {
+			lineEnd--
+		}
+
+		textStart := lineStart
+		for textStart < lineEnd && isSpace(p.content[textStart]) {
+			textStart++
+		}
+		if textStart == lineEnd {
+			continue
+		}
+
+		if p.content[textStart] == '#' {
+			p.comments = append(p.comments, source.Span{Start: lineStart, End: lineEnd})
+
+			// Only "#Keyword value" is a commented default, "# Keyword ..."
+			// being most likely a sentence
+			directive, _ := p.parseDirective(lineStart, textStart+1, lineEnd, true)
+			if directive == nil {
+				continue
+			}
+			if isBlockKeyword(directive.Keyword) {
+				defaultsEnded = true
+			} else if !defaultsEnded {
+				current.Defaults = append(current.Defaults, directive)
+			}
+			continue
+		}
+
+		directive, err := p.parseDirective(lineStart, textStart, lineEnd, false)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if isBlockKeyword(directive.Keyword) {
+			current = &Block{Header: directive}
+			blocks = append(blocks, current)
+			defaultsEnded = false
+			continue
+		}
+		current.Directives = append(current.Directives, directive)
+	}
+
+	return global, blocks, nil
+}
+
+func ParseSshContent(content string) (*Block, []*Block, []source.Span, error) {
+	p := &parser{content: content}
+
+	global, blocks, err := p.parse()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	return global, blocks, p.comments, nil
+}
+
+// isClientConfiguration tells if the file is an ssh_config file, from its
+// name or its Host blocks
+func isClientConfiguration(filePath string, blocks []*Block) bool {
+	name := filepath.Base(filePath)
+	if name == "ssh_config" || name == "config" {
+		return true
+	}
+
+	for _, block := range blocks {
+		if strings.EqualFold(block.Header.Keyword, "Host") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func GetParsedSshFile(filePath string) (SshConfiguration, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return SshConfiguration{}, err
+	}
+
+	global, blocks, comments, err := ParseSshContent(content)
+	if err != nil {
+		return SshConfiguration{}, err
+	}
+
+	return SshConfiguration{content, global, blocks, comments, isClientConfiguration(filePath, blocks), filePath}, nil
+}
@@ -0,0 +1,494 @@
+package ssh
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+var segmentRegexp = regexp.MustCompile(`^([A-Za-z]+)\[(.*)\]$`)
+
+// Key is a parsed key: a keyword in the global scope (PasswordAuthentication),
+// in a block (Match[User git].PasswordAuthentication, Host[2].User), or a
+// block alone (Match[User git]), in which case Keyword is empty
+type Key struct {
+	Block   string
+	Index   int
+	Pattern string
+	Keyword string
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
+
+func parseBlockSegment(segment string, key *Key) error {
+	if isBlockKeyword(segment) {
+		key.Block = segment
+
+		return nil
+	}
+
+	matches := segmentRegexp.FindStringSubmatch(segment)
+	if matches == nil || !isBlockKeyword(matches[1]) {
+		return errors.New(fmt.Sprintf("Invalid block %s: expected Match[...] or Host[...]", segment))
+	}
+	key.Block = matches[1]
+
+	predicate := strings.TrimSpace(matches[2])
+	if index, err := strconv.Atoi(predicate); err == nil {
+		if index < 1 {
+			return errors.New(fmt.Sprintf("Invalid position in %s: positions start at 1", segment))
+		}
+		key.Index = index
+	} else {
+		key.Pattern = strings.Join(strings.Fields(unquote(predicate)), " ")
+	}
+
+	return nil
+}
+
+func parseKey(notationStyle core.NotationStyle, key string) (Key, error) {
+	parts, err := core.DecomposeKeyKeepingPredicates(notationStyle, key)
+	if err != nil {
+		return Key{}, err
+	}
+
+	parsed := Key{}
+	switch len(parts) {
+	case 1:
+		if isBlockKeyword(parts[0]) || strings.Contains(parts[0], "[") {
+			return parsed, parseBlockSegment(parts[0], &parsed)
+		}
+		parsed.Keyword = parts[0]
+	case 2:
+		if err := parseBlockSegment(parts[0], &parsed); err != nil {
+			return parsed, err
+		}
+		parsed.Keyword = parts[1]
+	default:
+		return parsed, errors.New(fmt.Sprintf("Invalid key %s: expected Keyword or Match[...].Keyword", key))
+	}
+
+	if parsed.Keyword != "" && !keywordRegexp.MatchString(parsed.Keyword) {
+		return parsed, errors.New(fmt.Sprintf("Invalid keyword %s", parsed.Keyword))
+	}
+
+	return parsed, nil
+}
+
+func (block *Block) matches(pattern string) bool {
+	criteria := strings.Join(block.Header.Arguments, " ")
+	if criteria == pattern {
+		return true
+	}
+
+	// A Host block can be selected by any of its patterns
+	if strings.EqualFold(block.Header.Keyword, "Host") {
+		for _, argument := range block.Header.Arguments {
+			if argument == pattern {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// findBlock returns the block of the key, or nil for the global scope
+func (config *SshConfiguration) findBlock(key Key) (*Block, error) {
+	if key.Block == "" {
+		return nil, nil
+	}
+
+	matching := []*Block{}
+	for _, block := range config.Blocks {
+		if !strings.EqualFold(block.Header.Keyword, key.Block) {
+			continue
+		}
+		if key.Pattern != "" && !block.matches(key.Pattern) {
+			continue
+		}
+		matching = append(matching, block)
+	}
+
+	if key.Index > 0 {
+		if key.Index > len(matching) {
+			return nil, errors.New("Key not found")
+		}
+		matching = matching[key.Index-1 : key.Index]
+	}
+
+	if len(matching) == 0 {
+		return nil, errors.New("Key not found")
+	}
+	if len(matching) > 1 {
+		return nil, errors.New(fmt.Sprintf(
+			"%d %s blocks match, use a predicate to choose one (e.g. %s[1])",
+			len(matching), key.Block, key.Block,
+		))
+	}
+
+	return matching[0], nil
+}
+
+func (block *Block) find(keyword string) []*Directive {
+	found := []*Directive{}
+	for _, directive := range block.Directives {
+		if strings.EqualFold(directive.Keyword, keyword) {
+			found = append(found, directive)
+		}
+	}
+
+	return found
+}
+
+func (block *Block) findDefault(keyword string) *Directive {
+	for _, directive := range block.Defaults {
+		if strings.EqualFold(directive.Keyword, keyword) {
+			return directive
+		}
+	}
+
+	return nil
+}
+
+// hostPatternMatches tells if a Host pattern (*.example.com) matches a name,
+// its * and ? wildcards being matched by themselves as well
+func hostPatternMatches(pattern string, name string) bool {
+	expression := regexp.QuoteMeta(pattern)
+	expression = strings.ReplaceAll(expression, `\*`, ".*")
+	expression = strings.ReplaceAll(expression, `\?`, ".")
+
+	return regexp.MustCompile("(?i)^" + expression + "$").MatchString(name)
+}
+
+// covers tells if a Host block applies to all the hosts of the patterns: one
+// of its patterns matches each of them and none of its negated patterns does
+func (block *Block) covers(patterns []string) bool {
+	if !strings.EqualFold(block.Header.Keyword, "Host") {
+		return false
+	}
+
+	for _, pattern := range patterns {
+		matched := false
+		for _, argument := range block.Header.Arguments {
+			if strings.HasPrefix(argument, "!") {
+				if hostPatternMatches(argument[1:], pattern) {
+					return false
+				}
+			} else if hostPatternMatches(argument, pattern) {
+				matched = true
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	return true
+}
+
+// clientScopes returns the blocks an ssh client reads, in order, for the
+// hosts of a Host block, or for every host (block is nil): the block itself
+// and the Host blocks applying to all its hosts (Host *). Match blocks
+// depend on the connection and only apply to themselves.
+func (config *SshConfiguration) clientScopes(block *Block) []*Block {
+	patterns := []string{"*"}
+	if block != nil {
+		if !strings.EqualFold(block.Header.Keyword, "Host") {
+			return []*Block{block}
+		}
+		patterns = []string{}
+		for _, argument := range block.Header.Arguments {
+			if !strings.HasPrefix(argument, "!") {
+				patterns = append(patterns, argument)
+			}
+		}
+	}
+
+	scopes := []*Block{}
+	for _, candidate := range config.Blocks {
+		if candidate == block || candidate.covers(patterns) {
+			scopes = append(scopes, candidate)
+		}
+	}
+
+	return scopes
+}
+
+// Effective returns the directives in use for a keyword in the global scope
+// (block is nil) or in a block. The first value wins: sshd reads a Match
+// block before the global scope, ssh reads the file from the top, the global
+// scope and every Host block applying to the hosts of the block (Host *)
+// being read in turn. Cumulative keywords (Port, IdentityFile...) use all
+// their values.
+func (config *SshConfiguration) Effective(block *Block, keyword string) []*Directive {
+	scopes := []*Block{config.Global}
+	if config.Client {
+		scopes = append(scopes, config.clientScopes(block)...)
+	} else if block != nil {
+		scopes = []*Block{block, config.Global}
+	}
+
+	effective := []*Directive{}
+	for _, scope := range scopes {
+		found := scope.find(keyword)
+		if len(found) == 0 {
+			continue
+		}
+		if !isCumulative(keyword) {
+			return found[:1]
+		}
+		effective = append(effective, found...)
+	}
+
+	return effective
+}
+
+func OutputConfigFile(config *SshConfiguration, outputType core.OutputType) string {
+	if outputType != core.MeaningFullOutput {
+		return config.Content
+	}
+
+	return source.RemoveComments(config.Content, config.Comments)
+}
+
+func resolve(notationStyle core.NotationStyle, config *SshConfiguration, key string) (Key, *Block, error) {
+	parsed, err := parseKey(notationStyle, key)
+	if err != nil {
+		return parsed, nil, err
+	}
+
+	block, err := config.findBlock(parsed)
+
+	return parsed, block, err
+}
+
+// GetParameterFromPath returns the effective value of a keyword, one line per
+// value for cumulative keywords
+func GetParameterFromPath(notationStyle core.NotationStyle, filePath string, key string) (string, error) {
+	config, err := GetParsedSshFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	parsed, block, err := resolve(notationStyle, &config, key)
+	if err != nil {
+		return "", err
+	}
+	if parsed.Keyword == "" {
+		return "", errors.New(fmt.Sprintf("%s is a block, not a keyword", key))
+	}
+
+	directives := config.Effective(block, parsed.Keyword)
+	if len(directives) == 0 {
+		return "", errors.New("Key not found")
+	}
+
+	values := []string{}
+	for _, directive := range directives {
+		values = append(values, config.Content[directive.Value.Start:directive.Value.End])
+	}
+
+	return strings.Join(values, "\n"), nil
+}
+
+// EditConfigFile sets the value of a keyword in the global scope or in a
+// block. A missing keyword uncomments its commented default if there is one,
+// or is added after the other directives of its scope: before the first
+// block for the global scope, as it would be scoped by the block otherwise.
+func EditConfigFile(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+	add bool,
+) (*SshConfiguration, error) {
+	config, err := GetParsedSshFile(filePath)
+	if err != nil {
+		return &SshConfiguration{}, err
+	}
+
+	if strings.ContainsAny(value, "\r\n") {
+		return &SshConfiguration{}, errors.New("Values cannot contain newlines")
+	}
+	if strings.TrimSpace(value) == "" {
+		return &SshConfiguration{}, errors.New("Values cannot be empty")
+	}
+
+	parsed, block, err := resolve(notationStyle, &config, key)
+	if err != nil {
+		return &SshConfiguration{}, err
+	}
+	if parsed.Keyword == "" || isBlockKeyword(parsed.Keyword) {
+		return &SshConfiguration{}, errors.New(fmt.Sprintf("Cannot set %s: blocks are edited through their keywords", key))
+	}
+
+	scope := block
+	if scope == nil {
+		scope = config.Global
+	}
+	existing := scope.find(parsed.Keyword)
+
+	if block != nil && config.Client && !isCumulative(parsed.Keyword) {
+		if global := config.Global.find(parsed.Keyword); len(global) > 0 {
+			return &SshConfiguration{}, errors.New(fmt.Sprintf(
+				"Cannot set %s: %s is set in the global scope, which takes precedence over Host blocks",
+				key, global[0].Keyword,
+			))
+		}
+	}
+
+	switch {
+	case !add && len(existing) > 1 && isCumulative(parsed.Keyword):
+		return &SshConfiguration{}, errors.New(fmt.Sprintf(
+			"%d %s directives are used, use add to add another one or unset to remove them",
+			len(existing), existing[0].Keyword,
+		))
+	case !add && len(existing) > 0:
+		// Only the first occurrence is used
+		config.replace(existing[0].Value, value)
+	case len(existing) == 0 && scope.findDefault(parsed.Keyword) != nil:
+		config.uncomment(scope.findDefault(parsed.Keyword), value)
+	default:
+		config.insertDirective(scope, existing, parsed.Keyword, value)
+	}
+
+	return config.validate(key)
+}
+
+// RemoveFromConfigFile removes all the occurrences of a keyword in its scope,
+// or a whole block
+func RemoveFromConfigFile(notationStyle core.NotationStyle, filePath string, key string) (*SshConfiguration, error) {
+	config, err := GetParsedSshFile(filePath)
+	if err != nil {
+		return &SshConfiguration{}, err
+	}
+
+	parsed, block, err := resolve(notationStyle, &config, key)
+	if err != nil {
+		return &SshConfiguration{}, err
+	}
+
+	if parsed.Keyword == "" {
+		end := block.Header.Line.End
+		if len(block.Directives) > 0 {
+			end = block.Directives[len(block.Directives)-1].Line.End
+		}
+		config.removeLines(source.Span{Start: block.Header.Line.Start, End: end})
+
+		return config.validate(key)
+	}
+
+	scope := block
+	if scope == nil {
+		scope = config.Global
+	}
+	existing := scope.find(parsed.Keyword)
+	if len(existing) == 0 {
+		return &SshConfiguration{}, errors.New("Key not found")
+	}
+
+	for i := len(existing) - 1; i >= 0; i-- {
+		config.removeLines(existing[i].Line)
+	}
+
+	return config.validate(key)
+}
+
+func (config *SshConfiguration) validate(key string) (*SshConfiguration, error) {
+	global, blocks, comments, err := ParseSshContent(config.Content)
+	if err != nil {
+		return &SshConfiguration{}, errors.New(fmt.Sprintf("Refusing to edit %s: %s", key, err.Error()))
+	}
+	config.Global = global
+	config.Blocks = blocks
+	config.Comments = comments
+
+	return config, nil
+}
+
+func (config *SshConfiguration) replace(span source.Span, replacement string) {
+	config.Content = config.Content[:span.Start] + replacement + config.Content[span.End:]
+}
+
+// removeLines removes the lines of the span, with their newline
+func (config *SshConfiguration) removeLines(lines source.Span) {
+	end := strings.IndexByte(config.Content[lines.End:], '\n')
+	if end == -1 {
+		end = len(config.Content)
+	} else {
+		end += lines.End + 1
+	}
+
+	config.replace(source.Span{Start: lines.Start, End: end}, "")
+}
+
+func (config *SshConfiguration) uncomment(directive *Directive, value string) {
+	config.replace(directive.Value, value)
+	// The "#" is right before the keyword
+	config.replace(source.Span{Start: directive.KeywordStart - 1, End: directive.KeywordStart}, "")
+}
+
+func (config *SshConfiguration) indentOf(directive *Directive) string {
+	return config.Content[directive.Line.Start:directive.KeywordStart]
+}
+
+// blockIndent returns the indentation used in the blocks of the file
+func (config *SshConfiguration) blockIndent() string {
+	for _, block := range config.Blocks {
+		if len(block.Directives) > 0 {
+			return config.indentOf(block.Directives[0])
+		}
+	}
+
+	return "    "
+}
+
+func (config *SshConfiguration) insertAfter(directive *Directive, line string) {
+	config.replace(source.Span{Start: directive.Line.End, End: directive.Line.End}, "\n"+config.indentOf(directive)+line)
+}
+
+func (config *SshConfiguration) insertDirective(scope *Block, existing []*Directive, keyword string, value string) {
+	if len(existing) > 0 {
+		config.insertAfter(existing[len(existing)-1], existing[0].Keyword+" "+value)
+
+		return
+	}
+
+	line := keyword + " " + value
+	if len(scope.Directives) > 0 {
+		config.insertAfter(scope.Directives[len(scope.Directives)-1], line)
+
+		return
+	}
+
+	if scope.Header != nil {
+		header := scope.Header
+		config.replace(source.Span{Start: header.Line.End, End: header.Line.End}, "\n"+config.blockIndent()+line)
+
+		return
+	}
+
+	if len(config.Blocks) > 0 {
+		start := config.Blocks[0].Header.Line.Start
+		config.replace(source.Span{Start: start, End: start}, line+"\n")
+
+		return
+	}
+
+	if config.Content != "" && !strings.HasSuffix(config.Content, "\n") {
+		line = "\n" + line
+	}
+	config.replace(source.Span{Start: len(config.Content), End: len(config.Content)}, line+"\n")
+}
@@ -0,0 +1,187 @@
+package ssh
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/testutil"
+)
+
+const SSHD_FILE_PATH = "../../../data/ssh/sshd_config"
+const SSH_FILE_PATH = "../../../data/ssh/ssh_config"
+
+func TestGetParameter(t *testing.T) {
+	cases := map[string]map[string]string{
+		SSHD_FILE_PATH: {
+			"PasswordAuthentication":            "yes",
+			"passwordauthentication":            "yes",
+			"Subsystem":                         "sftp\t/usr/lib/openssh/sftp-server",
+			"HostKey":                           "/etc/ssh/ssh_host_rsa_key\n/etc/ssh/ssh_host_ed25519_key",
+			"Match[User anoncvs].X11Forwarding": "no",
+			"Match[1].UsePAM":                   "yes",
+			"Match[Group admins  Address 10.0.0.0/8].PrintMotd": "no",
+			"Match[2].PasswordAuthentication":                   "yes",
+		},
+		SSH_FILE_PATH: {
+			"ServerAliveInterval":              "60",
+			"Host[*].ServerAliveInterval":      "60",
+			"Host[*].Compression":              "yes",
+			"Host[gitlab.com].User":            "git",
+			"Host[github.com gitlab.com].User": "git",
+			"Host[1].IdentityFile":             "~/.ssh/id_ed25519\n~/.ssh/id_git",
+			"Host[bastion].Port":               "2222",
+			"Host[bastion].Compression":        "yes",
+			"Host[*.example.com].User":         "admin",
+			"Host[*.example.com].Compression":  "yes",
+			"Compression":                      "yes",
+		},
+	}
+
+	for filePath, fileCases := range cases {
+		for key, expectedValue := range fileCases {
+			t.Run("it gets existing parameter "+key, func(t *testing.T) {
+				value, err := GetParameterFromPath(core.DotNotation, filePath, key)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if value != expectedValue {
+					t.Fatal(fmt.Sprintf("Expected %s got %s", expectedValue, value))
+				}
+			})
+		}
+	}
+
+	errorCases := []string{"PermitRootLogin", "Match", "Match.X11Forwarding", "Match[3].UsePAM", "Match[User git].UsePAM", "Host[1].User", "Match[1].PermitTTY.foo"}
+	for _, key := range errorCases {
+		t.Run("it does not get "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, SSHD_FILE_PATH, key)
+			if err == nil {
+				t.Error("Should fail. Got " + value + " instead")
+			}
+		})
+	}
+}
+
+func TestEditParameter(t *testing.T) {
+	type EditTestElement struct {
+		filePath string
+		key      string
+		value    string
+		add      bool
+		old      string
+		new      string
+	}
+
+	cases := []EditTestElement{
+		{SSHD_FILE_PATH, "PasswordAuthentication", "no", false, "PasswordAuthentication yes\n#Permit", "PasswordAuthentication no\n#Permit"},
+		{SSHD_FILE_PATH, "permitrootlogin", "no", false, "#PermitRootLogin prohibit-password", "PermitRootLogin no"},
+		{SSHD_FILE_PATH, "Port", "2222", false, "#Port 22", "Port 2222"},
+		{SSHD_FILE_PATH, "ClientAliveInterval", "300", false, "\nPasswordAuthentication no\n", "\nPasswordAuthentication no\nClientAliveInterval 300\n"},
+		{SSHD_FILE_PATH, "HostKey", "/etc/ssh/ssh_host_ecdsa_key", true, "ed25519_key\n", "ed25519_key\nHostKey /etc/ssh/ssh_host_ecdsa_key\n"},
+		{SSHD_FILE_PATH, "Match[User anoncvs].X11Forwarding", "yes", false, "\tX11Forwarding no", "\tX11Forwarding yes"},
+		{SSHD_FILE_PATH, "Match[User anoncvs].PermitTTY", "yes", false, "\t#PermitTTY no", "\tPermitTTY yes"},
+		{SSHD_FILE_PATH, "Match[2].MaxSessions", "2", false, "\tPasswordAuthentication yes\n", "\tPasswordAuthentication yes\n\tMaxSessions 2\n"},
+		{SSH_FILE_PATH, "Host[bastion].ForwardAgent", "no", false, "    #ForwardAgent yes", "    ForwardAgent no"},
+		{SSH_FILE_PATH, "Host[github.com].IdentitiesOnly", "yes", false, "    IdentityFile ~/.ssh/id_git\n", "    IdentityFile ~/.ssh/id_git\n    IdentitiesOnly yes\n"},
+		{SSH_FILE_PATH, "StrictHostKeyChecking", "ask", false, "id_ed25519\n", "id_ed25519\nStrictHostKeyChecking ask\n"},
+	}
+
+	for _, element := range cases {
+		t.Run("it sets "+element.key, func(t *testing.T) {
+			config, err := EditConfigFile(core.DotNotation, element.filePath, element.key, element.value, element.add)
+			testutil.AssertEditedOutput(t, element.filePath, config, err, element.old, element.new)
+		})
+	}
+
+	errorCases := map[string]string{
+		"HostKey":                         "/etc/ssh/key",
+		"Match[User anoncvs]":             "Group admins",
+		"Match":                           "User git",
+		"PasswordAuthentication":          "no\nPermitRootLogin yes",
+		"UsePAM":                          " ",
+		"Banner":                          `"/etc/issue`,
+		"Match[User git].PermitTTY":       "no",
+		"Match[User anoncvs].PermitTTY.x": "no",
+	}
+	for key, value := range errorCases {
+		t.Run("it refuses to set "+key+" to "+value, func(t *testing.T) {
+			if _, err := EditConfigFile(core.DotNotation, SSHD_FILE_PATH, key, value, false); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+
+	t.Run("it refuses to set a Host keyword overridden by the global scope", func(t *testing.T) {
+		if _, err := EditConfigFile(core.DotNotation, SSH_FILE_PATH, "Host[*].ServerAliveInterval", "10", false); err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
+
+func TestUnsetParameter(t *testing.T) {
+	cases := map[string][]string{
+		"HostKey":                                {"HostKey /etc/ssh/ssh_host_rsa_key\nHostKey /etc/ssh/ssh_host_ed25519_key\n", ""},
+		"Match[User anoncvs].AllowTcpForwarding": {"\tAllowTcpForwarding no\n", ""},
+		"Match[Group admins Address 10.0.0.0/8]": {"Match Group admins Address 10.0.0.0/8\n\tPasswordAuthentication yes\n", ""},
+	}
+
+	for key, replacement := range cases {
+		t.Run("it unsets "+key, func(t *testing.T) {
+			config, err := RemoveFromConfigFile(core.DotNotation, SSHD_FILE_PATH, key)
+			testutil.AssertEditedOutput(t, SSHD_FILE_PATH, config, err, replacement[0], replacement[1])
+		})
+	}
+
+	t.Run("it unsets all the occurrences of a keyword in its scope", func(t *testing.T) {
+		config, err := RemoveFromConfigFile(core.DotNotation, SSHD_FILE_PATH, "PasswordAuthentication")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(config.Global.find("PasswordAuthentication")) != 0 {
+			t.Fatal("PasswordAuthentication is still set in the global scope")
+		}
+		if len(config.Blocks[1].find("PasswordAuthentication")) != 1 {
+			t.Fatal("PasswordAuthentication should still be set in the Match block")
+		}
+	})
+
+	for _, key := range []string{"PermitRootLogin", "Match", "Match[User anoncvs].PermitTTY"} {
+		t.Run("it does not unset "+key, func(t *testing.T) {
+			if _, err := RemoveFromConfigFile(core.DotNotation, SSHD_FILE_PATH, key); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestParseInvalidSsh(t *testing.T) {
+	cases := []string{
+		"PasswordAuthentication",
+		"Match",
+		"Banner \"/etc/issue",
+		"Pass-word no",
+	}
+
+	for _, content := range cases {
+		t.Run("it rejects "+content, func(t *testing.T) {
+			if _, _, _, err := ParseSshContent(content); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestReadDocument(t *testing.T) {
+	testutil.AssertDocumentValues(t, SshConfigurator{}, SSH_FILE_PATH, map[string]string{
+		"ServerAliveInterval": "60",
+		"Host[1].User":        "git",
+		"Host[2].Port":        "2222",
+	})
+	testutil.AssertDocumentValues(t, SshConfigurator{}, SSHD_FILE_PATH, map[string]string{
+		"HostKey.1": "/etc/ssh/ssh_host_ed25519_key",
+		"AcceptEnv": "LANG LC_*",
+	})
+}
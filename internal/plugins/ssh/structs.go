@@ -0,0 +1,122 @@
+package ssh
+
+import (
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+// Directive is a keyword and its arguments (PasswordAuthentication no). A
+// commented directive (#PermitRootLogin prohibit-password) is a default
+// that can be uncommented instead of adding a new line.
+type Directive struct {
+	Keyword   string
+	Arguments []string
+	// Line goes from the start of the line to its end, newline excluded
+	Line         source.Span
+	KeywordStart int
+	Value        source.Span
+	Commented    bool
+}
+
+// Block is the global scope of the file or a Match or Host block, which
+// applies to all the lines following it until the next block
+type Block struct {
+	Header     *Directive
+	Directives []*Directive
+	// Defaults are the commented directives of the block
+	Defaults []*Directive
+}
+
+func isBlockKeyword(keyword string) bool {
+	return strings.EqualFold(keyword, "Match") || strings.EqualFold(keyword, "Host")
+}
+
+// cumulativeKeywords can be given several times, all their values being used
+// instead of only the first one
+var cumulativeKeywords = []string{
+	"AcceptEnv", "CertificateFile", "DynamicForward", "HostCertificate", "HostKey",
+	"IdentityFile", "ListenAddress", "LocalForward", "Port", "RemoteForward",
+	"SendEnv", "Subsystem",
+}
+
+func isCumulative(keyword string) bool {
+	for _, cumulative := range cumulativeKeywords {
+		if strings.EqualFold(cumulative, keyword) {
+			return true
+		}
+	}
+
+	return false
+}
+
+type SshConfiguration struct {
+	Content string
+	Global  *Block
+	Blocks  []*Block
+	// Comments are the spans of the comment lines
+	Comments []source.Span
+	// Client is true for ssh_config files, where the global scope takes
+	// precedence over the Host blocks following it
+	Client   bool
+	FilePath string
+}
+
+func (config *SshConfiguration) OutputFile(outputType core.OutputType) (string, error) {
+	return OutputConfigFile(config, outputType), nil
+}
+
+func (config *SshConfiguration) WriteToFile(filepath string, outputType core.OutputType) error {
+	output, err := config.OutputFile(outputType)
+	if err != nil {
+		return err
+	}
+
+	return io.WriteFileContents(filepath, output)
+}
+
+type SshConfigurator struct{}
+
+func (configurator SshConfigurator) GetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (string, error) {
+	return GetParameterFromPath(notationStyle, filePath, key)
+}
+
+func (configurator SshConfigurator) SetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, false))
+}
+
+func (configurator SshConfigurator) AddParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, true))
+}
+
+func (configurator SshConfigurator) UnsetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (core.Configuration, error) {
+	return toConfiguration(RemoveFromConfigFile(notationStyle, filePath, key))
+}
+
+func toConfiguration(config *SshConfiguration, err error) (core.Configuration, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
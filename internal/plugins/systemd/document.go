@@ -0,0 +1,24 @@
+package systemd
+
+import (
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+var documentOptions = ini.DocumentOptions{
+	Overrides: func(key string) bool {
+		return !IsListKey(key)
+	},
+}
+
+// ReadDocument returns the document tree of the unit file: sections are
+// section nodes and the assignments of list keys (After, Environment...) are
+// lists, the last assignment of the other keys being the one in use
+func (configurator SystemdConfigurator) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	config, err := GetParsedSystemdFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return ini.BuildDocument(config.GlobalSection, config.Sections, documentOptions, losses), nil
+}
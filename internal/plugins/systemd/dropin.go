@@ -0,0 +1,128 @@
+package systemd
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+// DropInPath returns the path of the override.conf drop-in of a unit, in
+// the given directory or next to the unit file if directory is empty
+func DropInPath(unitFile string, directory string) string {
+	if directory == "" {
+		directory = filepath.Dir(unitFile)
+	}
+
+	return filepath.Join(directory, filepath.Base(unitFile)+".d", "override.conf")
+}
+
+// DropInFiles returns the drop-ins of a unit, sorted by name as systemd
+// applies them. They are searched next to the unit file then in the given
+// directories, a drop-in overriding the one with the same name in a previous
+// directory.
+func DropInFiles(unitFile string, directories []string) ([]string, error) {
+	byName := map[string]string{}
+	for _, directory := range append([]string{filepath.Dir(unitFile)}, directories...) {
+		paths, err := filepath.Glob(filepath.Join(directory, filepath.Base(unitFile)+".d", "*.conf"))
+		if err != nil {
+			return nil, err
+		}
+		for _, path := range paths {
+			byName[filepath.Base(path)] = path
+		}
+	}
+
+	names := []string{}
+	for name := range byName {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	files := []string{}
+	for _, name := range names {
+		files = append(files, byName[name])
+	}
+
+	return files, nil
+}
+
+// GetEffectiveParameter returns the value of a key once the unit file and all
+// its drop-ins are merged
+func GetEffectiveParameter(
+	notationStyle core.NotationStyle,
+	unitFile string,
+	directories []string,
+	key string,
+) (string, error) {
+	sectionName, keyName, err := decomposeKey(notationStyle, key)
+	if err != nil {
+		return "", err
+	}
+
+	dropIns, err := DropInFiles(unitFile, directories)
+	if err != nil {
+		return "", err
+	}
+
+	assignments := []string{}
+	for _, file := range append([]string{unitFile}, dropIns...) {
+		config, err := GetParsedSystemdFile(file)
+		if err != nil {
+			return "", err
+		}
+		assignments = append(assignments, config.Assignments(sectionName, keyName)...)
+	}
+
+	if len(assignments) == 0 {
+		return "", errors.New("Key not found")
+	}
+
+	return strings.Join(EffectiveValues(keyName, assignments), "\n"), nil
+}
+
+// OverrideParameter sets a key in the override.conf drop-in of a unit,
+// creating the drop-in if needed. The previous assignments of the key in the
+// drop-in are replaced, and a list key is reset first so that the value
+// replaces the one of the unit instead of being added to it.
+func OverrideParameter(
+	notationStyle core.NotationStyle,
+	unitFile string,
+	directory string,
+	key string,
+	value string,
+) (*SystemdConfiguration, error) {
+	if _, err := os.Stat(unitFile); err != nil {
+		return &SystemdConfiguration{}, err
+	}
+
+	sectionName, keyName, err := decomposeKey(notationStyle, key)
+	if err != nil {
+		return &SystemdConfiguration{}, err
+	}
+	if err := validateValue(value); err != nil {
+		return &SystemdConfiguration{}, err
+	}
+
+	path := DropInPath(unitFile, directory)
+	config := SystemdConfiguration{&ini.GlobalSection{Lines: []*ini.Line{}}, []*ini.Section{}, path}
+	if _, err := os.Stat(path); err == nil {
+		config, err = GetParsedSystemdFile(path)
+		if err != nil {
+			return &SystemdConfiguration{}, err
+		}
+	}
+
+	section := config.getOrAddSection(sectionName)
+	config.removeKey(section, keyName)
+	if IsListKey(keyName) {
+		config.addAssignment(section, keyName, "")
+	}
+	config.addAssignment(section, keyName, value)
+
+	return &config, nil
+}
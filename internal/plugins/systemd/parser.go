@@ -0,0 +1,122 @@
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+func isComment(trimmedLine string) bool {
+	return strings.HasPrefix(trimmedLine, "#") || strings.HasPrefix(trimmedLine, ";")
+}
+
+func isContinued(line string) bool {
+	return strings.HasSuffix(strings.TrimRight(line, " \t\r"), "\\")
+}
+
+// joinContinuation joins continued lines the way systemd does, replacing the
+// backslash and the newline by a space
+func joinContinuation(lines []string) string {
+	parts := []string{}
+	for i, line := range lines {
+		line = strings.TrimSpace(line)
+		if i < len(lines)-1 {
+			line = strings.TrimSpace(strings.TrimSuffix(line, "\\"))
+		}
+		parts = append(parts, line)
+	}
+
+	return strings.Join(parts, " ")
+}
+
+func otherLine(lineNumber int, lineString string, spacePrefix string) *ini.Line {
+	return &ini.Line{
+		LineNumber:    lineNumber,
+		StringContent: lineString,
+		SpacePrefix:   spacePrefix,
+		Status:        ini.Original,
+		ContentType:   ini.OtherType,
+	}
+}
+
+// ParseSystemdContent parses a unit file into INI lines. Unlike INI files,
+// comments start with "#" or ";", values can contain "=" and lines ending
+// with a backslash are continued on the next one.
+func ParseSystemdContent(content string) ([]*ini.Line, error) {
+	physicalLines := strings.Split(content, "\n")
+	lines := []*ini.Line{}
+
+	for idx := 0; idx < len(physicalLines); idx++ {
+		lineNumber := idx + 1
+		lineString := physicalLines[idx]
+		trimmedLine := strings.TrimSpace(lineString)
+		spacePrefix := lineString[:len(lineString)-len(strings.TrimLeft(lineString, " \t"))]
+
+		if trimmedLine == "" || isComment(trimmedLine) {
+			lines = append(lines, otherLine(lineNumber, lineString, spacePrefix))
+			continue
+		}
+
+		if strings.HasPrefix(trimmedLine, "[") {
+			if !strings.HasSuffix(trimmedLine, "]") {
+				return nil, errors.New(fmt.Sprintf("Invalid systemd unit on line %d: unterminated section header", lineNumber))
+			}
+
+			lines = append(lines, &ini.Line{
+				LineNumber:    lineNumber,
+				StringContent: lineString,
+				SpacePrefix:   spacePrefix,
+				Status:        ini.Original,
+				ContentType:   ini.SectionLineType,
+				SectionLine:   &ini.SectionLine{SectionName: trimmedLine[1 : len(trimmedLine)-1]},
+			})
+			continue
+		}
+
+		continued := []string{lineString}
+		for isContinued(continued[len(continued)-1]) && idx+1 < len(physicalLines) {
+			idx++
+			continued = append(continued, physicalLines[idx])
+		}
+
+		logicalLine := joinContinuation(continued)
+		separator := strings.Index(logicalLine, "=")
+		if separator == -1 {
+			return nil, errors.New(fmt.Sprintf("Invalid systemd unit on line %d: missing \"=\"", lineNumber))
+		}
+		key := strings.TrimSpace(logicalLine[:separator])
+		if key == "" {
+			return nil, errors.New(fmt.Sprintf("Invalid systemd unit on line %d: missing key", lineNumber))
+		}
+
+		lines = append(lines, &ini.Line{
+			LineNumber:    lineNumber,
+			StringContent: strings.Join(continued, "\n"),
+			SpacePrefix:   spacePrefix,
+			Status:        ini.Original,
+			ContentType:   ini.KeyValueType,
+			KeyValue:      &ini.KeyValue{Key: key, Value: strings.TrimSpace(logicalLine[separator+1:])},
+		})
+	}
+
+	return lines, nil
+}
+
+func GetParsedSystemdFile(filePath string) (SystemdConfiguration, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return SystemdConfiguration{}, err
+	}
+
+	lines, err := ParseSystemdContent(content)
+	if err != nil {
+		return SystemdConfiguration{}, err
+	}
+
+	globalSection, sections := ini.GetSections(lines)
+
+	return SystemdConfiguration{globalSection, sections, filePath}, nil
+}
@@ -0,0 +1,99 @@
+package systemd
+
+import (
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+// SystemdConfiguration is a unit file or a drop-in, parsed with the INI line
+// model. A line continued with a backslash is a single line whose
+// StringContent spans several lines of the file.
+type SystemdConfiguration struct {
+	GlobalSection *ini.GlobalSection
+	Sections      []*ini.Section
+	FilePath      string
+}
+
+// listKeyPrefixes are the keys which can be assigned several times to build
+// a list, an empty assignment resetting it. Other keys are overridden by
+// their last assignment.
+var listKeyPrefixes = []string{
+	"After", "Alias", "Also", "Assert", "Before", "BindPaths", "BindReadOnlyPaths",
+	"BindsTo", "Condition", "Conflicts", "DeviceAllow", "Documentation",
+	"Environment", "EnvironmentFile", "Exec", "InaccessiblePaths", "Listen",
+	"OnActiveSec", "OnBootSec", "OnCalendar", "OnFailure", "OnStartupSec",
+	"OnSuccess", "OnUnitActiveSec", "OnUnitInactiveSec", "PartOf", "PassEnvironment",
+	"ReadOnlyPaths", "ReadWritePaths", "RequiredBy", "Requires", "Requisite",
+	"Symlinks", "UpheldBy", "Upholds", "WantedBy", "Wants",
+}
+
+// IsListKey tells if the assignments of a key are added to a list
+func IsListKey(key string) bool {
+	for _, prefix := range listKeyPrefixes {
+		if strings.HasPrefix(key, prefix) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (config *SystemdConfiguration) OutputFile(outputType core.OutputType) (string, error) {
+	return OutputConfigFile(config, outputType), nil
+}
+
+func (config *SystemdConfiguration) WriteToFile(filepath string, outputType core.OutputType) error {
+	output, err := config.OutputFile(outputType)
+	if err != nil {
+		return err
+	}
+
+	return io.WriteFileContents(filepath, output)
+}
+
+type SystemdConfigurator struct{}
+
+func (configurator SystemdConfigurator) GetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (string, error) {
+	return GetParameterFromPath(notationStyle, filePath, key)
+}
+
+func (configurator SystemdConfigurator) SetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, false))
+}
+
+func (configurator SystemdConfigurator) AddParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(notationStyle, filePath, key, value, true))
+}
+
+func (configurator SystemdConfigurator) UnsetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (core.Configuration, error) {
+	return toConfiguration(RemoveFromConfigFile(notationStyle, filePath, key))
+}
+
+func toConfiguration(config *SystemdConfiguration, err error) (core.Configuration, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
@@ -0,0 +1,278 @@
+package systemd
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+// specifiers are the letters which can follow a "%" in a unit file
+const specifiers = "aAbBCdEfgGhHiIjJlLmMnNopPqsStTuUvVwWyY%"
+
+func OutputConfigFile(config *SystemdConfiguration, outputType core.OutputType) string {
+	lines := []string{}
+	for _, line := range config.lines() {
+		if outputType == core.MeaningFullOutput && line.ContentType == ini.OtherType {
+			continue
+		}
+		lines = append(lines, line.ToString())
+	}
+
+	if outputType == core.MeaningFullOutput {
+		return strings.Join(lines, "\n") + "\n"
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (config *SystemdConfiguration) lines() []*ini.Line {
+	lines := append([]*ini.Line{}, config.GlobalSection.Lines...)
+	for _, section := range config.Sections {
+		lines = append(lines, section.Lines...)
+	}
+
+	return lines
+}
+
+func decomposeKey(notationStyle core.NotationStyle, key string) (string, string, error) {
+	decomposedKey, err := core.DecomposeKey(notationStyle, key)
+	if err != nil {
+		return "", "", err
+	}
+	if len(decomposedKey) != 2 || decomposedKey[0] == "" || decomposedKey[1] == "" {
+		return "", "", errors.New(fmt.Sprintf("Invalid key %s: expected Section.Key (e.g. Service.ExecStart)", key))
+	}
+
+	return decomposedKey[0], decomposedKey[1], nil
+}
+
+// validateValue checks that a value fits on a line and only uses known
+// specifiers, a literal "%" being written "%%"
+func validateValue(value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return errors.New("Values cannot contain newlines")
+	}
+
+	for i := 0; i < len(value); i++ {
+		if value[i] != '%' {
+			continue
+		}
+		if i+1 == len(value) || !strings.ContainsRune(specifiers, rune(value[i+1])) {
+			return errors.New(fmt.Sprintf("Invalid specifier in %s: use %%%% for a literal %%", value))
+		}
+		i++
+	}
+
+	return nil
+}
+
+func getKeyLines(section *ini.Section, key string) []*ini.Line {
+	lines := []*ini.Line{}
+	if section == nil {
+		return lines
+	}
+
+	for _, line := range section.Lines {
+		if line.ContentType == ini.KeyValueType && line.KeyValue.Key == key {
+			lines = append(lines, line)
+		}
+	}
+
+	return lines
+}
+
+// Assignments returns the values assigned to a key, in order
+func (config *SystemdConfiguration) Assignments(sectionName string, key string) []string {
+	values := []string{}
+	for _, section := range config.Sections {
+		if section.Name != sectionName {
+			continue
+		}
+		for _, line := range getKeyLines(section, key) {
+			values = append(values, line.KeyValue.Value)
+		}
+	}
+
+	return values
+}
+
+// EffectiveValues applies assignments in order: an empty assignment resets a
+// list key, and the last assignment of another key overrides the previous
+// ones
+func EffectiveValues(key string, assignments []string) []string {
+	values := []string{}
+	for _, assignment := range assignments {
+		if assignment == "" || !IsListKey(key) {
+			values = []string{}
+		}
+		if assignment != "" {
+			values = append(values, assignment)
+		}
+	}
+
+	return values
+}
+
+// GetParameterFromPath returns the effective value of a key in the file, one
+// line per value for list keys
+func GetParameterFromPath(notationStyle core.NotationStyle, filePath string, key string) (string, error) {
+	config, err := GetParsedSystemdFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	sectionName, keyName, err := decomposeKey(notationStyle, key)
+	if err != nil {
+		return "", err
+	}
+
+	assignments := config.Assignments(sectionName, keyName)
+	if len(assignments) == 0 {
+		return "", errors.New("Key not found")
+	}
+
+	return strings.Join(EffectiveValues(keyName, assignments), "\n"), nil
+}
+
+// EditConfigFile sets the value of a key, or adds a new assignment if the key
+// does not exist yet or if add is true
+func EditConfigFile(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+	add bool,
+) (*SystemdConfiguration, error) {
+	config, err := GetParsedSystemdFile(filePath)
+	if err != nil {
+		return &SystemdConfiguration{}, err
+	}
+
+	sectionName, keyName, err := decomposeKey(notationStyle, key)
+	if err != nil {
+		return &SystemdConfiguration{}, err
+	}
+	if err := validateValue(value); err != nil {
+		return &SystemdConfiguration{}, err
+	}
+
+	section := config.getOrAddSection(sectionName)
+	existing := getKeyLines(section, keyName)
+
+	switch {
+	case add || len(existing) == 0:
+		config.addAssignment(section, keyName, value)
+	case len(existing) == 1 || !IsListKey(keyName):
+		// The last assignment is the one in use
+		existing[len(existing)-1].SetValue(value)
+	default:
+		return &SystemdConfiguration{}, errors.New(fmt.Sprintf(
+			"%s is assigned %d times, use add to add a value or unset to remove them",
+			key, len(existing),
+		))
+	}
+
+	return &config, nil
+}
+
+// RemoveFromConfigFile removes all the assignments of a key
+func RemoveFromConfigFile(notationStyle core.NotationStyle, filePath string, key string) (*SystemdConfiguration, error) {
+	config, err := GetParsedSystemdFile(filePath)
+	if err != nil {
+		return &SystemdConfiguration{}, err
+	}
+
+	sectionName, keyName, err := decomposeKey(notationStyle, key)
+	if err != nil {
+		return &SystemdConfiguration{}, err
+	}
+
+	section := ini.GetSectionByName(config.Sections, sectionName)
+	if len(getKeyLines(section, keyName)) == 0 {
+		return &SystemdConfiguration{}, errors.New("Key not found")
+	}
+	config.removeKey(section, keyName)
+
+	return &config, nil
+}
+
+func (config *SystemdConfiguration) removeKey(section *ini.Section, key string) {
+	lines := []*ini.Line{}
+	for _, line := range section.Lines {
+		if line.ContentType != ini.KeyValueType || line.KeyValue.Key != key {
+			lines = append(lines, line)
+		}
+	}
+	section.Lines = lines
+}
+
+func newKeyValueLine(key string, value string) *ini.Line {
+	return &ini.Line{
+		Status:      ini.Changed,
+		ContentType: ini.KeyValueType,
+		KeyValue:    &ini.KeyValue{Key: key, Value: value},
+	}
+}
+
+func isEmptyLine(line *ini.Line) bool {
+	return line.ContentType == ini.OtherType && strings.TrimSpace(line.StringContent) == ""
+}
+
+// addAssignment adds an assignment after the last assignment of the key, or
+// after the last assignment of the section
+func (config *SystemdConfiguration) addAssignment(section *ini.Section, key string, value string) {
+	hasKey := len(getKeyLines(section, key)) > 0
+
+	position := 0
+	for idx, line := range section.Lines {
+		switch {
+		case line.ContentType == ini.SectionLineType:
+			position = idx + 1
+		case line.ContentType == ini.KeyValueType && (!hasKey || line.KeyValue.Key == key):
+			position = idx + 1
+		}
+	}
+
+	lines := append([]*ini.Line{}, section.Lines[:position]...)
+	lines = append(lines, newKeyValueLine(key, value))
+	section.Lines = append(lines, section.Lines[position:]...)
+}
+
+// getOrAddSection returns the section with the given name, adding it at the
+// end of the file if needed
+func (config *SystemdConfiguration) getOrAddSection(name string) *ini.Section {
+	if section := ini.GetSectionByName(config.Sections, name); section != nil {
+		return section
+	}
+
+	last := &config.GlobalSection.Lines
+	if len(config.Sections) > 0 {
+		last = &config.Sections[len(config.Sections)-1].Lines
+	}
+
+	// Keep the newline at the end of the file after the new section
+	trailing := []*ini.Line{}
+	if len(*last) > 0 && (*last)[len(*last)-1].StringContent == "" && (*last)[len(*last)-1].ContentType == ini.OtherType {
+		trailing = (*last)[len(*last)-1:]
+		*last = (*last)[:len(*last)-1]
+	} else if len(config.lines()) == 0 {
+		trailing = []*ini.Line{{Status: ini.Original, ContentType: ini.OtherType}}
+	}
+
+	section := &ini.Section{Name: name}
+	if len(*last) > 0 && !isEmptyLine((*last)[len(*last)-1]) {
+		section.Lines = append(section.Lines, &ini.Line{Status: ini.Original, ContentType: ini.OtherType})
+	}
+	section.Lines = append(section.Lines, &ini.Line{
+		Status:      ini.Changed,
+		ContentType: ini.SectionLineType,
+		SectionLine: &ini.SectionLine{SectionName: name},
+	})
+	section.Lines = append(section.Lines, trailing...)
+	config.Sections = append(config.Sections, section)
+
+	return section
+}
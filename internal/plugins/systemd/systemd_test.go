@@ -0,0 +1,208 @@
+package systemd
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/testutil"
+)
+
+const UNIT_FILE_PATH = "../../../data/systemd/nginx.service"
+const ETC_DIRECTORY = "../../../data/systemd/etc"
+
+func TestGetParameter(t *testing.T) {
+	cases := map[string]string{
+		"Unit.Description":     "A high performance web server and a reverse proxy server",
+		"Service.Environment":  "LANG=C\n\"NGINX_OPTS=-q\"",
+		"Service.ExecStart":    "/usr/sbin/nginx -g 'daemon on; master_process on;' $NGINX_OPTS",
+		"Service.ExecStop":     "-/sbin/start-stop-daemon --quiet --stop --retry QUIT/5 --pidfile /run/nginx.pid",
+		"Service.Restart":      "always",
+		"Service.ExecStartPre": "/usr/sbin/nginx -t -q -g 'daemon on; master_process on;'",
+		"Install.WantedBy":     "multi-user.target",
+	}
+
+	for key, expectedValue := range cases {
+		t.Run("it gets existing parameter "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, UNIT_FILE_PATH, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value != expectedValue {
+				t.Fatal(fmt.Sprintf("Expected %s got %s", expectedValue, value))
+			}
+		})
+	}
+
+	for _, key := range []string{"Service.LimitNOFILE", "Service", "Service.Type.foo", "Socket.ListenStream"} {
+		t.Run("it does not get "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, UNIT_FILE_PATH, key)
+			if err == nil {
+				t.Error("Should fail. Got " + value + " instead")
+			}
+		})
+	}
+}
+
+func TestEffectiveValues(t *testing.T) {
+	cases := []struct {
+		key         string
+		assignments []string
+		expected    []string
+	}{
+		{"ExecStartPre", []string{"a", "b"}, []string{"a", "b"}},
+		{"ExecStartPre", []string{"a", "", "b"}, []string{"b"}},
+		{"ExecStartPre", []string{"a", ""}, []string{}},
+		{"Restart", []string{"on-failure", "always"}, []string{"always"}},
+		{"Restart", []string{"always", ""}, []string{}},
+	}
+
+	for _, element := range cases {
+		t.Run("it merges "+element.key+"="+strings.Join(element.assignments, ","), func(t *testing.T) {
+			values := EffectiveValues(element.key, element.assignments)
+			if strings.Join(values, ",") != strings.Join(element.expected, ",") {
+				t.Fatal(fmt.Sprintf("Expected %v got %v", element.expected, values))
+			}
+		})
+	}
+}
+
+func TestEditParameter(t *testing.T) {
+	type EditTestElement struct {
+		key   string
+		value string
+		add   bool
+		old   string
+		new   string
+	}
+
+	cases := []EditTestElement{
+		{"Service.Type", "simple", false, "Type=forking", "Type=simple"},
+		{"Service.Restart", "no", false, "Restart=always", "Restart=no"},
+		{"Service.ExecStart", "/usr/sbin/nginx -g 'daemon off;'", false, "ExecStart=/usr/sbin/nginx -g 'daemon on; master_process on;' \\\n          $NGINX_OPTS", "ExecStart=/usr/sbin/nginx -g 'daemon off;'"},
+		{"Service.Environment", "HOME=/var/www", true, "Environment=\"NGINX_OPTS=-q\"\n", "Environment=\"NGINX_OPTS=-q\"\nEnvironment=HOME=/var/www\n"},
+		{"Service.LimitNOFILE", "65535", false, "Restart=always\n", "Restart=always\nLimitNOFILE=65535\n"},
+		{"Install.Alias", "web.service", false, "WantedBy=multi-user.target\n", "WantedBy=multi-user.target\nAlias=web.service\n"},
+		{"Socket.ListenStream", "%t/nginx.sock", false, "WantedBy=multi-user.target\n", "WantedBy=multi-user.target\n\n[Socket]\nListenStream=%t/nginx.sock\n"},
+	}
+
+	for _, element := range cases {
+		t.Run("it sets "+element.key, func(t *testing.T) {
+			config, err := EditConfigFile(core.DotNotation, UNIT_FILE_PATH, element.key, element.value, element.add)
+			testutil.AssertEditedOutput(t, UNIT_FILE_PATH, config, err, element.old, element.new)
+		})
+	}
+
+	errorCases := map[string]string{
+		"Service.Environment": "FOO=bar",
+		"Service.Type":        "simple\nUser=root",
+		"Unit.Description":    "100% fast",
+		"Service":             "foo",
+	}
+	for key, value := range errorCases {
+		t.Run("it refuses to set "+key+" to "+value, func(t *testing.T) {
+			if _, err := EditConfigFile(core.DotNotation, UNIT_FILE_PATH, key, value, false); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestUnsetParameter(t *testing.T) {
+	config, err := RemoveFromConfigFile(core.DotNotation, UNIT_FILE_PATH, "Service.Restart")
+	testutil.AssertEditedOutput(t, UNIT_FILE_PATH, config, err, "Restart=on-failure\nRestart=always\n", "")
+
+	if _, err := RemoveFromConfigFile(core.DotNotation, UNIT_FILE_PATH, "Service.LimitNOFILE"); err == nil {
+		t.Fatal("Expected an error")
+	}
+}
+
+func TestGetEffectiveParameter(t *testing.T) {
+	cases := map[string]string{
+		"Service.LimitNOFILE": "65535",
+		// The override.conf next to the unit is shadowed by the one in etc
+		"Service.TimeoutStopSec": "5",
+		"Service.ExecStartPre":   "/usr/local/bin/check-certs %n",
+		"Service.Environment":    "LANG=C\n\"NGINX_OPTS=-q\"\nDEBUG=1",
+		"Service.Type":           "forking",
+	}
+
+	for key, expectedValue := range cases {
+		t.Run("it merges "+key, func(t *testing.T) {
+			value, err := GetEffectiveParameter(core.DotNotation, UNIT_FILE_PATH, []string{ETC_DIRECTORY}, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value != expectedValue {
+				t.Fatal(fmt.Sprintf("Expected %s got %s", expectedValue, value))
+			}
+		})
+	}
+
+	t.Run("it uses the drop-in next to the unit without directories", func(t *testing.T) {
+		value, err := GetEffectiveParameter(core.DotNotation, UNIT_FILE_PATH, []string{}, "Service.LimitNOFILE")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if value != "4096" {
+			t.Fatal("Expected 4096 got " + value)
+		}
+	})
+}
+
+func TestOverrideParameter(t *testing.T) {
+	t.Run("it updates an existing drop-in", func(t *testing.T) {
+		config, err := OverrideParameter(core.DotNotation, UNIT_FILE_PATH, "", "Service.TimeoutStopSec", "30")
+		testutil.AssertEditedOutput(t, DropInPath(UNIT_FILE_PATH, ""), config, err, "TimeoutStopSec=10", "TimeoutStopSec=30")
+	})
+
+	t.Run("it resets list keys", func(t *testing.T) {
+		config, err := OverrideParameter(core.DotNotation, UNIT_FILE_PATH, ETC_DIRECTORY, "Service.Environment", "DEBUG=0")
+		testutil.AssertEditedOutput(t, DropInPath(UNIT_FILE_PATH, ETC_DIRECTORY), config, err, "Environment=DEBUG=1\n", "Environment=\nEnvironment=DEBUG=0\n")
+	})
+
+	t.Run("it creates a new drop-in", func(t *testing.T) {
+		directory := t.TempDir()
+		config, err := OverrideParameter(core.DotNotation, UNIT_FILE_PATH, directory, "Service.LimitNOFILE", "65535")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if config.FilePath != filepath.Join(directory, "nginx.service.d", "override.conf") {
+			t.Fatal("Unexpected drop-in path " + config.FilePath)
+		}
+
+		expected := "[Service]\nLimitNOFILE=65535\n"
+		if output := OutputConfigFile(config, core.FullOutput); output != expected {
+			t.Fatal(fmt.Sprintf("Expected:\n%s\nGot:\n%s", expected, output))
+		}
+	})
+
+	t.Run("it refuses to override a missing unit", func(t *testing.T) {
+		if _, err := OverrideParameter(core.DotNotation, "missing.service", "", "Service.Type", "simple"); err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
+
+func TestParseInvalidSystemd(t *testing.T) {
+	for _, content := range []string{"[Service", "[Service]\nType", "[Service]\n=simple"} {
+		t.Run("it rejects "+content, func(t *testing.T) {
+			if _, err := ParseSystemdContent(content); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestReadDocument(t *testing.T) {
+	testutil.AssertDocumentValues(t, SystemdConfigurator{}, UNIT_FILE_PATH, map[string]string{
+		"Unit.Wants":            "network-online.target",
+		"Service.Environment.1": `"NGINX_OPTS=-q"`,
+		"Service.PIDFile":       "/run/nginx.pid",
+	})
+}
@@ -0,0 +1,118 @@
+package table
+
+import (
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+// ReadDocument returns the document tree of the table: records are maps of
+// their columns, keyed by the first of their primary values which designates
+// only them (the hostname, or else the IP address...). Fstab options are
+// maps and host aliases are lists. Crontab variables are values.
+func (configurator TableConfigurator) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	config, err := GetParsedTableFile(configurator.Format, filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	tree := core.NewMapNode()
+	tree.Path = []string{}
+
+	comments := []string{}
+	for _, line := range config.Lines {
+		trimmedLine := strings.TrimSpace(line.Content)
+		if line.Type == OtherLine {
+			if trimmedLine == "" {
+				comments = []string{}
+			} else {
+				losses.Add("comments are not converted")
+				comments = append(comments, strings.TrimSpace(strings.TrimPrefix(trimmedLine, "#")))
+			}
+			continue
+		}
+
+		primary := config.primaryKey(line)
+		if primary == "" {
+			losses.Add("%s cannot be designated by a key, it is not converted", trimmedLine)
+			continue
+		}
+
+		var node *core.Node
+		if line.Type == VariableLine {
+			node = core.NewScalarNode(core.UntypedScalar, line.field(1))
+			node.Path = []string{primary}
+		} else {
+			node = config.readRecord(line, []string{primary})
+		}
+		node.Span = core.Span{Start: line.Number, End: line.Number}
+		node.Comments = comments
+		comments = []string{}
+		tree.Set(primary, node)
+	}
+
+	return tree, nil
+}
+
+// primaryKey returns the first primary value of a record which designates
+// only this record
+func (config *TableConfiguration) primaryKey(line *Line) string {
+	for _, values := range config.Format.primaryValues(line) {
+		for _, value := range values {
+			if value == "" {
+				continue
+			}
+			if records := config.findRecords(value); len(records) == 1 && records[0] == line {
+				return value
+			}
+		}
+	}
+
+	return ""
+}
+
+func (config *TableConfiguration) readRecord(line *Line, path []string) *core.Node {
+	record := core.NewMapNode()
+	record.Path = path
+
+	for _, column := range config.Format.columnNames() {
+		if config.Format == Crontab && column != "schedule" && column != "user" && column != "command" {
+			// The columns of the schedule are part of it
+			continue
+		}
+		value, err := config.getValue(line, KeyPath{Primary: path[0], Column: column})
+		if err != nil {
+			continue
+		}
+
+		columnPath := append(append([]string{}, path...), column)
+		var node *core.Node
+		switch config.Format.listSeparator(column) {
+		case " ":
+			node = core.NewListNode()
+			for _, item := range splitItems(value, " ") {
+				// Items are designated by their value (nas.aliases.files)
+				itemNode := core.NewScalarNode(core.UntypedScalar, item)
+				itemNode.Path = append(append([]string{}, columnPath...), item)
+				itemNode.Span = core.Span{Start: line.Number, End: line.Number}
+				node.Items = append(node.Items, itemNode)
+			}
+		case ",":
+			node = core.NewMapNode()
+			for _, item := range splitItems(value, ",") {
+				name, optionValue, _ := strings.Cut(item, "=")
+				option := core.NewScalarNode(core.UntypedScalar, optionValue)
+				option.Path = append(append([]string{}, columnPath...), name)
+				option.Span = core.Span{Start: line.Number, End: line.Number}
+				node.Set(name, option)
+			}
+		default:
+			node = core.NewScalarNode(core.UntypedScalar, value)
+		}
+		node.Path = columnPath
+		node.Span = core.Span{Start: line.Number, End: line.Number}
+		record.Set(column, node)
+	}
+
+	return record
+}
@@ -0,0 +1,285 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+var (
+	hostnameRegexp      = regexp.MustCompile(`^[A-Za-z0-9_]([A-Za-z0-9_.-]*[A-Za-z0-9_])?$`)
+	numberRegexp        = regexp.MustCompile(`^[0-9]+$`)
+	scheduleFieldRegexp = regexp.MustCompile(`^[0-9A-Za-z*/,-]+$`)
+	userRegexp          = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_.-]*\$?$`)
+)
+
+var specialSchedules = []string{"@reboot", "@yearly", "@annually", "@monthly", "@weekly", "@daily", "@midnight", "@hourly"}
+
+var fstabColumns = []string{"device", "mountpoint", "type", "options", "dump", "pass"}
+var scheduleColumns = []string{"minute", "hour", "day", "month", "weekday"}
+
+func (format Format) String() string {
+	switch format {
+	case Hosts:
+		return "hosts"
+	case Fstab:
+		return "fstab"
+	default:
+		return "crontab"
+	}
+}
+
+func (format Format) columnNames() []string {
+	switch format {
+	case Hosts:
+		return []string{"ip", "hostname", "aliases"}
+	case Fstab:
+		return fstabColumns
+	default:
+		return append([]string{"schedule", "user", "command"}, scheduleColumns...)
+	}
+}
+
+func (format Format) isColumn(name string) bool {
+	for _, column := range format.columnNames() {
+		if column == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// listSeparator returns the separator of the items of a list column, or an
+// empty string if the column is not a list
+func (format Format) listSeparator(column string) string {
+	switch {
+	case format == Hosts && column == "aliases":
+		return " "
+	case format == Fstab && column == "options":
+		return ","
+	default:
+		return ""
+	}
+}
+
+// hasInlineComments tells if "#" starts a comment after the fields of a
+// record. Crontab commands and fstab paths can contain "#".
+func (format Format) hasInlineComments() bool {
+	return format == Hosts
+}
+
+// isSystemCrontab tells if a crontab has a user field: /etc/crontab and the
+// files of /etc/cron.d
+func isSystemCrontab(filePath string) bool {
+	directory := filepath.Base(filepath.Dir(filePath))
+
+	return directory == "cron.d" || directory == "etc" && filepath.Base(filePath) == "crontab"
+}
+
+func isSpecialSchedule(field string) bool {
+	for _, special := range specialSchedules {
+		if field == special {
+			return true
+		}
+	}
+
+	return false
+}
+
+// scheduleLength returns the number of fields of the schedule of a crontab
+// record: 1 for @daily and the like, 5 otherwise
+func scheduleLength(line *Line) int {
+	if len(line.Fields) > 0 && strings.HasPrefix(line.Content[line.Fields[0].Start:line.Fields[0].End], "@") {
+		return 1
+	}
+
+	return 5
+}
+
+// checkFields checks the number of fields of a record
+func (format Format) checkFields(line *Line) error {
+	count := len(line.Fields)
+
+	switch format {
+	case Hosts:
+		if count < 2 {
+			return errors.New("expected an IP address and at least one hostname")
+		}
+		if net.ParseIP(line.field(0)) == nil {
+			return errors.New(fmt.Sprintf("%s is not an IP address", line.field(0)))
+		}
+	case Fstab:
+		if count < 4 || count > 6 {
+			return errors.New(fmt.Sprintf("expected 4 to 6 fields, got %d", count))
+		}
+	default:
+		length := scheduleLength(line)
+		if length == 1 && !isSpecialSchedule(line.field(0)) {
+			return errors.New(fmt.Sprintf("unknown schedule %s", line.field(0)))
+		}
+		expected := length + 1
+		if line.System {
+			expected++
+		}
+		if count != expected {
+			return errors.New("expected a schedule and a command")
+		}
+	}
+
+	return nil
+}
+
+// columnRange returns the fields of a column of a record, from first to last
+// excluded
+func (format Format) columnRange(line *Line, column string) (int, int, error) {
+	count := len(line.Fields)
+
+	switch format {
+	case Hosts:
+		switch column {
+		case "ip":
+			return 0, 1, nil
+		case "hostname":
+			return 1, 2, nil
+		case "aliases":
+			return 2, count, nil
+		}
+	case Fstab:
+		for idx, name := range fstabColumns {
+			if name != column {
+				continue
+			}
+			if idx > count {
+				return 0, 0, errors.New(fmt.Sprintf("Set the %s column first", fstabColumns[idx-1]))
+			}
+			if idx == count {
+				// Missing optional column, set after the last one
+				return count, count, nil
+			}
+			return idx, idx + 1, nil
+		}
+	default:
+		length := scheduleLength(line)
+		switch column {
+		case "schedule":
+			return 0, length, nil
+		case "command":
+			return count - 1, count, nil
+		case "user":
+			if line.System {
+				return length, length + 1, nil
+			}
+			return 0, 0, errors.New("Only system crontabs have a user column")
+		}
+		for idx, name := range scheduleColumns {
+			if name == column {
+				if length != 5 {
+					return 0, 0, errors.New(fmt.Sprintf("The schedule %s has no %s column", line.field(0), column))
+				}
+				return idx, idx + 1, nil
+			}
+		}
+	}
+
+	return 0, 0, errors.New(fmt.Sprintf("Unknown %s column %s, expected one of %s", format, column, strings.Join(format.columnNames(), ", ")))
+}
+
+// primaryValues returns the values a record can be addressed with, by order
+// of priority
+func (format Format) primaryValues(line *Line) [][]string {
+	switch format {
+	case Hosts:
+		hostnames := []string{}
+		for idx := 1; idx < len(line.Fields); idx++ {
+			hostnames = append(hostnames, strings.ToLower(line.field(idx)))
+		}
+		return [][]string{hostnames, {line.field(0)}}
+	case Fstab:
+		return [][]string{{decode(line.field(1))}, {decode(line.field(0))}}
+	default:
+		if line.Type == VariableLine {
+			return [][]string{{line.field(0)}}
+		}
+		return [][]string{{line.Marker}, {line.field(len(line.Fields) - 1)}}
+	}
+}
+
+// normalizePrimary returns a primary key as compared with primaryValues
+func (format Format) normalizePrimary(primary string) string {
+	if format == Hosts {
+		return strings.ToLower(primary)
+	}
+
+	return primary
+}
+
+// validateColumn checks the value of a column, or of an item of a list
+// column
+func (format Format) validateColumn(column string, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return errors.New("Values cannot contain newlines")
+	}
+	if value == "" {
+		return errors.New(fmt.Sprintf("The %s column cannot be empty", column))
+	}
+
+	spaces := strings.ContainsAny(value, " \t")
+	switch {
+	case format == Hosts && column == "ip":
+		if net.ParseIP(value) == nil {
+			return errors.New(fmt.Sprintf("%s is not an IP address", value))
+		}
+	case format == Hosts:
+		for _, hostname := range strings.Fields(value) {
+			if !hostnameRegexp.MatchString(hostname) {
+				return errors.New(fmt.Sprintf("%s is not a valid hostname", hostname))
+			}
+		}
+		if column == "hostname" && spaces {
+			return errors.New("Use the aliases column to add other hostnames")
+		}
+	case format == Fstab && (column == "dump" || column == "pass"):
+		if !numberRegexp.MatchString(value) {
+			return errors.New(fmt.Sprintf("The %s column must be a number", column))
+		}
+	case format == Fstab && (column == "type" || column == "options") && spaces:
+		return errors.New(fmt.Sprintf("The %s column cannot contain spaces", column))
+	case format == Crontab && column == "schedule":
+		fields := strings.Fields(value)
+		if len(fields) == 1 && isSpecialSchedule(fields[0]) {
+			return nil
+		}
+		if len(fields) != 5 {
+			return errors.New(fmt.Sprintf("%q is not a valid schedule, expected 5 fields or one of %s", value, strings.Join(specialSchedules, ", ")))
+		}
+		for _, field := range fields {
+			if !scheduleFieldRegexp.MatchString(field) {
+				return errors.New(fmt.Sprintf("%q is not a valid schedule field", field))
+			}
+		}
+	case format == Crontab && column == "user":
+		if !userRegexp.MatchString(value) {
+			return errors.New(fmt.Sprintf("%s is not a valid user name", value))
+		}
+	case format == Crontab && column != "command":
+		if !scheduleFieldRegexp.MatchString(value) {
+			return errors.New(fmt.Sprintf("%q is not a valid %s", value, column))
+		}
+	}
+
+	return nil
+}
+
+// decode returns a fstab field with its octal escapes (\040 for a space)
+// decoded
+func decode(value string) string {
+	return strings.NewReplacer(`\040`, " ", `\011`, "\t", `\134`, `\`).Replace(value)
+}
+
+func encode(value string) string {
+	return strings.NewReplacer(" ", `\040`, "\t", `\011`).Replace(value)
+}
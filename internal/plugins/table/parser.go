@@ -0,0 +1,120 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/io"
+)
+
+var variableRegexp = regexp.MustCompile(`^[ \t]*([A-Za-z_][A-Za-z0-9_]*)[ \t]*=[ \t]*(.*?)[ \t\r]*$`)
+
+func isSpace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r'
+}
+
+func (line *Line) field(idx int) string {
+	return line.Content[line.Fields[idx].Start:line.Fields[idx].End]
+}
+
+// tokenize returns the whitespace separated fields of a line, up to an
+// inline comment if allowed
+func tokenize(content string, inlineComments bool) []Field {
+	fields := []Field{}
+
+	for pos := 0; pos < len(content); {
+		if isSpace(content[pos]) {
+			pos++
+			continue
+		}
+		if inlineComments && content[pos] == '#' {
+			break
+		}
+
+		start := pos
+		for pos < len(content) && !isSpace(content[pos]) {
+			pos++
+		}
+		fields = append(fields, Field{start, pos})
+	}
+
+	return fields
+}
+
+func parseLine(format Format, number int, content string, system bool) (*Line, error) {
+	line := &Line{Number: number, Content: content, Type: OtherLine, System: system}
+
+	trimmed := strings.TrimSpace(content)
+	if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+		return line, nil
+	}
+
+	if format == Crontab {
+		if matches := variableRegexp.FindStringSubmatchIndex(content); matches != nil {
+			line.Type = VariableLine
+			line.Fields = []Field{{matches[2], matches[3]}, {matches[4], matches[5]}}
+
+			return line, nil
+		}
+	}
+
+	line.Type = RecordLine
+	line.Fields = tokenize(content, format.hasInlineComments())
+
+	if format == Crontab {
+		// The command is the rest of the line
+		prefix := scheduleLength(line)
+		if system {
+			prefix++
+		}
+		if len(line.Fields) > prefix {
+			end := len(strings.TrimRight(content, " \t\r"))
+			line.Fields = append(line.Fields[:prefix], Field{line.Fields[prefix].Start, end})
+		}
+	}
+
+	if err := format.checkFields(line); err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid %s file on line %d: %s", format, number, err.Error()))
+	}
+
+	return line, nil
+}
+
+// ParseTableContent parses the lines of a table. Crontab records get the
+// comment right above them as marker.
+func ParseTableContent(format Format, content string, system bool) ([]*Line, error) {
+	lines := []*Line{}
+	for idx, lineString := range strings.Split(content, "\n") {
+		line, err := parseLine(format, idx+1, lineString, system)
+		if err != nil {
+			return nil, err
+		}
+
+		if format == Crontab && line.Type == RecordLine && idx > 0 {
+			previous := strings.TrimSpace(lines[idx-1].Content)
+			if strings.HasPrefix(previous, "#") {
+				line.Marker = strings.TrimSpace(strings.TrimLeft(previous, "#"))
+			}
+		}
+
+		lines = append(lines, line)
+	}
+
+	return lines, nil
+}
+
+func GetParsedTableFile(format Format, filePath string) (TableConfiguration, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return TableConfiguration{}, err
+	}
+
+	lines, err := ParseTableContent(format, content, format == Crontab && isSystemCrontab(filePath))
+	if err != nil {
+		return TableConfiguration{}, err
+	}
+
+	return TableConfiguration{lines, format, filePath}, nil
+}
@@ -0,0 +1,121 @@
+package table
+
+import (
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+)
+
+// Format is a line-oriented system table, whose records are lines of
+// whitespace separated fields
+type Format int
+
+const (
+	Hosts Format = iota
+	Fstab
+	Crontab
+)
+
+type LineType int
+
+const (
+	OtherLine LineType = iota
+	RecordLine
+	// VariableLine is a NAME=value line of a crontab
+	VariableLine
+)
+
+// Field is the position of a field in the content of its line
+type Field struct {
+	Start int
+	End   int
+}
+
+type Line struct {
+	Number  int
+	Content string
+	Type    LineType
+	Fields  []Field
+	// Marker is the text of the comment right above a crontab record, used to
+	// address it
+	Marker string
+	// System is true for the records of system crontabs, which have a user
+	// field
+	System bool
+}
+
+type TableConfiguration struct {
+	Lines    []*Line
+	Format   Format
+	FilePath string
+}
+
+func (config *TableConfiguration) OutputFile(outputType core.OutputType) (string, error) {
+	return OutputConfigFile(config, outputType), nil
+}
+
+func (config *TableConfiguration) WriteToFile(filepath string, outputType core.OutputType) error {
+	output, err := config.OutputFile(outputType)
+	if err != nil {
+		return err
+	}
+
+	return io.WriteFileContents(filepath, output)
+}
+
+// TableConfigurator handles the files of a format. Keys are a record,
+// addressed by its primary column (a hostname, a mount point, a crontab
+// command or marker), followed by a column and an item of a list column
+// (localhost.ip, /home.options.noatime).
+type TableConfigurator struct {
+	Format Format
+}
+
+func (configurator TableConfigurator) GetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (string, error) {
+	return GetParameterFromPath(configurator.Format, notationStyle, filePath, key)
+}
+
+func (configurator TableConfigurator) SetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(EditConfigFile(configurator.Format, notationStyle, filePath, key, value))
+}
+
+// SetsExistingKeysOnly tells that the records of hosts and fstab files are
+// added with AddParameter only, unlike the variables of crontabs which can be
+// set when missing
+func (configurator TableConfigurator) SetsExistingKeysOnly() bool {
+	return configurator.Format != Crontab
+}
+
+// AddParameter adds a record, or an item to a list column
+func (configurator TableConfigurator) AddParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return toConfiguration(AddToConfigFile(configurator.Format, notationStyle, filePath, key, value))
+}
+
+func (configurator TableConfigurator) UnsetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (core.Configuration, error) {
+	return toConfiguration(RemoveFromConfigFile(configurator.Format, notationStyle, filePath, key))
+}
+
+func toConfiguration(config *TableConfiguration, err error) (core.Configuration, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
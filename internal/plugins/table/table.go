@@ -0,0 +1,644 @@
+package table
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+// KeyPath is a record, addressed by its primary column, and optionally one
+// of its columns and an item of a list column
+type KeyPath struct {
+	Primary string
+	Column  string
+	Item    string
+	HasItem bool
+}
+
+// parseKey decomposes a key. As primary values can contain dots
+// (example.com, backup.sh), the column is the last part of the key which is a
+// column name, or the one before if the column is a list.
+func (format Format) parseKey(notationStyle core.NotationStyle, key string) (KeyPath, error) {
+	parts, err := core.DecomposeKeyKeepingPredicates(notationStyle, key)
+	if err != nil {
+		return KeyPath{}, err
+	}
+
+	path := KeyPath{Primary: key}
+	for idx := len(parts) - 1; idx >= 1; idx-- {
+		if !format.isColumn(parts[idx]) {
+			continue
+		}
+
+		if idx == len(parts)-1 || format.listSeparator(parts[idx]) != "" {
+			path = KeyPath{Primary: strings.Join(parts[:idx], "."), Column: parts[idx]}
+			if idx < len(parts)-1 {
+				path.Item = strings.Join(parts[idx+1:], ".")
+				path.HasItem = true
+			}
+			break
+		}
+	}
+
+	if path.Primary == "" || path.HasItem && path.Item == "" {
+		return path, errors.New(fmt.Sprintf("Invalid key %s", key))
+	}
+
+	return path, nil
+}
+
+func OutputConfigFile(config *TableConfiguration, outputType core.OutputType) string {
+	if outputType == core.MeaningFullOutput {
+		output := ""
+		for _, line := range config.Lines {
+			if line.Type != OtherLine {
+				output += line.Content + "\n"
+			}
+		}
+
+		return output
+	}
+
+	lines := []string{}
+	for _, line := range config.Lines {
+		lines = append(lines, line.Content)
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+// findRecords returns the records matching a primary key: the records whose
+// primary column matches, or else the ones whose secondary column does (the
+// IP address of a host, the device of a mount point, the command of a cron
+// job)
+func (config *TableConfiguration) findRecords(primary string) []*Line {
+	normalized := config.Format.normalizePrimary(primary)
+
+	for priority := 0; priority < 2; priority++ {
+		matching := []*Line{}
+		for _, line := range config.Lines {
+			if line.Type == OtherLine {
+				continue
+			}
+
+			values := config.Format.primaryValues(line)
+			if priority >= len(values) {
+				continue
+			}
+			for _, value := range values[priority] {
+				if value == normalized {
+					matching = append(matching, line)
+					break
+				}
+			}
+		}
+
+		if len(matching) > 0 {
+			return matching
+		}
+	}
+
+	return []*Line{}
+}
+
+func (config *TableConfiguration) findRecord(path KeyPath) (*Line, error) {
+	records := config.findRecords(path.Primary)
+	if len(records) == 0 {
+		return nil, errors.New("Key not found")
+	}
+	if len(records) > 1 {
+		return nil, errors.New(fmt.Sprintf("%d records match %s, address them by another column", len(records), path.Primary))
+	}
+
+	return records[0], nil
+}
+
+func (line *Line) span(first int, last int) string {
+	if first == last {
+		return ""
+	}
+
+	return line.Content[line.Fields[first].Start:line.Fields[last-1].End]
+}
+
+func splitItems(value string, separator string) []string {
+	if separator == " " {
+		return strings.Fields(value)
+	}
+	if value == "" {
+		return []string{}
+	}
+
+	return strings.Split(value, separator)
+}
+
+// itemName returns the name of an item of a list: the option of "uid=1000"
+func itemName(item string, separator string) string {
+	if separator == "," {
+		name, _, _ := strings.Cut(item, "=")
+		return name
+	}
+
+	return item
+}
+
+func findItem(items []string, name string, separator string) int {
+	for idx, item := range items {
+		if itemName(item, separator) == name {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+func (config *TableConfiguration) getValue(record *Line, path KeyPath) (string, error) {
+	if record.Type == VariableLine {
+		if path.Column != "" {
+			return "", errors.New(fmt.Sprintf("%s is a variable, it has no columns", path.Primary))
+		}
+		return record.field(1), nil
+	}
+
+	if path.Column == "" {
+		return strings.TrimRight(record.Content, " \t\r"), nil
+	}
+
+	first, last, err := config.Format.columnRange(record, path.Column)
+	if err != nil {
+		return "", err
+	}
+	separator := config.Format.listSeparator(path.Column)
+	if first == last && separator != " " {
+		return "", errors.New("Key not found")
+	}
+
+	value := record.span(first, last)
+	if config.Format == Fstab {
+		value = decode(value)
+	}
+
+	if !path.HasItem {
+		return value, nil
+	}
+
+	items := splitItems(value, separator)
+	idx := findItem(items, path.Item, separator)
+	if idx == -1 {
+		return "", errors.New("Key not found")
+	}
+	if separator != "," {
+		return items[idx], nil
+	}
+	_, itemValue, _ := strings.Cut(items[idx], "=")
+
+	return itemValue, nil
+}
+
+// GetParameterFromPath returns a record, a column or the value of an item of
+// a list column ("" for options without value), one line per matching record
+func GetParameterFromPath(format Format, notationStyle core.NotationStyle, filePath string, key string) (string, error) {
+	config, err := GetParsedTableFile(format, filePath)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := format.parseKey(notationStyle, key)
+	if err != nil {
+		return "", err
+	}
+
+	records := config.findRecords(path.Primary)
+	if len(records) == 0 {
+		return "", errors.New("Key not found")
+	}
+
+	values := []string{}
+	for _, record := range records {
+		value, err := config.getValue(record, path)
+		if err != nil {
+			return "", err
+		}
+		values = append(values, value)
+	}
+
+	return strings.Join(values, "\n"), nil
+}
+
+// EditConfigFile sets a column of a record, or an item of a list column. For
+// crontabs, it also sets variables (MAILTO).
+func EditConfigFile(format Format, notationStyle core.NotationStyle, filePath string, key string, value string) (*TableConfiguration, error) {
+	config, err := GetParsedTableFile(format, filePath)
+	if err != nil {
+		return &TableConfiguration{}, err
+	}
+
+	path, err := format.parseKey(notationStyle, key)
+	if err != nil {
+		return &TableConfiguration{}, err
+	}
+
+	if path.Column == "" {
+		if format == Crontab && variableRegexp.MatchString(path.Primary+"=") && !config.hasRecord(path.Primary) {
+			if err := config.setVariable(path.Primary, value); err != nil {
+				return &TableConfiguration{}, err
+			}
+			return config.validate(key)
+		}
+
+		return &TableConfiguration{}, errors.New(fmt.Sprintf(
+			"Set a column of the record (e.g. %s.%s), or use add to add a record",
+			path.Primary, format.columnNames()[0],
+		))
+	}
+
+	record, err := config.findRecord(path)
+	if err != nil {
+		return &TableConfiguration{}, err
+	}
+	first, last, err := format.columnRange(record, path.Column)
+	if err != nil {
+		return &TableConfiguration{}, err
+	}
+
+	newValue := value
+	if path.HasItem {
+		separator := format.listSeparator(path.Column)
+		items := splitItems(record.span(first, last), separator)
+		idx := findItem(items, path.Item, separator)
+
+		item := value
+		if separator == "," {
+			item = path.Item
+			if value != "" {
+				item += "=" + value
+			}
+		}
+		if err := format.validateColumn(path.Column, item); err != nil {
+			return &TableConfiguration{}, err
+		}
+
+		switch {
+		case idx != -1:
+			items[idx] = item
+		case separator == ",":
+			items = append(items, item)
+		default:
+			return &TableConfiguration{}, errors.New("Key not found")
+		}
+		newValue = strings.Join(items, separator)
+	} else {
+		if err := format.validateColumn(path.Column, value); err != nil {
+			return &TableConfiguration{}, err
+		}
+		if format == Fstab {
+			newValue = encode(value)
+		}
+	}
+
+	config.replaceRange(record, first, last, newValue)
+
+	return config.validate(key)
+}
+
+// AddToConfigFile adds a record, whose other columns are the value, or an
+// item to a list column. A crontab record is added with its marker comment:
+// the value is the whole line ("0 3 * * * backup.sh").
+func AddToConfigFile(format Format, notationStyle core.NotationStyle, filePath string, key string, value string) (*TableConfiguration, error) {
+	config, err := GetParsedTableFile(format, filePath)
+	if err != nil {
+		return &TableConfiguration{}, err
+	}
+
+	path, err := format.parseKey(notationStyle, key)
+	if err != nil {
+		return &TableConfiguration{}, err
+	}
+
+	if path.HasItem {
+		return &TableConfiguration{}, errors.New(fmt.Sprintf("Items are added to a list column, remove %s from the key", path.Item))
+	}
+	if path.Column == "" {
+		if err := config.addRecord(path.Primary, value); err != nil {
+			return &TableConfiguration{}, err
+		}
+		return config.validate(key)
+	}
+
+	separator := format.listSeparator(path.Column)
+	if separator == "" {
+		return &TableConfiguration{}, errors.New(fmt.Sprintf("%s is not a list, use set instead", path.Column))
+	}
+	if err := format.validateColumn(path.Column, value); err != nil {
+		return &TableConfiguration{}, err
+	}
+
+	record, err := config.findRecord(path)
+	if err != nil {
+		return &TableConfiguration{}, err
+	}
+	first, last, err := format.columnRange(record, path.Column)
+	if err != nil {
+		return &TableConfiguration{}, err
+	}
+
+	items := splitItems(record.span(first, last), separator)
+	for _, item := range splitItems(value, separator) {
+		if findItem(items, itemName(item, separator), separator) != -1 {
+			return &TableConfiguration{}, errors.New(fmt.Sprintf("%s is already in %s, use set to change it", itemName(item, separator), path.Column))
+		}
+	}
+
+	if separator == " " {
+		// New aliases are added after the last field, without changing the
+		// alignment of the others
+		end := record.Fields[len(record.Fields)-1].End
+		record.Content = record.Content[:end] + " " + value + record.Content[end:]
+	} else {
+		config.replaceRange(record, first, last, strings.Join(append(items, value), separator))
+	}
+
+	return config.validate(key)
+}
+
+// RemoveFromConfigFile removes a record, an optional column or an item of a
+// list column
+func RemoveFromConfigFile(format Format, notationStyle core.NotationStyle, filePath string, key string) (*TableConfiguration, error) {
+	config, err := GetParsedTableFile(format, filePath)
+	if err != nil {
+		return &TableConfiguration{}, err
+	}
+
+	path, err := format.parseKey(notationStyle, key)
+	if err != nil {
+		return &TableConfiguration{}, err
+	}
+
+	record, err := config.findRecord(path)
+	if err != nil {
+		return &TableConfiguration{}, err
+	}
+
+	if path.Column == "" {
+		config.removeRecord(record)
+		return config.validate(key)
+	}
+
+	first, last, err := format.columnRange(record, path.Column)
+	if err != nil {
+		return &TableConfiguration{}, err
+	}
+	separator := format.listSeparator(path.Column)
+
+	switch {
+	case path.HasItem:
+		items := splitItems(record.span(first, last), separator)
+		idx := findItem(items, path.Item, separator)
+		if idx == -1 {
+			return &TableConfiguration{}, errors.New("Key not found")
+		}
+
+		if separator == " " {
+			config.removeFields(record, first+idx, first+idx+1)
+			break
+		}
+		if len(items) == 1 {
+			return &TableConfiguration{}, errors.New(fmt.Sprintf("Cannot remove the last item of %s, set it instead", path.Column))
+		}
+		config.replaceRange(record, first, last, strings.Join(append(items[:idx], items[idx+1:]...), separator))
+	case separator == " " && first < last:
+		config.removeFields(record, first, last)
+	case format == Fstab && first >= 4 && last == len(record.Fields):
+		// dump and pass are optional, from the end
+		config.removeFields(record, first, last)
+	default:
+		return &TableConfiguration{}, errors.New(fmt.Sprintf("The %s column is required", path.Column))
+	}
+
+	return config.validate(key)
+}
+
+func (config *TableConfiguration) validate(key string) (*TableConfiguration, error) {
+	lines, err := ParseTableContent(config.Format, OutputConfigFile(config, core.FullOutput), config.isSystem())
+	if err != nil {
+		return &TableConfiguration{}, errors.New(fmt.Sprintf("Refusing to set %s: %s", key, err.Error()))
+	}
+	config.Lines = lines
+
+	return config, nil
+}
+
+func (config *TableConfiguration) isSystem() bool {
+	return config.Format == Crontab && isSystemCrontab(config.FilePath)
+}
+
+// replaceAligned replaces a part of a line, changing the spaces after it so
+// that the next field keeps its position when possible. Tab separated fields
+// are left as they are.
+func replaceAligned(content string, start int, end int, value string) string {
+	gapEnd := end
+	for gapEnd < len(content) && (content[gapEnd] == ' ' || content[gapEnd] == '\t') {
+		gapEnd++
+	}
+	if gapEnd == len(content) || content[gapEnd] == '\r' || strings.ContainsRune(content[end:gapEnd], '\t') {
+		return content[:start] + value + content[end:]
+	}
+
+	width := gapEnd - start - len(value)
+	if width < 1 {
+		width = 1
+	}
+
+	return content[:start] + value + strings.Repeat(" ", width) + content[gapEnd:]
+}
+
+// replaceRange replaces the fields of a column, or sets a missing optional
+// column after the last field
+func (config *TableConfiguration) replaceRange(record *Line, first int, last int, value string) {
+	if first == last {
+		end := record.Fields[len(record.Fields)-1].End
+		record.Content = record.Content[:end] + config.separator(record) + value + record.Content[end:]
+		return
+	}
+
+	record.Content = replaceAligned(record.Content, record.Fields[first].Start, record.Fields[last-1].End, value)
+}
+
+// removeFields removes fields with the spaces before them
+func (config *TableConfiguration) removeFields(record *Line, first int, last int) {
+	start := record.Fields[first-1].End
+	record.Content = record.Content[:start] + record.Content[record.Fields[last-1].End:]
+}
+
+// separator returns the separator of the fields of a record: a tab if it
+// uses tabs, a space otherwise
+func (config *TableConfiguration) separator(record *Line) string {
+	if record != nil && len(record.Fields) > 1 && strings.ContainsRune(record.Content[record.Fields[0].End:record.Fields[1].Start], '\t') {
+		return "\t"
+	}
+
+	return " "
+}
+
+func (config *TableConfiguration) indexOf(line *Line) int {
+	for idx, other := range config.Lines {
+		if other == line {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+func (config *TableConfiguration) insertLines(position int, newLines ...*Line) {
+	lines := append([]*Line{}, config.Lines[:position]...)
+	lines = append(lines, newLines...)
+	config.Lines = append(lines, config.Lines[position:]...)
+}
+
+// removeRecord removes a record, and its marker comment for crontabs
+func (config *TableConfiguration) removeRecord(record *Line) {
+	idx := config.indexOf(record)
+	first := idx
+	if record.Marker != "" && idx > 0 {
+		first--
+	}
+
+	config.Lines = append(config.Lines[:first], config.Lines[idx+1:]...)
+}
+
+// lastLine returns the last line of a type, or nil
+func (config *TableConfiguration) lastLine(lineType LineType) *Line {
+	var last *Line
+	for _, line := range config.Lines {
+		if line.Type == lineType {
+			last = line
+		}
+	}
+
+	return last
+}
+
+// endPosition returns the position of the end of the file, before its
+// trailing newline
+func (config *TableConfiguration) endPosition() int {
+	position := len(config.Lines)
+	if position > 0 && config.Lines[position-1].Content == "" {
+		position--
+	}
+
+	return position
+}
+
+// alignFields joins the fields of a new record, aligning them on the fields
+// of the reference record
+func (config *TableConfiguration) alignFields(reference *Line, fields []string) string {
+	separator := config.separator(reference)
+	if reference == nil || separator == "\t" {
+		return strings.Join(fields, separator)
+	}
+
+	builder := strings.Builder{}
+	builder.WriteString(reference.Content[:reference.Fields[0].Start])
+	for idx, field := range fields {
+		if idx > 0 {
+			width := 1
+			if idx < len(reference.Fields) && reference.Fields[idx].Start-builder.Len() > 1 {
+				width = reference.Fields[idx].Start - builder.Len()
+			}
+			builder.WriteString(strings.Repeat(" ", width))
+		}
+		builder.WriteString(field)
+	}
+
+	return builder.String()
+}
+
+func (config *TableConfiguration) addRecord(primary string, value string) error {
+	if len(config.findRecords(primary)) > 0 {
+		return errors.New(fmt.Sprintf("%s already exists, use set to change its columns", primary))
+	}
+
+	fields := strings.Fields(value)
+	reference := config.lastLine(RecordLine)
+
+	newLines := []*Line{}
+	switch config.Format {
+	case Hosts:
+		if len(fields) == 0 {
+			return errors.New("Expected the IP address of the host, and its aliases")
+		}
+		fields = append([]string{fields[0], primary}, fields[1:]...)
+		newLines = append(newLines, &Line{Content: config.alignFields(reference, fields)})
+	case Fstab:
+		if len(fields) < 3 {
+			return errors.New("Expected the device, the type and the options of the mount point, and optionally dump and pass")
+		}
+		fields = append([]string{fields[0], encode(primary)}, fields[1:]...)
+		newLines = append(newLines, &Line{Content: config.alignFields(reference, fields)})
+	default:
+		// The marker comment is not needed if the record is addressed by its
+		// command
+		line, err := parseLine(config.Format, 0, value, config.isSystem())
+		if err != nil || line.Type != RecordLine {
+			return errors.New(fmt.Sprintf("%q is not a valid crontab line", value))
+		}
+		if line.field(len(line.Fields)-1) != primary {
+			newLines = append(newLines, &Line{Content: "# " + primary})
+		}
+		newLines = append(newLines, &Line{Content: value})
+	}
+
+	position := config.endPosition()
+	if reference != nil {
+		position = config.indexOf(reference) + 1
+	}
+	config.insertLines(position, newLines...)
+
+	return nil
+}
+
+func (config *TableConfiguration) hasRecord(primary string) bool {
+	for _, line := range config.findRecords(primary) {
+		if line.Type == RecordLine {
+			return true
+		}
+	}
+
+	return false
+}
+
+// setVariable sets a crontab variable, adding it after the last variable or
+// before the first record
+func (config *TableConfiguration) setVariable(name string, value string) error {
+	if strings.ContainsAny(value, "\r\n") {
+		return errors.New("Values cannot contain newlines")
+	}
+
+	for _, line := range config.findRecords(name) {
+		if line.Type == VariableLine {
+			line.Content = line.Content[:line.Fields[1].Start] + value + line.Content[line.Fields[1].End:]
+			return nil
+		}
+	}
+
+	position := config.endPosition()
+	if last := config.lastLine(VariableLine); last != nil {
+		position = config.indexOf(last) + 1
+	} else {
+		for idx, line := range config.Lines {
+			if line.Type == RecordLine {
+				position = idx
+				if line.Marker != "" {
+					position--
+				}
+				break
+			}
+		}
+	}
+	config.insertLines(position, &Line{Content: name + "=" + value})
+
+	return nil
+}
@@ -0,0 +1,262 @@
+package table
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/testutil"
+)
+
+const HOSTS_FILE_PATH = "../../../data/table/hosts"
+const FSTAB_FILE_PATH = "../../../data/table/fstab"
+const CRONTAB_FILE_PATH = "../../../data/table/crontab"
+const SYSTEM_CRONTAB_FILE_PATH = "../../../data/table/cron.d/logrotate"
+
+var formats = map[string]Format{
+	HOSTS_FILE_PATH:          Hosts,
+	FSTAB_FILE_PATH:          Fstab,
+	CRONTAB_FILE_PATH:        Crontab,
+	SYSTEM_CRONTAB_FILE_PATH: Crontab,
+}
+
+func TestGetParameter(t *testing.T) {
+	cases := map[string]map[string]string{
+		HOSTS_FILE_PATH: {
+			"localhost.ip":                     "127.0.0.1\n::1",
+			"workstation.ip":                   "127.0.1.1",
+			"WORKSTATION.ip":                   "127.0.1.1",
+			"workstation.example.com.hostname": "workstation.example.com",
+			"nas.lan.ip":                       "192.168.1.10",
+			`"nas.lan".ip`:                     "192.168.1.10",
+			`"nas.lan".aliases.files`:          "files",
+			"nas.aliases":                      "nas files",
+			"nas.aliases.files":                "files",
+			"127.0.1.1.hostname":               "workstation.example.com",
+			"ip6-allnodes":                     "ff02::1         ip6-allnodes",
+		},
+		FSTAB_FILE_PATH: {
+			"/home.options":             "defaults,noatime",
+			"/home.options.noatime":     "",
+			"/mnt/My Share.options.uid": "1000",
+			"/mnt/My Share.device":      "//nas/share",
+			"/.pass":                    "1",
+			"/tmp.type":                 "tmpfs",
+			"/swapfile.mountpoint":      "none",
+			"UUID=0a1b2c3d-4e5f-6a7b-8c9d-0e1f2a3b4c5d.mountpoint": "/home",
+		},
+		CRONTAB_FILE_PATH: {
+			"backup.schedule":                     "0 3 * * *",
+			"backup.hour":                         "3",
+			"backup.command":                      "/usr/local/bin/backup.sh --full",
+			"/usr/local/bin/start-agent.schedule": "@reboot",
+			"MAILTO":                              "admin@example.com",
+		},
+		SYSTEM_CRONTAB_FILE_PATH: {
+			"Rotate the logs.user":                "root",
+			"/usr/bin/php /var/www/cron.php.user": "www-data",
+		},
+	}
+
+	for filePath, fileCases := range cases {
+		for key, expectedValue := range fileCases {
+			t.Run("it gets existing parameter "+key, func(t *testing.T) {
+				value, err := GetParameterFromPath(formats[filePath], core.DotNotation, filePath, key)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if value != expectedValue {
+					t.Fatal(fmt.Sprintf("Expected %s got %s", expectedValue, value))
+				}
+			})
+		}
+	}
+
+	t.Run("it gets a parameter with the brackets notation", func(t *testing.T) {
+		value, err := GetParameterFromPath(Fstab, core.BracketsNotation, FSTAB_FILE_PATH, "/home[options][noatime]")
+		if err != nil || value != "" {
+			t.Fatal(fmt.Sprintf("Expected an empty value got %s (%v)", value, err))
+		}
+	})
+
+	errorCases := map[string][]string{
+		HOSTS_FILE_PATH:   {"missing.ip", "localhost.mac", "nas.aliases.storage", `"nas.lan.ip`, "nas..ip"},
+		FSTAB_FILE_PATH:   {"/mnt/My Share.dump", "/home.options.ro", "/srv.type"},
+		CRONTAB_FILE_PATH: {"backup.user", "/usr/local/bin/start-agent.minute", "MAILTO.command"},
+	}
+	for filePath, keys := range errorCases {
+		for _, key := range keys {
+			t.Run("it does not get "+key, func(t *testing.T) {
+				value, err := GetParameterFromPath(formats[filePath], core.DotNotation, filePath, key)
+				if err == nil {
+					t.Error("Should fail. Got " + value + " instead")
+				}
+			})
+		}
+	}
+}
+
+type EditTestElement struct {
+	filePath string
+	key      string
+	value    string
+	old      string
+	new      string
+}
+
+func TestEditParameter(t *testing.T) {
+	cases := []EditTestElement{
+		{HOSTS_FILE_PATH, "workstation.ip", "10.0.0.2", "127.0.1.1       workstation", "10.0.0.2        workstation"},
+		{HOSTS_FILE_PATH, "nas.hostname", "nas.home.arpa", "nas.lan                   nas files", "nas.home.arpa             nas files"},
+		{HOSTS_FILE_PATH, "nas.aliases", "storage", "nas files   # storage", "storage     # storage"},
+		{HOSTS_FILE_PATH, "nas.aliases.files", "share", "nas files", "nas share"},
+		{FSTAB_FILE_PATH, "/home.options.commit", "60", "defaults,noatime          0", "defaults,noatime,commit=60 0"},
+		{FSTAB_FILE_PATH, "/.options", "defaults", "errors=remount-ro         0", "defaults                  0"},
+		{FSTAB_FILE_PATH, "/mnt/My Share.dump", "0", "_netdev\n", "_netdev 0\n"},
+		{FSTAB_FILE_PATH, "/home.mountpoint", "/srv/home data", "/home            ext4", "/srv/home\\040data ext4"},
+		{FSTAB_FILE_PATH, "/tmp.options.size", "4G", "defaults,size=2G", "defaults,size=4G"},
+		{FSTAB_FILE_PATH, "/mnt/My Share.options.uid", "1001", "uid=1000", "uid=1001"},
+		{CRONTAB_FILE_PATH, "backup.schedule", "30 4 * * 1-5", "0 3 * * * /usr", "30 4 * * 1-5 /usr"},
+		{CRONTAB_FILE_PATH, "backup.hour", "4", "0 3 * * *", "0 4 * * *"},
+		{CRONTAB_FILE_PATH, "MAILTO", "ops@example.com", "MAILTO=admin@example.com", "MAILTO=ops@example.com"},
+		{CRONTAB_FILE_PATH, "PATH", "/usr/bin:/bin", "MAILTO=admin@example.com\n", "MAILTO=admin@example.com\nPATH=/usr/bin:/bin\n"},
+		{CRONTAB_FILE_PATH, "/usr/local/bin/start-agent.command", "/usr/local/bin/start-agent --daemon", "start-agent\n", "start-agent --daemon\n"},
+		{SYSTEM_CRONTAB_FILE_PATH, "Rotate the logs.user", "syslog", "* root /usr", "* syslog /usr"},
+	}
+
+	for _, element := range cases {
+		t.Run("it sets "+element.key, func(t *testing.T) {
+			config, err := EditConfigFile(formats[element.filePath], core.DotNotation, element.filePath, element.key, element.value)
+			testutil.AssertEditedOutput(t, element.filePath, config, err, element.old, element.new)
+		})
+	}
+
+	errorCases := []EditTestElement{
+		{HOSTS_FILE_PATH, "workstation.ip", "999.1.1.1", "", ""},
+		{HOSTS_FILE_PATH, "workstation.hostname", "a b", "", ""},
+		{HOSTS_FILE_PATH, "localhost.ip", "127.0.0.2", "", ""},
+		{HOSTS_FILE_PATH, "workstation", "10.0.0.1", "", ""},
+		{FSTAB_FILE_PATH, "/home.dump", "x", "", ""},
+		{FSTAB_FILE_PATH, "/home.options.ro", "a b", "", ""},
+		{FSTAB_FILE_PATH, "/mnt/My Share.pass", "2", "", ""},
+		{CRONTAB_FILE_PATH, "backup.schedule", "every day", "", ""},
+		{CRONTAB_FILE_PATH, "backup.user", "root", "", ""},
+		{CRONTAB_FILE_PATH, "backup.minute", "a b", "", ""},
+		{CRONTAB_FILE_PATH, "backup", "x", "", ""},
+		{CRONTAB_FILE_PATH, "MAILTO", "a\nb", "", ""},
+	}
+	for _, element := range errorCases {
+		t.Run("it refuses to set "+element.key+" to "+element.value, func(t *testing.T) {
+			if _, err := EditConfigFile(formats[element.filePath], core.DotNotation, element.filePath, element.key, element.value); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestAddParameter(t *testing.T) {
+	cases := []EditTestElement{
+		{HOSTS_FILE_PATH, "db.lan", "10.0.0.5 db", "ff02::1         ip6-allnodes\n", "ff02::1         ip6-allnodes\n10.0.0.5        db.lan db\n"},
+		{HOSTS_FILE_PATH, "workstation.aliases", "ws", "workstation.example.com   workstation\n", "workstation.example.com   workstation ws\n"},
+		{FSTAB_FILE_PATH, "/data", "LABEL=data xfs defaults 0 2", "size=2G\t0\t0\n", "size=2G\t0\t0\nLABEL=data\t/data\txfs\tdefaults\t0\t2\n"},
+		{FSTAB_FILE_PATH, "/home.options", "nodev", "defaults,noatime          0", "defaults,noatime,nodev    0"},
+		{CRONTAB_FILE_PATH, "cleanup", "0 0 * * 0 /usr/local/bin/cleanup", "start-agent\n", "start-agent\n# cleanup\n0 0 * * 0 /usr/local/bin/cleanup\n"},
+	}
+
+	for _, element := range cases {
+		t.Run("it adds "+element.key, func(t *testing.T) {
+			config, err := AddToConfigFile(formats[element.filePath], core.DotNotation, element.filePath, element.key, element.value)
+			testutil.AssertEditedOutput(t, element.filePath, config, err, element.old, element.new)
+		})
+	}
+
+	errorCases := []EditTestElement{
+		{HOSTS_FILE_PATH, "localhost.aliases", "loopback", "", ""},
+		{HOSTS_FILE_PATH, "workstation.aliases", "workstation", "", ""},
+		{HOSTS_FILE_PATH, "nas.aliases.files", "share", "", ""},
+		{FSTAB_FILE_PATH, "/home.options", "noatime", "", ""},
+		{FSTAB_FILE_PATH, "/home.type", "xfs", "", ""},
+		{FSTAB_FILE_PATH, "/new", "dev ext4", "", ""},
+		{CRONTAB_FILE_PATH, "backup", "0 1 * * * backup.sh", "", ""},
+		{CRONTAB_FILE_PATH, "new", "not a cron line", "", ""},
+	}
+	for _, element := range errorCases {
+		t.Run("it refuses to add "+element.key, func(t *testing.T) {
+			if _, err := AddToConfigFile(formats[element.filePath], core.DotNotation, element.filePath, element.key, element.value); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestUnsetParameter(t *testing.T) {
+	cases := []EditTestElement{
+		{HOSTS_FILE_PATH, "nas.aliases.files", "", "nas files   #", "nas   #"},
+		{HOSTS_FILE_PATH, "workstation.aliases", "", "workstation.example.com   workstation\n", "workstation.example.com\n"},
+		{HOSTS_FILE_PATH, "ip6-allnodes", "", "ff02::1         ip6-allnodes\n", ""},
+		{FSTAB_FILE_PATH, "/home.options.noatime", "", "defaults,noatime          0", "defaults                  0"},
+		{FSTAB_FILE_PATH, "/.pass", "", "errors=remount-ro         0      1\n", "errors=remount-ro         0\n"},
+		{FSTAB_FILE_PATH, "/swapfile", "", "/swapfile                                 none             swap   sw                        0      0\n", ""},
+		{CRONTAB_FILE_PATH, "backup", "", "# backup\n0 3 * * * /usr/local/bin/backup.sh --full\n", ""},
+		{CRONTAB_FILE_PATH, "MAILTO", "", "MAILTO=admin@example.com\n", ""},
+	}
+
+	for _, element := range cases {
+		t.Run("it unsets "+element.key, func(t *testing.T) {
+			config, err := RemoveFromConfigFile(formats[element.filePath], core.DotNotation, element.filePath, element.key)
+			testutil.AssertEditedOutput(t, element.filePath, config, err, element.old, element.new)
+		})
+	}
+
+	errorCases := []EditTestElement{
+		{HOSTS_FILE_PATH, "localhost", "", "", ""},
+		{HOSTS_FILE_PATH, "workstation.hostname", "", "", ""},
+		{FSTAB_FILE_PATH, "/home.options.ro", "", "", ""},
+		{FSTAB_FILE_PATH, "/.dump", "", "", ""},
+		{FSTAB_FILE_PATH, "/swapfile.options.sw", "", "", ""},
+		{CRONTAB_FILE_PATH, "backup.command", "", "", ""},
+	}
+	for _, element := range errorCases {
+		t.Run("it does not unset "+element.key, func(t *testing.T) {
+			if _, err := RemoveFromConfigFile(formats[element.filePath], core.DotNotation, element.filePath, element.key); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestParseInvalidTable(t *testing.T) {
+	type ParseTestElement struct {
+		format  Format
+		content string
+	}
+
+	cases := []ParseTestElement{
+		{Hosts, "127.0.0.1"},
+		{Hosts, "localhost 127.0.0.1"},
+		{Fstab, "/dev/sda1 / ext4"},
+		{Fstab, "/dev/sda1 / ext4 defaults 0 1 extra"},
+		{Crontab, "0 3 * * *"},
+		{Crontab, "@sometimes backup.sh"},
+	}
+
+	for _, element := range cases {
+		t.Run("it rejects "+element.content, func(t *testing.T) {
+			if _, err := ParseTableContent(element.format, element.content, false); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestReadDocument(t *testing.T) {
+	testutil.AssertDocumentValues(t, TableConfigurator{Format: Hosts}, HOSTS_FILE_PATH, map[string]string{
+		`"nas.lan".ip`:            "192.168.1.10",
+		`"nas.lan".aliases.files`: "files",
+	})
+	testutil.AssertDocumentValues(t, TableConfigurator{Format: Crontab}, CRONTAB_FILE_PATH, map[string]string{
+		"MAILTO":          "admin@example.com",
+		"backup.schedule": "0 3 * * *",
+	})
+}
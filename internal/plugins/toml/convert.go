@@ -0,0 +1,139 @@
+package toml
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+var scalarTypes = map[ValueKind]core.ScalarType{
+	StringKind:   core.StringScalar,
+	IntegerKind:  core.IntegerScalar,
+	FloatKind:    core.FloatScalar,
+	BooleanKind:  core.BoolScalar,
+	DateTimeKind: core.DateTimeScalar,
+}
+
+// normalizeScalar returns integers in decimal, floats without underscores
+// and datetimes with a "T" separator
+func normalizeScalar(value *Value) string {
+	switch value.Kind {
+	case StringKind:
+		return value.ToString()
+	case IntegerKind:
+		if number, err := strconv.ParseInt(value.Raw, 0, 64); err == nil {
+			return strconv.FormatInt(number, 10)
+		}
+	case FloatKind:
+		return strings.ReplaceAll(value.Raw, "_", "")
+	case DateTimeKind:
+		if len(value.Raw) > 10 && value.Raw[10] == ' ' {
+			return value.Raw[:10] + "T" + value.Raw[11:]
+		}
+	}
+
+	return value.Raw
+}
+
+// WriteTree writes a tree as a TOML document: the values of a table come
+// first, then its sub-tables and its arrays of tables
+func (configurator TomlConfigurator) WriteTree(tree *core.Node, losses *core.Losses) (string, error) {
+	if !tree.IsMap() {
+		return "", errors.New("Only maps can be written as TOML documents")
+	}
+
+	var builder strings.Builder
+	writeTable(&builder, []string{}, tree, losses)
+
+	return builder.String(), nil
+}
+
+func isArrayOfTables(node *core.Node) bool {
+	if node.Kind != core.ListNode || len(node.Items) == 0 {
+		return false
+	}
+	for _, item := range node.Items {
+		if !item.IsMap() {
+			return false
+		}
+	}
+
+	return true
+}
+
+func writeTable(builder *strings.Builder, path []string, table *core.Node, losses *core.Losses) {
+	for _, key := range table.Keys {
+		child := table.Get(key)
+		if !child.IsMap() && !isArrayOfTables(child) {
+			builder.WriteString(formatKey([]string{key}) + " = " + encodeNode(child, losses) + "\n")
+		}
+	}
+
+	for _, key := range table.Keys {
+		child := table.Get(key)
+		childPath := append(append([]string{}, path...), key)
+
+		switch {
+		case child.IsMap():
+			if builder.Len() > 0 {
+				builder.WriteString("\n")
+			}
+			builder.WriteString("[" + formatKey(childPath) + "]\n")
+			writeTable(builder, childPath, child, losses)
+		case isArrayOfTables(child):
+			for _, item := range child.Items {
+				if builder.Len() > 0 {
+					builder.WriteString("\n")
+				}
+				builder.WriteString("[[" + formatKey(childPath) + "]]\n")
+				writeTable(builder, childPath, item, losses)
+			}
+		}
+	}
+}
+
+// encodeNode returns a value as written in TOML, inline for arrays and
+// tables
+func encodeNode(node *core.Node, losses *core.Losses) string {
+	switch node.Kind {
+	case core.ListNode:
+		items := []string{}
+		for _, item := range node.Items {
+			items = append(items, encodeNode(item, losses))
+		}
+		return "[" + strings.Join(items, ", ") + "]"
+	case core.MapNode, core.SectionNode:
+		if len(node.Keys) == 0 {
+			return "{}"
+		}
+		fields := []string{}
+		for _, key := range node.Keys {
+			fields = append(fields, formatKey([]string{key})+" = "+encodeNode(node.Get(key), losses))
+		}
+		return "{ " + strings.Join(fields, ", ") + " }"
+	}
+
+	var kind ValueKind
+	switch node.Type {
+	case core.IntegerScalar:
+		kind = IntegerKind
+	case core.FloatScalar:
+		kind = FloatKind
+	case core.BoolScalar:
+		kind = BooleanKind
+	case core.DateTimeScalar:
+		kind = DateTimeKind
+	default:
+		return EncodeString(node.Value, BasicString)
+	}
+
+	encoded, err := encodeValue(kind, BasicString, node.Value)
+	if err != nil {
+		losses.Add("%s is not a valid TOML %s and is written as a string", node.Value, kind)
+		return EncodeString(node.Value, BasicString)
+	}
+
+	return encoded
+}
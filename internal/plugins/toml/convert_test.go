@@ -0,0 +1,103 @@
+package toml
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+func TestReadDocumentLosses(t *testing.T) {
+	losses := &core.Losses{}
+	tree, err := TomlConfigurator{}.ReadDocument(CARGO_FILE_PATH, losses)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		path       []string
+		scalarType core.ScalarType
+		value      string
+	}{
+		{[]string{"package", "edition"}, core.StringScalar, "2021"},
+		{[]string{"package", "publish"}, core.BoolScalar, "false"},
+		{[]string{"package", "metadata", "docs", "rs", "all-features"}, core.BoolScalar, "true"},
+		{[]string{"dependencies", "serde", "version"}, core.StringScalar, "1.0"},
+		{[]string{"profile", "release", "opt-level"}, core.IntegerScalar, "3"},
+		{[]string{"profile", "release", "debug-ratio"}, core.FloatScalar, "0.5"},
+		{[]string{"release", "date"}, core.DateTimeScalar, "2024-05-27T07:32:00Z"},
+	}
+
+	for _, testCase := range testCases {
+		node := tree
+		for _, name := range testCase.path {
+			node = node.Get(name)
+			if node == nil {
+				t.Fatalf("%v not found", testCase.path)
+			}
+		}
+		if node.Type != testCase.scalarType || node.Value != testCase.value {
+			t.Errorf("Expected %v to be the %s %q, got the %s %q", testCase.path, testCase.scalarType, testCase.value, node.Type, node.Value)
+		}
+	}
+
+	bins := tree.Get("bin")
+	if bins.Kind != core.ListNode || len(bins.Items) != 2 || bins.Items[1].Get("path").Value != `src\daemon.rs` {
+		t.Errorf("Expected bin to be a list of 2 tables, got %v", bins)
+	}
+	if !reflect.DeepEqual(losses.Messages, []string{"comments are not converted"}) {
+		t.Errorf("Unexpected losses %v", losses.Messages)
+	}
+}
+
+func TestWriteTree(t *testing.T) {
+	tree := core.NewMapNode()
+	tree.Set("name", core.NewScalarNode(core.UntypedScalar, "edicon"))
+	tree.Set("port", core.NewScalarNode(core.IntegerScalar, "0x1F90"))
+	tree.Set("ratio", core.NewScalarNode(core.FloatScalar, "1e3"))
+
+	server := core.NewMapNode()
+	server.Set("hosts", core.NewListNode())
+	server.Get("hosts").Items = append(server.Get("hosts").Items, core.NewScalarNode(core.StringScalar, "a"))
+	tree.Set("server", server)
+
+	bins := core.NewListNode()
+	for _, name := range []string{"a", "b"} {
+		bin := core.NewMapNode()
+		bin.Set("name", core.NewScalarNode(core.StringScalar, name))
+		bins.Items = append(bins.Items, bin)
+	}
+	tree.Set("bin", bins)
+	tree.Set("timeout", core.NewScalarNode(core.IntegerScalar, "1.5"))
+
+	losses := &core.Losses{}
+	output, err := TomlConfigurator{}.WriteTree(tree, losses)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `name = "edicon"
+port = 0x1F90
+ratio = 1e3
+timeout = "1.5"
+
+[server]
+hosts = ["a"]
+
+[[bin]]
+name = "a"
+
+[[bin]]
+name = "b"
+`
+	if output != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+	if !reflect.DeepEqual(losses.Messages, []string{"1.5 is not a valid TOML integer and is written as a string"}) {
+		t.Errorf("Unexpected losses %v", losses.Messages)
+	}
+
+	if _, err := ParseTomlContent(output); err != nil {
+		t.Error(err)
+	}
+}
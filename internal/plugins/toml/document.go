@@ -0,0 +1,160 @@
+package toml
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+// MatchesKeyPatterns marks the paths of the tree as TOML dotted keys
+func (configurator TomlConfigurator) MatchesKeyPatterns() {}
+
+// ReadDocument returns the document tree of the logical document: tables
+// defined by a header are sections, arrays and arrays of tables are lists
+func (configurator TomlConfigurator) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	config, err := GetParsedTomlFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildDocument(config, losses), nil
+}
+
+type documentBuilder struct {
+	// comments are the comments attached to the headers and key values
+	comments map[*Statement][]string
+}
+
+func buildDocument(config TomlConfiguration, losses *core.Losses) *core.Node {
+	builder := documentBuilder{comments: map[*Statement][]string{}}
+
+	comments := []string{}
+	for _, section := range config.Sections {
+		for _, statement := range section.Statements {
+			comment := statementComment(statement)
+			if comment != "" {
+				losses.Add("comments are not converted")
+			}
+
+			if statement.Type == OtherType {
+				if strings.TrimSpace(statement.Raw) == "" {
+					comments = []string{}
+				} else if comment != "" {
+					comments = append(comments, comment)
+				}
+				continue
+			}
+
+			if comment != "" {
+				comments = append(comments, comment)
+			}
+			builder.comments[statement] = comments
+			comments = []string{}
+		}
+	}
+
+	return builder.readNode(config.Root, []string{})
+}
+
+// statementComment returns the comment of a statement: a comment line or the
+// comment ending a header or a key value
+func statementComment(statement *Statement) string {
+	raw := statement.Raw
+	if statement.Value != nil {
+		raw = raw[statement.Value.End:]
+	} else if statement.Type != OtherType {
+		raw = raw[strings.LastIndex(raw, "]")+1:]
+	}
+
+	position := strings.Index(raw, "#")
+	if position == -1 {
+		return ""
+	}
+
+	return strings.TrimSpace(raw[position+1:])
+}
+
+func statementSpan(statement *Statement) core.Span {
+	return core.Span{
+		Start: statement.LineNumber,
+		End:   statement.LineNumber + strings.Count(statement.Raw, "\n"),
+	}
+}
+
+func childPath(path []string, name string) []string {
+	return append(append([]string{}, path...), name)
+}
+
+func (builder documentBuilder) readNode(node *Node, path []string) *core.Node {
+	switch node.Kind {
+	case ValueNode:
+		value := readValue(node.Value, path, statementSpan(node.Statement))
+		value.Comments = builder.comments[node.Statement]
+		return value
+	case ArrayTableNode:
+		list := core.NewListNode()
+		list.Path = path
+		for idx, item := range node.Items {
+			list.Items = append(list.Items, builder.readNode(item, childPath(path, strconv.Itoa(idx))))
+		}
+		if len(list.Items) > 0 {
+			list.Span = core.Span{Start: list.Items[0].Span.Start, End: list.Items[len(list.Items)-1].Span.End}
+		}
+		return list
+	}
+
+	table := core.NewMapNode()
+	table.Path = path
+	if node.Section != nil && node.Section.Header != nil {
+		header := node.Section.Header
+		table.Kind = core.SectionNode
+		table.Comments = builder.comments[header]
+		table.Span = statementSpan(header)
+		for _, statement := range node.Section.Statements {
+			if statement.Type != OtherType {
+				table.Span.End = statementSpan(statement).End
+			}
+		}
+	}
+	for _, key := range node.Keys {
+		table.Set(key, builder.readNode(node.Children[key], childPath(path, key)))
+	}
+
+	return table
+}
+
+func readValue(value *Value, path []string, span core.Span) *core.Node {
+	var node *core.Node
+	switch value.Kind {
+	case ArrayKind:
+		node = core.NewListNode()
+	case InlineTableKind:
+		node = core.NewMapNode()
+	default:
+		node = core.NewScalarNode(scalarTypes[value.Kind], normalizeScalar(value))
+	}
+	node.Path = path
+	node.Span = span
+
+	for idx, item := range value.Items {
+		node.Items = append(node.Items, readValue(item, childPath(path, strconv.Itoa(idx)), span))
+	}
+	for _, field := range value.Fields {
+		parent := node
+		for _, name := range field.Key[:len(field.Key)-1] {
+			child := parent.Get(name)
+			if child == nil {
+				child = core.NewMapNode()
+				child.Path = childPath(parent.Path, name)
+				child.Span = span
+				parent.Set(name, child)
+			}
+			parent = child
+		}
+		name := field.Key[len(field.Key)-1]
+		parent.Set(name, readValue(field.Value, childPath(parent.Path, name), span))
+	}
+
+	return node
+}
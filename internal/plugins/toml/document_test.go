@@ -0,0 +1,50 @@
+package toml
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+func TestReadDocument(t *testing.T) {
+	document, err := TomlConfigurator{}.ReadDocument(CARGO_FILE_PATH, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testCases := []struct {
+		path     []string
+		kind     core.NodeKind
+		span     core.Span
+		comments []string
+	}{
+		{[]string{"package"}, core.SectionNode, core.Span{Start: 2, End: 11}, []string{"A Cargo manifest"}},
+		{[]string{"package", "version"}, core.ScalarNode, core.Span{Start: 4, End: 4}, []string{"bumped by CI"}},
+		{[]string{"package", "description"}, core.ScalarNode, core.Span{Start: 7, End: 9}, []string{}},
+		{[]string{"package", "authors", "1"}, core.ScalarNode, core.Span{Start: 6, End: 6}, nil},
+		{[]string{"bin"}, core.ListNode, core.Span{Start: 22, End: 28}, nil},
+		{[]string{"bin", "1", "path"}, core.ScalarNode, core.Span{Start: 28, End: 28}, []string{}},
+	}
+
+	for _, testCase := range testCases {
+		node := document
+		for _, name := range testCase.path {
+			if node.Kind == core.ListNode {
+				node = node.Items[int(name[0]-'0')]
+			} else {
+				node = node.Get(name)
+			}
+		}
+
+		if !reflect.DeepEqual(node.Path, testCase.path) {
+			t.Errorf("Expected the path %v, got %v", testCase.path, node.Path)
+		}
+		if node.Kind != testCase.kind || node.Span != testCase.span {
+			t.Errorf("Expected %v to be of kind %v on lines %v, got %v on lines %v", testCase.path, testCase.kind, testCase.span, node.Kind, node.Span)
+		}
+		if !reflect.DeepEqual(node.Comments, testCase.comments) {
+			t.Errorf("Expected %v to have the comments %v, got %v", testCase.path, testCase.comments, node.Comments)
+		}
+	}
+}
@@ -0,0 +1,541 @@
+package toml
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/io"
+)
+
+var (
+	bareKeyRegexp        = regexp.MustCompile(`^[A-Za-z0-9_-]+$`)
+	decimalIntegerRegexp = regexp.MustCompile(`^[+-]?(0|[1-9](_?[0-9])*)$`)
+	integerRegexp        = regexp.MustCompile(`^([+-]?(0|[1-9](_?[0-9])*)|0x[0-9A-Fa-f](_?[0-9A-Fa-f])*|0o[0-7](_?[0-7])*|0b[01](_?[01])*)$`)
+	floatRegexp          = regexp.MustCompile(`^([+-]?(0|[1-9](_?[0-9])*)(\.[0-9](_?[0-9])*([eE][+-]?[0-9](_?[0-9])*)?|[eE][+-]?[0-9](_?[0-9])*)|[+-]?(inf|nan))$`)
+	dateTimeRegexp       = regexp.MustCompile(`^(\d{4}-\d{2}-\d{2}([Tt ]\d{2}:\d{2}:\d{2}(\.\d+)?([Zz]|[+-]\d{2}:\d{2})?)?|\d{2}:\d{2}:\d{2}(\.\d+)?)$`)
+	localDateRegexp      = regexp.MustCompile(`^\d{4}-\d{2}-\d{2}$`)
+	timePrefixRegexp     = regexp.MustCompile(`^ \d{2}:`)
+)
+
+type parser struct {
+	content string
+	pos     int
+	// offsets of values are relative to base, the start of the statement
+	base int
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.content[:p.pos], "\n") + 1
+	message := fmt.Sprintf(format, args...)
+
+	return errors.New(fmt.Sprintf("Invalid TOML on line %d: %s", line, message))
+}
+
+func (p *parser) atEnd() bool {
+	return p.pos >= len(p.content)
+}
+
+func (p *parser) peek() byte {
+	if p.atEnd() {
+		return 0
+	}
+
+	return p.content[p.pos]
+}
+
+func (p *parser) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(p.content[p.pos:], prefix)
+}
+
+func (p *parser) atEndOfLine() bool {
+	return p.atEnd() || p.peek() == '\n' || p.hasPrefix("\r\n")
+}
+
+func (p *parser) skipWhitespace() {
+	for !p.atEnd() && (p.peek() == ' ' || p.peek() == '\t') {
+		p.pos++
+	}
+}
+
+func (p *parser) skipComment() {
+	for !p.atEnd() && p.peek() != '\n' {
+		p.pos++
+	}
+}
+
+// skipBlank skips whitespace, comments and newlines, as allowed inside arrays
+func (p *parser) skipBlank() {
+	for !p.atEnd() {
+		switch p.peek() {
+		case ' ', '\t', '\r', '\n':
+			p.pos++
+		case '#':
+			p.skipComment()
+		default:
+			return
+		}
+	}
+}
+
+// endOfLine consumes an optional comment and stops right before the newline
+func (p *parser) endOfLine() error {
+	p.skipWhitespace()
+	if p.peek() == '#' {
+		p.skipComment()
+		return nil
+	}
+
+	if !p.atEndOfLine() {
+		return p.errorf("unexpected %q", p.peek())
+	}
+	if p.peek() == '\r' {
+		p.pos++
+	}
+
+	return nil
+}
+
+func (p *parser) expect(expected string) error {
+	if !p.hasPrefix(expected) {
+		if p.atEnd() {
+			return p.errorf("expected %q, got end of file", expected)
+		}
+
+		return p.errorf("expected %q, got %q", expected, p.peek())
+	}
+	p.pos += len(expected)
+
+	return nil
+}
+
+func (p *parser) parseStatement() (*Statement, error) {
+	p.skipWhitespace()
+
+	if p.atEndOfLine() || p.peek() == '#' {
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+
+		return &Statement{Type: OtherType}, nil
+	}
+
+	if p.peek() == '[' {
+		statementType := TableType
+		closing := "]"
+		if p.hasPrefix("[[") {
+			statementType = ArrayTableType
+			closing = "]]"
+		}
+		p.pos += len(closing)
+
+		p.skipWhitespace()
+		key, err := p.parseKey()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(closing); err != nil {
+			return nil, err
+		}
+		if err := p.endOfLine(); err != nil {
+			return nil, err
+		}
+
+		return &Statement{Type: statementType, Key: key}, nil
+	}
+
+	key, value, err := p.parseKeyValue()
+	if err != nil {
+		return nil, err
+	}
+	if err := p.endOfLine(); err != nil {
+		return nil, err
+	}
+
+	return &Statement{Type: KeyValueType, Key: key, Value: value}, nil
+}
+
+func (p *parser) parseKeyValue() ([]string, *Value, error) {
+	key, err := p.parseKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := p.expect("="); err != nil {
+		return nil, nil, err
+	}
+	p.skipWhitespace()
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return key, value, nil
+}
+
+// parseKey parses a (possibly dotted) key and the whitespace following it
+func (p *parser) parseKey() ([]string, error) {
+	key := []string{}
+
+	for {
+		p.skipWhitespace()
+
+		var segment string
+		switch p.peek() {
+		case '"':
+			value, err := p.parseBasicString()
+			if err != nil {
+				return nil, err
+			}
+			segment, err = decodeString(value)
+			if err != nil {
+				return nil, err
+			}
+		case '\'':
+			value, err := p.parseLiteralString()
+			if err != nil {
+				return nil, err
+			}
+			segment, _ = decodeString(value)
+		default:
+			start := p.pos
+			for !p.atEnd() && bareKeyRegexp.MatchString(p.content[p.pos:p.pos+1]) {
+				p.pos++
+			}
+			if start == p.pos {
+				if p.atEnd() {
+					return nil, p.errorf("expected a key, got end of file")
+				}
+
+				return nil, p.errorf("expected a key, got %q", p.peek())
+			}
+			segment = p.content[start:p.pos]
+		}
+		key = append(key, segment)
+
+		p.skipWhitespace()
+		if p.peek() != '.' {
+			return key, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) newValue(kind ValueKind, start int) *Value {
+	return &Value{
+		Kind:  kind,
+		Raw:   p.content[start:p.pos],
+		Start: start - p.base,
+		End:   p.pos - p.base,
+	}
+}
+
+func (p *parser) parseValue() (*Value, error) {
+	switch p.peek() {
+	case '"':
+		if p.hasPrefix(`"""`) {
+			return p.parseMultilineString(`"""`, MultilineBasicString)
+		}
+		return p.parseBasicString()
+	case '\'':
+		if p.hasPrefix(`'''`) {
+			return p.parseMultilineString(`'''`, MultilineLiteralString)
+		}
+		return p.parseLiteralString()
+	case '[':
+		return p.parseArray()
+	case '{':
+		return p.parseInlineTable()
+	}
+
+	return p.parseScalar()
+}
+
+func (p *parser) parseBasicString() (*Value, error) {
+	start := p.pos
+	p.pos++
+
+	for {
+		if p.atEndOfLine() {
+			return nil, p.errorf("unterminated string")
+		}
+
+		switch p.peek() {
+		case '"':
+			p.pos++
+			value := p.newValue(StringKind, start)
+			value.StringStyle = BasicString
+			if _, err := decodeString(value); err != nil {
+				return nil, p.errorf("%s", err.Error())
+			}
+
+			return value, nil
+		case '\\':
+			p.pos++
+			if p.atEndOfLine() {
+				return nil, p.errorf("unterminated string")
+			}
+			p.pos++
+		default:
+			p.pos++
+		}
+	}
+}
+
+func (p *parser) parseLiteralString() (*Value, error) {
+	start := p.pos
+	p.pos++
+
+	for {
+		if p.atEndOfLine() {
+			return nil, p.errorf("unterminated string")
+		}
+
+		if p.peek() == '\'' {
+			p.pos++
+			value := p.newValue(StringKind, start)
+			value.StringStyle = LiteralString
+
+			return value, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseMultilineString(delimiter string, style StringStyle) (*Value, error) {
+	start := p.pos
+	p.pos += len(delimiter)
+
+	for {
+		if p.atEnd() {
+			return nil, p.errorf("unterminated multiline string")
+		}
+
+		if style == MultilineBasicString && p.peek() == '\\' {
+			p.pos += 2
+			continue
+		}
+
+		if p.hasPrefix(delimiter) {
+			// Up to two quotes can be placed right before the closing delimiter
+			for i := 0; i < 2 && p.hasPrefix(delimiter+delimiter[:1]); i++ {
+				p.pos++
+			}
+			p.pos += len(delimiter)
+
+			value := p.newValue(StringKind, start)
+			value.StringStyle = style
+			if _, err := decodeString(value); err != nil {
+				return nil, p.errorf("%s", err.Error())
+			}
+
+			return value, nil
+		}
+		p.pos++
+	}
+}
+
+func (p *parser) parseArray() (*Value, error) {
+	start := p.pos
+	p.pos++
+
+	items := []*Value{}
+	for {
+		p.skipBlank()
+		if p.peek() == ']' {
+			break
+		}
+
+		item, err := p.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+
+		p.skipBlank()
+		if p.peek() == ',' {
+			p.pos++
+			continue
+		}
+		if p.peek() != ']' {
+			if p.atEnd() {
+				return nil, p.errorf("unterminated array")
+			}
+
+			return nil, p.errorf("expected ',' or ']' in array, got %q", p.peek())
+		}
+		break
+	}
+	p.pos++
+
+	value := p.newValue(ArrayKind, start)
+	value.Items = items
+
+	return value, nil
+}
+
+func (p *parser) parseInlineTable() (*Value, error) {
+	start := p.pos
+	p.pos++
+
+	fields := []*InlineField{}
+	p.skipWhitespace()
+	if p.peek() != '}' {
+		for {
+			key, value, err := p.parseKeyValue()
+			if err != nil {
+				return nil, err
+			}
+			fields = append(fields, &InlineField{key, value})
+
+			p.skipWhitespace()
+			if p.peek() == ',' {
+				p.pos++
+				p.skipWhitespace()
+				if p.peek() == '}' {
+					return nil, p.errorf("trailing comma in inline table")
+				}
+				continue
+			}
+			if p.peek() != '}' {
+				if p.atEndOfLine() {
+					return nil, p.errorf("inline tables must be written on a single line")
+				}
+
+				return nil, p.errorf("expected ',' or '}' in inline table, got %q", p.peek())
+			}
+			break
+		}
+	}
+	p.pos++
+
+	value := p.newValue(InlineTableKind, start)
+	value.Fields = fields
+
+	return value, nil
+}
+
+func isScalarChar(c byte) bool {
+	return c >= 'a' && c <= 'z' ||
+		c >= 'A' && c <= 'Z' ||
+		c >= '0' && c <= '9' ||
+		strings.IndexByte("_+-.:", c) != -1
+}
+
+func (p *parser) parseScalar() (*Value, error) {
+	start := p.pos
+	for !p.atEnd() && isScalarChar(p.peek()) {
+		p.pos++
+	}
+
+	// A space can separate the date from the time in a date-time
+	if localDateRegexp.MatchString(p.content[start:p.pos]) &&
+		timePrefixRegexp.MatchString(p.content[p.pos:]) {
+		p.pos++
+		for !p.atEnd() && isScalarChar(p.peek()) {
+			p.pos++
+		}
+	}
+
+	token := p.content[start:p.pos]
+	kind, ok := scalarKind(token)
+	if !ok {
+		if token == "" {
+			if p.atEnd() {
+				return nil, p.errorf("expected a value, got end of file")
+			}
+
+			return nil, p.errorf("expected a value, got %q", p.peek())
+		}
+
+		return nil, p.errorf("invalid value %q", token)
+	}
+
+	return p.newValue(kind, start), nil
+}
+
+func scalarKind(token string) (ValueKind, bool) {
+	switch {
+	case token == "true" || token == "false":
+		return BooleanKind, true
+	case integerRegexp.MatchString(token):
+		return IntegerKind, true
+	case floatRegexp.MatchString(token):
+		return FloatKind, true
+	case dateTimeRegexp.MatchString(token):
+		return DateTimeKind, true
+	}
+
+	return 0, false
+}
+
+// ParseValue parses a single TOML value, e.g. a value given on the command line
+func ParseValue(raw string) (*Value, error) {
+	p := &parser{content: raw}
+
+	value, err := p.parseValue()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipWhitespace()
+	if !p.atEnd() {
+		return nil, p.errorf("unexpected %q after value", p.peek())
+	}
+
+	return value, nil
+}
+
+func ParseTomlContent(content string) ([]*Statement, error) {
+	p := &parser{content: content}
+
+	statements := []*Statement{}
+	for !p.atEnd() {
+		p.base = p.pos
+		lineNumber := strings.Count(content[:p.pos], "\n") + 1
+
+		statement, err := p.parseStatement()
+		if err != nil {
+			return nil, err
+		}
+		statement.LineNumber = lineNumber
+		statement.Raw = content[p.base:p.pos]
+		statements = append(statements, statement)
+
+		// skip the newline
+		p.pos++
+	}
+
+	return statements, nil
+}
+
+func ParseTomlFile(filePath string) ([]*Statement, bool, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return nil, false, err
+	}
+
+	statements, err := ParseTomlContent(content)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return statements, strings.HasSuffix(content, "\n"), nil
+}
+
+func getSections(statements []*Statement) []*Section {
+	currentSection := &Section{nil, []*Statement{}}
+	sections := []*Section{currentSection}
+
+	for _, statement := range statements {
+		if statement.Type == TableType || statement.Type == ArrayTableType {
+			currentSection = &Section{statement, []*Statement{}}
+			sections = append(sections, currentSection)
+		}
+
+		currentSection.Statements = append(currentSection.Statements, statement)
+	}
+
+	return sections
+}
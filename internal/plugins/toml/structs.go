@@ -0,0 +1,124 @@
+package toml
+
+import (
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+)
+
+type StatementType int
+
+const (
+	KeyValueType StatementType = iota
+	TableType
+	ArrayTableType
+	OtherType
+)
+
+type ValueKind int
+
+const (
+	StringKind ValueKind = iota
+	IntegerKind
+	FloatKind
+	BooleanKind
+	DateTimeKind
+	ArrayKind
+	InlineTableKind
+)
+
+type StringStyle int
+
+const (
+	BasicString StringStyle = iota
+	LiteralString
+	MultilineBasicString
+	MultilineLiteralString
+)
+
+// Value is a TOML value as written in the file. Start and End are the offsets
+// of Raw in the raw content of the statement holding the value.
+type Value struct {
+	Kind        ValueKind
+	Raw         string
+	Start       int
+	End         int
+	StringStyle StringStyle
+	Items       []*Value
+	Fields      []*InlineField
+}
+
+type InlineField struct {
+	Key   []string
+	Value *Value
+}
+
+// Statement is a logical line of the file. Multiline strings and arrays make
+// a key value statement span several physical lines.
+type Statement struct {
+	LineNumber int
+	Raw        string
+	Type       StatementType
+	Key        []string
+	Value      *Value
+}
+
+// Section is a table header (nil for the root table) followed by its
+// statements. The header is also the first statement of the section.
+type Section struct {
+	Header     *Statement
+	Statements []*Statement
+}
+
+type TomlConfiguration struct {
+	Sections        []*Section
+	TrailingNewline bool
+	FilePath        string
+	Root            *Node
+}
+
+func (config *TomlConfiguration) OutputFile(outputType core.OutputType) (string, error) {
+	return OutputConfigFile(config, outputType), nil
+}
+
+func (config *TomlConfiguration) WriteToFile(filepath string, outputType core.OutputType) error {
+	output, err := config.OutputFile(outputType)
+	if err != nil {
+		return err
+	}
+
+	return io.WriteFileContents(filepath, output)
+}
+
+type TomlConfigurator struct{}
+
+func (configurator TomlConfigurator) GetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (string, error) {
+	return GetParameterFromPath(notationStyle, filePath, key)
+}
+
+func (configurator TomlConfigurator) SetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	return configurator.SetTypedParameter(notationStyle, filePath, key, value, "")
+}
+
+func (configurator TomlConfigurator) SetTypedParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+	valueType string,
+) (core.Configuration, error) {
+	config, err := EditConfigFile(notationStyle, filePath, key, value, valueType)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
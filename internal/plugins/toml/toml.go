@@ -0,0 +1,286 @@
+package toml
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+var indentRegexp = regexp.MustCompile(`^[ \t]*`)
+
+func OutputConfigFile(config *TomlConfiguration, outputType core.OutputType) string {
+	lines := []string{}
+
+	for _, section := range config.Sections {
+		for _, statement := range section.Statements {
+			if outputType == core.MeaningFullOutput && statement.Type == OtherType {
+				continue
+			}
+
+			lines = append(lines, statement.Raw)
+		}
+	}
+
+	output := strings.Join(lines, "\n")
+	if config.TrailingNewline {
+		output += "\n"
+	}
+
+	return output
+}
+
+func newTomlConfiguration(statements []*Statement, trailingNewline bool, filePath string) (TomlConfiguration, error) {
+	sections := getSections(statements)
+
+	root, err := buildTree(sections)
+	if err != nil {
+		return TomlConfiguration{}, err
+	}
+
+	return TomlConfiguration{sections, trailingNewline, filePath, root}, nil
+}
+
+func GetParsedTomlFile(filePath string) (TomlConfiguration, error) {
+	statements, trailingNewline, err := ParseTomlFile(filePath)
+	if err != nil {
+		return TomlConfiguration{}, err
+	}
+
+	return newTomlConfiguration(statements, trailingNewline, filePath)
+}
+
+func GetParameterFromPath(notationStyle core.NotationStyle, filePath string, key string) (string, error) {
+	config, err := GetParsedTomlFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := core.DecomposeKey(notationStyle, key)
+	if err != nil {
+		return "", err
+	}
+	result := resolvePath(config.Root, path)
+	if result.Depth != len(path) {
+		return "", errors.New("Key not found")
+	}
+
+	if result.Value == nil {
+		return "", errors.New(fmt.Sprintf("%s is a table, not a value", key))
+	}
+
+	return result.Value.ToString(), nil
+}
+
+func EditConfigFile(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+	valueType string,
+) (*TomlConfiguration, error) {
+	config, err := GetParsedTomlFile(filePath)
+	if err != nil {
+		return &TomlConfiguration{}, err
+	}
+
+	path, err := core.DecomposeKey(notationStyle, key)
+	if err != nil {
+		return &TomlConfiguration{}, err
+	}
+	result := resolvePath(config.Root, path)
+
+	if result.Depth == len(path) {
+		err = setExistingValue(result, key, value, valueType)
+	} else {
+		err = setNewValue(&config, result, path, value, valueType)
+	}
+	if err != nil {
+		return &TomlConfiguration{}, err
+	}
+
+	// Refuse the edit if the result is not valid TOML anymore
+	output := OutputConfigFile(&config, core.FullOutput)
+	statements, err := ParseTomlContent(output)
+	if err != nil {
+		return &TomlConfiguration{}, errors.New(fmt.Sprintf("Refusing to set %s: %s", key, err.Error()))
+	}
+	if _, err = newTomlConfiguration(statements, config.TrailingNewline, filePath); err != nil {
+		return &TomlConfiguration{}, errors.New(fmt.Sprintf("Refusing to set %s: %s", key, err.Error()))
+	}
+
+	return &config, nil
+}
+
+func replaceValue(statement *Statement, value *Value, newRaw string) {
+	statement.Raw = statement.Raw[:value.Start] + newRaw + statement.Raw[value.End:]
+}
+
+func setExistingValue(result resolution, key string, value string, valueType string) error {
+	if result.Value == nil {
+		return errors.New(fmt.Sprintf("Cannot set %s: it is a table", key))
+	}
+
+	kind := result.Value.Kind
+	if valueType != "" {
+		var err error
+		kind, err = getValueKind(valueType)
+		if err != nil {
+			return err
+		}
+	}
+
+	newRaw, err := encodeValue(kind, result.Value.StringStyle, value)
+	if err != nil {
+		return err
+	}
+	replaceValue(result.Statement, result.Value, newRaw)
+
+	return nil
+}
+
+func encodeNewValue(value string, valueType string) (string, error) {
+	if valueType == "" {
+		return inferValue(value), nil
+	}
+
+	kind, err := getValueKind(valueType)
+	if err != nil {
+		return "", err
+	}
+
+	return encodeValue(kind, BasicString, value)
+}
+
+func setNewValue(
+	config *TomlConfiguration,
+	result resolution,
+	path []string,
+	value string,
+	valueType string,
+) error {
+	newRaw, err := encodeNewValue(value, valueType)
+	if err != nil {
+		return err
+	}
+
+	missingKey := formatKey(path[:result.Depth+1])
+
+	if result.Value != nil {
+		if result.Value.Kind != InlineTableKind {
+			return errors.New(fmt.Sprintf("Cannot set %s: %s is not a table", formatKey(path), formatKey(path[:result.Depth])))
+		}
+
+		insertInlineField(result.Statement, result.Value, formatKey(path[result.Depth:])+" = "+newRaw)
+
+		return nil
+	}
+
+	if result.Node.Kind == ArrayTableNode {
+		return errors.New(fmt.Sprintf("Cannot set %s: %s is not an item of the array of tables", formatKey(path), missingKey))
+	}
+
+	container := result.Container
+	rest := path[result.ContainerDepth:]
+
+	if len(rest) == 1 || result.Node.Dotted || (result.Node == container && container != config.Root) {
+		insertKeyValue(container.Section, formatKey(rest)+" = "+newRaw)
+
+		return nil
+	}
+
+	// A new table is needed: as it is added at the end of the file, it would
+	// belong to the last item of any array of tables on its path
+	for _, isLastItem := range result.LastArrayItems {
+		if !isLastItem {
+			return errors.New(fmt.Sprintf("Cannot create table %s: only the last item of an array of tables can be extended", missingKey))
+		}
+	}
+	appendTable(config, tablePath(config.Root, path[:len(path)-1]), formatKey(path[len(path)-1:])+" = "+newRaw)
+
+	return nil
+}
+
+// tablePath removes the array indexes from a path, to use it in a header
+func tablePath(root *Node, path []string) []string {
+	header := []string{}
+
+	node := root
+	for _, segment := range path {
+		if node == nil {
+			header = append(header, segment)
+			continue
+		}
+
+		if node.Kind == ArrayTableNode {
+			node = node.Items[len(node.Items)-1]
+			continue
+		}
+
+		header = append(header, segment)
+		node = node.Children[segment]
+	}
+
+	return header
+}
+
+func insertInlineField(statement *Statement, value *Value, field string) {
+	if len(value.Fields) == 0 {
+		statement.Raw = statement.Raw[:value.Start] + "{ " + field + " }" + statement.Raw[value.End:]
+
+		return
+	}
+
+	last := value.Fields[len(value.Fields)-1].Value
+	statement.Raw = statement.Raw[:last.End] + ", " + field + statement.Raw[last.End:]
+}
+
+// insertKeyValue adds a key value statement after the last key value of the
+// section, using the same indentation
+func insertKeyValue(section *Section, content string) {
+	index := -1
+	indent := ""
+
+	for i, statement := range section.Statements {
+		if statement.Type == KeyValueType {
+			index = i
+			indent = indentRegexp.FindString(statement.Raw)
+		}
+	}
+
+	if index == -1 {
+		if section.Header != nil {
+			index = 0
+		} else {
+			// Root table without keys: add it before the blank lines
+			// separating the root comments from the first table
+			index = len(section.Statements) - 1
+			for index >= 0 && strings.TrimSpace(section.Statements[index].Raw) == "" {
+				index--
+			}
+		}
+	}
+
+	statement := &Statement{Raw: indent + content, Type: KeyValueType}
+
+	statements := append([]*Statement{}, section.Statements[:index+1]...)
+	statements = append(statements, statement)
+	section.Statements = append(statements, section.Statements[index+1:]...)
+}
+
+func appendTable(config *TomlConfiguration, path []string, content string) {
+	lastSection := config.Sections[len(config.Sections)-1]
+	statements := lastSection.Statements
+
+	if len(statements) > 0 && strings.TrimSpace(statements[len(statements)-1].Raw) != "" {
+		lastSection.Statements = append(statements, &Statement{Type: OtherType})
+	}
+
+	header := &Statement{Raw: "[" + formatKey(path) + "]", Type: TableType, Key: path}
+	section := &Section{header, []*Statement{header}}
+	config.Sections = append(config.Sections, section)
+
+	insertKeyValue(section, content)
+}
@@ -0,0 +1,252 @@
+package toml
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+)
+
+const (
+	CARGO_FILE_PATH     = "../../../data/toml/cargo.toml"
+	PYPROJECT_FILE_PATH = "../../../data/toml/pyproject.toml"
+)
+
+func testEditParameter(
+	t *testing.T,
+	notationStyle core.NotationStyle,
+	filepath string,
+	key string,
+	value string,
+	valueType string,
+	replacements map[string]string,
+) {
+	original, err := io.GetFileContents(filepath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := EditConfigFile(notationStyle, filepath, key, value, valueType)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := original
+	for old, new := range replacements {
+		if !strings.Contains(expected, old) {
+			t.Fatal("The fixture does not contain", old)
+		}
+		expected = strings.Replace(expected, old, new, 1)
+	}
+
+	output := OutputConfigFile(config, core.FullOutput)
+	if output != expected {
+		t.Fatal(fmt.Sprintf("Expected:\n%s\nGot:\n%s", expected, output))
+	}
+}
+
+func TestOutputTomlFile(t *testing.T) {
+	for _, filepath := range []string{CARGO_FILE_PATH, PYPROJECT_FILE_PATH} {
+		t.Run("it prints "+filepath+" unchanged", func(t *testing.T) {
+			original, err := io.GetFileContents(filepath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			config, err := GetParsedTomlFile(filepath)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if output := OutputConfigFile(&config, core.FullOutput); output != original {
+				t.Fatal(fmt.Sprintf("Expected:\n%s\nGot:\n%s", original, output))
+			}
+		})
+	}
+
+	t.Run("it prints the key values only", func(t *testing.T) {
+		config, err := GetParsedTomlFile(PYPROJECT_FILE_PATH)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := `[build-system]
+requires = ["setuptools>=61.0"]
+build-backend = "setuptools.build_meta"
+[project]
+name = "edicon-py"
+version = "0.3.0"
+dependencies = [
+    "requests>=2.0",  # http
+    "click",
+]
+[tool.poetry]
+name = "edicon-py"
+`
+		if output := OutputConfigFile(&config, core.MeaningFullOutput); output != expected {
+			t.Fatal(fmt.Sprintf("Expected:\n%s\nGot:\n%s", expected, output))
+		}
+	})
+}
+
+func TestGetParameter(t *testing.T) {
+	dotCases := map[string]string{
+		"package.name":                          "edicon",
+		"package.version":                       "0.1.0",
+		"package.edition":                       "2021",
+		"package.authors.1":                     "John <john@example.com>",
+		"package.description":                   "Edit configuration files\nfrom the terminal.",
+		"package.publish":                       "false",
+		"package.metadata.docs.rs":              "{ all-features = true }",
+		"package.metadata.docs.rs.all-features": "true",
+		"dependencies.serde.version":            "1.0",
+		"dependencies.serde.features":           `["derive"]`,
+		"dependencies.serde.features.0":         "derive",
+		"profile.release.opt-level":             "3",
+		"profile.release.debug-ratio":           "0.5",
+		"bin.0.name":                            "edicon",
+		"bin.1.path":                            `src\daemon.rs`,
+		"release.date":                          "2024-05-27T07:32:00Z",
+		"release.day":                           "2024-05-27",
+	}
+
+	for key, expectedValue := range dotCases {
+		t.Run("it gets existing parameter "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, CARGO_FILE_PATH, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value != expectedValue {
+				t.Fatal(fmt.Sprintf("Expected %s got %s", expectedValue, value))
+			}
+		})
+	}
+
+	t.Run("it gets existing parameter with brackets notation", func(t *testing.T) {
+		value, err := GetParameterFromPath(core.BracketsNotation, PYPROJECT_FILE_PATH, "project[dependencies][0]")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if value != "requests>=2.0" {
+			t.Fatal("Expected requests>=2.0 got " + value)
+		}
+	})
+
+	missingCases := []string{"package.license", "bin.2.name", "bin.name", "package.authors.5", "profile.debug", "profile.release"}
+	for _, key := range missingCases {
+		t.Run("it does not get "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, CARGO_FILE_PATH, key)
+			if err == nil {
+				t.Error("Should be missing. Got " + value + " instead")
+			}
+		})
+	}
+}
+
+func TestEditParameter(t *testing.T) {
+	type EditTestElement struct {
+		key          string
+		value        string
+		valueType    string
+		replacements map[string]string
+	}
+
+	cases := []EditTestElement{
+		{"package.version", "0.2.0", "", map[string]string{`version = "0.1.0"   # bumped`: `version = "0.2.0"   # bumped`}},
+		{"package.edition", "2024", "", map[string]string{`edition = '2021'`: `edition = '2024'`}},
+		{"package.name", `it's "quoted"`, "", map[string]string{`name = "edicon"`: `name = "it's \"quoted\""`}},
+		{"package.publish", "True", "", map[string]string{"publish = false": "publish = true"}},
+		{"package.publish", "registry", "string", map[string]string{"publish = false": `publish = "registry"`}},
+		{"package.authors.0", "Bob", "", map[string]string{`["Jane <jane@example.com>"`: `["Bob"`}},
+		{"package.description", "One line", "", map[string]string{"\"\"\"\nEdit configuration files\nfrom the terminal.\"\"\"": `"""One line"""`}},
+		{"dependencies.serde.version", "1.1", "", map[string]string{`{ version = "1.0"`: `{ version = "1.1"`}},
+		{"dependencies.serde.optional", "true", "", map[string]string{`features = ["derive"] }`: `features = ["derive"], optional = true }`}},
+		{"dependencies.regex", "{ version = \"1.11\" }", "inline-table", map[string]string{`regex = "1.10"`: `regex = { version = "1.11" }`}},
+		{"dependencies.toml", "0.8", "string", map[string]string{"regex = \"1.10\"\n": "regex = \"1.10\"\ntoml = \"0.8\"\n"}},
+		{"profile.release.opt-level", "2", "", map[string]string{"opt-level = 3": "opt-level = 2"}},
+		{"profile.release.debug-ratio", "1", "", map[string]string{"debug-ratio = 0.5": "debug-ratio = 1.0"}},
+		{"profile.release.codegen-units", "1", "", map[string]string{"debug-ratio = 0.5\n": "debug-ratio = 0.5\ncodegen-units = 1\n"}},
+		{"bin.1.path", "src/d.rs", "", map[string]string{`path = 'src\daemon.rs'`: `path = 'src/d.rs'`}},
+		{"bin.0.test", "false", "", map[string]string{"path = \"src/main.rs\"\n": "path = \"src/main.rs\"\ntest = false\n"}},
+		{"bin.0.target.name", "foo", "", map[string]string{"path = \"src/main.rs\"\n": "path = \"src/main.rs\"\ntarget.name = \"foo\"\n"}},
+		{"release.date", "2025-01-01T00:00:00Z", "", map[string]string{"2024-05-27T07:32:00Z": "2025-01-01T00:00:00Z"}},
+		{"package.metadata.docs.rs.all-features", "false", "", map[string]string{"all-features = true": "all-features = false"}},
+		{"package.metadata.playground.features", `["a"]`, "", map[string]string{"metadata.docs.rs = { all-features = true }\n": "metadata.docs.rs = { all-features = true }\nmetadata.playground.features = [\"a\"]\n"}},
+		{"workspace.resolver", "2", "string", map[string]string{"day = 2024-05-27\n": "day = 2024-05-27\n\n[workspace]\nresolver = \"2\"\n"}},
+	}
+
+	for _, element := range cases {
+		t.Run("it sets "+element.key+" to "+element.value, func(t *testing.T) {
+			testEditParameter(t, core.DotNotation, CARGO_FILE_PATH, element.key, element.value, element.valueType, element.replacements)
+		})
+	}
+
+	t.Run("it sets a key in an implicit table", func(t *testing.T) {
+		testEditParameter(t, core.DotNotation, PYPROJECT_FILE_PATH, "tool.black.line-length", "88", "", map[string]string{
+			"[tool.poetry]\nname = \"edicon-py\"\n": "[tool.poetry]\nname = \"edicon-py\"\n\n[tool.black]\nline-length = 88\n",
+		})
+	})
+
+	t.Run("it sets a value in a multiline array", func(t *testing.T) {
+		testEditParameter(t, core.DotNotation, PYPROJECT_FILE_PATH, "project.dependencies.1", "rich", "", map[string]string{
+			`"click",`: `"rich",`,
+		})
+	})
+
+	invalidCases := []EditTestElement{
+		{"package.version", "1.0", "integer", nil},
+		{"profile.release.opt-level", "fast", "", nil},
+		{"package.publish", "no", "", nil},
+		{"release.day", "tomorrow", "", nil},
+		{"package.authors", "nobody", "", nil},
+		{"package.version", "1", "complex", nil},
+		{"profile.release", "fast", "", nil},
+		{"profile", "fast", "", nil},
+		{"package.name.first", "foo", "", nil},
+		{"bin.2.name", "foo", "", nil},
+	}
+
+	for _, element := range invalidCases {
+		t.Run("it refuses to set "+element.key+" to "+element.value, func(t *testing.T) {
+			_, err := EditConfigFile(core.DotNotation, CARGO_FILE_PATH, element.key, element.value, element.valueType)
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestParseInvalidToml(t *testing.T) {
+	cases := []string{
+		"key = ",
+		"key = \"unterminated",
+		"key = value",
+		"[table]\n[table]",
+		"a = 1\na = 2",
+		"a = 1\n[a]",
+		"[a]\nb.c = 1\n[a.b]",
+		"[[a]]\n[a]",
+		"a = { b = 1, }",
+		"a = { b = 1,\n c = 2 }",
+		"a = [1, 2",
+		"a = \"\\q\"",
+		"[a] b = 1",
+	}
+
+	for _, content := range cases {
+		t.Run("it rejects "+content, func(t *testing.T) {
+			statements, err := ParseTomlContent(content)
+			if err == nil {
+				_, err = newTomlConfiguration(statements, false, "")
+			}
+
+			if err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,268 @@
+package toml
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+type NodeKind int
+
+const (
+	TableNode NodeKind = iota
+	ArrayTableNode
+	ValueNode
+)
+
+// Node is an element of the logical TOML document: a table, an array of
+// tables or a key holding a value.
+type Node struct {
+	Kind     NodeKind
+	Keys     []string
+	Children map[string]*Node
+	Items    []*Node
+	// Section is the section defining the table, if defined by a header
+	// (or the root section for the root table)
+	Section   *Section
+	Statement *Statement
+	Value     *Value
+	// Defined is true for tables defined by a header, Dotted for tables
+	// defined by dotted keys. Tables which are neither are implicit.
+	Defined bool
+	Dotted  bool
+}
+
+func newTableNode() *Node {
+	return &Node{Kind: TableNode, Children: map[string]*Node{}}
+}
+
+func (node *Node) addChild(name string, child *Node) {
+	node.Children[name] = child
+	node.Keys = append(node.Keys, name)
+}
+
+func statementError(statement *Statement, format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...)
+
+	return errors.New(fmt.Sprintf("Invalid TOML on line %d: %s", statement.LineNumber, message))
+}
+
+// buildTree builds the logical document, returning an error if the same key
+// or table is defined more than once
+func buildTree(sections []*Section) (*Node, error) {
+	root := newTableNode()
+	root.Section = sections[0]
+	root.Defined = true
+
+	for _, section := range sections {
+		table := root
+		if section.Header != nil {
+			var err error
+			table, err = defineTable(root, section)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		for _, statement := range section.Statements {
+			if statement.Type != KeyValueType {
+				continue
+			}
+
+			err := defineKeyValue(table, statement)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	return root, nil
+}
+
+func defineTable(root *Node, section *Section) (*Node, error) {
+	header := section.Header
+	path := header.Key
+
+	node := root
+	for i, name := range path[:len(path)-1] {
+		child, ok := node.Children[name]
+		if !ok {
+			child = newTableNode()
+			node.addChild(name, child)
+		}
+
+		switch child.Kind {
+		case ArrayTableNode:
+			child = child.Items[len(child.Items)-1]
+		case ValueNode:
+			return nil, statementError(header, "%s is already defined as a value", formatKey(path[:i+1]))
+		}
+		node = child
+	}
+
+	name := path[len(path)-1]
+	child, exists := node.Children[name]
+
+	if header.Type == ArrayTableType {
+		if !exists {
+			child = &Node{Kind: ArrayTableNode}
+			node.addChild(name, child)
+		} else if child.Kind != ArrayTableNode {
+			return nil, statementError(header, "%s is already defined and is not an array of tables", formatKey(path))
+		}
+
+		item := newTableNode()
+		item.Section = section
+		item.Defined = true
+		child.Items = append(child.Items, item)
+
+		return item, nil
+	}
+
+	if !exists {
+		child = newTableNode()
+		node.addChild(name, child)
+	} else if child.Kind != TableNode || child.Defined || child.Dotted {
+		return nil, statementError(header, "table %s is defined more than once", formatKey(path))
+	}
+	child.Defined = true
+	child.Section = section
+
+	return child, nil
+}
+
+func defineKeyValue(table *Node, statement *Statement) error {
+	path := statement.Key
+
+	node := table
+	for i, name := range path[:len(path)-1] {
+		child, ok := node.Children[name]
+		if !ok {
+			child = newTableNode()
+			child.Dotted = true
+			node.addChild(name, child)
+		} else if child.Kind != TableNode || !child.Dotted {
+			return statementError(statement, "%s is already defined and cannot be extended with dotted keys", formatKey(path[:i+1]))
+		}
+		node = child
+	}
+
+	name := path[len(path)-1]
+	if _, exists := node.Children[name]; exists {
+		return statementError(statement, "key %s is defined more than once", formatKey(path))
+	}
+	node.addChild(name, &Node{Kind: ValueNode, Statement: statement, Value: statement.Value})
+
+	return nil
+}
+
+// resolution is the result of walking a key path through the document
+type resolution struct {
+	// Depth is the number of segments of the path which were found
+	Depth     int
+	Node      *Node
+	Statement *Statement
+	// Value is set when the path reached a key, and goes deeper when the
+	// path continues into an array or an inline table
+	Value *Value
+	// Container is the deepest table found which has its own section
+	Container      *Node
+	ContainerDepth int
+	// LastArrayItems tells, for each array of tables crossed, if the last
+	// item was chosen
+	LastArrayItems []bool
+}
+
+func resolvePath(root *Node, path []string) resolution {
+	result := resolution{Node: root, Container: root}
+
+	for i := 0; i < len(path); i++ {
+		if result.Value != nil {
+			value, consumed := lookupInValue(result.Value, path[i:])
+			if value == nil {
+				return result
+			}
+			result.Value = value
+			i += consumed - 1
+			result.Depth = i + 1
+			continue
+		}
+
+		var child *Node
+		switch result.Node.Kind {
+		case TableNode:
+			child = result.Node.Children[path[i]]
+		case ArrayTableNode:
+			index, err := strconv.Atoi(path[i])
+			if err == nil && index >= 0 && index < len(result.Node.Items) {
+				child = result.Node.Items[index]
+				result.LastArrayItems = append(result.LastArrayItems, index == len(result.Node.Items)-1)
+			}
+		}
+		if child == nil {
+			return result
+		}
+
+		result.Node = child
+		result.Depth = i + 1
+		if child.Kind == ValueNode {
+			result.Statement = child.Statement
+			result.Value = child.Value
+		}
+		if child.Section != nil {
+			result.Container = child
+			result.ContainerDepth = i + 1
+		}
+	}
+
+	return result
+}
+
+func lookupInValue(value *Value, path []string) (*Value, int) {
+	switch value.Kind {
+	case ArrayKind:
+		index, err := strconv.Atoi(path[0])
+		if err != nil || index < 0 || index >= len(value.Items) {
+			return nil, 0
+		}
+
+		return value.Items[index], 1
+	case InlineTableKind:
+		for _, field := range value.Fields {
+			if len(field.Key) <= len(path) && equalKeys(field.Key, path[:len(field.Key)]) {
+				return field.Value, len(field.Key)
+			}
+		}
+	}
+
+	return nil, 0
+}
+
+func equalKeys(a []string, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// formatKey formats a key path, quoting the segments which are not bare keys
+func formatKey(path []string) string {
+	segments := []string{}
+	for _, segment := range path {
+		if bareKeyRegexp.MatchString(segment) {
+			segments = append(segments, segment)
+		} else {
+			segments = append(segments, EncodeString(segment, BasicString))
+		}
+	}
+
+	return strings.Join(segments, ".")
+}
@@ -0,0 +1,271 @@
+package toml
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var valueTypes = map[string]ValueKind{
+	"string":       StringKind,
+	"int":          IntegerKind,
+	"integer":      IntegerKind,
+	"float":        FloatKind,
+	"bool":         BooleanKind,
+	"boolean":      BooleanKind,
+	"datetime":     DateTimeKind,
+	"date":         DateTimeKind,
+	"array":        ArrayKind,
+	"inline-table": InlineTableKind,
+	"table":        InlineTableKind,
+}
+
+func getValueKind(valueType string) (ValueKind, error) {
+	kind, ok := valueTypes[strings.ToLower(valueType)]
+	if !ok {
+		return 0, errors.New(fmt.Sprintf("Unknown TOML type: %s", valueType))
+	}
+
+	return kind, nil
+}
+
+func (kind ValueKind) String() string {
+	switch kind {
+	case StringKind:
+		return "string"
+	case IntegerKind:
+		return "integer"
+	case FloatKind:
+		return "float"
+	case BooleanKind:
+		return "boolean"
+	case DateTimeKind:
+		return "datetime"
+	case ArrayKind:
+		return "array"
+	default:
+		return "inline table"
+	}
+}
+
+// ToString returns the value as it should be displayed: strings are decoded,
+// any other value is returned as written in the file
+func (value *Value) ToString() string {
+	if value.Kind != StringKind {
+		return value.Raw
+	}
+
+	decoded, err := decodeString(value)
+	if err != nil {
+		return value.Raw
+	}
+
+	return decoded
+}
+
+func decodeString(value *Value) (string, error) {
+	raw := value.Raw
+
+	switch value.StringStyle {
+	case LiteralString:
+		return raw[1 : len(raw)-1], nil
+	case MultilineLiteralString:
+		return trimFirstNewline(raw[3 : len(raw)-3]), nil
+	case MultilineBasicString:
+		return unescape(trimFirstNewline(raw[3:len(raw)-3]), true)
+	default:
+		return unescape(raw[1:len(raw)-1], false)
+	}
+}
+
+func trimFirstNewline(content string) string {
+	if strings.HasPrefix(content, "\r\n") {
+		return content[2:]
+	}
+
+	return strings.TrimPrefix(content, "\n")
+}
+
+func unescape(content string, multiline bool) (string, error) {
+	var builder strings.Builder
+
+	for i := 0; i < len(content); i++ {
+		c := content[i]
+		if c != '\\' {
+			builder.WriteByte(c)
+			continue
+		}
+
+		i++
+		if i >= len(content) {
+			return "", errors.New("invalid escape sequence at the end of a string")
+		}
+
+		switch content[i] {
+		case 'b':
+			builder.WriteByte('\b')
+		case 't':
+			builder.WriteByte('\t')
+		case 'n':
+			builder.WriteByte('\n')
+		case 'f':
+			builder.WriteByte('\f')
+		case 'r':
+			builder.WriteByte('\r')
+		case '"':
+			builder.WriteByte('"')
+		case '\\':
+			builder.WriteByte('\\')
+		case 'u', 'U':
+			length := 4
+			if content[i] == 'U' {
+				length = 8
+			}
+			if i+1+length > len(content) {
+				return "", errors.New("invalid unicode escape sequence")
+			}
+			code, err := strconv.ParseUint(content[i+1:i+1+length], 16, 32)
+			if err != nil || !utf8.ValidRune(rune(code)) {
+				return "", errors.New("invalid unicode escape sequence")
+			}
+			builder.WriteRune(rune(code))
+			i += length
+		default:
+			// A line ending backslash trims all whitespace up to the next
+			// non-whitespace character in multiline strings
+			rest := strings.TrimLeft(content[i:], " \t")
+			if multiline && (strings.HasPrefix(rest, "\n") || strings.HasPrefix(rest, "\r\n")) {
+				rest = strings.TrimLeft(rest, " \t\r\n")
+				i = len(content) - len(rest) - 1
+				continue
+			}
+
+			return "", errors.New(fmt.Sprintf("invalid escape sequence \\%c", content[i]))
+		}
+	}
+
+	return builder.String(), nil
+}
+
+func escape(content string, multiline bool) string {
+	var builder strings.Builder
+
+	for _, r := range content {
+		switch r {
+		case '\\':
+			builder.WriteString(`\\`)
+		case '"':
+			builder.WriteString(`\"`)
+		case '\b':
+			builder.WriteString(`\b`)
+		case '\t':
+			builder.WriteString(`\t`)
+		case '\f':
+			builder.WriteString(`\f`)
+		case '\r':
+			builder.WriteString(`\r`)
+		case '\n':
+			if multiline {
+				builder.WriteRune(r)
+			} else {
+				builder.WriteString(`\n`)
+			}
+		default:
+			if r < 0x20 || r == 0x7f {
+				builder.WriteString(fmt.Sprintf(`\u%04X`, r))
+			} else {
+				builder.WriteRune(r)
+			}
+		}
+	}
+
+	return builder.String()
+}
+
+func canBeLiteral(content string, multiline bool) bool {
+	for _, r := range content {
+		if r == '\n' && multiline {
+			continue
+		}
+		if r < 0x20 && r != '\t' || r == 0x7f {
+			return false
+		}
+	}
+
+	if multiline {
+		return !strings.Contains(content, "'''")
+	}
+
+	return !strings.Contains(content, "'")
+}
+
+// EncodeString encodes a string, keeping the given style when possible
+func EncodeString(content string, style StringStyle) string {
+	switch style {
+	case LiteralString:
+		if canBeLiteral(content, false) {
+			return "'" + content + "'"
+		}
+	case MultilineLiteralString:
+		if canBeLiteral(content, true) {
+			return "'''" + content + "'''"
+		}
+		return `"""` + escape(content, true) + `"""`
+	case MultilineBasicString:
+		return `"""` + escape(content, true) + `"""`
+	}
+
+	return `"` + escape(content, false) + `"`
+}
+
+func encodeValue(kind ValueKind, style StringStyle, value string) (string, error) {
+	invalid := func() (string, error) {
+		return "", errors.New(fmt.Sprintf("%q is not a valid TOML %s, use --type to change the type of the value", value, kind))
+	}
+
+	switch kind {
+	case StringKind:
+		return EncodeString(value, style), nil
+	case IntegerKind:
+		if !integerRegexp.MatchString(value) {
+			return invalid()
+		}
+	case FloatKind:
+		if decimalIntegerRegexp.MatchString(value) {
+			return value + ".0", nil
+		}
+		if !floatRegexp.MatchString(value) {
+			return invalid()
+		}
+	case BooleanKind:
+		lowered := strings.ToLower(value)
+		if lowered != "true" && lowered != "false" {
+			return invalid()
+		}
+		return lowered, nil
+	case DateTimeKind:
+		if !dateTimeRegexp.MatchString(value) {
+			return invalid()
+		}
+	case ArrayKind, InlineTableKind:
+		parsed, err := ParseValue(value)
+		if err != nil || parsed.Kind != kind {
+			return invalid()
+		}
+	}
+
+	return value, nil
+}
+
+// inferValue encodes a value whose type is not known, e.g. for a new key:
+// anything which is not a valid TOML value is considered as a string
+func inferValue(value string) string {
+	parsed, err := ParseValue(value)
+	if err != nil {
+		return EncodeString(value, BasicString)
+	}
+
+	return parsed.Raw
+}
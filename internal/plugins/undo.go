@@ -0,0 +1,101 @@
+package plugins
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/history"
+	"github.com/einenlum/edicon/internal/io"
+)
+
+func describeValue(value string, missing bool) string {
+	if missing {
+		return "missing"
+	}
+
+	return strconv.Quote(value)
+}
+
+// RevertChanges sets the keys of a file back to their value before the
+// changes, the changes being reverted in the given order on a temporary copy
+// of the file. A key whose value is no longer the one written by edicon is
+// not reverted, unless force is true, and warn is called instead. It returns
+// the configuration with all the changes reverted (nil if none is) and the
+// changes made to revert them.
+func RevertChanges(
+	filePath string,
+	changes []history.Change,
+	force bool,
+	warn func(message string),
+) (core.Configuration, []history.Change, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return nil, nil, err
+	}
+	tempFilePath, err := io.GenerateRandomTempFilePath()
+	if err != nil {
+		return nil, nil, err
+	}
+	defer io.RemoveFile(tempFilePath)
+	if err := io.WriteFileContents(tempFilePath, content); err != nil {
+		return nil, nil, err
+	}
+
+	var config core.Configuration
+	reverts := []history.Change{}
+	for _, change := range changes {
+		configurator, err := GetConfigurator(change.Type)
+		if err != nil {
+			return nil, nil, err
+		}
+		notationStyle, err := core.ParseNotationStyle(change.Notation)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		current, err := configurator.GetParameter(notationStyle, tempFilePath, change.Key)
+		currentMissing := err != nil
+		if !force && (currentMissing != change.NewMissing || (!currentMissing && current != change.New)) {
+			warn(fmt.Sprintf(
+				"%s: %s is %s, not %s as written by edicon, it is not reverted",
+				filePath, change.Key, describeValue(current, currentMissing), describeValue(change.New, change.NewMissing),
+			))
+			continue
+		}
+
+		var reverted core.Configuration
+		switch {
+		case change.OldMissing:
+			remover, ok := configurator.(core.ParameterRemover)
+			if !ok {
+				warn(fmt.Sprintf("%s: %s cannot be removed from %s files, it is not reverted", filePath, change.Key, change.Type))
+				continue
+			}
+			reverted, err = remover.UnsetParameter(notationStyle, tempFilePath, change.Key)
+		default:
+			reverted, err = configurator.SetParameter(notationStyle, tempFilePath, change.Key, change.Old)
+		}
+		if err != nil {
+			return nil, nil, errors.New(fmt.Sprintf("Cannot revert %s: %s", change.Key, err.Error()))
+		}
+		if err := reverted.WriteToFile(tempFilePath, core.FullOutput); err != nil {
+			return nil, nil, err
+		}
+
+		config = reverted
+		reverts = append(reverts, history.Change{
+			File:       change.File,
+			Type:       change.Type,
+			Notation:   change.Notation,
+			Key:        change.Key,
+			Old:        current,
+			OldMissing: currentMissing,
+			NewMissing: change.OldMissing,
+			Undoes:     change.ID,
+		})
+	}
+
+	return config, reverts, nil
+}
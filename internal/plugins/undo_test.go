@@ -0,0 +1,49 @@
+package plugins
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/history"
+)
+
+func TestRevertChanges(t *testing.T) {
+	filePath := filepath.Join(t.TempDir(), "redis.conf")
+	content := "# Redis\nport 6380\nbind 0.0.0.0\nmaxmemory 1gb\n"
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	changes := []history.Change{
+		{ID: "3", Type: "redis", Notation: "dot", Key: "maxmemory", OldMissing: true, New: "1gb"},
+		{ID: "2", Type: "redis", Notation: "dot", Key: "bind", Old: "127.0.0.1", New: "::1"},
+		{ID: "1", Type: "redis", Notation: "dot", Key: "port", Old: "6379", New: "6380"},
+	}
+	warnings := []string{}
+	warn := func(message string) {
+		warnings = append(warnings, message)
+	}
+
+	config, reverts, err := RevertChanges(filePath, changes, false, warn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	output, _ := config.OutputFile(core.FullOutput)
+	if expected := "# Redis\nport 6379\nbind 0.0.0.0\n"; output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+	if len(warnings) != 1 || len(reverts) != 2 {
+		t.Errorf("Expected bind not to be reverted, got %v %v", warnings, reverts)
+	}
+	if reverts[0].Undoes != "3" || !reverts[0].NewMissing || reverts[1].Old != "6380" || reverts[1].Undoes != "1" {
+		t.Errorf("Unexpected reverts %v", reverts)
+	}
+
+	config, reverts, _ = RevertChanges(filePath, changes[1:2], true, warn)
+	output, _ = config.OutputFile(core.FullOutput)
+	if expected := "# Redis\nport 6380\nbind 127.0.0.1\nmaxmemory 1gb\n"; output != expected || len(reverts) != 1 {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
@@ -0,0 +1,67 @@
+package xml
+
+import (
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+// ReadDocument returns the document tree of the file, the root element being
+// the only key of the tree: elements with attributes or children are maps,
+// their attributes being @name keys, their text a #text key and the
+// occurrences of a repeated child a list designated by their position
+// (Service.Connector[2].@port). Other elements are their text.
+func (configurator XmlConfigurator) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	config, err := GetParsedXmlFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(config.Comments) > 0 {
+		losses.Add("comments are not converted")
+	}
+	document := source.NewDocument(config.Content, config.Comments, "<!-")
+	document.Closing = "-->"
+
+	tree := core.NewMapNode()
+	tree.Path = []string{}
+	tree.Set(config.Root.Name, readElement(document, config.Root, []string{config.Root.Name}, losses))
+
+	return tree, nil
+}
+
+func readElement(document *source.Document, element *Element, path []string, losses *core.Losses) *core.Node {
+	content := document.Content
+	text, err := element.Text(content)
+
+	var node *core.Node
+	if err == nil && len(element.Attributes) == 0 {
+		node = core.NewScalarNode(core.UntypedScalar, text)
+	} else {
+		if err != nil && len(element.Children) == 0 {
+			losses.Add("the text of the elements with comments or processing instructions is not converted")
+		}
+
+		node = core.NewMapNode()
+		for _, attribute := range element.Attributes {
+			value := core.NewScalarNode(core.UntypedScalar, decodeEntities(content[attribute.Value.Start:attribute.Value.End]))
+			value.Path = append(append([]string{}, path...), "@"+attribute.Name)
+			value.Span = document.Lines(attribute.Value)
+			node.Set("@"+attribute.Name, value)
+		}
+		if err == nil && text != "" {
+			value := core.NewScalarNode(core.UntypedScalar, text)
+			value.Path = append(append([]string{}, path...), textKey)
+			value.Span = document.Lines(element.Span)
+			node.Set(textKey, value)
+		}
+		for _, child := range element.Children {
+			node.AddIndexed(child.Name, readElement(document, child, append(append([]string{}, path...), child.Name), losses))
+		}
+	}
+
+	node.Path = path
+	node.Span = document.Lines(element.Span)
+	node.Comments = document.AttachedComments(element.Span)
+
+	return node
+}
@@ -0,0 +1,293 @@
+package xml
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+type parser struct {
+	content  string
+	pos      int
+	comments []source.Span
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	line := strings.Count(p.content[:p.pos], "\n") + 1
+	message := fmt.Sprintf(format, args...)
+
+	return errors.New(fmt.Sprintf("Invalid XML on line %d: %s", line, message))
+}
+
+func (p *parser) atEnd() bool {
+	return p.pos >= len(p.content)
+}
+
+func (p *parser) hasPrefix(prefix string) bool {
+	return strings.HasPrefix(p.content[p.pos:], prefix)
+}
+
+func (p *parser) skipWhitespace() {
+	for !p.atEnd() && isWhitespace(p.content[p.pos]) {
+		p.pos++
+	}
+}
+
+func isWhitespace(c byte) bool {
+	return c == ' ' || c == '\t' || c == '\r' || c == '\n'
+}
+
+func isNameChar(c byte) bool {
+	return !isWhitespace(c) && strings.IndexByte("<>/=\"'?!", c) == -1
+}
+
+// skipUntil moves right after the given delimiter
+func (p *parser) skipUntil(delimiter string, what string) error {
+	index := strings.Index(p.content[p.pos:], delimiter)
+	if index == -1 {
+		return p.errorf("unterminated %s", what)
+	}
+	p.pos += index + len(delimiter)
+
+	return nil
+}
+
+func (p *parser) parseName() (string, error) {
+	start := p.pos
+	for !p.atEnd() && isNameChar(p.content[p.pos]) {
+		p.pos++
+	}
+	if start == p.pos {
+		return "", p.errorf("expected a name")
+	}
+
+	return p.content[start:p.pos], nil
+}
+
+// parseMisc parses a node which is not an element nor text, if any
+func (p *parser) parseMisc() (*Node, error) {
+	start := p.pos
+
+	switch {
+	case p.hasPrefix("<!--"):
+		if err := p.skipUntil("-->", "comment"); err != nil {
+			return nil, err
+		}
+		span := source.Span{Start: start, End: p.pos}
+		p.comments = append(p.comments, span)
+
+		return &Node{Type: CommentNode, Span: span}, nil
+	case p.hasPrefix("<?"):
+		if err := p.skipUntil("?>", "processing instruction"); err != nil {
+			return nil, err
+		}
+
+		return &Node{Type: ProcessingInstructionNode, Span: source.Span{Start: start, End: p.pos}}, nil
+	case p.hasPrefix("<![CDATA["):
+		if err := p.skipUntil("]]>", "CDATA section"); err != nil {
+			return nil, err
+		}
+
+		return &Node{Type: CDataNode, Span: source.Span{Start: start, End: p.pos}}, nil
+	case p.hasPrefix("<!"):
+		// Document type declaration, which can contain an internal subset
+		depth := 0
+		for !p.atEnd() {
+			c := p.content[p.pos]
+			p.pos++
+			if c == '[' {
+				depth++
+			} else if c == ']' {
+				depth--
+			} else if c == '>' && depth == 0 {
+				return &Node{Type: ProcessingInstructionNode, Span: source.Span{Start: start, End: p.pos}}, nil
+			}
+		}
+
+		return nil, p.errorf("unterminated document type declaration")
+	}
+
+	return nil, nil
+}
+
+func (p *parser) parseElement(parent *Element) (*Element, error) {
+	element := &Element{Parent: parent}
+	element.Span.Start = p.pos
+	p.pos++
+
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+	element.Name = name
+
+	for {
+		p.skipWhitespace()
+		if p.atEnd() {
+			return nil, p.errorf("unterminated start tag <%s>", name)
+		}
+
+		if p.hasPrefix("/>") {
+			p.pos += 2
+			element.SelfClosing = true
+			element.StartTag = source.Span{Start: element.Span.Start, End: p.pos}
+			element.Content = source.Span{Start: p.pos, End: p.pos}
+			element.Span.End = p.pos
+
+			return element, nil
+		}
+		if p.content[p.pos] == '>' {
+			p.pos++
+			break
+		}
+
+		attribute, err := p.parseAttribute()
+		if err != nil {
+			return nil, err
+		}
+		for _, existing := range element.Attributes {
+			if existing.Name == attribute.Name {
+				return nil, p.errorf("duplicate attribute %s", attribute.Name)
+			}
+		}
+		element.Attributes = append(element.Attributes, attribute)
+	}
+	element.StartTag = source.Span{Start: element.Span.Start, End: p.pos}
+	element.Content.Start = p.pos
+
+	for {
+		if p.atEnd() {
+			return nil, p.errorf("unclosed element <%s>", name)
+		}
+
+		if p.hasPrefix("</") {
+			element.Content.End = p.pos
+			p.pos += 2
+
+			endName, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+			if endName != name {
+				return nil, p.errorf("expected </%s>, got </%s>", name, endName)
+			}
+			p.skipWhitespace()
+			if p.atEnd() || p.content[p.pos] != '>' {
+				return nil, p.errorf("unterminated end tag </%s>", name)
+			}
+			p.pos++
+			element.Span.End = p.pos
+
+			return element, nil
+		}
+
+		node, err := p.parseMisc()
+		if err != nil {
+			return nil, err
+		}
+		if node != nil {
+			element.Nodes = append(element.Nodes, node)
+			continue
+		}
+
+		if p.content[p.pos] == '<' {
+			child, err := p.parseElement(element)
+			if err != nil {
+				return nil, err
+			}
+			element.Children = append(element.Children, child)
+			element.Nodes = append(element.Nodes, &Node{Type: ElementNode, Span: child.Span, Element: child})
+			continue
+		}
+
+		start := p.pos
+		for !p.atEnd() && p.content[p.pos] != '<' {
+			p.pos++
+		}
+		element.Nodes = append(element.Nodes, &Node{Type: TextNode, Span: source.Span{Start: start, End: p.pos}})
+	}
+}
+
+func (p *parser) parseAttribute() (*Attribute, error) {
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+
+	p.skipWhitespace()
+	if p.atEnd() || p.content[p.pos] != '=' {
+		return nil, p.errorf("expected '=' after attribute %s", name)
+	}
+	p.pos++
+	p.skipWhitespace()
+
+	if p.atEnd() || (p.content[p.pos] != '"' && p.content[p.pos] != '\'') {
+		return nil, p.errorf("expected a quoted value for attribute %s", name)
+	}
+	quote := p.content[p.pos]
+	p.pos++
+
+	start := p.pos
+	index := strings.IndexByte(p.content[p.pos:], quote)
+	if index == -1 {
+		return nil, p.errorf("unterminated value for attribute %s", name)
+	}
+	p.pos += index + 1
+
+	return &Attribute{name, quote, source.Span{Start: start, End: start + index}}, nil
+}
+
+func ParseXmlContent(content string) (*Element, []source.Span, error) {
+	p := &parser{content: content}
+
+	var root *Element
+	for {
+		p.skipWhitespace()
+		if p.atEnd() {
+			break
+		}
+
+		node, err := p.parseMisc()
+		if err != nil {
+			return nil, nil, err
+		}
+		if node != nil {
+			continue
+		}
+
+		if p.content[p.pos] != '<' {
+			return nil, nil, p.errorf("unexpected text outside of the root element")
+		}
+		if root != nil {
+			return nil, nil, p.errorf("only one root element is allowed")
+		}
+
+		root, err = p.parseElement(nil)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if root == nil {
+		return nil, nil, errors.New("Invalid XML: no root element")
+	}
+
+	return root, p.comments, nil
+}
+
+func GetParsedXmlFile(filePath string) (XmlConfiguration, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return XmlConfiguration{}, err
+	}
+
+	root, comments, err := ParseXmlContent(content)
+	if err != nil {
+		return XmlConfiguration{}, err
+	}
+
+	return XmlConfiguration{content, root, comments, filePath}, nil
+}
@@ -0,0 +1,289 @@
+package xml
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+// textKey is the last segment of a key designating the text of an element,
+// as the document trees name it for the elements with attributes
+const textKey = "#text"
+
+var (
+	predicateRegexp = regexp.MustCompile(`^(@?)([^=]+?)\s*(=\s*(.*))?$`)
+	errKeyNotFound  = errors.New("Key not found")
+)
+
+// Predicate filters repeated siblings: by position (Connector[2], starting
+// at 1 as in XPath), by attribute (Connector[@port=8443]) or by the text of
+// a child element (dependency[artifactId=junit])
+type Predicate struct {
+	Index     int
+	Attribute string
+	Child     string
+	Value     string
+	HasValue  bool
+}
+
+type Segment struct {
+	Name       string
+	Predicates []Predicate
+	// Attribute is set when the segment selects an attribute of the element
+	// (Connector[@port] or @port)
+	Attribute string
+}
+
+func decomposeKey(notationStyle core.NotationStyle, key string) ([]string, error) {
+	return core.DecomposeKeyKeepingPredicates(notationStyle, key)
+}
+
+func parseSegment(part string) (Segment, error) {
+	segment := Segment{}
+
+	if strings.HasPrefix(part, "@") {
+		segment.Attribute = part[1:]
+
+		return segment, nil
+	}
+
+	bracket := strings.IndexByte(part, '[')
+	if bracket == -1 {
+		segment.Name = part
+
+		return segment, nil
+	}
+	segment.Name = part[:bracket]
+
+	rest := part[bracket:]
+	for rest != "" {
+		end := strings.IndexByte(rest, ']')
+		if !strings.HasPrefix(rest, "[") || end == -1 {
+			return segment, errors.New(fmt.Sprintf("Invalid key segment: %s", part))
+		}
+		content := strings.TrimSpace(rest[1:end])
+		rest = rest[end+1:]
+
+		if index, err := strconv.Atoi(content); err == nil {
+			if index < 1 {
+				return segment, errors.New(fmt.Sprintf("Invalid position in %s: positions start at 1", part))
+			}
+			segment.Predicates = append(segment.Predicates, Predicate{Index: index})
+			continue
+		}
+
+		matches := predicateRegexp.FindStringSubmatch(content)
+		if matches == nil {
+			return segment, errors.New(fmt.Sprintf("Invalid key segment: %s", part))
+		}
+
+		isAttribute := matches[1] == "@"
+		name := strings.TrimSpace(matches[2])
+		if isAttribute && matches[3] == "" && rest == "" {
+			segment.Attribute = name
+			continue
+		}
+
+		predicate := Predicate{HasValue: matches[3] != "", Value: unquote(strings.TrimSpace(matches[4]))}
+		if isAttribute {
+			predicate.Attribute = name
+		} else {
+			predicate.Child = name
+		}
+		segment.Predicates = append(segment.Predicates, predicate)
+	}
+
+	return segment, nil
+}
+
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+
+	return value
+}
+
+// nameMatches compares names, ignoring the namespace prefix of the element
+// when the key does not have one
+func nameMatches(elementName string, name string) bool {
+	if elementName == name {
+		return true
+	}
+
+	colon := strings.IndexByte(elementName, ':')
+
+	return colon != -1 && !strings.Contains(name, ":") && elementName[colon+1:] == name
+}
+
+func (element *Element) GetAttribute(name string) *Attribute {
+	for _, attribute := range element.Attributes {
+		if nameMatches(attribute.Name, name) {
+			return attribute
+		}
+	}
+
+	return nil
+}
+
+func matchesPredicate(content string, element *Element, predicate Predicate) bool {
+	if predicate.Attribute != "" {
+		attribute := element.GetAttribute(predicate.Attribute)
+		if attribute == nil {
+			return false
+		}
+
+		return !predicate.HasValue || decodeEntities(content[attribute.Value.Start:attribute.Value.End]) == predicate.Value
+	}
+
+	for _, child := range element.Children {
+		if !nameMatches(child.Name, predicate.Child) {
+			continue
+		}
+
+		if !predicate.HasValue {
+			return true
+		}
+
+		text, err := child.Text(content)
+		if err == nil && text == predicate.Value {
+			return true
+		}
+	}
+
+	return false
+}
+
+func filterElements(content string, candidates []*Element, segment Segment) []*Element {
+	matching := []*Element{}
+	for _, candidate := range candidates {
+		if nameMatches(candidate.Name, segment.Name) {
+			matching = append(matching, candidate)
+		}
+	}
+
+	for _, predicate := range segment.Predicates {
+		if predicate.Index > 0 {
+			if predicate.Index > len(matching) {
+				return []*Element{}
+			}
+			matching = matching[predicate.Index-1 : predicate.Index]
+			continue
+		}
+
+		filtered := []*Element{}
+		for _, element := range matching {
+			if matchesPredicate(content, element, predicate) {
+				filtered = append(filtered, element)
+			}
+		}
+		matching = filtered
+	}
+
+	return matching
+}
+
+// Target is what a key points to: an element, or one of its attributes
+type Target struct {
+	Element   *Element
+	Attribute string
+}
+
+// MissingError is returned when the parent of the key exists but not the
+// last element or attribute, so that it can be created
+type MissingError struct {
+	Parent  *Element
+	Segment Segment
+}
+
+func (err *MissingError) Error() string {
+	return "Key not found"
+}
+
+// Resolve finds the target of a key. As element names can contain dots
+// (java.version), consecutive parts of the key are joined when no element
+// matches them separately, the longest name being tried first.
+func Resolve(content string, root *Element, parts []string) (Target, error) {
+	if len(parts) == 0 {
+		return Target{}, errKeyNotFound
+	}
+
+	return resolveParts(content, []*Element{root}, nil, parts)
+}
+
+func resolveParts(content string, candidates []*Element, parent *Element, parts []string) (Target, error) {
+	missing := errKeyNotFound
+
+	for end := len(parts); end >= 1; end-- {
+		segment, err := parseSegment(strings.Join(parts[:end], "."))
+		if err != nil {
+			if end == 1 {
+				return Target{}, err
+			}
+			continue
+		}
+
+		if segment.Name == "" {
+			// Attribute of the parent element (@port)
+			if parent == nil || end != len(parts) {
+				continue
+			}
+			if parent.GetAttribute(segment.Attribute) == nil {
+				return Target{}, &MissingError{parent, segment}
+			}
+
+			return Target{parent, segment.Attribute}, nil
+		}
+
+		if segment.Name == textKey && len(segment.Predicates) == 0 && segment.Attribute == "" {
+			// Text of the parent element (Engine.#text)
+			if parent == nil || end != len(parts) {
+				continue
+			}
+
+			return Target{parent, ""}, nil
+		}
+
+		matching := filterElements(content, candidates, segment)
+		if len(matching) == 0 {
+			if end == len(parts) && parent != nil && len(segment.Predicates) == 0 {
+				missing = &MissingError{parent, segment}
+			}
+			continue
+		}
+		if len(matching) > 1 {
+			return Target{}, errors.New(fmt.Sprintf(
+				"%d elements match %s, use a predicate to choose one (e.g. %s[1] or %s[@attribute=value])",
+				len(matching), segment.Name, segment.Name, segment.Name,
+			))
+		}
+
+		element := matching[0]
+		if end == len(parts) {
+			if segment.Attribute != "" && element.GetAttribute(segment.Attribute) == nil {
+				return Target{}, &MissingError{element, Segment{Attribute: segment.Attribute}}
+			}
+
+			return Target{element, segment.Attribute}, nil
+		}
+		if segment.Attribute != "" {
+			continue
+		}
+
+		target, err := resolveParts(content, element.Children, element, parts[end:])
+		if err == nil {
+			return target, nil
+		}
+		if _, isMissing := err.(*MissingError); isMissing {
+			missing = err
+		} else if err != errKeyNotFound {
+			return Target{}, err
+		}
+	}
+
+	return Target{}, missing
+}
@@ -0,0 +1,90 @@
+package xml
+
+import (
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+type Attribute struct {
+	Name  string
+	Quote byte
+	// Value is the span of the value, without the quotes
+	Value source.Span
+}
+
+type NodeType int
+
+const (
+	TextNode NodeType = iota
+	CDataNode
+	CommentNode
+	ProcessingInstructionNode
+	ElementNode
+)
+
+// Node is any node found inside an element: text, CDATA sections, comments,
+// processing instructions or elements
+type Node struct {
+	Type    NodeType
+	Span    source.Span
+	Element *Element
+}
+
+type Element struct {
+	Name       string
+	Attributes []*Attribute
+	Children   []*Element
+	Nodes      []*Node
+	Parent     *Element
+	// Span covers the whole element, StartTag its start tag and Content
+	// everything between the start and the end tags
+	Span        source.Span
+	StartTag    source.Span
+	Content     source.Span
+	SelfClosing bool
+}
+
+type XmlConfiguration struct {
+	Content  string
+	Root     *Element
+	Comments []source.Span
+	FilePath string
+}
+
+func (config *XmlConfiguration) OutputFile(outputType core.OutputType) (string, error) {
+	return OutputConfigFile(config, outputType), nil
+}
+
+func (config *XmlConfiguration) WriteToFile(filepath string, outputType core.OutputType) error {
+	output, err := config.OutputFile(outputType)
+	if err != nil {
+		return err
+	}
+
+	return io.WriteFileContents(filepath, output)
+}
+
+type XmlConfigurator struct{}
+
+func (configurator XmlConfigurator) GetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+) (string, error) {
+	return GetParameterFromPath(notationStyle, filePath, key)
+}
+
+func (configurator XmlConfigurator) SetParameter(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (core.Configuration, error) {
+	config, err := EditConfigFile(notationStyle, filePath, key, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return config, nil
+}
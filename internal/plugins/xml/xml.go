@@ -0,0 +1,254 @@
+package xml
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/source"
+)
+
+var (
+	entityRegexp = regexp.MustCompile(`&(#x[0-9A-Fa-f]+|#[0-9]+|[A-Za-z]+);`)
+	entities     = map[string]string{"amp": "&", "lt": "<", "gt": ">", "quot": "\"", "apos": "'"}
+	indentRegexp = regexp.MustCompile(`[ \t]*$`)
+)
+
+func decodeEntities(text string) string {
+	return entityRegexp.ReplaceAllStringFunc(text, func(entity string) string {
+		name := entity[1 : len(entity)-1]
+
+		if strings.HasPrefix(name, "#") {
+			base := 10
+			digits := name[1:]
+			if strings.HasPrefix(digits, "x") {
+				base = 16
+				digits = digits[1:]
+			}
+
+			code, err := strconv.ParseInt(digits, base, 32)
+			if err != nil {
+				return entity
+			}
+
+			return string(rune(code))
+		}
+
+		if decoded, ok := entities[name]; ok {
+			return decoded
+		}
+
+		return entity
+	})
+}
+
+func escapeText(text string) string {
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;").Replace(text)
+}
+
+func escapeAttribute(value string, quote byte) string {
+	if quote == '\'' {
+		return strings.NewReplacer("&", "&amp;", "<", "&lt;", "'", "&apos;").Replace(value)
+	}
+
+	return strings.NewReplacer("&", "&amp;", "<", "&lt;", "\"", "&quot;").Replace(value)
+}
+
+// textSpan returns the span of the text of an element, without the
+// surrounding whitespace, and whether it is a CDATA section
+func (element *Element) textSpan(content string) (source.Span, bool, error) {
+	if len(element.Children) > 0 {
+		return source.Span{}, false, errors.New(fmt.Sprintf("Element <%s> has child elements and no value", element.Name))
+	}
+
+	var cdata *Node
+	for _, node := range element.Nodes {
+		switch node.Type {
+		case CDataNode:
+			if cdata != nil {
+				return source.Span{}, false, errors.New(fmt.Sprintf("Element <%s> has several CDATA sections", element.Name))
+			}
+			cdata = node
+		case TextNode:
+		default:
+			return source.Span{}, false, errors.New(fmt.Sprintf("Element <%s> contains comments or processing instructions", element.Name))
+		}
+	}
+
+	if cdata != nil {
+		for _, node := range element.Nodes {
+			if node.Type == TextNode && strings.TrimSpace(content[node.Span.Start:node.Span.End]) != "" {
+				return source.Span{}, false, errors.New(fmt.Sprintf("Element <%s> mixes text and CDATA sections", element.Name))
+			}
+		}
+
+		return source.Span{Start: cdata.Span.Start + len("<![CDATA["), End: cdata.Span.End - len("]]>")}, true, nil
+	}
+
+	text := content[element.Content.Start:element.Content.End]
+	trimmed := strings.TrimSpace(text)
+	if trimmed == "" {
+		return element.Content, false, nil
+	}
+	start := element.Content.Start + strings.Index(text, trimmed)
+
+	return source.Span{Start: start, End: start + len(trimmed)}, false, nil
+}
+
+func (element *Element) Text(content string) (string, error) {
+	span, isCData, err := element.textSpan(content)
+	if err != nil {
+		return "", err
+	}
+
+	text := content[span.Start:span.End]
+	if isCData {
+		return text, nil
+	}
+
+	return decodeEntities(text), nil
+}
+
+func OutputConfigFile(config *XmlConfiguration, outputType core.OutputType) string {
+	if outputType != core.MeaningFullOutput {
+		return config.Content
+	}
+
+	return source.RemoveComments(config.Content, config.Comments)
+}
+
+func GetParameterFromPath(notationStyle core.NotationStyle, filePath string, key string) (string, error) {
+	config, err := GetParsedXmlFile(filePath)
+	if err != nil {
+		return "", err
+	}
+
+	path, err := decomposeKey(notationStyle, key)
+	if err != nil {
+		return "", err
+	}
+	target, err := Resolve(config.Content, config.Root, path)
+	if err != nil {
+		return "", err
+	}
+
+	if target.Attribute != "" {
+		attribute := target.Element.GetAttribute(target.Attribute)
+
+		return decodeEntities(config.Content[attribute.Value.Start:attribute.Value.End]), nil
+	}
+
+	return target.Element.Text(config.Content)
+}
+
+func EditConfigFile(
+	notationStyle core.NotationStyle,
+	filePath string,
+	key string,
+	value string,
+) (*XmlConfiguration, error) {
+	config, err := GetParsedXmlFile(filePath)
+	if err != nil {
+		return &XmlConfiguration{}, err
+	}
+
+	path, err := decomposeKey(notationStyle, key)
+	if err != nil {
+		return &XmlConfiguration{}, err
+	}
+	target, err := Resolve(config.Content, config.Root, path)
+	if missing, isMissing := err.(*MissingError); isMissing {
+		err = config.create(missing, value)
+	} else if err == nil {
+		err = config.set(target, value)
+	}
+	if err != nil {
+		return &XmlConfiguration{}, err
+	}
+
+	root, comments, err := ParseXmlContent(config.Content)
+	if err != nil {
+		return &XmlConfiguration{}, errors.New(fmt.Sprintf("Refusing to set %s: %s", key, err.Error()))
+	}
+	config.Root = root
+	config.Comments = comments
+
+	return &config, nil
+}
+
+func (config *XmlConfiguration) replace(span source.Span, replacement string) {
+	config.Content = config.Content[:span.Start] + replacement + config.Content[span.End:]
+}
+
+func (config *XmlConfiguration) set(target Target, value string) error {
+	element := target.Element
+
+	if target.Attribute != "" {
+		attribute := element.GetAttribute(target.Attribute)
+		config.replace(attribute.Value, escapeAttribute(value, attribute.Quote))
+
+		return nil
+	}
+
+	if element.SelfClosing {
+		startTag := config.Content[element.StartTag.Start:element.StartTag.End]
+		startTag = strings.TrimRight(strings.TrimSuffix(startTag, "/>"), " \t\r\n")
+		config.replace(element.Span, startTag+">"+escapeText(value)+"</"+element.Name+">")
+
+		return nil
+	}
+
+	span, isCData, err := element.textSpan(config.Content)
+	if err != nil {
+		return err
+	}
+
+	if isCData && !strings.Contains(value, "]]>") {
+		config.replace(span, value)
+	} else if isCData {
+		config.replace(source.Span{Start: span.Start - len("<![CDATA["), End: span.End + len("]]>")}, escapeText(value))
+	} else {
+		config.replace(span, escapeText(value))
+	}
+
+	return nil
+}
+
+// create adds a missing attribute, or a missing element after the last child
+// of its parent, using the same indentation
+func (config *XmlConfiguration) create(missing *MissingError, value string) error {
+	parent := missing.Parent
+
+	if missing.Segment.Name == "" {
+		position := parent.StartTag.End - 1
+		if parent.SelfClosing {
+			position--
+		}
+		for isWhitespace(config.Content[position-1]) {
+			position--
+		}
+		attribute := " " + missing.Segment.Attribute + "=\"" + escapeAttribute(value, '"') + "\""
+		config.replace(source.Span{Start: position, End: position}, attribute)
+
+		return nil
+	}
+
+	name := missing.Segment.Name
+	element := "<" + name + ">" + escapeText(value) + "</" + name + ">"
+	if missing.Segment.Attribute != "" {
+		element = "<" + name + " " + missing.Segment.Attribute + "=\"" + escapeAttribute(value, '"') + "\"/>"
+	}
+
+	if len(parent.Children) == 0 {
+		return errors.New(fmt.Sprintf("Key not found: cannot add <%s> to <%s> which has no child elements", name, parent.Name))
+	}
+
+	last := parent.Children[len(parent.Children)-1]
+	indent := indentRegexp.FindString(config.Content[:last.Span.Start])
+	config.replace(source.Span{Start: last.Span.End, End: last.Span.End}, "\n"+indent+element)
+
+	return nil
+}
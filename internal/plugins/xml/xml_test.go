@@ -0,0 +1,204 @@
+package xml
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+	"github.com/einenlum/edicon/internal/testutil"
+)
+
+const (
+	POM_FILE_PATH    = "../../../data/xml/pom.xml"
+	SERVER_FILE_PATH = "../../../data/xml/server.xml"
+)
+
+func testEditParameter(
+	t *testing.T,
+	notationStyle core.NotationStyle,
+	filepath string,
+	key string,
+	value string,
+	old string,
+	new string,
+) {
+	original, err := io.GetFileContents(filepath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	config, err := EditConfigFile(notationStyle, filepath, key, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(original, old) {
+		t.Fatal("The fixture does not contain", old)
+	}
+	expected := strings.Replace(original, old, new, 1)
+
+	output := OutputConfigFile(config, core.FullOutput)
+	if output != expected {
+		t.Fatal(fmt.Sprintf("Expected:\n%s\nGot:\n%s", expected, output))
+	}
+}
+
+func TestGetParameter(t *testing.T) {
+	pomCases := map[string]string{
+		"project.modelVersion":                                      "4.0.0",
+		"project.version":                                           "1.0-SNAPSHOT",
+		"project.description":                                       "Edit <any> configuration",
+		"project.properties.java.version":                           "17",
+		`project.properties."java.version"`:                         "17",
+		"project.properties.project.build.sourceEncoding":           "UTF-8",
+		"project.dependencies.dependency[2].artifactId":             "slf4j-api",
+		"project.dependencies.dependency[artifactId=junit].version": "4.13.2",
+		"project.dependencies.dependency[scope].groupId":            "junit",
+		"project[@xsi:schemaLocation]":                              "http://maven.apache.org/POM/4.0.0 https://maven.apache.org/xsd/maven-4.0.0.xsd",
+	}
+
+	for key, expectedValue := range pomCases {
+		t.Run("it gets existing parameter "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, POM_FILE_PATH, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value != expectedValue {
+				t.Fatal(fmt.Sprintf("Expected %s got %s", expectedValue, value))
+			}
+		})
+	}
+
+	serverCases := map[string]string{
+		"Server[@port]":                                           "8005",
+		"Server.@shutdown":                                        "SHUTDOWN",
+		"Server.Service[@name]":                                   "Catalina",
+		"Server.Service.Connector[1][@port]":                      "8080",
+		"Server.Service.Connector[@SSLEnabled=true][@port]":       "8443",
+		"Server.Service.Connector[@address=127.0.0.1][@protocol]": "org.apache.coyote.http11.Http11NioProtocol",
+		"Server.Service.Engine.Host[@appBase]":                    "webapps",
+		"Server.Service.Engine.Host.Alias":                        "www.localhost & localhost",
+		"Server.Service.Engine.Host.Alias.#text":                  "www.localhost & localhost",
+	}
+
+	for key, expectedValue := range serverCases {
+		t.Run("it gets existing parameter "+key, func(t *testing.T) {
+			value, err := GetParameterFromPath(core.DotNotation, SERVER_FILE_PATH, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if value != expectedValue {
+				t.Fatal(fmt.Sprintf("Expected %s got %s", expectedValue, value))
+			}
+		})
+	}
+
+	t.Run("it gets existing parameter with brackets notation", func(t *testing.T) {
+		value, err := GetParameterFromPath(core.BracketsNotation, POM_FILE_PATH, "project[properties][java.version]")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if value != "17" {
+			t.Fatal("Expected 17 got " + value)
+		}
+	})
+
+	errorCases := []string{
+		"project.name",
+		"build.version",
+		"project.dependencies.dependency.version",
+		"project.dependencies.dependency[3].version",
+		"project.properties",
+		`project."properties.java".version`,
+		"project.dependencies.dependency[2.version",
+		"Server.Service.Connector[@port]",
+		"Server.Service.Engine.Host",
+		"Server.#text.Service",
+	}
+	for _, key := range errorCases {
+		t.Run("it does not get "+key, func(t *testing.T) {
+			filepath := POM_FILE_PATH
+			if strings.HasPrefix(key, "Server") {
+				filepath = SERVER_FILE_PATH
+			}
+
+			value, err := GetParameterFromPath(core.DotNotation, filepath, key)
+			if err == nil {
+				t.Error("Should fail. Got " + value + " instead")
+			}
+		})
+	}
+}
+
+func TestEditParameter(t *testing.T) {
+	type EditTestElement struct {
+		filepath string
+		key      string
+		value    string
+		old      string
+		new      string
+	}
+
+	cases := []EditTestElement{
+		{POM_FILE_PATH, "project.version", "1.1", "<version>1.0-SNAPSHOT</version>", "<version>1.1</version>"},
+		{POM_FILE_PATH, "project.properties.java.version", "21", "<java.version>17</java.version>", "<java.version>21</java.version>"},
+		{POM_FILE_PATH, "project.description", "New", "<![CDATA[Edit <any> configuration]]>", "<![CDATA[New]]>"},
+		{POM_FILE_PATH, "project.groupId", "a&b", "<groupId>com.example</groupId>", "<groupId>a&amp;b</groupId>"},
+		{POM_FILE_PATH, "project.dependencies.dependency[artifactId=slf4j-api].version", "2.0.10", "<version>2.0.9</version>", "<version>2.0.10</version>"},
+		{POM_FILE_PATH, "project.properties.maven.compiler.release", "17", "<project.build.sourceEncoding>UTF-8</project.build.sourceEncoding>", "<project.build.sourceEncoding>UTF-8</project.build.sourceEncoding>\n        <maven.compiler.release>17</maven.compiler.release>"},
+		{SERVER_FILE_PATH, "Server.Service.Connector[@port=8443][@address]", "0.0.0.0", `address="127.0.0.1"`, `address="0.0.0.0"`},
+		{SERVER_FILE_PATH, "Server.Service.Connector[2].@port", "9443", `port='8443'`, `port='9443'`},
+		{SERVER_FILE_PATH, "Server[@shutdown]", `"STOP"`, `shutdown="SHUTDOWN"`, `shutdown="&quot;STOP&quot;"`},
+		{SERVER_FILE_PATH, "Server.Service.Connector[1][@maxThreads]", "200", `redirectPort="8443"`, `redirectPort="8443" maxThreads="200"`},
+		{SERVER_FILE_PATH, "Server.Listener", "text", `<Listener className="org.apache.catalina.startup.VersionLoggerListener" />`, `<Listener className="org.apache.catalina.startup.VersionLoggerListener">text</Listener>`},
+	}
+
+	for _, element := range cases {
+		t.Run("it sets "+element.key, func(t *testing.T) {
+			testEditParameter(t, core.DotNotation, element.filepath, element.key, element.value, element.old, element.new)
+		})
+	}
+
+	t.Run("it does not set the value of an element with children", func(t *testing.T) {
+		_, err := EditConfigFile(core.DotNotation, POM_FILE_PATH, "project.properties", "foo")
+		if err == nil {
+			t.Fatal("Expected an error")
+		}
+	})
+}
+
+func TestParseInvalidXml(t *testing.T) {
+	cases := []string{
+		"",
+		"<a>",
+		"<a></b>",
+		"<a><b></a></b>",
+		"<a/><b/>",
+		"<a b=\"1\" b=\"2\"/>",
+		"<a b=1/>",
+		"<a><!-- unterminated</a>",
+		"text<a/>",
+	}
+
+	for _, content := range cases {
+		t.Run("it rejects "+content, func(t *testing.T) {
+			if _, _, err := ParseXmlContent(content); err == nil {
+				t.Fatal("Expected an error")
+			}
+		})
+	}
+}
+
+func TestReadDocument(t *testing.T) {
+	testutil.AssertDocumentValues(t, XmlConfigurator{}, SERVER_FILE_PATH, map[string]string{
+		"Server.@port":                           "8005",
+		"Server.Service.Connector[2].@port":      "8443",
+		"Server.Service.Connector[1].@protocol":  "HTTP/1.1",
+		"Server.Service.Engine.Host.Alias.#text": "www.localhost & localhost",
+	})
+}
@@ -0,0 +1,932 @@
+package yaml
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+)
+
+var (
+	yamlIntegerRegexp  = regexp.MustCompile(`^[-+]?[0-9]+$|^0x[0-9a-fA-F]+$|^0o[0-7]+$`)
+	yamlFloatRegexp    = regexp.MustCompile(`^[-+]?(\.[0-9]+|[0-9]+(\.[0-9]*)?)([eE][-+]?[0-9]+)?$`)
+	yamlDateTimeRegexp = regexp.MustCompile(`^[0-9]{4}-[0-9]{2}-[0-9]{2}[Tt ][0-9]{2}:[0-9]{2}:[0-9]{2}(\.[0-9]+)?[ \t]*([Zz]|[-+][0-9]{2}:[0-9]{2})$`)
+	escapedBreakRegexp = regexp.MustCompile(`\\\n[ \t]*`)
+)
+
+// parser reads the lines of a YAML document. The lines holding a node after
+// an indicator ("- ", "key: ", "&anchor ") are rewritten with spaces instead
+// of the indicator, so that the node is read as if it started the line.
+type parser struct {
+	lines   []string
+	pos     int
+	anchors map[string]*core.Node
+	losses  *core.Losses
+}
+
+// ReadDocument reads a YAML file: block and flow collections, plain, quoted
+// and block scalars, anchors, aliases and merge keys (<<). Tags are ignored,
+// and complex keys and files with several documents are not supported.
+// Scalars have the types of the YAML 1.2 core schema.
+func (format YamlFormat) ReadDocument(filePath string, losses *core.Losses) (*core.Node, error) {
+	content, err := io.GetFileContents(filePath)
+	if err != nil {
+		return nil, err
+	}
+
+	content = strings.TrimSuffix(strings.ReplaceAll(content, "\r\n", "\n"), "\n")
+	parser := &parser{lines: strings.Split(content, "\n"), anchors: map[string]*core.Node{}, losses: losses}
+
+	tree, err := parser.parseDocument()
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid YAML: %s", err.Error()))
+	}
+
+	return tree, nil
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " "))
+}
+
+func isDocumentMarker(line string, marker string) bool {
+	return line == marker || strings.HasPrefix(line, marker+" ") || strings.HasPrefix(line, marker+"\t")
+}
+
+func isSequenceEntry(text string) bool {
+	return text == "-" || strings.HasPrefix(text, "- ") || strings.HasPrefix(text, "-\t")
+}
+
+// closingQuote returns the position of the quote closing the quoted scalar
+// starting the text, -1 if it is not closed
+func closingQuote(text string) int {
+	quote := text[0]
+	for i := 1; i < len(text); i++ {
+		switch {
+		case quote == '"' && text[i] == '\\':
+			i++
+		case text[i] == quote && quote == '\'' && i+1 < len(text) && text[i+1] == '\'':
+			i++
+		case text[i] == quote:
+			return i
+		}
+	}
+
+	return -1
+}
+
+// stripComment removes the comment ending a line, a # preceded by a space
+// outside of quoted scalars
+func (p *parser) stripComment(line string) string {
+	var quote byte
+	for i := 0; i < len(line); i++ {
+		c := line[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t[{,", line[i-1]) != -1):
+			quote = c
+		case c == '#' && (i == 0 || line[i-1] == ' ' || line[i-1] == '\t'):
+			p.losses.Add("comments are not converted")
+			return strings.TrimRight(line[:i], " \t")
+		}
+	}
+
+	return strings.TrimRight(line, " \t")
+}
+
+// skipBlank moves to the next line holding a node, returning false at the
+// end of the document
+func (p *parser) skipBlank() bool {
+	for ; p.pos < len(p.lines); p.pos++ {
+		text := strings.TrimSpace(p.lines[p.pos])
+		if strings.HasPrefix(text, "#") {
+			p.losses.Add("comments are not converted")
+		} else if text != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *parser) errorf(format string, args ...interface{}) error {
+	return errors.New(fmt.Sprintf("line %d: %s", p.pos+1, fmt.Sprintf(format, args...)))
+}
+
+func (p *parser) null() *core.Node {
+	p.losses.Add("null values are converted to empty strings")
+
+	return core.NewScalarNode(core.UntypedScalar, "")
+}
+
+func (p *parser) parseDocument() (*core.Node, error) {
+	for p.skipBlank() && strings.HasPrefix(p.lines[p.pos], "%") {
+		p.pos++
+	}
+	if p.pos < len(p.lines) && isDocumentMarker(p.lines[p.pos], "---") {
+		p.lines[p.pos] = "   " + p.lines[p.pos][3:]
+	}
+	if !p.skipBlank() {
+		return core.NewMapNode(), nil
+	}
+
+	tree, err := p.parseBlock(-1)
+	if err != nil {
+		return nil, err
+	}
+
+	if p.skipBlank() && isDocumentMarker(p.lines[p.pos], "...") {
+		p.pos++
+	}
+	if p.skipBlank() {
+		if isDocumentMarker(p.lines[p.pos], "---") {
+			return nil, errors.New("files with several documents are not supported")
+		}
+		return nil, p.errorf("unexpected %s", strings.TrimSpace(p.lines[p.pos]))
+	}
+
+	return tree, nil
+}
+
+// readProperties reads the anchor and the tag starting the current line,
+// removing them from it. It returns the anchor, and true if the node they
+// belong to starts on the next line.
+func (p *parser) readProperties() (string, bool) {
+	line := p.lines[p.pos]
+	column := indentOf(line)
+	anchor := ""
+	for column < len(line) && (line[column] == '&' || line[column] == '!') {
+		end := column
+		for end < len(line) && line[end] != ' ' && line[end] != '\t' {
+			end++
+		}
+		if line[column] == '&' {
+			anchor = line[column+1 : end]
+		} else {
+			p.losses.Add("tags are not converted (%s)", line[column:end])
+		}
+		column = end
+		for column < len(line) && (line[column] == ' ' || line[column] == '\t') {
+			column++
+		}
+	}
+
+	if p.stripComment(line[column:]) == "" {
+		p.pos++
+		return anchor, true
+	}
+	p.lines[p.pos] = strings.Repeat(" ", column) + line[column:]
+
+	return anchor, false
+}
+
+func (p *parser) setAnchor(anchor string, node *core.Node) {
+	if anchor != "" {
+		p.anchors[anchor] = node
+	}
+}
+
+// parseBlock reads the node starting at the current line, which must be
+// indented more than its parent to belong to it
+func (p *parser) parseBlock(parentIndent int) (*core.Node, error) {
+	if !p.skipBlank() || indentOf(p.lines[p.pos]) <= parentIndent {
+		return p.null(), nil
+	}
+
+	anchor, nested := p.readProperties()
+	if nested {
+		node, err := p.parseNested(parentIndent)
+		p.setAnchor(anchor, node)
+		return node, err
+	}
+
+	line := p.lines[p.pos]
+	indent := indentOf(line)
+	text := p.stripComment(line)[indent:]
+	if strings.HasPrefix(text, "\t") {
+		return nil, p.errorf("tabs cannot be used for indentation")
+	}
+
+	var node *core.Node
+	var err error
+	if isSequenceEntry(text) {
+		node, err = p.parseSequence(indent)
+	} else if _, _, isKey, keyErr := splitKey(text); keyErr != nil {
+		return nil, p.errorf("%s", keyErr.Error())
+	} else if isKey {
+		node, err = p.parseMapping(indent)
+	} else {
+		node, err = p.parseScalar(parentIndent)
+	}
+	p.setAnchor(anchor, node)
+
+	return node, err
+}
+
+// parseNested reads the node following a key or properties ending a line:
+// a sequence can be indented as the key it belongs to
+func (p *parser) parseNested(parentIndent int) (*core.Node, error) {
+	if p.skipBlank() && indentOf(p.lines[p.pos]) == parentIndent && isSequenceEntry(p.stripComment(p.lines[p.pos])[parentIndent:]) {
+		return p.parseSequence(parentIndent)
+	}
+
+	return p.parseBlock(parentIndent)
+}
+
+// parseInline reads the value following a key on the same line, which cannot
+// be a block collection
+func (p *parser) parseInline(parentIndent int) (*core.Node, error) {
+	anchor, nested := p.readProperties()
+
+	var node *core.Node
+	var err error
+	if nested {
+		node, err = p.parseNested(parentIndent)
+	} else {
+		node, err = p.parseScalar(parentIndent)
+	}
+	p.setAnchor(anchor, node)
+
+	return node, err
+}
+
+func (p *parser) parseSequence(indent int) (*core.Node, error) {
+	list := core.NewListNode()
+	for p.skipBlank() {
+		line := p.lines[p.pos]
+		if indentOf(line) > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		if indentOf(line) < indent || !isSequenceEntry(p.stripComment(line)[indent:]) {
+			break
+		}
+
+		column := indent + 1
+		for column < len(line) && (line[column] == ' ' || line[column] == '\t') {
+			column++
+		}
+		if p.stripComment(line[column:]) == "" {
+			p.pos++
+		} else {
+			p.lines[p.pos] = strings.Repeat(" ", column) + line[column:]
+		}
+
+		item, err := p.parseBlock(indent)
+		if err != nil {
+			return nil, err
+		}
+		list.Items = append(list.Items, item)
+	}
+
+	return list, nil
+}
+
+// splitKey returns the key starting a mapping entry and the position of its
+// value, isKey being false if the text is not a mapping entry
+func splitKey(text string) (key string, valueStart int, isKey bool, err error) {
+	if strings.HasPrefix(text, "? ") || text == "?" {
+		return "", 0, false, errors.New("complex keys are not supported")
+	}
+	if text == "" || strings.IndexByte("[{*|>", text[0]) != -1 || isSequenceEntry(text) {
+		return "", 0, false, nil
+	}
+
+	colon := -1
+	if text[0] == '"' || text[0] == '\'' {
+		end := closingQuote(text)
+		if end == -1 {
+			return "", 0, false, nil
+		}
+		rest := strings.TrimLeft(text[end+1:], " \t")
+		if !strings.HasPrefix(rest, ":") {
+			return "", 0, false, nil
+		}
+		key, err = unquoteScalar(text[:end+1])
+		if err != nil {
+			return "", 0, false, err
+		}
+		colon = len(text) - len(rest)
+	} else {
+		for i := 0; i < len(text) && colon == -1; i++ {
+			if text[i] == ':' && (i+1 == len(text) || text[i+1] == ' ' || text[i+1] == '\t') {
+				colon = i
+			}
+		}
+		if colon == -1 {
+			return "", 0, false, nil
+		}
+		key = strings.TrimRight(text[:colon], " \t")
+	}
+	if colon+1 < len(text) && text[colon+1] != ' ' && text[colon+1] != '\t' {
+		return "", 0, false, nil
+	}
+
+	valueStart = colon + 1
+	for valueStart < len(text) && (text[valueStart] == ' ' || text[valueStart] == '\t') {
+		valueStart++
+	}
+
+	return key, valueStart, true, nil
+}
+
+func (p *parser) parseMapping(indent int) (*core.Node, error) {
+	mapping := core.NewMapNode()
+	merged := map[string]bool{}
+	for p.skipBlank() {
+		line := p.lines[p.pos]
+		if indentOf(line) > indent {
+			return nil, p.errorf("unexpected indentation")
+		}
+		if indentOf(line) < indent {
+			break
+		}
+
+		key, valueStart, isKey, err := splitKey(p.stripComment(line)[indent:])
+		if err != nil {
+			return nil, p.errorf("%s", err.Error())
+		}
+		if !isKey {
+			break
+		}
+
+		column := indent + valueStart
+		var value *core.Node
+		if p.stripComment(line[column:]) == "" {
+			p.pos++
+			value, err = p.parseNested(indent)
+		} else {
+			p.lines[p.pos] = strings.Repeat(" ", column) + line[column:]
+			value, err = p.parseInline(indent)
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		if key == "<<" && (value.IsMap() || value.Kind == core.ListNode) {
+			sources := []*core.Node{value}
+			if value.Kind == core.ListNode {
+				sources = value.Items
+			}
+			for _, source := range sources {
+				if !source.IsMap() {
+					return nil, p.errorf("only maps can be merged")
+				}
+				for _, sourceKey := range source.Keys {
+					if mapping.Get(sourceKey) == nil {
+						mapping.Set(sourceKey, source.Children[sourceKey])
+						merged[sourceKey] = true
+					}
+				}
+			}
+			continue
+		}
+
+		if mapping.Get(key) != nil && !merged[key] {
+			p.losses.Add("%s is defined several times, only the last value is kept", key)
+		}
+		delete(merged, key)
+		mapping.Set(key, value)
+	}
+
+	return mapping, nil
+}
+
+// parseScalar reads the scalar, the alias or the flow collection starting
+// at the current line
+func (p *parser) parseScalar(parentIndent int) (*core.Node, error) {
+	line := p.lines[p.pos]
+	indent := indentOf(line)
+	text := p.stripComment(line)[indent:]
+
+	switch text[0] {
+	case '*':
+		p.pos++
+		node, exists := p.anchors[text[1:]]
+		if !exists {
+			return nil, p.errorf("unknown alias %s", text)
+		}
+		return node, nil
+	case '[', '{':
+		return p.parseFlow(text)
+	case '"', '\'':
+		return p.parseQuoted(line[indent:])
+	case '|', '>':
+		return p.parseBlockScalar(text, parentIndent)
+	}
+
+	// Plain scalars continue on the lines indented more than their parent,
+	// a line break being a space
+	value := text
+	breaks := 0
+	next := p.pos + 1
+	for end := next; end < len(p.lines); end++ {
+		trimmed := strings.TrimSpace(p.lines[end])
+		if trimmed == "" {
+			breaks++
+			continue
+		}
+		if strings.HasPrefix(trimmed, "#") || indentOf(p.lines[end]) <= parentIndent ||
+			isDocumentMarker(p.lines[end], "---") || isDocumentMarker(p.lines[end], "...") {
+			break
+		}
+		if breaks > 0 {
+			value += strings.Repeat("\n", breaks)
+		} else {
+			value += " "
+		}
+		value += strings.TrimSpace(p.stripComment(p.lines[end]))
+		breaks = 0
+		next = end + 1
+	}
+	p.pos = next
+
+	return p.resolvePlain(value), nil
+}
+
+// resolvePlain returns a plain scalar with its type: null, boolean, integer,
+// float or datetime, a string otherwise
+func (p *parser) resolvePlain(value string) *core.Node {
+	switch value {
+	case "", "~", "null", "Null", "NULL":
+		return p.null()
+	case "true", "True", "TRUE":
+		return core.NewScalarNode(core.BoolScalar, "true")
+	case "false", "False", "FALSE":
+		return core.NewScalarNode(core.BoolScalar, "false")
+	}
+
+	if yamlIntegerRegexp.MatchString(value) {
+		digits := strings.TrimPrefix(value, "+")
+		base := 10
+		if strings.HasPrefix(digits, "0x") {
+			digits, base = digits[2:], 16
+		} else if strings.HasPrefix(digits, "0o") {
+			digits, base = digits[2:], 8
+		}
+		if integer, err := strconv.ParseInt(digits, base, 64); err == nil {
+			return core.NewScalarNode(core.IntegerScalar, strconv.FormatInt(integer, 10))
+		}
+	}
+	if yamlFloatRegexp.MatchString(value) {
+		if float, err := strconv.ParseFloat(value, 64); err == nil {
+			formatted := strconv.FormatFloat(float, 'g', -1, 64)
+			if !strings.ContainsAny(formatted, ".e") {
+				formatted += ".0"
+			}
+			return core.NewScalarNode(core.FloatScalar, formatted)
+		}
+	}
+	if yamlDateTimeRegexp.MatchString(value) {
+		normalized := value[:10] + "T" + strings.ToUpper(strings.Join(strings.Fields(value[11:]), ""))
+		if _, err := time.Parse(time.RFC3339Nano, normalized); err == nil {
+			return core.NewScalarNode(core.DateTimeScalar, normalized)
+		}
+	}
+
+	return core.NewScalarNode(core.StringScalar, value)
+}
+
+// flowDepth returns the number of flow collections left open by the text
+func flowDepth(text string) int {
+	depth := 0
+	var quote byte
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		switch {
+		case quote == '"' && c == '\\':
+			i++
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case (c == '"' || c == '\'') && (i == 0 || strings.IndexByte(" \t[{,:", text[i-1]) != -1):
+			quote = c
+		case c == '[' || c == '{':
+			depth++
+		case c == ']' || c == '}':
+			depth--
+		}
+	}
+
+	return depth
+}
+
+// parseFlow reads a flow collection ([a, b] or {a: 1}), which can span
+// several lines
+func (p *parser) parseFlow(text string) (*core.Node, error) {
+	start := p.pos
+	for flowDepth(text) > 0 {
+		p.pos++
+		if p.pos >= len(p.lines) {
+			p.pos = start
+			return nil, p.errorf("unterminated flow collection")
+		}
+		text += "\n" + strings.TrimSpace(p.stripComment(p.lines[p.pos]))
+	}
+	p.pos++
+
+	reader := &flowReader{parser: p, text: text}
+	node, err := reader.parseValue()
+	if err == nil {
+		reader.skipSpaces()
+		if reader.pos < len(reader.text) {
+			err = errors.New(fmt.Sprintf("unexpected %s after the flow collection", reader.text[reader.pos:]))
+		}
+	}
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("line %d: %s", start+1, err.Error()))
+	}
+
+	return node, nil
+}
+
+// parseQuoted reads a quoted scalar, which can span several lines
+func (p *parser) parseQuoted(text string) (*core.Node, error) {
+	start := p.pos
+	end := closingQuote(text)
+	for end == -1 {
+		p.pos++
+		if p.pos >= len(p.lines) {
+			p.pos = start
+			return nil, p.errorf("unterminated quoted scalar")
+		}
+		text += "\n" + p.lines[p.pos]
+		end = closingQuote(text)
+	}
+
+	if rest := p.stripComment(text[end+1:]); strings.TrimSpace(rest) != "" {
+		return nil, p.errorf("unexpected %s after the quoted scalar", strings.TrimSpace(rest))
+	}
+	p.pos++
+
+	value, err := unquoteScalar(text[:end+1])
+	if err != nil {
+		return nil, errors.New(fmt.Sprintf("line %d: %s", start+1, err.Error()))
+	}
+
+	return core.NewScalarNode(core.StringScalar, value), nil
+}
+
+// foldLines folds the lines of a quoted scalar: a line break is a space,
+// and the empty lines are line breaks
+func foldLines(text string) string {
+	lines := strings.Split(text, "\n")
+	if len(lines) == 1 {
+		return text
+	}
+
+	var builder strings.Builder
+	breaks := 0
+	for i, line := range lines {
+		if i > 0 {
+			line = strings.TrimLeft(line, " \t")
+		}
+		if i < len(lines)-1 {
+			line = strings.TrimRight(line, " \t")
+		}
+		if i > 0 && i < len(lines)-1 && line == "" {
+			breaks++
+			continue
+		}
+		if i > 0 && breaks > 0 {
+			builder.WriteString(strings.Repeat("\n", breaks))
+		} else if i > 0 {
+			builder.WriteString(" ")
+		}
+		builder.WriteString(line)
+		breaks = 0
+	}
+
+	return builder.String()
+}
+
+var yamlEscapes = map[byte]string{
+	'0': "\x00", 'a': "\a", 'b': "\b", 't': "\t", '\t': "\t", 'n': "\n", 'v': "\v", 'f': "\f",
+	'r': "\r", 'e': "\x1b", ' ': " ", '"': "\"", '/': "/", '\\': "\\",
+	'N': "\u0085", '_': "\u00a0", 'L': "\u2028", 'P': "\u2029",
+}
+
+// unquoteScalar returns the value of a single or double quoted scalar
+func unquoteScalar(quoted string) (string, error) {
+	inner := quoted[1 : len(quoted)-1]
+	if quoted[0] == '\'' {
+		return strings.ReplaceAll(foldLines(inner), "''", "'"), nil
+	}
+
+	inner = foldLines(escapedBreakRegexp.ReplaceAllString(inner, ""))
+	var builder strings.Builder
+	for i := 0; i < len(inner); i++ {
+		if inner[i] != '\\' {
+			builder.WriteByte(inner[i])
+			continue
+		}
+		if i+1 == len(inner) {
+			return "", errors.New("invalid escape at the end of a quoted scalar")
+		}
+		i++
+		if escaped, exists := yamlEscapes[inner[i]]; exists {
+			builder.WriteString(escaped)
+			continue
+		}
+
+		size := map[byte]int{'x': 2, 'u': 4, 'U': 8}[inner[i]]
+		if size == 0 || i+size >= len(inner) {
+			return "", errors.New(fmt.Sprintf("invalid escape \\%c", inner[i]))
+		}
+		code, err := strconv.ParseUint(inner[i+1:i+1+size], 16, 32)
+		if err != nil {
+			return "", errors.New(fmt.Sprintf("invalid escape \\%s", inner[i:i+1+size]))
+		}
+		builder.WriteRune(rune(code))
+		i += size
+	}
+
+	return builder.String(), nil
+}
+
+// parseBlockScalar reads a literal (|) or folded (>) block scalar, with its
+// chomping (-, +) and indentation indicators
+func (p *parser) parseBlockScalar(header string, parentIndent int) (*core.Node, error) {
+	chomping := byte(0)
+	indent := 0
+	for i := 1; i < len(header); i++ {
+		switch c := header[i]; {
+		case (c == '-' || c == '+') && chomping == 0:
+			chomping = c
+		case c >= '1' && c <= '9' && indent == 0:
+			// The indentation is relative to the parent
+			indent = int(c - '0')
+			if parentIndent > 0 {
+				indent += parentIndent
+			}
+		default:
+			return nil, p.errorf("invalid block scalar header %s", header)
+		}
+	}
+	p.pos++
+
+	lines := []string{}
+	for ; p.pos < len(p.lines); p.pos++ {
+		line := p.lines[p.pos]
+		if strings.TrimSpace(line) == "" {
+			if indent > 0 && len(line) > indent {
+				line = line[indent:]
+			} else {
+				line = ""
+			}
+			lines = append(lines, line)
+			continue
+		}
+		if indent == 0 {
+			indent = indentOf(line)
+		}
+		if indentOf(line) < indent || indent <= parentIndent {
+			break
+		}
+		lines = append(lines, line[indent:])
+	}
+
+	trailing := 0
+	for trailing < len(lines) && lines[len(lines)-1-trailing] == "" {
+		trailing++
+	}
+	body := lines[:len(lines)-trailing]
+
+	value := strings.Join(body, "\n")
+	if header[0] == '>' {
+		value = foldBlock(body)
+	}
+	switch {
+	case chomping == '+' && len(body) > 0:
+		value += "\n" + strings.Repeat("\n", trailing)
+	case chomping == '+':
+		value += strings.Repeat("\n", trailing)
+	case chomping == 0 && len(body) > 0:
+		value += "\n"
+	}
+
+	return core.NewScalarNode(core.StringScalar, value), nil
+}
+
+// foldBlock folds the lines of a folded block scalar: a line break between
+// two lines is a space, unless one of them is more indented
+func foldBlock(lines []string) string {
+	var builder strings.Builder
+	breaks := 0
+	previous := ""
+	started := false
+	for _, line := range lines {
+		if line == "" {
+			breaks++
+			continue
+		}
+
+		moreIndented := strings.HasPrefix(line, " ") || strings.HasPrefix(line, "\t") ||
+			strings.HasPrefix(previous, " ") || strings.HasPrefix(previous, "\t")
+		switch {
+		case !started:
+			builder.WriteString(strings.Repeat("\n", breaks))
+		case moreIndented:
+			builder.WriteString(strings.Repeat("\n", breaks+1))
+		case breaks > 0:
+			builder.WriteString(strings.Repeat("\n", breaks))
+		default:
+			builder.WriteString(" ")
+		}
+		builder.WriteString(line)
+		previous = line
+		started = true
+		breaks = 0
+	}
+
+	return builder.String()
+}
+
+// flowReader reads a flow collection, gathered on a single string
+type flowReader struct {
+	parser *parser
+	text   string
+	pos    int
+}
+
+func (reader *flowReader) skipSpaces() {
+	for reader.pos < len(reader.text) && strings.IndexByte(" \t\n", reader.text[reader.pos]) != -1 {
+		reader.pos++
+	}
+}
+
+// readPlain reads a plain scalar, which ends with a flow indicator or a
+// colon followed by a space
+func (reader *flowReader) readPlain() string {
+	start := reader.pos
+	for ; reader.pos < len(reader.text); reader.pos++ {
+		c := reader.text[reader.pos]
+		if strings.IndexByte(",[]{}", c) != -1 {
+			break
+		}
+		if c == ':' && (reader.pos+1 == len(reader.text) || strings.IndexByte(" \t\n,[]{}", reader.text[reader.pos+1]) != -1) {
+			break
+		}
+	}
+
+	return strings.Join(strings.Fields(reader.text[start:reader.pos]), " ")
+}
+
+func (reader *flowReader) readQuoted() (string, error) {
+	end := closingQuote(reader.text[reader.pos:])
+	if end == -1 {
+		return "", errors.New("unterminated quoted scalar")
+	}
+	quoted := reader.text[reader.pos : reader.pos+end+1]
+	reader.pos += end + 1
+
+	return unquoteScalar(quoted)
+}
+
+func (reader *flowReader) parseValue() (*core.Node, error) {
+	reader.skipSpaces()
+	if reader.pos >= len(reader.text) {
+		return nil, errors.New("unterminated flow collection")
+	}
+
+	anchor := ""
+	for reader.text[reader.pos] == '&' || reader.text[reader.pos] == '!' {
+		start := reader.pos
+		for reader.pos < len(reader.text) && strings.IndexByte(" \t\n,[]{}", reader.text[reader.pos]) == -1 {
+			reader.pos++
+		}
+		if reader.text[start] == '&' {
+			anchor = reader.text[start+1 : reader.pos]
+		} else {
+			reader.parser.losses.Add("tags are not converted (%s)", reader.text[start:reader.pos])
+		}
+		reader.skipSpaces()
+		if reader.pos >= len(reader.text) {
+			return nil, errors.New("unterminated flow collection")
+		}
+	}
+
+	var node *core.Node
+	var err error
+	switch reader.text[reader.pos] {
+	case '[':
+		node, err = reader.parseSequence()
+	case '{':
+		node, err = reader.parseMapping()
+	case '"', '\'':
+		var value string
+		value, err = reader.readQuoted()
+		node = core.NewScalarNode(core.StringScalar, value)
+	case '*':
+		reader.pos++
+		name := reader.readPlain()
+		var exists bool
+		if node, exists = reader.parser.anchors[name]; !exists {
+			err = errors.New(fmt.Sprintf("unknown alias *%s", name))
+		}
+	default:
+		node = reader.parser.resolvePlain(reader.readPlain())
+	}
+	if err != nil {
+		return nil, err
+	}
+	reader.parser.setAnchor(anchor, node)
+
+	return node, nil
+}
+
+// next moves after the comma separating the entries of a collection, or to
+// its end
+func (reader *flowReader) next(closing byte) error {
+	reader.skipSpaces()
+	if reader.pos < len(reader.text) && reader.text[reader.pos] == ',' {
+		reader.pos++
+		return nil
+	}
+	if reader.pos < len(reader.text) && reader.text[reader.pos] == closing {
+		return nil
+	}
+
+	return errors.New(fmt.Sprintf("expected , or %c in the flow collection", closing))
+}
+
+func (reader *flowReader) parseSequence() (*core.Node, error) {
+	list := core.NewListNode()
+	reader.pos++
+	for {
+		reader.skipSpaces()
+		if reader.pos >= len(reader.text) {
+			return nil, errors.New("unterminated flow collection")
+		}
+		if reader.text[reader.pos] == ']' {
+			reader.pos++
+			return list, nil
+		}
+
+		item, err := reader.parseValue()
+		if err != nil {
+			return nil, err
+		}
+		list.Items = append(list.Items, item)
+		if err := reader.next(']'); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (reader *flowReader) parseMapping() (*core.Node, error) {
+	mapping := core.NewMapNode()
+	reader.pos++
+	for {
+		reader.skipSpaces()
+		if reader.pos >= len(reader.text) {
+			return nil, errors.New("unterminated flow collection")
+		}
+		if reader.text[reader.pos] == '}' {
+			reader.pos++
+			return mapping, nil
+		}
+
+		var key string
+		var err error
+		if c := reader.text[reader.pos]; c == '"' || c == '\'' {
+			if key, err = reader.readQuoted(); err != nil {
+				return nil, err
+			}
+		} else {
+			key = reader.readPlain()
+		}
+
+		reader.skipSpaces()
+		var value *core.Node
+		if reader.pos < len(reader.text) && reader.text[reader.pos] == ':' {
+			reader.pos++
+			reader.skipSpaces()
+			if reader.pos < len(reader.text) && (reader.text[reader.pos] == ',' || reader.text[reader.pos] == '}') {
+				value = reader.parser.null()
+			} else if value, err = reader.parseValue(); err != nil {
+				return nil, err
+			}
+		} else {
+			value = reader.parser.null()
+		}
+
+		if mapping.Get(key) != nil {
+			reader.parser.losses.Add("%s is defined several times, only the last value is kept", key)
+		}
+		mapping.Set(key, value)
+		if err := reader.next('}'); err != nil {
+			return nil, err
+		}
+	}
+}
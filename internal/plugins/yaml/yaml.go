@@ -0,0 +1,112 @@
+package yaml
+
+import (
+	"regexp"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/json"
+)
+
+var (
+	// yamlReservedRegexp matches plain scalars which YAML would not read as
+	// strings: booleans, nulls and numbers
+	yamlReservedRegexp = regexp.MustCompile(`^(?i:true|false|yes|no|on|off|y|n|null|~|[-+]?(\.inf|\.nan|[0-9][0-9_]*(\.[0-9_]*)?([eE][-+]?[0-9]+)?|\.[0-9]+|0x[0-9a-f]+|0o[0-7]+))$`)
+	yamlIndicators     = "-?:,[]{}#&*!|>'\"%@`"
+)
+
+// YamlFormat converts configurations from and to YAML, written in block
+// style
+type YamlFormat struct{}
+
+func (format YamlFormat) WriteTree(tree *core.Node, losses *core.Losses) (string, error) {
+	if tree.Kind == core.ScalarNode {
+		return yamlScalar(tree) + "\n", nil
+	}
+	if isEmptyCollection(tree) {
+		return yamlEmpty(tree) + "\n", nil
+	}
+
+	var builder strings.Builder
+	writeYAMLCollection(&builder, tree, "")
+
+	return builder.String(), nil
+}
+
+// yamlString returns a string as a plain scalar when YAML would read it back
+// as the same string, double quoted otherwise
+func yamlString(value string) string {
+	plain := value != "" &&
+		strings.TrimSpace(value) == value &&
+		!yamlReservedRegexp.MatchString(value) &&
+		!strings.ContainsAny(value[:1], yamlIndicators) &&
+		!strings.Contains(value, ": ") &&
+		!strings.Contains(value, " #") &&
+		!strings.HasSuffix(value, ":") &&
+		!strings.ContainsAny(value, "\n\r\t")
+	if plain {
+		return value
+	}
+
+	return json.Quote(value)
+}
+
+func yamlScalar(node *core.Node) string {
+	switch node.Type {
+	case core.IntegerScalar, core.FloatScalar, core.BoolScalar, core.DateTimeScalar:
+		return node.Value
+	default:
+		return yamlString(node.Value)
+	}
+}
+
+func isEmptyCollection(node *core.Node) bool {
+	return node.IsMap() && len(node.Keys) == 0 || node.Kind == core.ListNode && len(node.Items) == 0
+}
+
+func yamlEmpty(node *core.Node) string {
+	if node.IsMap() {
+		return "{}"
+	}
+
+	return "[]"
+}
+
+// writeYAMLValue writes the value following a "key:" or a "- " indicator
+func writeYAMLValue(builder *strings.Builder, node *core.Node, indent string, inList bool) {
+	switch {
+	case node.Kind == core.ScalarNode:
+		builder.WriteString(" " + yamlScalar(node) + "\n")
+	case isEmptyCollection(node):
+		builder.WriteString(" " + yamlEmpty(node) + "\n")
+	case inList && node.IsMap():
+		// The first key of a map in a list follows the "- " indicator
+		var nested strings.Builder
+		writeYAMLCollection(&nested, node, indent+"  ")
+		builder.WriteString(" " + strings.TrimPrefix(nested.String(), indent+"  "))
+	default:
+		builder.WriteString("\n")
+		childIndent := indent + "  "
+		if node.Kind == core.ListNode && !inList {
+			// Lists are not indented under their key
+			childIndent = indent
+		}
+		writeYAMLCollection(builder, node, childIndent)
+	}
+}
+
+func writeYAMLCollection(builder *strings.Builder, node *core.Node, indent string) {
+	if node.Kind == core.ListNode {
+		for _, item := range node.Items {
+			builder.WriteString(indent + "-")
+			writeYAMLValue(builder, item, indent, true)
+		}
+
+		return
+	}
+
+	for _, key := range node.Keys {
+		builder.WriteString(indent + yamlString(key) + ":")
+		writeYAMLValue(builder, node.Children[key], indent, false)
+	}
+}
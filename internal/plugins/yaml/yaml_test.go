@@ -0,0 +1,194 @@
+package yaml
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/json"
+)
+
+func TestWriteTree(t *testing.T) {
+	tree := core.NewMapNode()
+	tree.Set("name", core.NewScalarNode(core.StringScalar, "edicon"))
+	tree.Set("version", core.NewScalarNode(core.StringScalar, "1.0"))
+	tree.Set("answer", core.NewScalarNode(core.StringScalar, "no"))
+	tree.Set("port", core.NewScalarNode(core.IntegerScalar, "8080"))
+	tree.Set("empty", core.NewListNode())
+
+	server := core.NewMapNode()
+	server.Set("host", core.NewScalarNode(core.UntypedScalar, "localhost: 80"))
+	servers := core.NewListNode()
+	servers.Items = append(servers.Items, server)
+	tree.Set("servers", servers)
+
+	output, err := YamlFormat{}.WriteTree(tree, &core.Losses{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `name: edicon
+version: "1.0"
+answer: "no"
+port: 8080
+empty: []
+servers:
+- host: "localhost: 80"
+`
+	if output != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+}
+
+func readTestDocument(t *testing.T, content string, losses *core.Losses) (*core.Node, error) {
+	t.Helper()
+
+	filePath := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(filePath, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return YamlFormat{}.ReadDocument(filePath, losses)
+}
+
+func TestReadDocument(t *testing.T) {
+	content := `%YAML 1.2
+---
+# Service
+name: edicon
+version: "1.0"
+port: 0x1F90
+ratio: .5
+debug: true
+answer: no
+released: 2024-01-15 10:30:00 Z
+empty:
+defaults: &defaults
+  adapter: postgres
+  host: localhost # inline comment
+development:
+  <<: *defaults
+  host: db.local
+tags: [a, "b, c", {d: 1}]
+servers:
+- host: 'it''s'
+  ports:
+    - 80
+    - 443
+-   host: "tab\there"
+script: |
+  echo "a"
+    indented
+
+folded: >-
+  first
+  line
+
+  second
+plain: a
+  multi line
+quoted: "a \
+  b
+  c"
+nested:
+- - x
+  - y
+- {}
+name: other
+...
+`
+
+	losses := &core.Losses{}
+	tree, err := readTestDocument(t, content, losses)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	output, err := json.JsonFormat{}.WriteTree(tree, &core.Losses{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `{
+  "name": "other",
+  "version": "1.0",
+  "port": 8080,
+  "ratio": 0.5,
+  "debug": true,
+  "answer": "no",
+  "released": "2024-01-15T10:30:00Z",
+  "empty": "",
+  "defaults": {
+    "adapter": "postgres",
+    "host": "localhost"
+  },
+  "development": {
+    "adapter": "postgres",
+    "host": "db.local"
+  },
+  "tags": [
+    "a",
+    "b, c",
+    {
+      "d": 1
+    }
+  ],
+  "servers": [
+    {
+      "host": "it's",
+      "ports": [
+        80,
+        443
+      ]
+    },
+    {
+      "host": "tab\there"
+    }
+  ],
+  "script": "echo \"a\"\n  indented\n",
+  "folded": "first line\nsecond",
+  "plain": "a multi line",
+  "quoted": "a b c",
+  "nested": [
+    [
+      "x",
+      "y"
+    ],
+    {}
+  ]
+}
+`
+	if output != expected {
+		t.Errorf("Expected:\n%s\nGot:\n%s", expected, output)
+	}
+
+	expectedLosses := []string{
+		"comments are not converted",
+		"null values are converted to empty strings",
+		"name is defined several times, only the last value is kept",
+	}
+	if !reflect.DeepEqual(losses.Messages, expectedLosses) {
+		t.Errorf("Expected losses %v, got %v", expectedLosses, losses.Messages)
+	}
+}
+
+func TestReadInvalidDocument(t *testing.T) {
+	dataProvider := map[string]string{
+		"several documents":     "a: 1\n---\nb: 2\n",
+		"an unknown alias":      "a: *missing\n",
+		"an unterminated quote": "a: \"b\n",
+		"a wrong indentation":   "a:\n    b: 1\n  c: 2\n",
+		"a complex key":         "? a\n: b\n",
+		"an unclosed flow":      "a: [1, 2\n",
+	}
+
+	for name, content := range dataProvider {
+		t.Run("it fails for "+name, func(t *testing.T) {
+			if _, err := readTestDocument(t, content, nil); err == nil {
+				t.Error("Expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,382 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+type tokenType int
+
+const (
+	identToken tokenType = iota
+	numberToken
+	stringToken
+	punctToken
+	endToken
+)
+
+type token struct {
+	Type tokenType
+	Raw  string
+	Pos  int
+	// Spaced is true when the token follows a whitespace, ". foo" not being
+	// the key foo
+	Spaced bool
+}
+
+var operators = []string{"..", "==", "!=", "<=", ">=", ".", "|", "(", ")", "[", "]", "?", "<", ">"}
+
+var comparisonOperators = map[string]bool{
+	"==": true, "!=": true, "<=": true, ">=": true, "<": true, ">": true,
+}
+
+func queryError(pos int, format string, args ...interface{}) error {
+	message := fmt.Sprintf(format, args...)
+
+	return errors.New(fmt.Sprintf("Invalid query at position %d: %s", pos+1, message))
+}
+
+func isIdentStart(c byte) bool {
+	return c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z')
+}
+
+func isIdentChar(c byte) bool {
+	return isIdentStart(c) || isDigit(c)
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+func tokenize(query string) ([]token, error) {
+	tokens := []token{}
+
+	pos := 0
+	for {
+		start := pos
+		for pos < len(query) && strings.ContainsRune(" \t\r\n", rune(query[pos])) {
+			pos++
+		}
+		spaced := pos > start
+		if pos >= len(query) {
+			return append(tokens, token{Type: endToken, Pos: pos, Spaced: spaced}), nil
+		}
+
+		start = pos
+		c := query[pos]
+		switch {
+		case isIdentStart(c):
+			for pos < len(query) && isIdentChar(query[pos]) {
+				pos++
+			}
+			tokens = append(tokens, token{identToken, query[start:pos], start, spaced})
+		case isDigit(c) || (c == '-' && pos+1 < len(query) && isDigit(query[pos+1])):
+			pos++
+			for pos < len(query) && (isDigit(query[pos]) || query[pos] == '.') {
+				pos++
+			}
+			tokens = append(tokens, token{numberToken, query[start:pos], start, spaced})
+		case c == '"':
+			pos++
+			for pos < len(query) && query[pos] != '"' {
+				if query[pos] == '\\' {
+					pos++
+				}
+				pos++
+			}
+			if pos >= len(query) {
+				return nil, queryError(start, "missing closing quote")
+			}
+			pos++
+			value, err := strconv.Unquote(query[start:pos])
+			if err != nil {
+				return nil, queryError(start, "invalid string %s", query[start:pos])
+			}
+			tokens = append(tokens, token{stringToken, value, start, spaced})
+		default:
+			matched := false
+			for _, operator := range operators {
+				if strings.HasPrefix(query[pos:], operator) {
+					pos += len(operator)
+					tokens = append(tokens, token{punctToken, operator, start, spaced})
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				return nil, queryError(start, "unexpected %q", string(c))
+			}
+		}
+	}
+}
+
+type parser struct {
+	tokens []token
+	pos    int
+}
+
+func (p *parser) peek() token {
+	return p.tokens[p.pos]
+}
+
+func (p *parser) next() token {
+	current := p.tokens[p.pos]
+	if current.Type != endToken {
+		p.pos++
+	}
+
+	return current
+}
+
+func (p *parser) isPunct(raw string) bool {
+	return p.peek().Type == punctToken && p.peek().Raw == raw
+}
+
+func (p *parser) isKeyword(raw string) bool {
+	return p.peek().Type == identToken && p.peek().Raw == raw
+}
+
+func (p *parser) expect(raw string) error {
+	if !p.isPunct(raw) {
+		return p.unexpected()
+	}
+	p.next()
+
+	return nil
+}
+
+func (p *parser) unexpected() error {
+	current := p.peek()
+	if current.Type == endToken {
+		return queryError(current.Pos, "unexpected end of the query")
+	}
+	if current.Type == stringToken {
+		return queryError(current.Pos, "unexpected string %q", current.Raw)
+	}
+
+	return queryError(current.Pos, "unexpected %q", current.Raw)
+}
+
+// parsePipe parses "a | b", the lowest precedence
+func (p *parser) parsePipe() (expression, error) {
+	left, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isPunct("|") {
+		p.next()
+		right, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		left = pipe{left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseOr() (expression, error) {
+	left, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("or") {
+		p.next()
+		right, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{"or", left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseAnd() (expression, error) {
+	left, err := p.parseComparison()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.isKeyword("and") {
+		p.next()
+		right, err := p.parseComparison()
+		if err != nil {
+			return nil, err
+		}
+		left = logical{"and", left, right}
+	}
+
+	return left, nil
+}
+
+func (p *parser) parseComparison() (expression, error) {
+	left, err := p.parsePostfix()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek().Type == punctToken && comparisonOperators[p.peek().Raw] {
+		operator := p.next().Raw
+		right, err := p.parsePostfix()
+		if err != nil {
+			return nil, err
+		}
+		return comparison{operator, left, right}, nil
+	}
+
+	return left, nil
+}
+
+// parsePostfix parses a term followed by key selections (".a", ".\"b\""),
+// indexes ("[0]", "[\"c\"]"), iterations ("[]") and "?"
+func (p *parser) parsePostfix() (expression, error) {
+	term, err := p.parseTerm()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		switch {
+		case p.isPunct(".") && !p.peek().Spaced:
+			p.next()
+			if p.isPunct("[") && !p.peek().Spaced {
+				continue
+			}
+			name, err := p.parseKeyName()
+			if err != nil {
+				return nil, err
+			}
+			term = field{term, name}
+		case p.isPunct("[") && !p.peek().Spaced:
+			term, err = p.parseBrackets(term)
+			if err != nil {
+				return nil, err
+			}
+		case p.isPunct("?"):
+			p.next()
+			term = try{term}
+		default:
+			return term, nil
+		}
+	}
+}
+
+// parseKeyName parses the key following a ".", a name or a quoted string
+func (p *parser) parseKeyName() (string, error) {
+	current := p.peek()
+	if current.Spaced || (current.Type != identToken && current.Type != stringToken) {
+		return "", p.unexpected()
+	}
+	p.next()
+
+	return current.Raw, nil
+}
+
+func (p *parser) parseBrackets(target expression) (expression, error) {
+	p.next()
+	if p.isPunct("]") {
+		p.next()
+		return iterate{target}, nil
+	}
+
+	current := p.peek()
+	var selected expression
+	switch current.Type {
+	case stringToken:
+		selected = field{target, current.Raw}
+	case numberToken:
+		idx, err := strconv.Atoi(current.Raw)
+		if err != nil {
+			return nil, queryError(current.Pos, "invalid index %s", current.Raw)
+		}
+		selected = index{target, idx}
+	default:
+		return nil, p.unexpected()
+	}
+	p.next()
+
+	return selected, p.expect("]")
+}
+
+func (p *parser) parseTerm() (expression, error) {
+	current := p.peek()
+
+	switch current.Type {
+	case stringToken:
+		p.next()
+		return literal{core.NewScalarNode(core.StringScalar, current.Raw)}, nil
+	case numberToken:
+		p.next()
+		if strings.Contains(current.Raw, ".") {
+			if _, err := strconv.ParseFloat(current.Raw, 64); err != nil {
+				return nil, queryError(current.Pos, "invalid number %s", current.Raw)
+			}
+			return literal{core.NewScalarNode(core.FloatScalar, current.Raw)}, nil
+		}
+		return literal{core.NewScalarNode(core.IntegerScalar, current.Raw)}, nil
+	case identToken:
+		return p.parseFunction()
+	}
+
+	switch {
+	case p.isPunct(".."):
+		p.next()
+		return recurse{}, nil
+	case p.isPunct("."):
+		p.next()
+		if next := p.peek(); !next.Spaced && (next.Type == identToken || next.Type == stringToken) {
+			p.next()
+			return field{identity{}, next.Raw}, nil
+		}
+		return identity{}, nil
+	case p.isPunct("("):
+		p.next()
+		inner, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		return inner, p.expect(")")
+	}
+
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseFunction() (expression, error) {
+	name := p.next()
+
+	switch name.Raw {
+	case "true", "false":
+		return literal{core.NewScalarNode(core.BoolScalar, name.Raw)}, nil
+	case "null":
+		return literal{nil}, nil
+	}
+
+	arity, exists := functionArities[name.Raw]
+	if !exists {
+		return nil, queryError(name.Pos, "unknown function %s", name.Raw)
+	}
+
+	arguments := []expression{}
+	if arity > 0 {
+		if err := p.expect("("); err != nil {
+			return nil, err
+		}
+		argument, err := p.parsePipe()
+		if err != nil {
+			return nil, err
+		}
+		if err := p.expect(")"); err != nil {
+			return nil, err
+		}
+		arguments = append(arguments, argument)
+	}
+
+	return call{name.Raw, arguments}, nil
+}
@@ -0,0 +1,570 @@
+package query
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+// Query is a parsed query, e.g. `.[] | select(.enabled == false) | key`.
+// It is evaluated over a document tree and returns a stream of nodes, null
+// being a nil node.
+type Query struct {
+	expression expression
+}
+
+// Parse parses a query
+func Parse(query string) (*Query, error) {
+	tokens, err := tokenize(query)
+	if err != nil {
+		return nil, err
+	}
+
+	p := &parser{tokens: tokens}
+	parsed, err := p.parsePipe()
+	if err != nil {
+		return nil, err
+	}
+	if p.peek().Type != endToken {
+		return nil, p.unexpected()
+	}
+
+	return &Query{parsed}, nil
+}
+
+// Run evaluates the query over a document. The paths returned by the path
+// and section functions are in the given notation.
+func (query *Query) Run(document *core.Node, notationStyle core.NotationStyle) ([]*core.Node, error) {
+	context := &evaluation{document, notationStyle}
+
+	return query.expression.evaluate(context, document)
+}
+
+type evaluation struct {
+	document      *core.Node
+	notationStyle core.NotationStyle
+}
+
+type expression interface {
+	evaluate(context *evaluation, input *core.Node) ([]*core.Node, error)
+}
+
+type identity struct{}
+
+type recurse struct{}
+
+type field struct {
+	target expression
+	name   string
+}
+
+type index struct {
+	target expression
+	index  int
+}
+
+type iterate struct {
+	target expression
+}
+
+type try struct {
+	target expression
+}
+
+type pipe struct {
+	left  expression
+	right expression
+}
+
+type logical struct {
+	operator string
+	left     expression
+	right    expression
+}
+
+type comparison struct {
+	operator string
+	left     expression
+	right    expression
+}
+
+type literal struct {
+	value *core.Node
+}
+
+type call struct {
+	name      string
+	arguments []expression
+}
+
+func describe(node *core.Node) string {
+	switch {
+	case node == nil:
+		return "null"
+	case node.IsMap():
+		return "a map"
+	case node.Kind == core.ListNode:
+		return "a list"
+	default:
+		return fmt.Sprintf("the value %q", node.Value)
+	}
+}
+
+func newBool(value bool) *core.Node {
+	return core.NewScalarNode(core.BoolScalar, strconv.FormatBool(value))
+}
+
+// isTrue returns false for null and false, values of formats without types
+// included
+func isTrue(node *core.Node) bool {
+	return node != nil && !(node.Kind == core.ScalarNode && node.Value == "false")
+}
+
+func (expr identity) evaluate(context *evaluation, input *core.Node) ([]*core.Node, error) {
+	return []*core.Node{input}, nil
+}
+
+func (expr recurse) evaluate(context *evaluation, input *core.Node) ([]*core.Node, error) {
+	if input == nil {
+		return []*core.Node{nil}, nil
+	}
+
+	results := []*core.Node{}
+	input.Walk(func(node *core.Node) error {
+		results = append(results, node)
+		return nil
+	})
+
+	return results, nil
+}
+
+// evaluate selects a key of maps; the keys of null and scalars are null
+func (expr field) evaluate(context *evaluation, input *core.Node) ([]*core.Node, error) {
+	targets, err := expr.target.evaluate(context, input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*core.Node{}
+	for _, target := range targets {
+		switch {
+		case target != nil && target.Kind == core.ListNode:
+			return nil, errors.New(fmt.Sprintf("Cannot select the key %q of a list", expr.name))
+		case target != nil && target.IsMap():
+			results = append(results, target.Get(expr.name))
+		default:
+			results = append(results, nil)
+		}
+	}
+
+	return results, nil
+}
+
+func (expr index) evaluate(context *evaluation, input *core.Node) ([]*core.Node, error) {
+	targets, err := expr.target.evaluate(context, input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*core.Node{}
+	for _, target := range targets {
+		if target != nil && target.Kind != core.ListNode {
+			return nil, errors.New(fmt.Sprintf("Cannot index %s with %d", describe(target), expr.index))
+		}
+
+		idx := expr.index
+		if target != nil && idx < 0 {
+			idx += len(target.Items)
+		}
+		if target == nil || idx < 0 || idx >= len(target.Items) {
+			results = append(results, nil)
+			continue
+		}
+		results = append(results, target.Items[idx])
+	}
+
+	return results, nil
+}
+
+func (expr iterate) evaluate(context *evaluation, input *core.Node) ([]*core.Node, error) {
+	targets, err := expr.target.evaluate(context, input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*core.Node{}
+	for _, target := range targets {
+		switch {
+		case target != nil && target.Kind == core.ListNode:
+			results = append(results, target.Items...)
+		case target != nil && target.IsMap():
+			for _, key := range target.Keys {
+				results = append(results, target.Get(key))
+			}
+		default:
+			return nil, errors.New(fmt.Sprintf("Cannot iterate over %s", describe(target)))
+		}
+	}
+
+	return results, nil
+}
+
+// evaluate returns the results of the target, or nothing if it fails
+func (expr try) evaluate(context *evaluation, input *core.Node) ([]*core.Node, error) {
+	results, err := expr.target.evaluate(context, input)
+	if err != nil {
+		return []*core.Node{}, nil
+	}
+
+	return results, nil
+}
+
+func (expr pipe) evaluate(context *evaluation, input *core.Node) ([]*core.Node, error) {
+	inputs, err := expr.left.evaluate(context, input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*core.Node{}
+	for _, node := range inputs {
+		outputs, err := expr.right.evaluate(context, node)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, outputs...)
+	}
+
+	return results, nil
+}
+
+func (expr logical) evaluate(context *evaluation, input *core.Node) ([]*core.Node, error) {
+	lefts, err := expr.left.evaluate(context, input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*core.Node{}
+	for _, left := range lefts {
+		if isTrue(left) == (expr.operator == "or") {
+			results = append(results, newBool(isTrue(left)))
+			continue
+		}
+
+		rights, err := expr.right.evaluate(context, input)
+		if err != nil {
+			return nil, err
+		}
+		for _, right := range rights {
+			results = append(results, newBool(isTrue(right)))
+		}
+	}
+
+	return results, nil
+}
+
+func (expr comparison) evaluate(context *evaluation, input *core.Node) ([]*core.Node, error) {
+	lefts, err := expr.left.evaluate(context, input)
+	if err != nil {
+		return nil, err
+	}
+	rights, err := expr.right.evaluate(context, input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*core.Node{}
+	for _, left := range lefts {
+		for _, right := range rights {
+			result, err := compare(expr.operator, left, right)
+			if err != nil {
+				return nil, err
+			}
+			results = append(results, newBool(result))
+		}
+	}
+
+	return results, nil
+}
+
+// compare compares scalars as numbers when both are numbers, as strings
+// otherwise, so that the untyped values of INI files can be compared with
+// numbers and booleans. Null is lower than any value.
+func compare(operator string, left *core.Node, right *core.Node) (bool, error) {
+	var order int
+	switch {
+	case left == nil || right == nil:
+		if left == nil && right == nil {
+			order = 0
+		} else if left == nil {
+			order = -1
+		} else {
+			order = 1
+		}
+	case left.Kind != core.ScalarNode || right.Kind != core.ScalarNode:
+		if operator != "==" && operator != "!=" {
+			return false, errors.New(fmt.Sprintf("Cannot compare %s and %s", describe(left), describe(right)))
+		}
+		order = 1
+		if equalNodes(left, right) {
+			order = 0
+		}
+	default:
+		leftNumber, leftErr := strconv.ParseFloat(left.Value, 64)
+		rightNumber, rightErr := strconv.ParseFloat(right.Value, 64)
+		switch {
+		case leftErr == nil && rightErr == nil && leftNumber < rightNumber:
+			order = -1
+		case leftErr == nil && rightErr == nil && leftNumber > rightNumber:
+			order = 1
+		case leftErr == nil && rightErr == nil:
+			order = 0
+		default:
+			order = strings.Compare(left.Value, right.Value)
+		}
+	}
+
+	switch operator {
+	case "==":
+		return order == 0, nil
+	case "!=":
+		return order != 0, nil
+	case "<":
+		return order < 0, nil
+	case "<=":
+		return order <= 0, nil
+	case ">":
+		return order > 0, nil
+	default:
+		return order >= 0, nil
+	}
+}
+
+func equalNodes(left *core.Node, right *core.Node) bool {
+	if left == nil || right == nil {
+		return left == right
+	}
+	if left.IsMap() != right.IsMap() || (!left.IsMap() && left.Kind != right.Kind) {
+		return false
+	}
+
+	switch {
+	case left.IsMap():
+		if len(left.Keys) != len(right.Keys) {
+			return false
+		}
+		for _, key := range left.Keys {
+			if !equalNodes(left.Get(key), right.Get(key)) {
+				return false
+			}
+		}
+		return true
+	case left.Kind == core.ListNode:
+		if len(left.Items) != len(right.Items) {
+			return false
+		}
+		for idx := range left.Items {
+			if !equalNodes(left.Items[idx], right.Items[idx]) {
+				return false
+			}
+		}
+		return true
+	default:
+		equal, _ := compare("==", left, right)
+		return equal
+	}
+}
+
+func (expr literal) evaluate(context *evaluation, input *core.Node) ([]*core.Node, error) {
+	return []*core.Node{expr.value}, nil
+}
+
+// functionArities are the functions of the language and their number of
+// arguments
+var functionArities = map[string]int{
+	"select":     1,
+	"keys":       0,
+	"length":     0,
+	"not":        0,
+	"scalars":    0,
+	"key":        0,
+	"path":       0,
+	"section":    0,
+	"comment":    0,
+	"line":       0,
+	"test":       1,
+	"contains":   1,
+	"startswith": 1,
+	"endswith":   1,
+}
+
+func (expr call) evaluate(context *evaluation, input *core.Node) ([]*core.Node, error) {
+	if len(expr.arguments) == 0 {
+		result, err := context.callBuiltin(expr.name, input)
+		if err != nil {
+			return nil, err
+		}
+		if result == nil && expr.name == "scalars" {
+			return []*core.Node{}, nil
+		}
+		return []*core.Node{result}, nil
+	}
+
+	arguments, err := expr.arguments[0].evaluate(context, input)
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*core.Node{}
+	for _, argument := range arguments {
+		if expr.name == "select" {
+			if isTrue(argument) {
+				results = append(results, input)
+			}
+			continue
+		}
+
+		matched, err := matchString(expr.name, input, argument)
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, newBool(matched))
+	}
+
+	return results, nil
+}
+
+func (context *evaluation) callBuiltin(name string, input *core.Node) (*core.Node, error) {
+	switch name {
+	case "keys":
+		return keys(input)
+	case "length":
+		switch {
+		case input == nil:
+			return core.NewScalarNode(core.IntegerScalar, "0"), nil
+		case input.IsMap():
+			return core.NewScalarNode(core.IntegerScalar, strconv.Itoa(len(input.Keys))), nil
+		case input.Kind == core.ListNode:
+			return core.NewScalarNode(core.IntegerScalar, strconv.Itoa(len(input.Items))), nil
+		default:
+			return core.NewScalarNode(core.IntegerScalar, strconv.Itoa(utf8.RuneCountInString(input.Value))), nil
+		}
+	case "not":
+		return newBool(!isTrue(input)), nil
+	case "scalars":
+		if input != nil && input.Kind == core.ScalarNode {
+			return input, nil
+		}
+		return nil, nil
+	}
+
+	if input == nil {
+		return nil, nil
+	}
+
+	switch name {
+	case "key":
+		if len(input.Path) == 0 {
+			return nil, nil
+		}
+		return core.NewScalarNode(core.StringScalar, input.Path[len(input.Path)-1]), nil
+	case "path":
+		if input.Path == nil {
+			return nil, nil
+		}
+		return core.NewScalarNode(core.StringScalar, core.ComposeKey(context.notationStyle, input.Path)), nil
+	case "section":
+		return context.section(input), nil
+	case "comment":
+		return core.NewScalarNode(core.StringScalar, strings.Join(input.Comments, "\n")), nil
+	default:
+		if input.Span.Start == 0 {
+			return nil, nil
+		}
+		return core.NewScalarNode(core.IntegerScalar, strconv.Itoa(input.Span.Start)), nil
+	}
+}
+
+// keys returns the keys of a map in the order of the file, or the indexes
+// of a list
+func keys(input *core.Node) (*core.Node, error) {
+	result := core.NewListNode()
+
+	switch {
+	case input != nil && input.IsMap():
+		for _, key := range input.Keys {
+			result.Items = append(result.Items, core.NewScalarNode(core.StringScalar, key))
+		}
+	case input != nil && input.Kind == core.ListNode:
+		for idx := range input.Items {
+			result.Items = append(result.Items, core.NewScalarNode(core.IntegerScalar, strconv.Itoa(idx)))
+		}
+	default:
+		return nil, errors.New(fmt.Sprintf("Cannot get the keys of %s", describe(input)))
+	}
+
+	return result, nil
+}
+
+// section returns the path of the innermost section containing a node, null
+// for the nodes which are not in a section
+func (context *evaluation) section(input *core.Node) *core.Node {
+	var sectionPath []string
+
+	current := context.document
+	for idx, name := range input.Path {
+		switch {
+		case current.IsMap():
+			current = current.Get(name)
+		case current.Kind == core.ListNode:
+			itemIdx, err := strconv.Atoi(name)
+			if err != nil || itemIdx >= len(current.Items) {
+				current = nil
+			} else {
+				current = current.Items[itemIdx]
+			}
+		default:
+			current = nil
+		}
+		if current == nil {
+			break
+		}
+		if current.Kind == core.SectionNode {
+			sectionPath = input.Path[:idx+1]
+		}
+	}
+
+	if sectionPath == nil {
+		return nil
+	}
+
+	return core.NewScalarNode(core.StringScalar, core.ComposeKey(context.notationStyle, sectionPath))
+}
+
+func matchString(name string, input *core.Node, argument *core.Node) (bool, error) {
+	if input == nil {
+		return false, nil
+	}
+	if input.Kind != core.ScalarNode || argument == nil || argument.Kind != core.ScalarNode {
+		return false, errors.New(fmt.Sprintf("Cannot apply %s to %s with %s", name, describe(input), describe(argument)))
+	}
+
+	switch name {
+	case "test":
+		compiled, err := regexp.Compile(argument.Value)
+		if err != nil {
+			return false, errors.New(fmt.Sprintf("Invalid regexp %s", argument.Value))
+		}
+		return compiled.MatchString(input.Value), nil
+	case "contains":
+		return strings.Contains(input.Value, argument.Value), nil
+	case "startswith":
+		return strings.HasPrefix(input.Value, argument.Value), nil
+	default:
+		return strings.HasSuffix(input.Value, argument.Value), nil
+	}
+}
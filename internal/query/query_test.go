@@ -0,0 +1,128 @@
+package query
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/plugins/ini"
+)
+
+const INI_FILE_PATH = "../../data/ini/ini.ini"
+
+func values(results []*core.Node) []string {
+	actual := []string{}
+	for _, result := range results {
+		if result == nil {
+			actual = append(actual, "null")
+			continue
+		}
+		if result.Kind == core.ListNode {
+			actual = append(actual, values(result.Items)...)
+			continue
+		}
+		actual = append(actual, result.Value)
+	}
+
+	return actual
+}
+
+func TestRun(t *testing.T) {
+	document, err := ini.IniConfigurator{}.ReadDocument(INI_FILE_PATH, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataProvider := []struct {
+		query    string
+		expected []string
+	}{
+		{".core.editor", []string{"vim"}},
+		{`.core."editor"`, []string{"vim"}},
+		{`.["core"]["editor"]`, []string{"vim"}},
+		{".core.missing", []string{"null"}},
+		{".alias | keys", []string{"co", "br", "ci", "st"}},
+		{".alias | length", []string{"4"}},
+		{"keys | length", []string{"5"}},
+		{".alias[]", []string{"checkout", "branch", "commit", "status"}},
+		{".[] | select(.fileMode == true) | key", []string{"core"}},
+		{".[] | select(.editor?) | section", []string{"core"}},
+		{".. | scalars | select(comment != \"\") | path", []string{"core.editor"}},
+		{".. | scalars | select(test(\"^c\")) | key", []string{"co", "ci"}},
+		{".. | scalars | select(line >= 18) | path", []string{"alias.st", "push.default"}},
+		{".core | .[] | select(. == false or . == \"vim\") | key", []string{"editor", "ignoreCase"}},
+		{".user.name | startswith(\"U\") and endswith(\"r\")", []string{"true"}},
+		{".orphan_key | section", []string{"null"}},
+		{".core.editor | section", []string{"core"}},
+		{".core | not", []string{"false"}},
+		{"1 < 10", []string{"true"}},
+		{"\"1\" < \"10.5\"", []string{"true"}},
+	}
+
+	for _, element := range dataProvider {
+		t.Run(element.query, func(t *testing.T) {
+			parsed, err := Parse(element.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			results, err := parsed.Run(document, core.DotNotation)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if actual := values(results); !reflect.DeepEqual(actual, element.expected) {
+				t.Errorf("Expected %v, got %v", element.expected, actual)
+			}
+		})
+	}
+}
+
+func TestParseError(t *testing.T) {
+	dataProvider := map[string]string{
+		".[":          "Invalid query at position 3: unexpected end of the query",
+		"foo":         "Invalid query at position 1: unknown function foo",
+		".a | ":       "Invalid query at position 6: unexpected end of the query",
+		"select(.a":   "Invalid query at position 10: unexpected end of the query",
+		`.a == "b`:    "Invalid query at position 7: missing closing quote",
+		".a .b":       `Invalid query at position 4: unexpected "."`,
+		".a = 1":      `Invalid query at position 4: unexpected "="`,
+		"select(.a))": `Invalid query at position 11: unexpected ")"`,
+	}
+
+	for query, expected := range dataProvider {
+		t.Run(query, func(t *testing.T) {
+			_, err := Parse(query)
+			if err == nil || err.Error() != expected {
+				t.Errorf("Expected the error %q, got %v", expected, err)
+			}
+		})
+	}
+}
+
+func TestRunError(t *testing.T) {
+	document, err := ini.IniConfigurator{}.ReadDocument(INI_FILE_PATH, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dataProvider := map[string]string{
+		".core.editor[]":      `Cannot iterate over the value "vim"`,
+		".core[0]":            "Cannot index a map with 0",
+		".core < 1":           "Cannot compare a map and the value \"1\"",
+		".core.editor | keys": `Cannot get the keys of the value "vim"`,
+	}
+
+	for query, expected := range dataProvider {
+		t.Run(query, func(t *testing.T) {
+			parsed, err := Parse(query)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = parsed.Run(document, core.DotNotation)
+			if err == nil || err.Error() != expected {
+				t.Errorf("Expected the error %q, got %v", expected, err)
+			}
+		})
+	}
+}
@@ -0,0 +1,94 @@
+package source
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/einenlum/edicon/internal/core"
+)
+
+// Document finds the lines and the comments of the parts of a file, to build
+// its document tree
+type Document struct {
+	Content  string
+	Comments []Span
+	// Markers are the characters starting a comment, and Closing the end of
+	// the comments which have one (-->), both removed from their text
+	Markers string
+	Closing string
+	// lineStarts are the offsets of the start of each line
+	lineStarts []int
+}
+
+func NewDocument(content string, comments []Span, markers string) *Document {
+	lineStarts := []int{0}
+	for idx, char := range content {
+		if char == '\n' {
+			lineStarts = append(lineStarts, idx+1)
+		}
+	}
+
+	return &Document{Content: content, Comments: comments, Markers: markers, lineStarts: lineStarts}
+}
+
+// Line returns the line of an offset, starting at 1
+func (document *Document) Line(offset int) int {
+	return sort.Search(len(document.lineStarts), func(idx int) bool {
+		return document.lineStarts[idx] > offset
+	})
+}
+
+// Lines returns the lines of a span
+func (document *Document) Lines(span Span) core.Span {
+	end := span.End
+	if end > span.Start {
+		end--
+	}
+
+	return core.Span{Start: document.Line(span.Start), End: document.Line(end)}
+}
+
+// startsLine returns true if only spaces precede an offset on its line
+func (document *Document) startsLine(offset int) bool {
+	lineStart := document.lineStarts[document.Line(offset)-1]
+
+	return strings.TrimSpace(document.Content[lineStart:offset]) == ""
+}
+
+func (document *Document) text(comment Span) string {
+	text := strings.TrimLeft(document.Content[comment.Start:comment.End], document.Markers)
+
+	return strings.TrimSpace(strings.TrimSuffix(text, document.Closing))
+}
+
+// AttachedComments returns the text of the comments attached to a span: the
+// comment lines right above it and the comment following it on its last
+// line
+func (document *Document) AttachedComments(span Span) []string {
+	comments := []string{}
+
+	before := sort.Search(len(document.Comments), func(idx int) bool {
+		return document.Comments[idx].Start >= span.Start
+	})
+	line := document.Line(span.Start)
+	for idx := before - 1; idx >= 0; idx-- {
+		comment := document.Comments[idx]
+		if document.Lines(comment).End != line-1 || !document.startsLine(comment.Start) {
+			break
+		}
+		comments = append([]string{document.text(comment)}, comments...)
+		line = document.Line(comment.Start)
+	}
+
+	after := sort.Search(len(document.Comments), func(idx int) bool {
+		return document.Comments[idx].Start >= span.End
+	})
+	if after < len(document.Comments) {
+		comment := document.Comments[after]
+		if document.Line(comment.Start) == document.Lines(span).End {
+			comments = append(comments, document.text(comment))
+		}
+	}
+
+	return comments
+}
@@ -0,0 +1,30 @@
+package source
+
+import "strings"
+
+// Span is the position of a part of a file, as offsets in its content
+type Span struct {
+	Start int
+	End   int
+}
+
+// RemoveComments removes the comments from a content, then the lines left
+// empty and the spaces left at the end of the lines
+func RemoveComments(content string, comments []Span) string {
+	output := ""
+	last := 0
+	for _, comment := range comments {
+		output += content[last:comment.Start]
+		last = comment.End
+	}
+	output += content[last:]
+
+	lines := []string{}
+	for _, line := range strings.Split(output, "\n") {
+		if strings.TrimSpace(line) != "" {
+			lines = append(lines, strings.TrimRight(line, " \t"))
+		}
+	}
+
+	return strings.Join(lines, "\n") + "\n"
+}
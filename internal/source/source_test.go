@@ -0,0 +1,13 @@
+package source
+
+import "testing"
+
+func TestRemoveComments(t *testing.T) {
+	content := "# comment\nworker_processes 4; # inline\n\n  # indented\nevents {\n}\n"
+	comments := []Span{{Start: 0, End: 9}, {Start: 30, End: 38}, {Start: 42, End: 52}}
+
+	expected := "worker_processes 4;\nevents {\n}\n"
+	if output := RemoveComments(content, comments); output != expected {
+		t.Errorf("Expected %q, got %q", expected, output)
+	}
+}
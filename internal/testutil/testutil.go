@@ -0,0 +1,71 @@
+package testutil
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/einenlum/edicon/internal/core"
+	"github.com/einenlum/edicon/internal/io"
+)
+
+// AssertEditedOutput fails the test if the edit failed, or if the edited
+// configuration is not the file with the first occurrence of old replaced by
+// new, the rest of the file being left untouched
+func AssertEditedOutput(t *testing.T, filePath string, config core.Configuration, err error, old string, new string) {
+	t.Helper()
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	original, err := io.GetFileContents(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(original, old) {
+		t.Fatal("The fixture does not contain", old)
+	}
+	expected := strings.Replace(original, old, new, 1)
+
+	output, err := config.OutputFile(core.FullOutput)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if output != expected {
+		t.Fatal(fmt.Sprintf("Expected:\n%s\nGot:\n%s", expected, output))
+	}
+}
+
+// AssertDocumentValues fails the test if the document tree of the file does
+// not have the expected scalars, designated by the key listing them, or if
+// one of its scalars has no path or no lines
+func AssertDocumentValues(t *testing.T, reader core.DocumentReader, filePath string, expected map[string]string) {
+	t.Helper()
+
+	document, err := reader.ReadDocument(filePath, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values := map[string]string{}
+	document.Walk(func(node *core.Node) error {
+		if node.Kind != core.ScalarNode {
+			return nil
+		}
+		key := core.ComposeKey(core.DotNotation, node.Path)
+		if node.Path == nil || node.Span.Start == 0 {
+			t.Errorf("Expected %s to have a path and lines, got %v on lines %v", key, node.Path, node.Span)
+		}
+		values[key] = node.Value
+
+		return nil
+	})
+
+	for key, value := range expected {
+		if actual, ok := values[key]; !ok || actual != value {
+			t.Errorf("Expected %s to be %q, got %q", key, value, actual)
+		}
+	}
+}
@@ -0,0 +1,12 @@
+//go:build !unix
+
+package transaction
+
+import (
+	"os"
+)
+
+// fileOwner returns -1 as files have no user and group ids
+func fileOwner(info os.FileInfo) (int, int) {
+	return -1, -1
+}
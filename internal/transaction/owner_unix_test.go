@@ -0,0 +1,57 @@
+//go:build unix
+
+package transaction
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func assertOwner(t *testing.T, filePath string, uid int, gid int) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if actualUid, actualGid := fileOwner(info); actualUid != uid || actualGid != gid {
+		t.Errorf("Expected %s to be owned by %d:%d, got %d:%d", filePath, uid, gid, actualUid, actualGid)
+	}
+}
+
+func TestKeepOwner(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.ini": "a = 1\n"})
+	filePath := filepath.Join(dir, "a.ini")
+
+	uid, gid := os.Getuid(), os.Getgid()
+	if uid == 0 {
+		// Only root can give a file to another user
+		uid, gid = 1234, 5678
+		if err := os.Chown(filePath, uid, gid); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tx, err := Begin("edicon ini set -w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stage(t, tx, filePath, "a = 2\n")
+	if tx.Entries[0].Uid != uid || tx.Entries[0].Gid != gid {
+		t.Errorf("Expected the owner %d:%d to be recorded, got %d:%d", uid, gid, tx.Entries[0].Uid, tx.Entries[0].Gid)
+	}
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	assertOwner(t, filePath, uid, gid)
+
+	if os.Getuid() == 0 {
+		if err := os.Chown(filePath, 0, 0); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tx.Rollback(true); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, filePath, "a = 1\n")
+	assertOwner(t, filePath, uid, gid)
+}
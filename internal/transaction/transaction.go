@@ -0,0 +1,513 @@
+package transaction
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/einenlum/edicon/internal/io"
+)
+
+type Status string
+
+const (
+	Pending    Status = "pending"
+	Committed  Status = "committed"
+	RolledBack Status = "rolled back"
+)
+
+// Entry is a file written by a transaction. The original content of the
+// file is kept in the journal, so that it can be restored.
+type Entry struct {
+	Path string `json:"path"`
+	// Original is the file of the journal holding the original content, empty
+	// if the file is created by the transaction
+	Original string      `json:"original"`
+	Mode     os.FileMode `json:"mode"`
+	// Uid and Gid are the owner of the original file, kept when it is
+	// replaced, -1 if unknown
+	Uid int `json:"uid"`
+	Gid int `json:"gid"`
+	// Written is the SHA-256 of the content written by the transaction
+	Written string `json:"written"`
+	// Staged is the temporary file holding the new content until it is
+	// renamed over the file
+	Staged string `json:"staged"`
+}
+
+// Transaction writes several files at once: the new contents are staged in
+// temporary files next to the files, then renamed over them. If a rename
+// fails, the files already renamed are restored from the journal kept under
+// the state directory, which also allows to roll back a committed
+// transaction later. The journal is locked as long as the transaction is in
+// progress, so that a pending transaction whose journal is not locked is
+// known to be interrupted.
+type Transaction struct {
+	ID      string    `json:"id"`
+	Time    time.Time `json:"time"`
+	Command string    `json:"command"`
+	Status  Status    `json:"status"`
+	Entries []*Entry  `json:"files"`
+	dir     string
+	lock    *io.Lock
+}
+
+// inProgressError is the error of a transaction whose journal is locked by
+// the process writing it
+type inProgressError struct {
+	id string
+}
+
+func (err inProgressError) Error() string {
+	return fmt.Sprintf("Transaction %s is in progress", err.id)
+}
+
+// StateDir returns the directory where edicon keeps its state:
+// $EDICON_STATE_DIR, or $XDG_STATE_HOME/edicon, or ~/.local/state/edicon
+func StateDir() (string, error) {
+	if dir := os.Getenv("EDICON_STATE_DIR"); dir != "" {
+		return dir, nil
+	}
+	if dir := os.Getenv("XDG_STATE_HOME"); dir != "" {
+		return filepath.Join(dir, "edicon"), nil
+	}
+
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(home, ".local", "state", "edicon"), nil
+}
+
+func transactionsDir() (string, error) {
+	stateDir, err := StateDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(stateDir, "transactions"), nil
+}
+
+// Begin starts a transaction, the command being recorded in its journal
+func Begin(command string) (*Transaction, error) {
+	dir, err := transactionsDir()
+	if err != nil {
+		return nil, err
+	}
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	transaction := &Transaction{Time: now, Command: command, Status: Pending, Entries: []*Entry{}}
+	for attempt := 0; ; attempt++ {
+		transaction.ID = now.Format("20060102-150405.000000")
+		if attempt > 0 {
+			transaction.ID += "-" + strconv.Itoa(attempt)
+		}
+		transaction.dir = filepath.Join(dir, transaction.ID)
+		err := os.Mkdir(transaction.dir, 0700)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+	}
+
+	lock, err := transaction.tryLock()
+	if err != nil {
+		return nil, err
+	}
+	transaction.lock = lock
+
+	return transaction, transaction.save()
+}
+
+func (transaction *Transaction) journalPath() string {
+	return filepath.Join(transaction.dir, "journal.json")
+}
+
+// tryLock takes the lock of the journal, failing with an inProgressError if
+// it is held by another process
+func (transaction *Transaction) tryLock() (*io.Lock, error) {
+	lock, err := io.TryLockFile(transaction.journalPath())
+	if errors.Is(err, io.ErrLocked) {
+		return nil, inProgressError{transaction.ID}
+	}
+
+	return lock, err
+}
+
+// unlock releases the lock of the journal, if it is held
+func (transaction *Transaction) unlock() {
+	if transaction.lock != nil {
+		transaction.lock.Unlock()
+		transaction.lock = nil
+	}
+}
+
+func (transaction *Transaction) save() error {
+	content, err := json.MarshalIndent(transaction, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(transaction.journalPath(), content, 0600)
+}
+
+func hash(content []byte) string {
+	sum := sha256.Sum256(content)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Stage stages the new content of a file, written by write to a temporary
+// file next to it. Symbolic links are followed, so that the file they point
+// to is written instead of being replaced. A file which does not exist is
+// created.
+func (transaction *Transaction) Stage(filePath string, write func(tempFilePath string) error) error {
+	path := filePath
+	if resolved, err := filepath.EvalSymlinks(filePath); err == nil {
+		path = resolved
+	}
+	path, err := filepath.Abs(path)
+	if err != nil {
+		return err
+	}
+	for _, entry := range transaction.Entries {
+		if entry.Path == path {
+			return errors.New(fmt.Sprintf("%s is already written by the transaction", filePath))
+		}
+	}
+
+	entry := &Entry{Path: path, Mode: 0644, Uid: -1, Gid: -1}
+	info, err := os.Stat(path)
+	switch {
+	case err == nil:
+		original, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		entry.Original = "original-" + strconv.Itoa(len(transaction.Entries))
+		entry.Mode = info.Mode().Perm()
+		entry.Uid, entry.Gid = fileOwner(info)
+		if err := os.WriteFile(filepath.Join(transaction.dir, entry.Original), original, 0600); err != nil {
+			return err
+		}
+	case !os.IsNotExist(err):
+		return err
+	}
+
+	staged, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".edicon-*")
+	if err != nil {
+		return err
+	}
+	staged.Close()
+	entry.Staged = staged.Name()
+	transaction.Entries = append(transaction.Entries, entry)
+
+	if err := write(entry.Staged); err != nil {
+		return err
+	}
+	if err := os.Chmod(entry.Staged, entry.Mode); err != nil {
+		return err
+	}
+	if err := keepOwner(entry.Staged, entry); err != nil {
+		return errors.New(fmt.Sprintf("Cannot keep the owner of %s: %s", filePath, err.Error()))
+	}
+	content, err := os.ReadFile(entry.Staged)
+	if err != nil {
+		return err
+	}
+	entry.Written = hash(content)
+
+	return transaction.save()
+}
+
+// Abort removes the staged files and the journal of a transaction which is
+// not committed
+func (transaction *Transaction) Abort() {
+	for _, entry := range transaction.Entries {
+		os.Remove(entry.Staged)
+	}
+	transaction.unlock()
+	os.RemoveAll(transaction.dir)
+}
+
+// Commit renames the staged files over the files. If a rename fails, the
+// files already renamed are restored and the transaction is rolled back. If
+// they cannot all be restored, the transaction is left pending, to be rolled
+// back later.
+func (transaction *Transaction) Commit() error {
+	defer transaction.unlock()
+
+	for idx, entry := range transaction.Entries {
+		if err := os.Rename(entry.Staged, entry.Path); err != nil {
+			failures := []string{}
+			for _, renamed := range transaction.Entries[:idx] {
+				if err := transaction.restore(renamed); err != nil {
+					failures = append(failures, fmt.Sprintf("%s: %s", renamed.Path, err.Error()))
+				}
+			}
+			for _, staged := range transaction.Entries[idx:] {
+				os.Remove(staged.Staged)
+			}
+
+			if len(failures) > 0 {
+				return errors.New(fmt.Sprintf(
+					"Cannot write %s: %s, and the files already written cannot be restored, use rollback %s:\n  %s",
+					entry.Path, err.Error(), transaction.ID, strings.Join(failures, "\n  "),
+				))
+			}
+
+			transaction.Status = RolledBack
+			transaction.save()
+
+			return errors.New(fmt.Sprintf("Cannot write %s, no file was changed: %s", entry.Path, err.Error()))
+		}
+	}
+
+	transaction.Status = Committed
+
+	return transaction.save()
+}
+
+// restore writes back the original content of a file, through a temporary
+// file renamed over it, or removes a file created by the transaction
+func (transaction *Transaction) restore(entry *Entry) error {
+	if entry.Original == "" {
+		return os.Remove(entry.Path)
+	}
+
+	original, err := os.ReadFile(filepath.Join(transaction.dir, entry.Original))
+	if err != nil {
+		return err
+	}
+
+	temp, err := os.CreateTemp(filepath.Dir(entry.Path), "."+filepath.Base(entry.Path)+".edicon-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(temp.Name())
+	_, err = temp.Write(original)
+	temp.Close()
+	if err != nil {
+		return err
+	}
+	if err := os.Chmod(temp.Name(), entry.Mode); err != nil {
+		return err
+	}
+	if err := keepOwner(temp.Name(), entry); err != nil {
+		return err
+	}
+
+	return os.Rename(temp.Name(), entry.Path)
+}
+
+// keepOwner gives a file replacing the one of an entry its owner, when it is
+// known and differs from the one of the process writing it
+func keepOwner(path string, entry *Entry) error {
+	if entry.Uid < 0 && entry.Gid < 0 {
+		return nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return err
+	}
+	if uid, gid := fileOwner(info); uid == entry.Uid && gid == entry.Gid {
+		return nil
+	}
+
+	return os.Chown(path, entry.Uid, entry.Gid)
+}
+
+// Rollback restores the files of a committed transaction, or of a pending
+// transaction which was interrupted, whose files are restored only if they
+// were renamed. It fails if a file was changed since, unless force is true.
+func (transaction *Transaction) Rollback(force bool) error {
+	lock, err := transaction.tryLock()
+	if err != nil {
+		return err
+	}
+	defer lock.Unlock()
+
+	// the journal is read again, as it may have been written by the process
+	// which held its lock
+	current, err := Load(transaction.ID)
+	if err != nil {
+		return err
+	}
+	transaction.Status, transaction.Entries = current.Status, current.Entries
+
+	if transaction.Status != Committed && transaction.Status != Pending {
+		return errors.New(fmt.Sprintf("Transaction %s is %s and cannot be rolled back", transaction.ID, transaction.Status))
+	}
+
+	restored := []*Entry{}
+	changed := []string{}
+	for _, entry := range transaction.Entries {
+		content, err := os.ReadFile(entry.Path)
+		switch {
+		case err == nil && hash(content) == entry.Written:
+			restored = append(restored, entry)
+		case transaction.Status == Pending && transaction.isOriginal(entry):
+			// not renamed before the transaction was interrupted
+		case force:
+			restored = append(restored, entry)
+		default:
+			changed = append(changed, entry.Path)
+		}
+	}
+	if len(changed) > 0 {
+		return errors.New(fmt.Sprintf(
+			"Files were changed since transaction %s, use --force to restore them anyway:\n  %s",
+			transaction.ID, strings.Join(changed, "\n  "),
+		))
+	}
+
+	for _, entry := range restored {
+		if err := transaction.restore(entry); err != nil {
+			return errors.New(fmt.Sprintf("Cannot restore %s: %s", entry.Path, err.Error()))
+		}
+	}
+	if transaction.Status == Pending {
+		for _, entry := range transaction.Entries {
+			os.Remove(entry.Staged)
+		}
+	}
+
+	transaction.Status = RolledBack
+
+	return transaction.save()
+}
+
+// isOriginal tells if a file of an entry is still the original one: a file
+// created by the transaction does not exist yet
+func (transaction *Transaction) isOriginal(entry *Entry) bool {
+	content, err := os.ReadFile(entry.Path)
+	if entry.Original == "" {
+		return os.IsNotExist(err)
+	}
+	if err != nil {
+		return false
+	}
+	original, err := os.ReadFile(filepath.Join(transaction.dir, entry.Original))
+
+	return err == nil && hash(content) == hash(original)
+}
+
+// Recover rolls back the pending transactions which were interrupted, e.g.
+// by the death of the process between two renames, returning those rolled
+// back. The transactions which cannot be rolled back are returned as
+// errors.
+func Recover() ([]*Transaction, []error) {
+	transactions, err := List()
+	if err != nil {
+		return nil, []error{err}
+	}
+
+	recovered := []*Transaction{}
+	errs := []error{}
+	for _, transaction := range transactions {
+		if transaction.Status != Pending {
+			continue
+		}
+		err := transaction.Rollback(false)
+		if _, inProgress := err.(inProgressError); inProgress {
+			continue
+		}
+		if err != nil {
+			errs = append(errs, errors.New(fmt.Sprintf("Transaction %s was interrupted and cannot be rolled back: %s", transaction.ID, err.Error())))
+			continue
+		}
+		recovered = append(recovered, transaction)
+	}
+
+	return recovered, errs
+}
+
+// Load loads the journal of a transaction
+func Load(id string) (*Transaction, error) {
+	dir, err := transactionsDir()
+	if err != nil {
+		return nil, err
+	}
+	if id == "" || strings.ContainsAny(id, `/\`) || strings.HasPrefix(id, ".") {
+		return nil, errors.New(fmt.Sprintf("Invalid transaction %s", id))
+	}
+
+	content, err := os.ReadFile(filepath.Join(dir, id, "journal.json"))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, errors.New(fmt.Sprintf("Transaction %s not found", id))
+		}
+		return nil, err
+	}
+
+	transaction := &Transaction{dir: filepath.Join(dir, id)}
+	if err := json.Unmarshal(content, transaction); err != nil {
+		return nil, errors.New(fmt.Sprintf("Invalid journal of transaction %s: %s", id, err.Error()))
+	}
+
+	return transaction, nil
+}
+
+// List returns the transactions, the oldest first
+func List() ([]*Transaction, error) {
+	dir, err := transactionsDir()
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return []*Transaction{}, nil
+		}
+		return nil, err
+	}
+
+	transactions := []*Transaction{}
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		transaction, err := Load(entry.Name())
+		if err != nil {
+			continue
+		}
+		transactions = append(transactions, transaction)
+	}
+	sort.SliceStable(transactions, func(i, j int) bool {
+		return transactions[i].Time.Before(transactions[j].Time)
+	})
+
+	return transactions, nil
+}
+
+// Last returns the last committed transaction
+func Last() (*Transaction, error) {
+	transactions, err := List()
+	if err != nil {
+		return nil, err
+	}
+
+	for idx := len(transactions) - 1; idx >= 0; idx-- {
+		if transactions[idx].Status == Committed {
+			return transactions[idx], nil
+		}
+	}
+
+	return nil, errors.New("No transaction to roll back")
+}
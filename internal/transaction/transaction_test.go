@@ -0,0 +1,232 @@
+package transaction
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeFiles(t *testing.T, contents map[string]string) string {
+	dir := t.TempDir()
+	t.Setenv("EDICON_STATE_DIR", filepath.Join(dir, "state"))
+
+	for name, content := range contents {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0640); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return dir
+}
+
+func assertContent(t *testing.T, filePath string, expected string) {
+	content, err := os.ReadFile(filePath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(content) != expected {
+		t.Errorf("Expected %s to contain %q, got %q", filePath, expected, string(content))
+	}
+}
+
+func stage(t *testing.T, tx *Transaction, filePath string, content string) {
+	err := tx.Stage(filePath, func(tempFilePath string) error {
+		return os.WriteFile(tempFilePath, []byte(content), 0600)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestCommitAndRollback(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.ini": "a = 1\n", "b.ini": "b = 1\n"})
+
+	tx, err := Begin("edicon ini set -w")
+	if err != nil {
+		t.Fatal(err)
+	}
+	stage(t, tx, filepath.Join(dir, "a.ini"), "a = 2\n")
+	stage(t, tx, filepath.Join(dir, "b.ini"), "b = 2\n")
+	assertContent(t, filepath.Join(dir, "a.ini"), "a = 1\n")
+
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, filepath.Join(dir, "a.ini"), "a = 2\n")
+	assertContent(t, filepath.Join(dir, "b.ini"), "b = 2\n")
+
+	info, _ := os.Stat(filepath.Join(dir, "a.ini"))
+	if info.Mode().Perm() != 0640 {
+		t.Errorf("Expected the mode of the file to be kept, got %v", info.Mode())
+	}
+
+	last, err := Last()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if last.ID != tx.ID || last.Command != "edicon ini set -w" || len(last.Entries) != 2 {
+		t.Errorf("Expected the last transaction to be %v, got %v", tx, last)
+	}
+
+	if err := last.Rollback(false); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, filepath.Join(dir, "a.ini"), "a = 1\n")
+	assertContent(t, filepath.Join(dir, "b.ini"), "b = 1\n")
+
+	if err := last.Rollback(false); err == nil {
+		t.Error("Expected a transaction rolled back not to be rolled back again")
+	}
+	if _, err := Last(); err == nil {
+		t.Error("Expected no transaction to roll back")
+	}
+}
+
+func TestRollbackChangedFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.ini": "a = 1\n"})
+
+	tx, _ := Begin("")
+	stage(t, tx, filepath.Join(dir, "a.ini"), "a = 2\n")
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	os.WriteFile(filepath.Join(dir, "a.ini"), []byte("a = 3\n"), 0640)
+
+	loaded, err := Load(tx.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := loaded.Rollback(false); err == nil {
+		t.Error("Expected a changed file not to be restored")
+	}
+	assertContent(t, filepath.Join(dir, "a.ini"), "a = 3\n")
+
+	if err := loaded.Rollback(true); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, filepath.Join(dir, "a.ini"), "a = 1\n")
+}
+
+func TestFailedCommit(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.ini": "a = 1\n", "b.ini": "b = 1\n"})
+
+	tx, _ := Begin("")
+	stage(t, tx, filepath.Join(dir, "a.ini"), "a = 2\n")
+	stage(t, tx, filepath.Join(dir, "b.ini"), "b = 2\n")
+	os.Remove(tx.Entries[1].Staged)
+
+	if err := tx.Commit(); err == nil {
+		t.Fatal("Expected the commit to fail")
+	}
+	assertContent(t, filepath.Join(dir, "a.ini"), "a = 1\n")
+	assertContent(t, filepath.Join(dir, "b.ini"), "b = 1\n")
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 3 {
+		t.Errorf("Expected the staged files to be removed, got %v", entries)
+	}
+}
+
+func TestAbort(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.ini": "a = 1\n"})
+
+	tx, _ := Begin("")
+	stage(t, tx, filepath.Join(dir, "a.ini"), "a = 2\n")
+	tx.Abort()
+
+	assertContent(t, filepath.Join(dir, "a.ini"), "a = 1\n")
+	if _, err := Load(tx.ID); err == nil {
+		t.Error("Expected the journal of an aborted transaction to be removed")
+	}
+	if transactions, _ := List(); len(transactions) != 0 {
+		t.Errorf("Expected no transaction, got %v", transactions)
+	}
+}
+
+func TestCreatedFile(t *testing.T) {
+	dir := writeFiles(t, map[string]string{})
+
+	tx, _ := Begin("")
+	stage(t, tx, filepath.Join(dir, "new.conf"), "a = 1\n")
+	if err := tx.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	assertContent(t, filepath.Join(dir, "new.conf"), "a = 1\n")
+
+	if err := tx.Rollback(false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "new.conf")); !os.IsNotExist(err) {
+		t.Errorf("Expected the file created by the transaction to be removed, got %v", err)
+	}
+}
+
+func TestRecoverInterrupted(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.ini": "a = 1\n", "b.ini": "b = 1\n"})
+
+	tx, _ := Begin("")
+	stage(t, tx, filepath.Join(dir, "a.ini"), "a = 2\n")
+	stage(t, tx, filepath.Join(dir, "b.ini"), "b = 2\n")
+	stage(t, tx, filepath.Join(dir, "new.conf"), "c = 2\n")
+
+	running, _ := Begin("")
+	stage(t, running, filepath.Join(dir, "running.conf"), "d = 2\n")
+	defer running.Abort()
+
+	// the process dies after renaming the first file
+	if err := os.Rename(tx.Entries[0].Staged, tx.Entries[0].Path); err != nil {
+		t.Fatal(err)
+	}
+	tx.unlock()
+
+	recovered, errs := Recover()
+	if len(errs) > 0 {
+		t.Fatal(errs)
+	}
+	if len(recovered) != 1 || recovered[0].ID != tx.ID {
+		t.Fatalf("Expected only the interrupted transaction to be recovered, got %v", recovered)
+	}
+	assertContent(t, filepath.Join(dir, "a.ini"), "a = 1\n")
+	assertContent(t, filepath.Join(dir, "b.ini"), "b = 1\n")
+	if _, err := os.Stat(filepath.Join(dir, "new.conf")); !os.IsNotExist(err) {
+		t.Errorf("Expected the file not yet created to be left missing, got %v", err)
+	}
+	for _, entry := range tx.Entries {
+		if _, err := os.Stat(entry.Staged); !os.IsNotExist(err) {
+			t.Errorf("Expected the staged file %s to be removed, got %v", entry.Staged, err)
+		}
+	}
+
+	loaded, _ := Load(tx.ID)
+	if loaded.Status != RolledBack {
+		t.Errorf("Expected the interrupted transaction to be rolled back, got %s", loaded.Status)
+	}
+	if err := running.Rollback(false); err == nil {
+		t.Error("Expected a transaction in progress not to be rolled back")
+	}
+}
+
+func TestFailedCommitRestore(t *testing.T) {
+	dir := writeFiles(t, map[string]string{"a.ini": "a = 1\n", "b.ini": "b = 1\n"})
+
+	tx, _ := Begin("")
+	stage(t, tx, filepath.Join(dir, "a.ini"), "a = 2\n")
+	stage(t, tx, filepath.Join(dir, "b.ini"), "b = 2\n")
+	os.Remove(tx.Entries[1].Staged)
+	os.Remove(filepath.Join(tx.dir, tx.Entries[0].Original))
+
+	err := tx.Commit()
+	if err == nil || !strings.Contains(err.Error(), "cannot be restored") {
+		t.Fatalf("Expected the failed restore to be reported, got %v", err)
+	}
+	assertContent(t, filepath.Join(dir, "a.ini"), "a = 2\n")
+
+	loaded, _ := Load(tx.ID)
+	if loaded.Status != Pending {
+		t.Errorf("Expected the transaction to be left pending, got %s", loaded.Status)
+	}
+	if _, errs := Recover(); len(errs) != 1 {
+		t.Errorf("Expected the transaction not to be recovered, got %v", errs)
+	}
+}